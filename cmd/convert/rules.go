@@ -0,0 +1,233 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+)
+
+var ErrNoTargetVersion = errors.New("no target version provided")
+
+// NewConvertRulesCommand represents the "convert rules" command.
+func NewConvertRulesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules [path to ruleset]",
+		Short: "Converts heimdall's ruleset to the given version",
+		Long: "Converts heimdall's ruleset to the given version. The format (JSON or YAML) of the source is preserved.\n" +
+			"Comments are not. Supported versions are " + strings.Join(config.SupportedRuleSetVersions(), ", ") + ".",
+		Args:    cobra.ExactArgs(1),
+		Example: "heimdall convert rules --to " + config.CurrentRuleSetVersion + " myruleset.yaml > myruleset-new.yaml",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := convertRuleSet(cmd, args); err != nil {
+				cmd.PrintErrf("%v\n", err)
+
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.PersistentFlags().String("to", "",
+		"The version to convert the ruleset to")
+	cmd.PersistentFlags().StringP("output", "o", "",
+		"Path to the file to write the converted ruleset to. Defaults to stdout")
+
+	return cmd
+}
+
+func convertRuleSet(cmd *cobra.Command, args []string) error {
+	targetVersion, _ := cmd.Flags().GetString("to")
+	if len(targetVersion) == 0 {
+		return ErrNoTargetVersion
+	}
+
+	raw, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	// env variables are not substituted to keep the references to these in the converted rule set
+	ruleSet, err := config.ParseRules("application/yaml", bytes.NewReader(raw), false)
+	if err != nil {
+		return err
+	}
+
+	converted, err := config.ConvertRuleSet(ruleSet, targetVersion)
+	if err != nil {
+		return err
+	}
+
+	if err = removeDefaultStrategies(raw, converted); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+
+	if outputPath, _ := cmd.Flags().GetString("output"); len(outputPath) != 0 {
+		outFile, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+
+		defer outFile.Close()
+
+		out = outFile
+	}
+
+	return writeRuleSet(out, converted, strings.EqualFold(filepath.Ext(args[0]), ".json"))
+}
+
+// removeDefaultStrategies clears the matching strategy of the rules, which do not define it
+// in the source rule set, so that the default set while parsing the rule set is not written
+// to the converted one.
+func removeDefaultStrategies(raw []byte, ruleSet *config.RuleSet) error {
+	var source struct {
+		Rules []struct {
+			ID    string `yaml:"id"`
+			Match any    `yaml:"match"`
+		} `yaml:"rules"`
+	}
+
+	if err := yaml.Unmarshal(raw, &source); err != nil {
+		return fmt.Errorf("failed to parse rule set: %w", err)
+	}
+
+	withStrategy := make(map[string]bool, len(source.Rules))
+
+	for _, rule := range source.Rules {
+		if match, ok := rule.Match.(map[string]any); ok {
+			_, withStrategy[rule.ID] = match["strategy"]
+		}
+	}
+
+	for idx := range ruleSet.Rules {
+		if !withStrategy[ruleSet.Rules[idx].ID] {
+			ruleSet.Rules[idx].RuleMatcher.Strategy = ""
+		}
+	}
+
+	return nil
+}
+
+func writeRuleSet(out io.Writer, ruleSet *config.RuleSet, asJSON bool) error {
+	const indent = 2
+
+	// the yaml representation of the rule set omits all properties not set
+	buf := &bytes.Buffer{}
+
+	yamlEnc := yaml.NewEncoder(buf)
+	yamlEnc.SetIndent(indent)
+
+	if err := yamlEnc.Encode(ruleSet); err != nil {
+		return fmt.Errorf("failed to marshal rule set: %w", err)
+	}
+
+	if !asJSON {
+		_, err := out.Write(buf.Bytes())
+
+		return err
+	}
+
+	// the rule set is encoded from its yaml representation to keep the order of the properties
+	var node yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &node); err != nil {
+		return fmt.Errorf("failed to marshal rule set: %w", err)
+	}
+
+	jsonEnc := json.NewEncoder(out)
+	jsonEnc.SetEscapeHTML(false)
+	jsonEnc.SetIndent("", strings.Repeat(" ", indent))
+
+	return jsonEnc.Encode((*orderedNode)(&node))
+}
+
+// orderedNode encodes a yaml node as JSON keeping the order of the keys of its mappings.
+type orderedNode yaml.Node
+
+func (n *orderedNode) MarshalJSON() ([]byte, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return marshalJSON((*orderedNode)(n.Content[0]))
+	case yaml.AliasNode:
+		return marshalJSON((*orderedNode)(n.Alias))
+	case yaml.SequenceNode:
+		items := make([]*orderedNode, len(n.Content))
+		for idx, item := range n.Content {
+			items[idx] = (*orderedNode)(item)
+		}
+
+		return marshalJSON(items)
+	case yaml.MappingNode:
+		buf := &bytes.Buffer{}
+		buf.WriteByte('{')
+
+		for idx := 0; idx < len(n.Content); idx += 2 {
+			if idx != 0 {
+				buf.WriteByte(',')
+			}
+
+			key, err := marshalJSON(n.Content[idx].Value)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := marshalJSON((*orderedNode)(n.Content[idx+1]))
+			if err != nil {
+				return nil, err
+			}
+
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+
+		buf.WriteByte('}')
+
+		return buf.Bytes(), nil
+	default:
+		var value any
+		if err := (*yaml.Node)(n).Decode(&value); err != nil {
+			return nil, err
+		}
+
+		return marshalJSON(value)
+	}
+}
+
+func marshalJSON(value any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
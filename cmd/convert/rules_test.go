@@ -0,0 +1,207 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package convert
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestConvertRuleSet(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc        string
+		rulesFile string
+		version   string
+		assert    func(t *testing.T, err error, out []byte)
+	}{
+		{
+			uc:        "no target version provided",
+			rulesFile: "test_data/ruleset-1alpha4.yaml",
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrNoTargetVersion)
+			},
+		},
+		{
+			uc:        "not existing rule set file",
+			rulesFile: "doesnotexist.yaml",
+			version:   config.CurrentRuleSetVersion,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, os.ErrNotExist)
+			},
+		},
+		{
+			uc:        "unsupported target version",
+			rulesFile: "test_data/ruleset-1alpha4.yaml",
+			version:   "1beta1",
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported target")
+			},
+		},
+		{
+			uc:        "rule set not convertible",
+			rulesFile: "test_data/ruleset-with-canary.yaml",
+			version:   config.RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "canary")
+			},
+		},
+		{
+			uc:        "YAML rule set converted to an older version",
+			rulesFile: "test_data/ruleset-1alpha4.yaml",
+			version:   config.RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, out []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				ruleSet, err := config.ParseRules("application/yaml", bytes.NewReader(out), false)
+				require.NoError(t, err)
+
+				assert.Equal(t, config.RuleSetVersion1Alpha3, ruleSet.Version)
+				assert.Equal(t, "test-rule-set", ruleSet.Name)
+				assert.Nil(t, ruleSet.Defaults)
+				assert.Empty(t, ruleSet.Fragments)
+				require.Len(t, ruleSet.Rules, 1)
+				assert.Equal(t, []string{"GET"}, ruleSet.Rules[0].Methods)
+				assert.Len(t, ruleSet.Rules[0].Execute, 3)
+				assert.NotContains(t, string(out), "mode")
+				assert.NotContains(t, string(out), "canary")
+				assert.NotContains(t, string(out), "strategy")
+			},
+		},
+		{
+			uc:        "JSON rule set converted to a newer version",
+			rulesFile: "test_data/ruleset-1alpha3.json",
+			version:   config.RuleSetVersion1Alpha4,
+			assert: func(t *testing.T, err error, out []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, bytes.HasPrefix(out, []byte("{")))
+
+				ruleSet, err := config.ParseRules("application/json", bytes.NewReader(out), false)
+				require.NoError(t, err)
+
+				assert.Equal(t, config.RuleSetVersion1Alpha4, ruleSet.Version)
+				require.Len(t, ruleSet.Rules, 2)
+				assert.Equal(t, "http://foo.bar/<**>", ruleSet.Rules[0].RuleMatcher.URL)
+				assert.Equal(t, "regex", ruleSet.Rules[1].RuleMatcher.Strategy)
+
+				// the order of the properties is kept and the default strategy is not added
+				versionIdx := bytes.Index(out, []byte(`"version"`))
+				nameIdx := bytes.Index(out, []byte(`"name"`))
+				rulesIdx := bytes.Index(out, []byte(`"rules"`))
+				assert.Less(t, versionIdx, nameIdx)
+				assert.Less(t, nameIdx, rulesIdx)
+				assert.Less(t, bytes.Index(out, []byte(`"id"`)), bytes.Index(out, []byte(`"match"`)))
+				assert.Equal(t, 1, bytes.Count(out, []byte(`"strategy"`)))
+				assert.NotContains(t, string(out), `\u003c`)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			buf := &bytes.Buffer{}
+
+			cmd := NewConvertRulesCommand()
+			cmd.SetOut(buf)
+
+			if len(tc.version) != 0 {
+				err := cmd.ParseFlags([]string{"--to", tc.version})
+				require.NoError(t, err)
+			}
+
+			// WHEN
+			err := convertRuleSet(cmd, []string{tc.rulesFile})
+
+			// THEN
+			tc.assert(t, err, buf.Bytes())
+		})
+	}
+}
+
+func TestRunConvertRulesCommand(t *testing.T) {
+	for _, tc := range []struct {
+		uc       string
+		flags    []string
+		expError string
+	}{
+		{
+			uc:       "conversion fails",
+			expError: "no target version",
+		},
+		{
+			uc:    "conversion succeeds",
+			flags: []string{"--to", config.RuleSetVersion1Alpha3},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			exit, err := testsupport.PatchOSExit(t, func(int) {})
+			require.NoError(t, err)
+
+			outFile := filepath.Join(t.TempDir(), "ruleset.yaml")
+
+			cmd := NewConvertRulesCommand()
+
+			buf := bytes.NewBuffer([]byte{})
+			cmd.SetOut(buf)
+			cmd.SetErr(buf)
+
+			err = cmd.ParseFlags(append(tc.flags, "--output", outFile))
+			require.NoError(t, err)
+
+			// WHEN
+			cmd.Run(cmd, []string{"test_data/ruleset-1alpha4.yaml"})
+
+			// THEN
+			if len(tc.expError) != 0 {
+				assert.Contains(t, buf.String(), tc.expError)
+				assert.True(t, exit.Called)
+				assert.Equal(t, 1, exit.Code)
+			} else {
+				assert.False(t, exit.Called)
+				assert.Empty(t, buf.String())
+
+				contents, err := os.ReadFile(outFile)
+				require.NoError(t, err)
+				assert.Contains(t, string(contents), "version: "+config.RuleSetVersion1Alpha3)
+			}
+		})
+	}
+}
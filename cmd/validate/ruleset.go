@@ -80,7 +80,7 @@ func validateRuleSet(cmd *cobra.Command, args []string) error {
 
 	conf.Providers.FileSystem = map[string]any{"src": args[0]}
 
-	mFactory, err := mechanisms.NewFactory(conf, logger)
+	mFactory, err := mechanisms.NewFactory(conf, logger, nil)
 	if err != nil {
 		return err
 	}
@@ -94,7 +94,15 @@ func validateRuleSet(cmd *cobra.Command, args []string) error {
 
 	defer close(queue)
 
-	provider, err := filesystem.NewProvider(conf, rules.NewRuleSetProcessor(queue, rFactory, logger), logger)
+	// rule conflicts are reported as warnings, unless rejected according to the configured policy
+	conflictsLogger := zerolog.New(zerolog.ConsoleWriter{
+		Out:          cmd.ErrOrStderr(),
+		NoColor:      true,
+		PartsExclude: []string{zerolog.TimestampFieldName},
+	}).Level(zerolog.WarnLevel)
+
+	provider, err := filesystem.NewProvider(conf,
+		rules.NewRuleSetProcessor(queue, rFactory, conf, conflictsLogger), logger)
 	if err != nil {
 		return err
 	}
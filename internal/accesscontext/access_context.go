@@ -23,8 +23,12 @@ import (
 type ctxKey struct{}
 
 type accessContext struct {
-	err     error
-	subject string
+	err         error
+	subject     string
+	ruleVariant string
+
+	shadowEvaluated bool
+	shadowDeniedBy  []string
 }
 
 func New(ctx context.Context) context.Context {
@@ -58,3 +62,42 @@ func SetSubject(ctx context.Context, subject string) {
 		c.subject = subject
 	}
 }
+
+// AddShadowOutcome records the outcome of a rule or a pipeline step executed in shadow
+// mode. A non nil error means, the request would have been denied by the rule or step
+// with the given id.
+func AddShadowOutcome(ctx context.Context, id string, err error) {
+	if c, ok := ctx.Value(ctxKey{}).(*accessContext); ok {
+		c.shadowEvaluated = true
+
+		if err != nil {
+			c.shadowDeniedBy = append(c.shadowDeniedBy, id)
+		}
+	}
+}
+
+// ShadowOutcome returns whether anything has been executed in shadow mode, and if so, the
+// ids of the rules and pipeline steps, which would have denied the request.
+func ShadowOutcome(ctx context.Context) (bool, []string) {
+	if c, ok := ctx.Value(ctxKey{}).(*accessContext); ok {
+		return c.shadowEvaluated, c.shadowDeniedBy
+	}
+
+	return false, nil
+}
+
+// SetRuleVariant records the variant (stable or canary) of the rule executed for the request.
+func SetRuleVariant(ctx context.Context, variant string) {
+	if c, ok := ctx.Value(ctxKey{}).(*accessContext); ok {
+		c.ruleVariant = variant
+	}
+}
+
+// RuleVariant returns the variant of the executed rule, if the rule defines a canary variant.
+func RuleVariant(ctx context.Context) string {
+	if c, ok := ctx.Value(ctxKey{}).(*accessContext); ok {
+		return c.ruleVariant
+	}
+
+	return ""
+}
@@ -29,4 +29,7 @@ type Cache interface {
 
 	Get(ctx context.Context, key string) ([]byte, error)
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetIfAbsent atomically sets the value for the given key only if there is no entry
+	// for it yet. It returns true if the value has been set.
+	SetIfAbsent(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
 }
@@ -68,3 +68,9 @@ func (c *Cache) Set(_ context.Context, key string, value []byte, ttl time.Durati
 
 	return nil
 }
+
+func (c *Cache) SetIfAbsent(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	_, found := c.c.GetOrSet(key, value, ttlcache.WithTTL[string, []byte](ttl))
+
+	return !found, nil
+}
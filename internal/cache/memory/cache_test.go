@@ -18,6 +18,8 @@ package memory
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -120,3 +122,43 @@ func TestMemoryCacheExpiration(t *testing.T) {
 
 	assert.LessOrEqual(t, hits, 4)
 }
+
+func TestMemoryCacheSetIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cache, _ := NewCache(nil, nil)
+	cache.Set(context.TODO(), "foo", []byte("bar"), 1*time.Microsecond)
+
+	time.Sleep(200 * time.Millisecond)
+
+	var (
+		stored atomic.Int32
+		wg     sync.WaitGroup
+	)
+
+	// WHEN
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ok, err := cache.SetIfAbsent(context.TODO(), "foo", []byte("baz"), 10*time.Minute)
+			assert.NoError(t, err)
+
+			if ok {
+				stored.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// THEN
+	assert.Equal(t, int32(1), stored.Load())
+
+	value, err := cache.Get(context.TODO(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("baz"), value)
+}
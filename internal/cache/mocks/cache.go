@@ -122,6 +122,61 @@ func (_c *CacheMock_Set_Call) RunAndReturn(run func(context.Context, string, []b
 	return _c
 }
 
+// SetIfAbsent provides a mock function with given fields: ctx, key, value, ttl
+func (_m *CacheMock) SetIfAbsent(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, key, value, ttl)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, time.Duration) (bool, error)); ok {
+		return rf(ctx, key, value, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, time.Duration) bool); ok {
+		r0 = rf(ctx, key, value, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte, time.Duration) error); ok {
+		r1 = rf(ctx, key, value, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CacheMock_SetIfAbsent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetIfAbsent'
+type CacheMock_SetIfAbsent_Call struct {
+	*mock.Call
+}
+
+// SetIfAbsent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - value []byte
+//   - ttl time.Duration
+func (_e *CacheMock_Expecter) SetIfAbsent(ctx interface{}, key interface{}, value interface{}, ttl interface{}) *CacheMock_SetIfAbsent_Call {
+	return &CacheMock_SetIfAbsent_Call{Call: _e.mock.On("SetIfAbsent", ctx, key, value, ttl)}
+}
+
+func (_c *CacheMock_SetIfAbsent_Call) Run(run func(ctx context.Context, key string, value []byte, ttl time.Duration)) *CacheMock_SetIfAbsent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]byte), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *CacheMock_SetIfAbsent_Call) Return(_a0 bool, _a1 error) *CacheMock_SetIfAbsent_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *CacheMock_SetIfAbsent_Call) RunAndReturn(run func(context.Context, string, []byte, time.Duration) (bool, error)) *CacheMock_SetIfAbsent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Start provides a mock function with given fields: ctx
 func (_m *CacheMock) Start(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -30,3 +30,7 @@ func (*Cache) Get(_ context.Context, _ string) ([]byte, error)
 func (*Cache) Set(_ context.Context, _ string, _ []byte, _ time.Duration) error { return nil }
 func (*Cache) Start(_ context.Context) error                                    { return nil }
 func (*Cache) Stop(_ context.Context) error                                     { return nil }
+
+func (*Cache) SetIfAbsent(_ context.Context, _ string, _ []byte, _ time.Duration) (bool, error) {
+	return true, nil
+}
@@ -66,3 +66,13 @@ func (c *redisCache) Get(ctx context.Context, key string) ([]byte, error) {
 func (c *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
 	return c.c.Do(ctx, c.c.B().Set().Key(key).Value(stringx.ToString(value)).Px(ttl).Build()).Error()
 }
+
+func (c *redisCache) SetIfAbsent(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	err := c.c.Do(ctx, c.c.B().Set().Key(key).Value(stringx.ToString(value)).Nx().Px(ttl).Build()).Error()
+	if rueidis.IsRedisNil(err) {
+		// the key is already present
+		return false, nil
+	}
+
+	return err == nil, err
+}
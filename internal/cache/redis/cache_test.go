@@ -107,3 +107,38 @@ func TestCacheUsage(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheSetIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	db := miniredis.RunT(t)
+	cch, err := NewStandaloneCache(map[string]any{
+		"address":      db.Addr(),
+		"client_cache": map[string]any{"disabled": true},
+		"tls":          map[string]any{"disabled": true},
+	}, nil)
+	require.NoError(t, err)
+
+	cch.Start(context.TODO())
+	defer cch.Stop(context.TODO())
+
+	// WHEN
+	first, err1 := cch.SetIfAbsent(context.Background(), "foo", []byte("bar"), 10*time.Minute)
+	second, err2 := cch.SetIfAbsent(context.Background(), "foo", []byte("baz"), 10*time.Minute)
+
+	db.FastForward(11 * time.Minute)
+
+	third, err3 := cch.SetIfAbsent(context.Background(), "foo", []byte("baz"), 10*time.Minute)
+
+	// THEN
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	require.NoError(t, err3)
+	assert.True(t, first)
+	assert.False(t, second)
+	assert.True(t, third)
+
+	data, err := cch.Get(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("baz"), data)
+}
@@ -35,6 +35,8 @@ type Configuration struct { //nolint:musttag
 	Prototypes           *MechanismPrototypes `koanf:"mechanisms,omitempty"`
 	Default              *DefaultRule         `koanf:"default_rule,omitempty"`
 	Providers            RuleProviders        `koanf:"providers,omitempty"`
+	RuleConflicts        RuleConflictsConfig  `koanf:"rule_conflicts"`
+	Revocation           RevocationConfig     `koanf:"revocation"`
 	SecretsReloadEnabled bool                 `koanf:"secrets_reload_enabled"`
 }
 
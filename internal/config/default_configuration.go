@@ -38,6 +38,8 @@ const (
 
 	defaultBufferSize = 4 * bytesize.KB
 
+	defaultRevocationTTL = time.Hour * 24
+
 	loopbackIP = "127.0.0.1"
 )
 
@@ -109,5 +111,12 @@ func defaultConfig() Configuration {
 			Name: "heimdall",
 		},
 		Prototypes: &MechanismPrototypes{},
+		RuleConflicts: RuleConflictsConfig{
+			Policy: RuleConflictPolicyWarn,
+		},
+		Revocation: RevocationConfig{
+			Enabled: false,
+			TTL:     defaultRevocationTTL,
+		},
 	}
 }
@@ -56,9 +56,44 @@ type TrustStore struct {
 	Path string `koanf:"path" mapstructure:"path"`
 }
 
+const (
+	// ClientAuthNone disables client authentication.
+	ClientAuthNone = "none"
+	// ClientAuthRequest requests a client certificate, which is verified if presented.
+	ClientAuthRequest = "request"
+	// ClientAuthRequire requires the client to present a valid certificate.
+	ClientAuthRequire = "require"
+)
+
+type AllowedSANs struct {
+	DNSNames       []string `koanf:"dns_names"       mapstructure:"dns_names"`
+	IPAddresses    []string `koanf:"ip_addresses"    mapstructure:"ip_addresses"`
+	EmailAddresses []string `koanf:"email_addresses" mapstructure:"email_addresses"`
+	URIs           []string `koanf:"uris"            mapstructure:"uris"`
+}
+
+func (s AllowedSANs) IsEmpty() bool {
+	return len(s.DNSNames) == 0 && len(s.IPAddresses) == 0 && len(s.EmailAddresses) == 0 && len(s.URIs) == 0
+}
+
+type ClientAuth struct {
+	TrustStore  TrustStore  `koanf:"trust_store"  mapstructure:"trust_store"`
+	Mode        string      `koanf:"mode"         mapstructure:"mode"`
+	AllowedSANs AllowedSANs `koanf:"allowed_sans" mapstructure:"allowed_sans"`
+}
+
+func (c *ClientAuth) ModeOrDefault() string {
+	if len(c.Mode) == 0 {
+		return ClientAuthRequire
+	}
+
+	return c.Mode
+}
+
 type TLS struct {
 	KeyStore     KeyStore        `koanf:"key_store"     mapstructure:"key_store"`
 	KeyID        string          `koanf:"key_id"        mapstructure:"key_id"`
 	CipherSuites TLSCipherSuites `koanf:"cipher_suites" mapstructure:"cipher_suites"`
 	MinVersion   TLSMinVersion   `koanf:"min_version"   mapstructure:"min_version"`
+	ClientAuth   *ClientAuth     `koanf:"client_auth"   mapstructure:"client_auth"`
 }
@@ -58,8 +58,19 @@ func (r *requestContext) Finalize(_ rule.Backend) error {
 		r.rw.Header().Set(k, uh.Get(k))
 	}
 
+	// cookies, which should be set on the client side, are more specific than the ones
+	// for the upstream, so these take precedence if both are present for the same name
+	clientCookies := make(map[string]bool, len(r.ClientCookies()))
+	for _, cookie := range r.ClientCookies() {
+		clientCookies[cookie.Name] = true
+
+		http.SetCookie(r.rw, cookie)
+	}
+
 	for k, v := range r.UpstreamCookies() {
-		http.SetCookie(r.rw, &http.Cookie{Name: k, Value: v})
+		if !clientCookies[k] {
+			http.SetCookie(r.rw, &http.Cookie{Name: k, Value: v})
+		}
 	}
 
 	r.rw.WriteHeader(r.responseCode)
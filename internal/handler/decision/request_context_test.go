@@ -102,6 +102,28 @@ func TestRequestContextFinalize(t *testing.T) {
 				assert.Equal(t, http.StatusAccepted, rec.Code)
 			},
 		},
+		{
+			uc:   "client cookies take precedence over upstream cookies",
+			code: http.StatusOK,
+			setup: func(t *testing.T, rc requestcontext.Context) {
+				t.Helper()
+
+				rc.AddCookieForUpstream("session", "foo")
+				rc.AddCookieForUpstream("x-bar", "foo")
+				rc.AddCookieForClient(&http.Cookie{Name: "session", Value: "bar", Path: "/", HttpOnly: true})
+			},
+			assert: func(t *testing.T, err error, rec *httptest.ResponseRecorder) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				cookies := rec.Header().Values("Set-Cookie")
+				assert.Len(t, cookies, 2)
+				assert.Contains(t, cookies, "session=bar; Path=/; HttpOnly")
+				assert.Contains(t, cookies, "x-bar=foo")
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
 		{
 			uc:   "everything is set",
 			code: http.StatusOK,
@@ -18,8 +18,11 @@ package grpcv3
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"strings"
 
@@ -38,13 +41,15 @@ import (
 type RequestContext struct {
 	ctx             context.Context // nolint: containedctx
 	ips             []string
+	clientCerts     []*x509.Certificate
 	reqMethod       string
 	reqHeaders      map[string]string
-	reqURL          *url.URL
+	reqURL          *heimdall.URL
 	reqBody         string
 	reqRawBody      []byte
 	upstreamHeaders http.Header
 	upstreamCookies map[string]string
+	clientCookies   []*http.Cookie
 	jwtSigner       heimdall.JWTSigner
 	err             error
 
@@ -62,16 +67,19 @@ func NewRequestContext(ctx context.Context, req *envoy_auth.CheckRequest, signer
 	}
 
 	return &RequestContext{
-		ctx:        ctx,
-		ips:        clientIPs,
-		reqMethod:  req.GetAttributes().GetRequest().GetHttp().GetMethod(),
-		reqHeaders: canonicalizeHeaders(req.GetAttributes().GetRequest().GetHttp().GetHeaders()),
-		reqURL: &url.URL{
-			Scheme:   req.GetAttributes().GetRequest().GetHttp().GetScheme(),
-			Host:     req.GetAttributes().GetRequest().GetHttp().GetHost(),
-			Path:     req.GetAttributes().GetRequest().GetHttp().GetPath(),
-			RawQuery: req.GetAttributes().GetRequest().GetHttp().GetQuery(),
-			Fragment: req.GetAttributes().GetRequest().GetHttp().GetFragment(),
+		ctx:         ctx,
+		ips:         clientIPs,
+		clientCerts: peerCertificates(req.GetAttributes().GetSource().GetCertificate()),
+		reqMethod:   req.GetAttributes().GetRequest().GetHttp().GetMethod(),
+		reqHeaders:  canonicalizeHeaders(req.GetAttributes().GetRequest().GetHttp().GetHeaders()),
+		reqURL: &heimdall.URL{
+			URL: url.URL{
+				Scheme:   req.GetAttributes().GetRequest().GetHttp().GetScheme(),
+				Host:     req.GetAttributes().GetRequest().GetHttp().GetHost(),
+				Path:     req.GetAttributes().GetRequest().GetHttp().GetPath(),
+				RawQuery: req.GetAttributes().GetRequest().GetHttp().GetQuery(),
+				Fragment: req.GetAttributes().GetRequest().GetHttp().GetFragment(),
+			},
 		},
 		reqBody:         req.GetAttributes().GetRequest().GetHttp().GetBody(),
 		reqRawBody:      req.GetAttributes().GetRequest().GetHttp().GetRawBody(),
@@ -81,6 +89,40 @@ func NewRequestContext(ctx context.Context, req *envoy_auth.CheckRequest, signer
 	}
 }
 
+// peerCertificates parses the certificate of the downstream peer, which envoy provides
+// url encoded in PEM format if configured to do so (include_peer_certificate setting).
+func peerCertificates(encoded string) []*x509.Certificate {
+	if len(encoded) == 0 {
+		return nil
+	}
+
+	decoded, err := url.PathUnescape(encoded)
+	if err != nil {
+		return nil
+	}
+
+	var (
+		certs []*x509.Certificate
+		block *pem.Block
+	)
+
+	for rest := []byte(decoded); ; {
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil
+		}
+
+		certs = append(certs, cert)
+	}
+
+	return certs
+}
+
 func canonicalizeHeaders(headers map[string]string) map[string]string {
 	result := make(map[string]string, len(headers))
 
@@ -93,16 +135,29 @@ func canonicalizeHeaders(headers map[string]string) map[string]string {
 
 func (r *RequestContext) Request() *heimdall.Request {
 	return &heimdall.Request{
-		RequestFunctions:  r,
-		Method:            r.reqMethod,
-		URL:               r.reqURL,
-		ClientIPAddresses: r.ips,
+		RequestFunctions:   r,
+		Method:             r.reqMethod,
+		URL:                r.reqURL,
+		ClientIPAddresses:  r.ips,
+		ClientCertificates: r.clientCerts,
+		// check requests are sent by envoy, which is the proxy in front of heimdall by definition
+		FromTrustedProxy: true,
 	}
 }
 
 func (r *RequestContext) Headers() map[string]string { return r.reqHeaders }
 func (r *RequestContext) Header(name string) string  { return r.reqHeaders[name] }
 
+// HeaderValues returns the value of the given header as a single one, as envoy merges the
+// values of repeated headers before sending the check request.
+func (r *RequestContext) HeaderValues(name string) []string {
+	if value, ok := r.reqHeaders[textproto.CanonicalMIMEHeaderKey(name)]; ok {
+		return []string{value}
+	}
+
+	return nil
+}
+
 func (r *RequestContext) Cookie(name string) string {
 	values, ok := r.reqHeaders["Cookie"]
 	if !ok {
@@ -118,6 +173,8 @@ func (r *RequestContext) Cookie(name string) string {
 	return ""
 }
 
+func (r *RequestContext) RawBody() []byte { return r.reqRawBody }
+
 func (r *RequestContext) Body() any {
 	if r.savedBody == nil {
 		decoder, err := contenttype.NewDecoder(r.Header("Content-Type"))
@@ -146,6 +203,10 @@ func (r *RequestContext) AddHeaderForUpstream(name, value string) { r.upstreamHe
 func (r *RequestContext) AddCookieForUpstream(name, value string) { r.upstreamCookies[name] = value }
 func (r *RequestContext) Signer() heimdall.JWTSigner              { return r.jwtSigner }
 
+func (r *RequestContext) AddCookieForClient(cookie *http.Cookie) {
+	r.clientCookies = append(r.clientCookies, cookie)
+}
+
 func (r *RequestContext) Finalize() (*envoy_auth.CheckResponse, error) {
 	if r.err != nil {
 		return nil, r.err
@@ -188,7 +249,26 @@ func (r *RequestContext) Finalize() (*envoy_auth.CheckResponse, error) {
 	return &envoy_auth.CheckResponse{
 		Status: &status.Status{Code: int32(codes.OK)},
 		HttpResponse: &envoy_auth.CheckResponse_OkResponse{
-			OkResponse: &envoy_auth.OkHttpResponse{Headers: headers},
+			OkResponse: &envoy_auth.OkHttpResponse{
+				Headers:              headers,
+				ResponseHeadersToAdd: r.clientCookieHeaders(),
+			},
 		},
 	}, nil
 }
+
+func (r *RequestContext) clientCookieHeaders() []*envoy_core.HeaderValueOption {
+	if len(r.clientCookies) == 0 {
+		return nil
+	}
+
+	headers := make([]*envoy_core.HeaderValueOption, len(r.clientCookies))
+
+	for idx, cookie := range r.clientCookies {
+		headers[idx] = &envoy_core.HeaderValueOption{
+			Header: &envoy_core.HeaderValue{Key: "Set-Cookie", Value: cookie.String()},
+		}
+	}
+
+	return headers
+}
@@ -18,10 +18,13 @@ package grpcv3
 
 import (
 	"context"
+	"encoding/pem"
 	"errors"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
@@ -32,6 +35,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
 
 func TestNewRequestContext(t *testing.T) {
@@ -81,8 +85,11 @@ func TestNewRequestContext(t *testing.T) {
 	require.Equal(t, httpReq.GetQuery(), ctx.Request().URL.RawQuery)
 	require.Equal(t, "moo", ctx.Request().URL.Query().Get("bar"))
 	require.Equal(t, map[string]any{"content": []string{"heimdall"}}, ctx.Request().Body())
+	require.Equal(t, httpReq.GetRawBody(), ctx.Request().RawBody())
 	require.Len(t, ctx.Request().Headers(), 3)
 	require.Equal(t, "barfoo", ctx.Request().Header("X-Foo-Bar"))
+	require.Equal(t, []string{"barfoo"}, ctx.Request().HeaderValues("x-foo-bar"))
+	require.Empty(t, ctx.Request().HeaderValues("x-bar-foo"))
 	require.Equal(t, "foo", ctx.Request().Cookie("bar"))
 	require.Equal(t, "baz", ctx.Request().Cookie("foo"))
 	require.Empty(t, ctx.Request().Cookie("baz"))
@@ -91,6 +98,71 @@ func TestNewRequestContext(t *testing.T) {
 	assert.Equal(t, []string{"127.0.0.1", "192.168.1.1"}, ctx.Request().ClientIPAddresses)
 }
 
+func TestNewRequestContextWithPeerCertificate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ca, err := testsupport.NewRootCA("Test CA", time.Hour)
+	require.NoError(t, err)
+
+	encodedCert := url.PathEscape(string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: ca.Certificate.Raw,
+	})))
+
+	for _, tc := range []struct {
+		uc          string
+		certificate string
+		assert      func(t *testing.T, req *heimdall.Request)
+	}{
+		{
+			uc: "without peer certificate",
+			assert: func(t *testing.T, req *heimdall.Request) {
+				t.Helper()
+
+				assert.Empty(t, req.ClientCertificates)
+			},
+		},
+		{
+			uc:          "with malformed peer certificate",
+			certificate: "foo%zz",
+			assert: func(t *testing.T, req *heimdall.Request) {
+				t.Helper()
+
+				assert.Empty(t, req.ClientCertificates)
+			},
+		},
+		{
+			uc:          "with peer certificate",
+			certificate: encodedCert,
+			assert: func(t *testing.T, req *heimdall.Request) {
+				t.Helper()
+
+				require.Len(t, req.ClientCertificates, 1)
+				assert.True(t, ca.Certificate.Equal(req.ClientCertificates[0]))
+				assert.True(t, req.FromTrustedProxy)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			checkReq := &envoy_auth.CheckRequest{
+				Attributes: &envoy_auth.AttributeContext{
+					Source: &envoy_auth.AttributeContext_Peer{Certificate: tc.certificate},
+					Request: &envoy_auth.AttributeContext_Request{
+						Http: &envoy_auth.AttributeContext_HttpRequest{Method: http.MethodGet},
+					},
+				},
+			}
+
+			// WHEN
+			ctx := NewRequestContext(context.Background(), checkReq, mocks.NewJWTSignerMock(t))
+
+			// THEN
+			tc.assert(t, ctx.Request())
+		})
+	}
+}
+
 func TestFinalizeRequestContext(t *testing.T) {
 	t.Parallel()
 
@@ -194,6 +266,33 @@ func TestFinalizeRequestContext(t *testing.T) {
 				assert.Equal(t, "some-cookie=value-1", header.GetValue())
 			},
 		},
+		{
+			uc: "successful with cookies for the client",
+			updateContext: func(t *testing.T, ctx heimdall.Context) {
+				t.Helper()
+
+				ctx.AddCookieForUpstream("session", "value-1")
+				ctx.AddCookieForClient(&http.Cookie{Name: "session", Value: "value-1", Path: "/", HttpOnly: true})
+			},
+			assert: func(t *testing.T, err error, response *envoy_auth.CheckResponse) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, response)
+
+				okResponse := response.GetOkResponse()
+				require.NotNil(t, okResponse)
+
+				header := findHeader(okResponse.GetHeaders(), "Cookie")
+				require.NotNil(t, header)
+				assert.Equal(t, "session=value-1", header.GetValue())
+
+				require.Len(t, okResponse.GetResponseHeadersToAdd(), 1)
+				header = okResponse.GetResponseHeadersToAdd()[0].GetHeader()
+				assert.Equal(t, "Set-Cookie", header.GetKey())
+				assert.Equal(t, "session=value-1; Path=/; HttpOnly", header.GetValue())
+			},
+		},
 		{
 			uc: "erroneous with header and cookie",
 			updateContext: func(t *testing.T, ctx heimdall.Context) {
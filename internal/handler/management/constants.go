@@ -19,4 +19,8 @@ package management
 const (
 	EndpointHealth = "/.well-known/health"
 	EndpointJWKS   = "/.well-known/jwks"
+
+	EndpointRevocations = "/revocations"
 )
+
+const maxRevocationRequestSize = 4 * 1024
@@ -17,7 +17,9 @@
 package management
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/go-http-utils/etag"
 	"github.com/go-jose/go-jose/v4"
@@ -25,15 +27,26 @@ import (
 	"github.com/justinas/alice"
 	"github.com/rs/zerolog"
 
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/methodfilter"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/revocation"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
-func newManagementHandler(signer heimdall.JWTSigner, eh errorhandler.ErrorHandler) http.Handler {
+func newManagementHandler(
+	signer heimdall.JWTSigner,
+	cch cache.Cache,
+	conf config.RevocationConfig,
+	eh errorhandler.ErrorHandler,
+) http.Handler {
 	mh := &handler{
-		s:  signer,
-		eh: eh,
+		s:   signer,
+		c:   cch,
+		ttl: conf.TTL,
+		eh:  eh,
 	}
 
 	mux := http.NewServeMux()
@@ -45,12 +58,20 @@ func newManagementHandler(signer heimdall.JWTSigner, eh errorhandler.ErrorHandle
 		alice.New(methodfilter.New(http.MethodGet)).
 			Then(etag.Handler(http.HandlerFunc(mh.jwks), false)))
 
+	if conf.Enabled {
+		mux.Handle(EndpointRevocations,
+			alice.New(methodfilter.New(http.MethodPost)).
+				Then(http.HandlerFunc(mh.revoke)))
+	}
+
 	return mux
 }
 
 type handler struct {
-	s  heimdall.JWTSigner
-	eh errorhandler.ErrorHandler
+	s   heimdall.JWTSigner
+	c   cache.Cache
+	ttl time.Duration
+	eh  errorhandler.ErrorHandler
 }
 
 // jwks implements an endpoint returning JWKS objects according to
@@ -84,3 +105,54 @@ func (h *handler) health(rw http.ResponseWriter, req *http.Request) {
 	rw.Header().Set("Content-Type", "application/json")
 	_, _ = rw.Write(res)
 }
+
+func (h *handler) revoke(rw http.ResponseWriter, req *http.Request) {
+	type revocationRequest struct {
+		Type  revocation.Type `json:"type"`
+		Value string          `json:"value"`
+		TTL   string          `json:"ttl,omitempty"`
+	}
+
+	var (
+		revReq revocationRequest
+		ttl    = h.ttl
+	)
+
+	if err := json.NewDecoder(http.MaxBytesReader(rw, req.Body, maxRevocationRequestSize)).
+		Decode(&revReq); err != nil {
+		h.eh.HandleError(rw, req, errorchain.NewWithMessage(heimdall.ErrArgument,
+			"failed to decode revocation request").CausedBy(err))
+
+		return
+	}
+
+	if len(revReq.TTL) != 0 {
+		var err error
+
+		if ttl, err = time.ParseDuration(revReq.TTL); err != nil {
+			h.eh.HandleError(rw, req, errorchain.NewWithMessage(heimdall.ErrArgument,
+				"failed to parse ttl").CausedBy(err))
+
+			return
+		}
+	}
+
+	if err := revocation.Revoke(req.Context(), h.c, revReq.Type, revReq.Value, ttl); err != nil {
+		if errors.Is(err, revocation.ErrUnsupportedType) || errors.Is(err, revocation.ErrEmptyValue) ||
+			errors.Is(err, revocation.ErrInvalidTTL) {
+			err = errorchain.NewWithMessage(heimdall.ErrArgument, "invalid revocation request").CausedBy(err)
+		}
+
+		zerolog.Ctx(req.Context()).Warn().Err(err).Msg("Failed to store revocation")
+		h.eh.HandleError(rw, req, err)
+
+		return
+	}
+
+	zerolog.Ctx(req.Context()).Info().
+		Str("_type", string(revReq.Type)).
+		Dur("_ttl", ttl).
+		Msg("Revocation stored")
+
+	rw.WriteHeader(http.StatusNoContent)
+}
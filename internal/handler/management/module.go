@@ -22,10 +22,12 @@ import (
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
 
+	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/fxlcm"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
 var Module = fx.Invoke( // nolint: gochecknoglobals
@@ -40,16 +42,27 @@ func newLifecycleManager(
 	conf *config.Configuration,
 	logger zerolog.Logger,
 	signer heimdall.JWTSigner,
+	cch cache.Cache,
 	cw watcher.Watcher,
-) *fxlcm.LifecycleManager {
+) (*fxlcm.LifecycleManager, error) {
 	cfg := conf.Serve.Management
 
+	// the revocation endpoint does not authenticate its clients on its own
+	if conf.Revocation.Enabled && !clientAuthRequired(cfg.TLS) {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"revocation can only be enabled if the management service requires client authentication")
+	}
+
 	return &fxlcm.LifecycleManager{
 		ServiceName:    "Management",
 		ServiceAddress: cfg.Address(),
-		Server:         newService(conf, logger, signer),
+		Server:         newService(conf, logger, signer, cch),
 		Logger:         logger,
 		TLSConf:        cfg.TLS,
 		FileWatcher:    cw,
-	}
+	}, nil
+}
+
+func clientAuthRequired(conf *config.TLS) bool {
+	return conf != nil && conf.ClientAuth != nil && conf.ClientAuth.ModeOrDefault() == config.ClientAuthRequire
 }
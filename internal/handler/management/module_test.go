@@ -0,0 +1,100 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache/noop"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+)
+
+func TestNewLifecycleManager(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		conf   config.Configuration
+		assert func(t *testing.T, err error)
+	}{
+		{
+			uc: "revocation disabled",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:   "revocation enabled without tls",
+			conf: config.Configuration{Revocation: config.RevocationConfig{Enabled: true}},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "requires client authentication")
+			},
+		},
+		{
+			uc: "revocation enabled with optional client authentication",
+			conf: config.Configuration{
+				Serve: config.ServeConfig{Management: config.ServiceConfig{
+					TLS: &config.TLS{ClientAuth: &config.ClientAuth{Mode: config.ClientAuthRequest}},
+				}},
+				Revocation: config.RevocationConfig{Enabled: true},
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc: "revocation enabled with required client authentication",
+			conf: config.Configuration{
+				Serve: config.ServeConfig{Management: config.ServiceConfig{
+					TLS: &config.TLS{ClientAuth: &config.ClientAuth{}},
+				}},
+				Revocation: config.RevocationConfig{Enabled: true},
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			lcm, err := newLifecycleManager(&tc.conf, log.Logger, mocks.NewJWTSignerMock(t), &noop.Cache{}, nil)
+
+			// THEN
+			tc.assert(t, err)
+
+			if err == nil {
+				require.NotNil(t, lcm)
+			}
+		})
+	}
+}
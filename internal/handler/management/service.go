@@ -26,6 +26,7 @@ import (
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
+	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/accesslog"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/dump"
@@ -44,6 +45,7 @@ func newService(
 	conf *config.Configuration,
 	log zerolog.Logger,
 	signer heimdall.JWTSigner,
+	cch cache.Cache,
 ) *http.Server {
 	cfg := conf.Serve.Management
 	eh := errorhandler2.New()
@@ -82,7 +84,7 @@ func newService(
 			},
 			func() func(http.Handler) http.Handler { return passthrough.New },
 		),
-	).Then(newManagementHandler(signer, eh))
+	).Then(newManagementHandler(signer, cch, conf.Revocation, eh))
 
 	return &http.Server{
 		Handler:        hc,
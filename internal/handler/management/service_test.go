@@ -25,18 +25,27 @@ import (
 	"crypto/x509/pkix"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-jose/go-jose/v4"
 	"github.com/goccy/go-json"
 	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/listener"
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
 	"github.com/dadrus/heimdall/internal/heimdall/mocks"
 	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/revocation"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
@@ -51,6 +60,7 @@ type ServiceTestSuite struct {
 	srv    *http.Server
 	ks     keystore.KeyStore
 	signer *mocks.JWTSignerMock
+	cch    cache.Cache
 	addr   string
 }
 
@@ -120,7 +130,8 @@ func (suite *ServiceTestSuite) SetupTest() {
 				CORS: &config.CORS{},
 			},
 		},
-		Metrics: config.MetricsConfig{Enabled: true},
+		Metrics:    config.MetricsConfig{Enabled: true},
+		Revocation: config.RevocationConfig{Enabled: true, TTL: time.Hour},
 	}
 
 	listener, err := listener.New("tcp", conf.Serve.Management.Address(), conf.Serve.Management.TLS, nil)
@@ -128,7 +139,9 @@ func (suite *ServiceTestSuite) SetupTest() {
 	suite.addr = "http://" + listener.Addr().String()
 
 	suite.signer = mocks.NewJWTSignerMock(suite.T())
-	suite.srv = newService(conf, log.Logger, suite.signer)
+	suite.cch, err = memory.NewCache(nil, nil)
+	suite.Require().NoError(err)
+	suite.srv = newService(conf, log.Logger, suite.signer, suite.cch)
 
 	go func() {
 		suite.srv.Serve(listener)
@@ -268,3 +281,107 @@ func (suite *ServiceTestSuite) TestHealthRequest() {
 
 	suite.JSONEq(`{ "status": "ok"}`, string(rawResp))
 }
+
+func (suite *ServiceTestSuite) TestRevocationRequest() {
+	for _, tc := range []struct {
+		uc     string
+		method string
+		body   string
+		assert func(resp *http.Response)
+	}{
+		{
+			uc:     "with not allowed method",
+			method: http.MethodGet,
+			assert: func(resp *http.Response) {
+				suite.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "with malformed body",
+			method: http.MethodPost,
+			body:   `{"type":`,
+			assert: func(resp *http.Response) {
+				suite.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "with unsupported type",
+			method: http.MethodPost,
+			body:   `{"type":"foo","value":"bar"}`,
+			assert: func(resp *http.Response) {
+				suite.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "with malformed ttl",
+			method: http.MethodPost,
+			body:   `{"type":"jti","value":"bar","ttl":"foo"}`,
+			assert: func(resp *http.Response) {
+				suite.Equal(http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "with valid request using configured ttl",
+			method: http.MethodPost,
+			body:   `{"type":"jti","value":"token-1"}`,
+			assert: func(resp *http.Response) {
+				suite.Equal(http.StatusNoContent, resp.StatusCode)
+				suite.True(revocation.IsRevoked(context.TODO(), suite.cch, &subject.Subject{
+					ID:         "foo",
+					Attributes: map[string]any{"jti": "token-1"},
+				}))
+			},
+		},
+		{
+			uc:     "with valid request using own ttl",
+			method: http.MethodPost,
+			body:   `{"type":"sub","value":"bar","ttl":"10m"}`,
+			assert: func(resp *http.Response) {
+				suite.Equal(http.StatusNoContent, resp.StatusCode)
+				suite.True(revocation.IsRevoked(context.TODO(), suite.cch, &subject.Subject{ID: "bar"}))
+			},
+		},
+	} {
+		suite.Run(tc.uc, func() {
+			// GIVEN
+			client := &http.Client{Transport: &http.Transport{}}
+			req, err := http.NewRequestWithContext(context.TODO(), tc.method, suite.addr+"/revocations",
+				strings.NewReader(tc.body))
+			suite.Require().NoError(err)
+
+			// WHEN
+			resp, err := client.Do(req)
+
+			// THEN
+			suite.Require().NoError(err)
+
+			defer resp.Body.Close()
+
+			tc.assert(resp)
+		})
+	}
+}
+
+func TestRevocationEndpointNotExposedIfDisabled(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	mh := newManagementHandler(mocks.NewJWTSignerMock(t), cch, config.RevocationConfig{}, errorhandler.New())
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, EndpointRevocations,
+		strings.NewReader(`{"type":"jti","value":"foo"}`))
+
+	// WHEN
+	mh.ServeHTTP(rec, req)
+
+	// THEN
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.False(t, revocation.IsRevoked(context.TODO(), cch, &subject.Subject{
+		ID:         "bar",
+		Attributes: map[string]any{"jti": "foo"},
+	}))
+}
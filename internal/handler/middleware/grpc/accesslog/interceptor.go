@@ -128,6 +128,22 @@ func logAccessStatus(ctx context.Context, event *zerolog.Event, err error) *zero
 		event.Str("_subject", subject).Bool("_access_granted", true)
 	}
 
+	if variant := accesscontext.RuleVariant(ctx); len(variant) != 0 {
+		event.Str("_rule_variant", variant)
+	}
+
+	return logShadowOutcome(ctx, event)
+}
+
+func logShadowOutcome(ctx context.Context, event *zerolog.Event) *zerolog.Event {
+	if evaluated, deniedBy := accesscontext.ShadowOutcome(ctx); evaluated {
+		event.Bool("_shadow_access_granted", len(deniedBy) == 0)
+
+		if len(deniedBy) != 0 {
+			event.Strs("_shadow_denied_by", deniedBy)
+		}
+	}
+
 	return event
 }
 
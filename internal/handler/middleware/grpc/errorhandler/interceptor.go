@@ -19,6 +19,7 @@ package errorhandler
 import (
 	"context"
 	"errors"
+	"net/http"
 
 	envoy_core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
@@ -81,14 +82,35 @@ func (h *interceptor) intercept(
 			HttpResponse: &envoy_auth.CheckResponse_DeniedResponse{
 				DeniedResponse: &envoy_auth.DeniedHttpResponse{
 					Status: &envoy_type.HttpStatus{Code: envoy_type.StatusCode(redirectError.Code)},
-					Headers: []*envoy_core.HeaderValueOption{
-						{
+					Headers: append(cookieHeaders(redirectError.Cookies),
+						&envoy_core.HeaderValueOption{
 							Header: &envoy_core.HeaderValue{
 								Key:   "Location",
 								Value: redirectError.RedirectTo,
 							},
 						},
-					},
+					),
+				},
+			},
+		}, nil
+	case errors.Is(err, &heimdall.ResponseError{}):
+		var responseError *heimdall.ResponseError
+
+		errors.As(err, &responseError)
+
+		return &envoy_auth.CheckResponse{
+			Status: &status.Status{Code: int32(codes.FailedPrecondition)},
+			HttpResponse: &envoy_auth.CheckResponse_DeniedResponse{
+				DeniedResponse: &envoy_auth.DeniedHttpResponse{
+					Status: &envoy_type.HttpStatus{Code: envoy_type.StatusCode(responseError.Code)},
+					Headers: append(cookieHeaders(responseError.Cookies),
+						&envoy_core.HeaderValueOption{
+							Header: &envoy_core.HeaderValue{
+								Key:   "Cache-Control",
+								Value: "no-store",
+							},
+						},
+					),
 				},
 			},
 		}, nil
@@ -101,6 +123,21 @@ func (h *interceptor) intercept(
 	}
 }
 
+func cookieHeaders(cookies []*http.Cookie) []*envoy_core.HeaderValueOption {
+	headers := make([]*envoy_core.HeaderValueOption, 0, len(cookies)+1)
+
+	for _, cookie := range cookies {
+		headers = append(headers, &envoy_core.HeaderValueOption{
+			Header: &envoy_core.HeaderValue{
+				Key:   "Set-Cookie",
+				Value: cookie.String(),
+			},
+		})
+	}
+
+	return headers
+}
+
 func acceptType(req any) string {
 	if req, ok := req.(*envoy_auth.CheckRequest); ok {
 		return req.GetAttributes().GetRequest().GetHttp().GetHeaders()["accept"]
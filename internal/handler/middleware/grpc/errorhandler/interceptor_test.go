@@ -47,6 +47,7 @@ func TestErrorInterceptor(t *testing.T) {
 		expGRPCCode codes.Code
 		expHTTPCode envoy_type.StatusCode
 		expBody     string
+		expHeaders  map[string]string
 	}{
 		{
 			uc:          "no error",
@@ -222,6 +223,26 @@ func TestErrorInterceptor(t *testing.T) {
 			expGRPCCode: codes.FailedPrecondition,
 			expHTTPCode: http.StatusFound,
 		},
+		{
+			uc:          "redirect error with cookies",
+			interceptor: New(),
+			err: &heimdall.RedirectError{
+				RedirectTo: "http://foo.local",
+				Code:       http.StatusFound,
+				Cookies:    []*http.Cookie{{Name: "foo", Value: "bar"}},
+			},
+			expGRPCCode: codes.FailedPrecondition,
+			expHTTPCode: http.StatusFound,
+			expHeaders:  map[string]string{"Location": "http://foo.local", "Set-Cookie": "foo=bar"},
+		},
+		{
+			uc:          "response error",
+			interceptor: New(),
+			err:         &heimdall.ResponseError{Code: http.StatusOK},
+			expGRPCCode: codes.FailedPrecondition,
+			expHTTPCode: http.StatusOK,
+			expHeaders:  map[string]string{"Cache-Control": "no-store"},
+		},
 		{
 			uc:          "internal error default",
 			interceptor: New(),
@@ -301,6 +322,15 @@ func TestErrorInterceptor(t *testing.T) {
 				require.NotNil(t, deniedResp)
 				assert.Equal(t, tc.expHTTPCode, deniedResp.GetStatus().GetCode())
 				assert.Equal(t, tc.expBody, deniedResp.GetBody())
+
+				headers := make(map[string]string, len(deniedResp.GetHeaders()))
+				for _, header := range deniedResp.GetHeaders() {
+					headers[header.GetHeader().GetKey()] = header.GetHeader().GetValue()
+				}
+
+				for name, value := range tc.expHeaders {
+					assert.Equal(t, value, headers[name])
+				}
 			}
 		})
 	}
@@ -275,6 +275,29 @@ func TestHandlerExecution(t *testing.T) {
 				assert.Equal(t, "TX finished", logEvent2["message"])
 			},
 		},
+		{
+			uc:        "with rule variant and shadow outcome set on context",
+			method:    http.MethodGet,
+			setHeader: func(t *testing.T, _ *http.Request) { t.Helper() },
+			handleRequest: func(t *testing.T, rw http.ResponseWriter, req *http.Request) {
+				t.Helper()
+
+				accesscontext.SetSubject(req.Context(), "bar")
+				accesscontext.SetRuleVariant(req.Context(), "canary")
+				accesscontext.AddShadowOutcome(req.Context(), "foo", errors.New("test error"))
+				rw.WriteHeader(http.StatusOK)
+			},
+			assert: func(t *testing.T, _ *http.Request, _, logEvent2 map[string]any) {
+				t.Helper()
+
+				assert.Equal(t, true, logEvent2["_access_granted"]) //nolint:testifylint
+				assert.Equal(t, "bar", logEvent2["_subject"])
+				assert.Equal(t, "canary", logEvent2["_rule_variant"])
+				assert.Equal(t, false, logEvent2["_shadow_access_granted"]) //nolint:testifylint
+				assert.Equal(t, []any{"foo"}, logEvent2["_shadow_denied_by"])
+				assert.Equal(t, "TX finished", logEvent2["message"])
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
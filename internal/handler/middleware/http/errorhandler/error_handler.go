@@ -67,9 +67,26 @@ func (h *errorHandler) HandleError(rw http.ResponseWriter, req *http.Request, er
 
 		errors.As(err, &redirectError)
 
+		for _, cookie := range redirectError.Cookies {
+			http.SetCookie(rw, cookie)
+		}
+
 		rw.Header().Set("Location", redirectError.RedirectTo)
 		rw.WriteHeader(redirectError.Code)
 
+		return
+	case errors.Is(err, &heimdall.ResponseError{}):
+		var responseError *heimdall.ResponseError
+
+		errors.As(err, &responseError)
+
+		for _, cookie := range responseError.Cookies {
+			http.SetCookie(rw, cookie)
+		}
+
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.WriteHeader(responseError.Code)
+
 		return
 	default:
 		logger := zerolog.Ctx(ctx)
@@ -31,12 +31,13 @@ func TestHandlerHandle(t *testing.T) {
 	t.Parallel()
 
 	for _, tc := range []struct {
-		uc      string
-		handler ErrorHandler
-		err     error
-		expCode int
-		accept  string
-		expBody string
+		uc        string
+		handler   ErrorHandler
+		err       error
+		expCode   int
+		accept    string
+		expBody   string
+		expHeader http.Header
 	}{
 		{
 			uc:      "authentication error default",
@@ -186,6 +187,33 @@ func TestHandlerHandle(t *testing.T) {
 			err:     &heimdall.RedirectError{RedirectTo: "http://foo.local", Code: http.StatusFound},
 			expCode: http.StatusFound,
 		},
+		{
+			uc:      "redirect error with cookies",
+			handler: New(),
+			err: &heimdall.RedirectError{
+				RedirectTo: "http://foo.local",
+				Code:       http.StatusFound,
+				Cookies:    []*http.Cookie{{Name: "foo", Value: "bar"}},
+			},
+			expCode: http.StatusFound,
+			expHeader: http.Header{
+				"Location":   []string{"http://foo.local"},
+				"Set-Cookie": []string{"foo=bar"},
+			},
+		},
+		{
+			uc:      "response error",
+			handler: New(WithVerboseErrors(true)),
+			err: &heimdall.ResponseError{
+				Code:    http.StatusOK,
+				Cookies: []*http.Cookie{{Name: "foo", MaxAge: -1}},
+			},
+			expCode: http.StatusOK,
+			expHeader: http.Header{
+				"Cache-Control": []string{"no-store"},
+				"Set-Cookie":    []string{"foo=; Max-Age=0"},
+			},
+		},
 		{
 			uc:      "internal error default",
 			handler: New(),
@@ -219,6 +247,10 @@ func TestHandlerHandle(t *testing.T) {
 
 			assert.Equal(t, tc.expCode, recorder.Code)
 			assert.Equal(t, tc.expBody, recorder.Body.String())
+
+			for name, values := range tc.expHeader {
+				assert.Equal(t, values, recorder.Header().Values(name))
+			}
 		})
 	}
 }
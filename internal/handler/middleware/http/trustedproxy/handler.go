@@ -78,7 +78,9 @@ func New(logger zerolog.Logger, proxies ...string) func(http.Handler) http.Handl
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-			if !trustedProxies.Contains(net.ParseIP(httpx.IPFromHostPort(req.RemoteAddr))) {
+			if trustedProxies.Contains(net.ParseIP(httpx.IPFromHostPort(req.RemoteAddr))) {
+				req = req.WithContext(httpx.WithTrustedProxy(req.Context()))
+			} else {
 				for _, name := range untrustedHeader {
 					req.Header.Del(name)
 				}
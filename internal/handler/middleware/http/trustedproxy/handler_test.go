@@ -26,6 +26,8 @@ import (
 	"github.com/justinas/alice"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/x/httpx"
 )
 
 func TestHandlerExecution(t *testing.T) {
@@ -54,12 +56,16 @@ func TestHandlerExecution(t *testing.T) {
 				"X-Foo-Bar":         []string{"foo"},
 			}
 
-			var received http.Header
+			var (
+				received         http.Header
+				fromTrustedProxy bool
+			)
 
 			srv := httptest.NewServer(
 				alice.New(New(log.Logger, tc.ips...)).
 					ThenFunc(func(rw http.ResponseWriter, req *http.Request) {
 						received = maps.Clone(req.Header)
+						fromTrustedProxy = httpx.FromTrustedProxy(req.Context())
 
 						rw.WriteHeader(http.StatusGone)
 					}))
@@ -79,6 +85,8 @@ func TestHandlerExecution(t *testing.T) {
 			require.NoError(t, err)
 			resp.Body.Close()
 
+			require.Equal(t, !tc.shouldDrop, fromTrustedProxy)
+
 			if tc.shouldDrop {
 				require.Empty(t, received.Get("X-Forwarded-Proto"))
 				require.Empty(t, received.Get("X-Forwarded-Host"))
@@ -106,6 +106,13 @@ func (r *requestContext) Finalize(upstream rule.Backend) error {
 				CausedBy(err)
 		},
 		Rewrite: r.rewriteRequest(upstream.URL()),
+		ModifyResponse: func(resp *http.Response) error {
+			for _, cookie := range r.ClientCookies() {
+				resp.Header.Add("Set-Cookie", cookie.String())
+			}
+
+			return nil
+		},
 		Transport: otelhttp.NewTransport(
 			httpx.NewTraceRoundTripper(r.transport),
 			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
@@ -140,8 +147,20 @@ func (r *requestContext) rewriteRequest(targetURL *url.URL) func(req *httputil.P
 			proxyReq.Out.Header.Del("Host")
 		}
 
-		for k, v := range r.UpstreamCookies() {
-			proxyReq.Out.AddCookie(&http.Cookie{Name: k, Value: v})
+		if upstreamCookies := r.UpstreamCookies(); len(upstreamCookies) != 0 {
+			// cookies set by the pipeline replace the ones with the same name sent by the client
+			cookies := proxyReq.Out.Cookies()
+			proxyReq.Out.Header.Del("Cookie")
+
+			for _, cookie := range cookies {
+				if _, present := upstreamCookies[cookie.Name]; !present {
+					proxyReq.Out.AddCookie(cookie)
+				}
+			}
+
+			for k, v := range upstreamCookies {
+				proxyReq.Out.AddCookie(&http.Cookie{Name: k, Value: v})
+			}
 		}
 
 		// set headers, which might be relevant for the upstream, if these are present in the original request
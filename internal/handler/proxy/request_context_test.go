@@ -43,6 +43,7 @@ func TestRequestContextFinalize(t *testing.T) {
 		headers        http.Header
 		setup          func(*testing.T, requestcontext.Context, *url.URL) rule.Backend
 		assertRequest  func(*testing.T, *http.Request)
+		assertResponse func(*testing.T, *httptest.ResponseRecorder)
 	}{
 		{
 			uc: "error was present, forwarding aborted",
@@ -177,6 +178,34 @@ func TestRequestContextFinalize(t *testing.T) {
 				assert.Equal(t, "someid", req.Header.Get("X-User-Id"))
 			},
 		},
+		{
+			uc:             "cookies from rule execution replace cookies sent by the client",
+			upstreamCalled: true,
+			headers: http.Header{
+				"Cookie": []string{"session=old; other=value"},
+			},
+			setup: func(t *testing.T, ctx requestcontext.Context, upstreamURL *url.URL) rule.Backend {
+				t.Helper()
+
+				ctx.AddCookieForUpstream("session", "new")
+				ctx.AddCookieForClient(&http.Cookie{Name: "session", Value: "new", Path: "/", HttpOnly: true})
+
+				backend := mocks2.NewBackendMock(t)
+				backend.EXPECT().URL().Return(upstreamURL)
+
+				return backend
+			},
+			assertRequest: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				assert.Equal(t, "other=value; session=new", req.Header.Get("Cookie"))
+			},
+			assertResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				t.Helper()
+
+				assert.Equal(t, []string{"session=new; Path=/; HttpOnly"}, rec.Header().Values("Set-Cookie"))
+			},
+		},
 		{
 			uc:             "Host header is set for upstream",
 			upstreamCalled: true,
@@ -322,6 +351,10 @@ func TestRequestContextFinalize(t *testing.T) {
 			if !tc.upstreamCalled {
 				require.Error(t, err)
 			}
+
+			if tc.assertResponse != nil {
+				tc.assertResponse(t, rw)
+			}
 		})
 	}
 }
@@ -4,8 +4,10 @@ package mocks
 
 import (
 	context "context"
+	http "net/http"
 
 	heimdall "github.com/dadrus/heimdall/internal/heimdall"
+
 	mock "github.com/stretchr/testify/mock"
 
 	rule "github.com/dadrus/heimdall/internal/rules/rule"
@@ -24,6 +26,39 @@ func (_m *ContextMock) EXPECT() *ContextMock_Expecter {
 	return &ContextMock_Expecter{mock: &_m.Mock}
 }
 
+// AddCookieForClient provides a mock function with given fields: cookie
+func (_m *ContextMock) AddCookieForClient(cookie *http.Cookie) {
+	_m.Called(cookie)
+}
+
+// ContextMock_AddCookieForClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddCookieForClient'
+type ContextMock_AddCookieForClient_Call struct {
+	*mock.Call
+}
+
+// AddCookieForClient is a helper method to define mock.On call
+//   - cookie *http.Cookie
+func (_e *ContextMock_Expecter) AddCookieForClient(cookie interface{}) *ContextMock_AddCookieForClient_Call {
+	return &ContextMock_AddCookieForClient_Call{Call: _e.mock.On("AddCookieForClient", cookie)}
+}
+
+func (_c *ContextMock_AddCookieForClient_Call) Run(run func(cookie *http.Cookie)) *ContextMock_AddCookieForClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*http.Cookie))
+	})
+	return _c
+}
+
+func (_c *ContextMock_AddCookieForClient_Call) Return() *ContextMock_AddCookieForClient_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ContextMock_AddCookieForClient_Call) RunAndReturn(run func(*http.Cookie)) *ContextMock_AddCookieForClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddCookieForUpstream provides a mock function with given fields: name, value
 func (_m *ContextMock) AddCookieForUpstream(name string, value string) {
 	_m.Called(name, value)
@@ -135,13 +170,13 @@ func (_c *ContextMock_AppContext_Call) RunAndReturn(run func() context.Context)
 	return _c
 }
 
-// Finalize provides a mock function with given fields: _a0
-func (_m *ContextMock) Finalize(_a0 rule.Backend) error {
-	ret := _m.Called(_a0)
+// Finalize provides a mock function with given fields: backend
+func (_m *ContextMock) Finalize(backend rule.Backend) error {
+	ret := _m.Called(backend)
 
 	var r0 error
 	if rf, ok := ret.Get(0).(func(rule.Backend) error); ok {
-		r0 = rf(_a0)
+		r0 = rf(backend)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -155,12 +190,12 @@ type ContextMock_Finalize_Call struct {
 }
 
 // Finalize is a helper method to define mock.On call
-//   - _a0 rule.Backend
-func (_e *ContextMock_Expecter) Finalize(_a0 interface{}) *ContextMock_Finalize_Call {
-	return &ContextMock_Finalize_Call{Call: _e.mock.On("Finalize", _a0)}
+//   - backend rule.Backend
+func (_e *ContextMock_Expecter) Finalize(backend interface{}) *ContextMock_Finalize_Call {
+	return &ContextMock_Finalize_Call{Call: _e.mock.On("Finalize", backend)}
 }
 
-func (_c *ContextMock_Finalize_Call) Run(run func(_a0 rule.Backend)) *ContextMock_Finalize_Call {
+func (_c *ContextMock_Finalize_Call) Run(run func(backend rule.Backend)) *ContextMock_Finalize_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(rule.Backend))
 	})
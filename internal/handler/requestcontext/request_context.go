@@ -22,7 +22,6 @@ import (
 	"io"
 	"net/http"
 	"net/textproto"
-	"net/url"
 	"strings"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
@@ -33,15 +32,17 @@ import (
 
 type RequestContext struct {
 	reqMethod       string
-	reqURL          *url.URL
+	reqURL          *heimdall.URL
 	upstreamHeaders http.Header
 	upstreamCookies map[string]string
+	clientCookies   []*http.Cookie
 	jwtSigner       heimdall.JWTSigner
 	req             *http.Request
 	err             error
 
 	// the following properties are created lazy and cached
 
+	rawBody   []byte
 	savedBody any
 	hmdlReq   *heimdall.Request
 	headers   map[string]string
@@ -51,7 +52,7 @@ func New(signer heimdall.JWTSigner, req *http.Request) *RequestContext {
 	return &RequestContext{
 		jwtSigner:       signer,
 		reqMethod:       extractMethod(req),
-		reqURL:          extractURL(req),
+		reqURL:          &heimdall.URL{URL: *extractURL(req)},
 		upstreamHeaders: make(http.Header),
 		upstreamCookies: make(map[string]string),
 		req:             req,
@@ -67,6 +68,15 @@ func (r *RequestContext) Header(name string) string {
 	return strings.Join(r.req.Header.Values(key), ",")
 }
 
+func (r *RequestContext) HeaderValues(name string) []string {
+	key := textproto.CanonicalMIMEHeaderKey(name)
+	if key == "Host" {
+		return []string{r.req.Host}
+	}
+
+	return r.req.Header.Values(key)
+}
+
 func (r *RequestContext) Cookie(name string) string {
 	if cookie, err := r.req.Cookie(name); err == nil {
 		return cookie.Value
@@ -88,24 +98,35 @@ func (r *RequestContext) Headers() map[string]string {
 	return r.headers
 }
 
-func (r *RequestContext) Body() any {
-	if r.req.Body == nil || r.req.Body == http.NoBody {
-		return ""
-	}
-
-	if r.savedBody == nil {
+func (r *RequestContext) RawBody() []byte {
+	if r.rawBody == nil && r.req.Body != nil && r.req.Body != http.NoBody {
 		// drain body by reading its contents into memory and preserving
 		var buf bytes.Buffer
 		if _, err := buf.ReadFrom(r.req.Body); err != nil {
-			return ""
+			return nil
 		}
 
 		if err := r.req.Body.Close(); err != nil {
-			return ""
+			return nil
 		}
 
-		body := buf.Bytes()
-		r.req.Body = io.NopCloser(bytes.NewReader(body))
+		r.rawBody = buf.Bytes()
+		r.req.Body = io.NopCloser(bytes.NewReader(r.rawBody))
+	}
+
+	return r.rawBody
+}
+
+func (r *RequestContext) Body() any {
+	if r.req.Body == nil || r.req.Body == http.NoBody {
+		return ""
+	}
+
+	if r.savedBody == nil {
+		body := r.RawBody()
+		if body == nil {
+			return ""
+		}
 
 		decoder, err := contenttype.NewDecoder(r.Header("Content-Type"))
 		if err != nil {
@@ -134,6 +155,17 @@ func (r *RequestContext) Request() *heimdall.Request {
 			Method:            r.reqMethod,
 			URL:               r.reqURL,
 			ClientIPAddresses: r.requestClientIPs(),
+			FromTrustedProxy:  httpx.FromTrustedProxy(r.req.Context()),
+		}
+
+		if r.req.TLS != nil {
+			// if client authentication is configured, the chain verified
+			// by the listener, including the trust anchor, is preferred
+			if len(r.req.TLS.VerifiedChains) != 0 {
+				r.hmdlReq.ClientCertificates = r.req.TLS.VerifiedChains[0]
+			} else {
+				r.hmdlReq.ClientCertificates = r.req.TLS.PeerCertificates
+			}
 		}
 	}
 
@@ -171,7 +203,12 @@ func (r *RequestContext) AddHeaderForUpstream(name, value string) { r.upstreamHe
 func (r *RequestContext) UpstreamHeaders() http.Header            { return r.upstreamHeaders }
 func (r *RequestContext) AddCookieForUpstream(name, value string) { r.upstreamCookies[name] = value }
 func (r *RequestContext) UpstreamCookies() map[string]string      { return r.upstreamCookies }
+func (r *RequestContext) ClientCookies() []*http.Cookie           { return r.clientCookies }
 func (r *RequestContext) AppContext() context.Context             { return r.req.Context() }
 func (r *RequestContext) SetPipelineError(err error)              { r.err = err }
 func (r *RequestContext) PipelineError() error                    { return r.err }
 func (r *RequestContext) Signer() heimdall.JWTSigner              { return r.jwtSigner }
+
+func (r *RequestContext) AddCookieForClient(cookie *http.Cookie) {
+	r.clientCookies = append(r.clientCookies, cookie)
+}
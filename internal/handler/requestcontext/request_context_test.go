@@ -18,6 +18,7 @@ package requestcontext
 
 import (
 	"bytes"
+	"crypto/x509"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -25,6 +26,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/x/httpx"
 )
 
 func TestRequestContextRequestClientIPs(t *testing.T) {
@@ -155,6 +158,28 @@ func TestRequestContextHeader(t *testing.T) {
 	assert.Empty(t, emptyValue)
 }
 
+func TestRequestContextHeaderValues(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	req := httptest.NewRequest(http.MethodHead, "https://foo.bar/test", nil)
+	req.Header.Set("X-Foo-Bar", "foo")
+	req.Header.Add("X-Foo-Bar", "bar, baz")
+	req.Host = "bar.foo"
+
+	ctx := New(nil, req)
+
+	// WHEN
+	xFooBarValues := ctx.Request().HeaderValues("x-foo-bar")
+	hostValues := ctx.Request().HeaderValues("Host")
+	emptyValues := ctx.Request().HeaderValues("X-Not-Present")
+
+	// THEN
+	assert.Equal(t, []string{"foo", "bar, baz"}, xFooBarValues)
+	assert.Equal(t, []string{"bar.foo"}, hostValues)
+	assert.Empty(t, emptyValues)
+}
+
 func TestRequestContextCookie(t *testing.T) {
 	t.Parallel()
 
@@ -240,3 +265,69 @@ func TestRequestContextBody(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestContextRawBody(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	req := httptest.NewRequest(http.MethodPost, "https://foo.bar/test", bytes.NewBufferString("content=heimdall"))
+	ctx := New(nil, req)
+
+	// WHEN
+	data := ctx.Request().RawBody()
+
+	// THEN
+	assert.Equal(t, []byte("content=heimdall"), data)
+
+	// body is still available
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, data, body)
+	assert.Equal(t, "content=heimdall", ctx.Request().Body())
+}
+
+func TestRequestContextClientCertificates(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cert := &x509.Certificate{}
+	caCert := &x509.Certificate{}
+
+	plainReq := httptest.NewRequest(http.MethodGet, "http://foo.bar/test", nil)
+	tlsReq := httptest.NewRequest(http.MethodGet, "https://foo.bar/test", nil)
+	tlsReq.TLS.PeerCertificates = []*x509.Certificate{cert}
+
+	verifiedReq := httptest.NewRequest(http.MethodGet, "https://foo.bar/test", nil)
+	verifiedReq.TLS.PeerCertificates = []*x509.Certificate{cert}
+	verifiedReq.TLS.VerifiedChains = [][]*x509.Certificate{{cert, caCert}}
+
+	// WHEN
+	plainCerts := New(nil, plainReq).Request().ClientCertificates
+	tlsCerts := New(nil, tlsReq).Request().ClientCertificates
+	verifiedCerts := New(nil, verifiedReq).Request().ClientCertificates
+
+	// THEN
+	assert.Empty(t, plainCerts)
+	require.Len(t, tlsCerts, 1)
+	assert.Same(t, cert, tlsCerts[0])
+	require.Len(t, verifiedCerts, 2)
+	assert.Same(t, cert, verifiedCerts[0])
+	assert.Same(t, caCert, verifiedCerts[1])
+}
+
+func TestRequestContextFromTrustedProxy(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	directReq := httptest.NewRequest(http.MethodGet, "http://foo.bar/test", nil)
+	proxiedReq := httptest.NewRequest(http.MethodGet, "http://foo.bar/test", nil)
+	proxiedReq = proxiedReq.WithContext(httpx.WithTrustedProxy(proxiedReq.Context()))
+
+	// WHEN
+	direct := New(nil, directReq).Request().FromTrustedProxy
+	proxied := New(nil, proxiedReq).Request().FromTrustedProxy
+
+	// THEN
+	assert.False(t, direct)
+	assert.True(t, proxied)
+}
@@ -18,6 +18,8 @@ package heimdall
 
 import (
 	"context"
+	"crypto/x509"
+	"net/http"
 	"net/url"
 )
 
@@ -28,6 +30,7 @@ type Context interface {
 
 	AddHeaderForUpstream(name, value string)
 	AddCookieForUpstream(name, value string)
+	AddCookieForClient(cookie *http.Cookie)
 
 	AppContext() context.Context
 
@@ -40,15 +43,38 @@ type Context interface {
 
 type RequestFunctions interface {
 	Header(name string) string
+	// HeaderValues returns the values of all fields with the given name in the order
+	// these are present in the request.
+	HeaderValues(name string) []string
 	Cookie(name string) string
 	Headers() map[string]string
 	Body() any
+	// RawBody returns the body of the request as is.
+	RawBody() []byte
 }
 
 type Request struct {
 	RequestFunctions
 
 	Method            string
-	URL               *url.URL
+	URL               *URL
 	ClientIPAddresses []string
+
+	// ClientCertificates holds the certificate chain presented by the client in the
+	// TLS handshake, starting with the end entity certificate. If client authentication
+	// is configured for the listener, it is the verified chain including the trust anchor.
+	// Empty if heimdall does not terminate TLS, or the client did not present a certificate.
+	ClientCertificates []*x509.Certificate
+
+	// FromTrustedProxy is set if the request has been received from a trusted proxy. Only
+	// then headers set by a proxy, like ones forwarding the client certificate, can be relied on.
+	FromTrustedProxy bool
+}
+
+type URL struct {
+	url.URL
+
+	// Captures holds the values of the named captures defined in the url pattern
+	// of the rule, which matched the request.
+	Captures map[string]string
 }
@@ -18,6 +18,7 @@ package heimdall
 
 import (
 	"errors"
+	"net/http"
 	"reflect"
 )
 
@@ -37,8 +38,22 @@ type RedirectError struct {
 	Message    string
 	Code       int
 	RedirectTo string
+	Cookies    []*http.Cookie
 }
 
 func (e *RedirectError) Error() string { return e.Message }
 
 func (e *RedirectError) Is(target error) bool { return reflect.TypeOf(e) == reflect.TypeOf(target) }
+
+// ResponseError instructs heimdall to answer the request with the given code and cookies
+// without forwarding it to the upstream. It is used by mechanisms, which implement protocol
+// endpoints on their own, like the back-channel logout endpoint of the oidc_session authenticator.
+type ResponseError struct {
+	Message string
+	Code    int
+	Cookies []*http.Cookie
+}
+
+func (e *ResponseError) Error() string { return e.Message }
+
+func (e *ResponseError) Is(target error) bool { return reflect.TypeOf(e) == reflect.TypeOf(target) }
@@ -17,6 +17,7 @@
 package heimdall
 
 import (
+	"crypto"
 	"time"
 
 	"github.com/go-jose/go-jose/v4"
@@ -28,4 +29,7 @@ type JWTSigner interface {
 	Sign(sub string, ttl time.Duration, claims map[string]any) (string, error)
 	Hash() []byte
 	Keys() []jose.JSONWebKey
+	// SigningKey returns the private key currently used for signing purposes
+	// together with its public JWK representation.
+	SigningKey() (jose.JSONWebKey, crypto.Signer)
 }
@@ -4,8 +4,10 @@ package mocks
 
 import (
 	context "context"
+	http "net/http"
 
 	heimdall "github.com/dadrus/heimdall/internal/heimdall"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -22,6 +24,39 @@ func (_m *ContextMock) EXPECT() *ContextMock_Expecter {
 	return &ContextMock_Expecter{mock: &_m.Mock}
 }
 
+// AddCookieForClient provides a mock function with given fields: cookie
+func (_m *ContextMock) AddCookieForClient(cookie *http.Cookie) {
+	_m.Called(cookie)
+}
+
+// ContextMock_AddCookieForClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddCookieForClient'
+type ContextMock_AddCookieForClient_Call struct {
+	*mock.Call
+}
+
+// AddCookieForClient is a helper method to define mock.On call
+//   - cookie *http.Cookie
+func (_e *ContextMock_Expecter) AddCookieForClient(cookie interface{}) *ContextMock_AddCookieForClient_Call {
+	return &ContextMock_AddCookieForClient_Call{Call: _e.mock.On("AddCookieForClient", cookie)}
+}
+
+func (_c *ContextMock_AddCookieForClient_Call) Run(run func(cookie *http.Cookie)) *ContextMock_AddCookieForClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*http.Cookie))
+	})
+	return _c
+}
+
+func (_c *ContextMock_AddCookieForClient_Call) Return() *ContextMock_AddCookieForClient_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ContextMock_AddCookieForClient_Call) RunAndReturn(run func(*http.Cookie)) *ContextMock_AddCookieForClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddCookieForUpstream provides a mock function with given fields: name, value
 func (_m *ContextMock) AddCookieForUpstream(name string, value string) {
 	_m.Called(name, value)
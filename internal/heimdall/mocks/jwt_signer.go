@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	crypto "crypto"
+
 	jose "github.com/go-jose/go-jose/v4"
 	mock "github.com/stretchr/testify/mock"
 
@@ -162,6 +164,59 @@ func (_c *JWTSignerMock_Sign_Call) RunAndReturn(run func(string, time.Duration,
 	return _c
 }
 
+// SigningKey provides a mock function with given fields:
+func (_m *JWTSignerMock) SigningKey() (jose.JSONWebKey, crypto.Signer) {
+	ret := _m.Called()
+
+	var r0 jose.JSONWebKey
+	var r1 crypto.Signer
+	if rf, ok := ret.Get(0).(func() (jose.JSONWebKey, crypto.Signer)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() jose.JSONWebKey); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(jose.JSONWebKey)
+	}
+
+	if rf, ok := ret.Get(1).(func() crypto.Signer); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(crypto.Signer)
+		}
+	}
+
+	return r0, r1
+}
+
+// JWTSignerMock_SigningKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SigningKey'
+type JWTSignerMock_SigningKey_Call struct {
+	*mock.Call
+}
+
+// SigningKey is a helper method to define mock.On call
+func (_e *JWTSignerMock_Expecter) SigningKey() *JWTSignerMock_SigningKey_Call {
+	return &JWTSignerMock_SigningKey_Call{Call: _e.mock.On("SigningKey")}
+}
+
+func (_c *JWTSignerMock_SigningKey_Call) Run(run func()) *JWTSignerMock_SigningKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *JWTSignerMock_SigningKey_Call) Return(_a0 jose.JSONWebKey, _a1 crypto.Signer) *JWTSignerMock_SigningKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *JWTSignerMock_SigningKey_Call) RunAndReturn(run func() (jose.JSONWebKey, crypto.Signer)) *JWTSignerMock_SigningKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 type mockConstructorTestingTNewJWTSignerMock interface {
 	mock.TestingT
 	Cleanup(func())
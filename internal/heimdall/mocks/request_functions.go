@@ -144,6 +144,50 @@ func (_c *RequestFunctionsMock_Header_Call) RunAndReturn(run func(string) string
 	return _c
 }
 
+// HeaderValues provides a mock function with given fields: name
+func (_m *RequestFunctionsMock) HeaderValues(name string) []string {
+	ret := _m.Called(name)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// RequestFunctionsMock_HeaderValues_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HeaderValues'
+type RequestFunctionsMock_HeaderValues_Call struct {
+	*mock.Call
+}
+
+// HeaderValues is a helper method to define mock.On call
+//   - name string
+func (_e *RequestFunctionsMock_Expecter) HeaderValues(name interface{}) *RequestFunctionsMock_HeaderValues_Call {
+	return &RequestFunctionsMock_HeaderValues_Call{Call: _e.mock.On("HeaderValues", name)}
+}
+
+func (_c *RequestFunctionsMock_HeaderValues_Call) Run(run func(name string)) *RequestFunctionsMock_HeaderValues_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *RequestFunctionsMock_HeaderValues_Call) Return(_a0 []string) *RequestFunctionsMock_HeaderValues_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *RequestFunctionsMock_HeaderValues_Call) RunAndReturn(run func(string) []string) *RequestFunctionsMock_HeaderValues_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Headers provides a mock function with given fields:
 func (_m *RequestFunctionsMock) Headers() map[string]string {
 	ret := _m.Called()
@@ -187,6 +231,49 @@ func (_c *RequestFunctionsMock_Headers_Call) RunAndReturn(run func() map[string]
 	return _c
 }
 
+// RawBody provides a mock function with given fields:
+func (_m *RequestFunctionsMock) RawBody() []byte {
+	ret := _m.Called()
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func() []byte); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	return r0
+}
+
+// RequestFunctionsMock_RawBody_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RawBody'
+type RequestFunctionsMock_RawBody_Call struct {
+	*mock.Call
+}
+
+// RawBody is a helper method to define mock.On call
+func (_e *RequestFunctionsMock_Expecter) RawBody() *RequestFunctionsMock_RawBody_Call {
+	return &RequestFunctionsMock_RawBody_Call{Call: _e.mock.On("RawBody")}
+}
+
+func (_c *RequestFunctionsMock_RawBody_Call) Run(run func()) *RequestFunctionsMock_RawBody_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *RequestFunctionsMock_RawBody_Call) Return(_a0 []byte) *RequestFunctionsMock_RawBody_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *RequestFunctionsMock_RawBody_Call) RunAndReturn(run func() []byte) *RequestFunctionsMock_RawBody_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 type mockConstructorTestingTNewRequestFunctionsMock interface {
 	mock.TestingT
 	Cleanup(func())
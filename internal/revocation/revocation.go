@@ -0,0 +1,147 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package revocation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// Type defines what has been revoked.
+type Type string
+
+const (
+	// TypeTokenID revokes a single token by its jti claim.
+	TypeTokenID Type = "jti"
+	// TypeSessionID revokes all tokens issued for a session (sid claim) before the revocation.
+	TypeSessionID Type = "sid"
+	// TypeSubject revokes all tokens issued for a subject before the revocation.
+	TypeSubject Type = "sub"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported revocation type")
+	ErrEmptyValue      = errors.New("revocation value is empty")
+	ErrInvalidTTL      = errors.New("revocation ttl must be greater than 0")
+)
+
+// Revoke stores the revocation of the given value in the cache for the given ttl. The ttl should not be
+// shorter than the lifetime of the tokens, respectively sessions, affected by the revocation.
+func Revoke(ctx context.Context, cch cache.Cache, typ Type, value string, ttl time.Duration) error {
+	switch {
+	case typ != TypeTokenID && typ != TypeSessionID && typ != TypeSubject:
+		return errorchain.NewWithMessagef(ErrUnsupportedType, "'%s'", typ)
+	case len(value) == 0:
+		return ErrEmptyValue
+	case ttl <= 0:
+		return ErrInvalidTTL
+	}
+
+	return cch.Set(ctx, cacheKey(typ, value),
+		stringx.ToBytes(strconv.FormatInt(time.Now().Unix(), 10)), ttl)
+}
+
+// IsRevoked checks whether the token, the session or the subject, the given subject has been created
+// from, is revoked. Session and subject revocations apply only to tokens issued before the revocation.
+// As the iat claim has a resolution of seconds, tokens issued within the same second as the revocation
+// are not affected. That way, tokens issued right after a revocation, e.g. on a new login, stay valid.
+// If the iat claim is not available, these apply to all tokens. Errors from the cache are treated as
+// "not revoked".
+func IsRevoked(ctx context.Context, cch cache.Cache, sub *subject.Subject) bool {
+	if sub == nil {
+		return false
+	}
+
+	if jti, ok := sub.Attributes["jti"].(string); ok && len(jti) != 0 {
+		if _, found := revokedAt(ctx, cch, TypeTokenID, jti); found {
+			return true
+		}
+	}
+
+	issuedAt, hasIssuedAt := issuedAt(sub.Attributes["iat"])
+
+	for typ, value := range map[Type]string{
+		TypeSessionID: stringValue(sub.Attributes["sid"]),
+		TypeSubject:   sub.ID,
+	} {
+		if len(value) == 0 {
+			continue
+		}
+
+		if at, found := revokedAt(ctx, cch, typ, value); found && (!hasIssuedAt || issuedAt.Before(at)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func revokedAt(ctx context.Context, cch cache.Cache, typ Type, value string) (time.Time, bool) {
+	entry, err := cch.Get(ctx, cacheKey(typ, value))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	seconds, err := strconv.ParseInt(stringx.ToString(entry), 10, 64)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("_type", string(typ)).Msg("Invalid revocation entry")
+
+		// better safe than sorry
+		return time.Now(), true
+	}
+
+	return time.Unix(seconds, 0), true
+}
+
+func cacheKey(typ Type, value string) string {
+	digest := sha256.Sum256(stringx.ToBytes(value))
+
+	return "revocation:" + string(typ) + ":" + hex.EncodeToString(digest[:])
+}
+
+func stringValue(value any) string {
+	str, _ := value.(string)
+
+	return str
+}
+
+func issuedAt(value any) (time.Time, bool) {
+	switch iat := value.(type) {
+	case float64:
+		return time.Unix(int64(iat), 0), true
+	case int64:
+		return time.Unix(iat, 0), true
+	case json.Number:
+		seconds, err := iat.Int64()
+
+		return time.Unix(seconds, 0), err == nil
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package revocation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/cache/noop"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+)
+
+func TestRevoke(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		typ    Type
+		value  string
+		ttl    time.Duration
+		assert func(t *testing.T, err error)
+	}{
+		{
+			uc:    "unsupported type",
+			typ:   "foo",
+			value: "bar",
+			ttl:   time.Minute,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedType)
+			},
+		},
+		{
+			uc:  "empty value",
+			typ: TypeTokenID,
+			ttl: time.Minute,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrEmptyValue)
+			},
+		},
+		{
+			uc:    "invalid ttl",
+			typ:   TypeSubject,
+			value: "bar",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidTTL)
+			},
+		},
+		{
+			uc:    "valid revocation",
+			typ:   TypeSessionID,
+			value: "bar",
+			ttl:   time.Minute,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			cch, err := memory.NewCache(nil, nil)
+			require.NoError(t, err)
+
+			// WHEN
+			err = Revoke(context.TODO(), cch, tc.typ, tc.value, tc.ttl)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
+func TestIsRevoked(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, Revoke(context.TODO(), cch, TypeTokenID, "revoked-token", time.Minute))
+	require.NoError(t, Revoke(context.TODO(), cch, TypeSessionID, "revoked-session", time.Minute))
+	require.NoError(t, Revoke(context.TODO(), cch, TypeSubject, "revoked-subject", time.Minute))
+	require.NoError(t, cch.Set(context.TODO(), cacheKey(TypeSubject, "broken-entry"), []byte("foo"), time.Minute))
+	require.NoError(t, cch.Set(context.TODO(), cacheKey(TypeSubject, "boundary"), []byte("1700000000"), time.Minute))
+
+	before := time.Now().Add(-time.Hour).Unix()
+	after := time.Now().Add(time.Hour).Unix()
+
+	for _, tc := range []struct {
+		uc      string
+		sub     *subject.Subject
+		revoked bool
+	}{
+		{uc: "no subject"},
+		{
+			uc:  "nothing revoked",
+			sub: &subject.Subject{ID: "foo", Attributes: map[string]any{"jti": "foo", "sid": "bar"}},
+		},
+		{
+			uc:      "token revoked",
+			sub:     &subject.Subject{ID: "foo", Attributes: map[string]any{"jti": "revoked-token", "iat": float64(after)}},
+			revoked: true,
+		},
+		{
+			uc:      "session revoked and token issued before revocation",
+			sub:     &subject.Subject{ID: "foo", Attributes: map[string]any{"sid": "revoked-session", "iat": float64(before)}},
+			revoked: true,
+		},
+		{
+			uc: "session revoked, but token issued after revocation",
+			sub: &subject.Subject{
+				ID:         "foo",
+				Attributes: map[string]any{"sid": "revoked-session", "iat": json.Number("9999999999")},
+			},
+		},
+		{
+			uc:      "subject revoked and no issuance time available",
+			sub:     &subject.Subject{ID: "revoked-subject", Attributes: map[string]any{}},
+			revoked: true,
+		},
+		{
+			uc:      "subject revoked and token issued before revocation",
+			sub:     &subject.Subject{ID: "revoked-subject", Attributes: map[string]any{"iat": before}},
+			revoked: true,
+		},
+		{
+			uc:  "subject revoked, but token issued after revocation",
+			sub: &subject.Subject{ID: "revoked-subject", Attributes: map[string]any{"iat": float64(after)}},
+		},
+		{
+			uc:  "subject revoked, but token issued in the same second as the revocation",
+			sub: &subject.Subject{ID: "boundary", Attributes: map[string]any{"iat": float64(1700000000)}},
+		},
+		{
+			uc:      "subject revoked and token issued a fraction of a second before the revocation",
+			sub:     &subject.Subject{ID: "boundary", Attributes: map[string]any{"iat": 1699999999.9}},
+			revoked: true,
+		},
+		{
+			uc:      "subject revoked and token issued a second before the revocation",
+			sub:     &subject.Subject{ID: "boundary", Attributes: map[string]any{"iat": json.Number("1699999999")}},
+			revoked: true,
+		},
+		{
+			uc:      "subject revoked with broken revocation entry",
+			sub:     &subject.Subject{ID: "broken-entry", Attributes: map[string]any{"iat": float64(before)}},
+			revoked: true,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			revoked := IsRevoked(context.TODO(), cch, tc.sub)
+
+			// THEN
+			assert.Equal(t, tc.revoked, revoked)
+		})
+	}
+}
+
+func TestIsRevokedWithoutCache(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	revoked := IsRevoked(context.TODO(), &noop.Cache{}, &subject.Subject{ID: "foo"})
+
+	// THEN
+	assert.False(t, revoked)
+}
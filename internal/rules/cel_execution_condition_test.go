@@ -104,11 +104,13 @@ func TestCelExecutionConditionCanExecute(t *testing.T) {
 
 			ctx.EXPECT().Request().Return(&heimdall.Request{
 				Method: http.MethodGet,
-				URL: &url.URL{
-					Scheme:   "http",
-					Host:     "localhost",
-					Path:     "/test",
-					RawQuery: "foo=bar&baz=zab",
+				URL: &heimdall.URL{
+					URL: url.URL{
+						Scheme:   "http",
+						Host:     "localhost",
+						Path:     "/test",
+						RawQuery: "foo=bar&baz=zab",
+					},
 				},
 				ClientIPAddresses: []string{"127.0.0.1", "10.10.10.10"},
 			})
@@ -24,7 +24,7 @@ import (
 
 type Backend struct {
 	Host        string       `json:"host"    yaml:"host"`
-	URLRewriter *URLRewriter `json:"rewrite" yaml:"rewrite"`
+	URLRewriter *URLRewriter `json:"rewrite" yaml:"rewrite,omitempty"`
 }
 
 func (f *Backend) CreateURL(value *url.URL) *url.URL {
@@ -0,0 +1,66 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"github.com/dadrus/heimdall/internal/config"
+)
+
+type CanaryStickiness string
+
+const (
+	CanaryStickyBySubject CanaryStickiness = "subject"
+	CanaryStickyByHeader  CanaryStickiness = "header"
+)
+
+// Canary defines a variant of a rule, which is used for the given percentage of the
+// matched requests only. Settings not defined by the variant are taken from the rule.
+type Canary struct {
+	Percentage   int                      `json:"percentage" yaml:"percentage"          validate:"min=0,max=100"`                  //nolint:lll,tagalign
+	StickyBy     CanaryStickiness         `json:"sticky_by"  yaml:"sticky_by,omitempty" validate:"omitempty,oneof=subject header"` //nolint:lll,tagalign
+	Header       string                   `json:"header"     yaml:"header,omitempty"    validate:"required_if=StickyBy header"`    //nolint:lll,tagalign
+	Backend      *Backend                 `json:"forward_to" yaml:"forward_to,omitempty"`
+	Execute      []config.MechanismConfig `json:"execute"    yaml:"execute,omitempty"`
+	ErrorHandler []config.MechanismConfig `json:"on_error"   yaml:"on_error,omitempty"`
+}
+
+func (in *Canary) DeepCopyInto(out *Canary) {
+	*out = *in
+
+	if in.Backend != nil {
+		out.Backend = new(Backend)
+		in.Backend.DeepCopyInto(out.Backend)
+	}
+
+	if in.Execute != nil {
+		in, out := &in.Execute, &out.Execute
+
+		*out = make([]config.MechanismConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+
+	if in.ErrorHandler != nil {
+		in, out := &in.ErrorHandler, &out.ErrorHandler
+
+		*out = make([]config.MechanismConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"slices"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// versionConversion converts rule sets between two adjacent rule set versions.
+type versionConversion struct {
+	from      string
+	to        string
+	upgrade   func(rs *RuleSet) error
+	downgrade func(rs *RuleSet) error
+}
+
+// versionConversions holds the conversions between the supported rule set versions ordered
+// from the oldest to the newest version. Support for a new rule set version is added by
+// appending the conversion from the previous version to it.
+//
+//nolint:gochecknoglobals
+var versionConversions = []versionConversion{
+	{
+		from: RuleSetVersion1Alpha3,
+		to:   RuleSetVersion1Alpha4,
+		// 1alpha4 is a superset of 1alpha3. So there is nothing to convert
+		upgrade:   func(*RuleSet) error { return nil },
+		downgrade: downgradeTo1Alpha3,
+	},
+}
+
+// SupportedRuleSetVersions returns the rule set versions supported by heimdall ordered
+// from the oldest to the newest one.
+func SupportedRuleSetVersions() []string {
+	versions := make([]string, 0, len(versionConversions)+1)
+	versions = append(versions, versionConversions[0].from)
+
+	for _, conversion := range versionConversions {
+		versions = append(versions, conversion.to)
+	}
+
+	return versions
+}
+
+func IsSupportedRuleSetVersion(version string) bool {
+	return slices.Contains(SupportedRuleSetVersions(), version)
+}
+
+// ConvertRuleSet returns a copy of the given rule set converted to the given version. The
+// conversion happens step by step over all versions in between. Conversion to an older version
+// fails if the rule set makes use of features not available in that version. Defaults and
+// fragments are resolved in that case.
+func ConvertRuleSet(rs *RuleSet, version string) (*RuleSet, error) {
+	versions := SupportedRuleSetVersions()
+
+	from := slices.Index(versions, rs.Version)
+	if from == -1 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported source rule set version '%s'", rs.Version)
+	}
+
+	to := slices.Index(versions, version)
+	if to == -1 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported target rule set version '%s'", version)
+	}
+
+	converted := rs.DeepCopy()
+
+	for ; from < to; from++ {
+		conversion := versionConversions[from]
+
+		if err := conversion.upgrade(converted); err != nil {
+			return nil, err
+		}
+
+		converted.Version = conversion.to
+	}
+
+	for ; from > to; from-- {
+		conversion := versionConversions[from-1]
+
+		if err := conversion.downgrade(converted); err != nil {
+			return nil, err
+		}
+
+		converted.Version = conversion.from
+	}
+
+	return converted, nil
+}
+
+func downgradeTo1Alpha3(rs *RuleSet) error {
+	if rs.Priority != 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"rule set defines a priority, which is not available in rule set version %s", RuleSetVersion1Alpha3)
+	}
+
+	for idx, rule := range rs.Rules {
+		expanded, err := rs.ExpandRule(rule)
+		if err != nil {
+			return err
+		}
+
+		var feature string
+
+		switch {
+		case expanded.RuleMatcher.HasRequestConditions():
+			feature = "request conditions"
+		case expanded.Canary != nil:
+			feature = "a canary"
+		case expanded.Priority != nil:
+			feature = "a priority"
+		case len(expanded.Mode) != 0 || hasStepModes(expanded.Execute) || hasStepModes(expanded.ErrorHandler):
+			feature = "execution modes"
+		default:
+			rs.Rules[idx] = expanded
+
+			continue
+		}
+
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"rule ID=%s makes use of %s, which is not available in rule set version %s",
+			rule.ID, feature, RuleSetVersion1Alpha3)
+	}
+
+	rs.Defaults = nil
+	rs.Fragments = nil
+
+	return nil
+}
+
+func hasStepModes(steps []config.MechanismConfig) bool {
+	return slices.ContainsFunc(steps, func(step config.MechanismConfig) bool {
+		_, found := step["mode"]
+
+		return found
+	})
+}
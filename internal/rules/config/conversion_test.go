@@ -0,0 +1,305 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func TestSupportedRuleSetVersions(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{RuleSetVersion1Alpha3, RuleSetVersion1Alpha4}, SupportedRuleSetVersions())
+	assert.Equal(t, CurrentRuleSetVersion, SupportedRuleSetVersions()[len(SupportedRuleSetVersions())-1])
+	assert.True(t, IsSupportedRuleSetVersion(RuleSetVersion1Alpha3))
+	assert.True(t, IsSupportedRuleSetVersion(RuleSetVersion1Alpha4))
+	assert.False(t, IsSupportedRuleSetVersion("1alpha2"))
+}
+
+func TestConvertRuleSet(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc      string
+		ruleSet *RuleSet
+		version string
+		assert  func(t *testing.T, err error, org, converted *RuleSet)
+	}{
+		{
+			uc:      "unsupported source version",
+			ruleSet: &RuleSet{Version: "1alpha2"},
+			version: CurrentRuleSetVersion,
+			assert: func(t *testing.T, err error, _, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported source")
+			},
+		},
+		{
+			uc:      "unsupported target version",
+			ruleSet: &RuleSet{Version: CurrentRuleSetVersion},
+			version: "1beta1",
+			assert: func(t *testing.T, err error, _, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported target")
+			},
+		},
+		{
+			uc: "conversion to the same version",
+			ruleSet: &RuleSet{
+				Version: CurrentRuleSetVersion,
+				Name:    "test",
+				Rules:   []Rule{{ID: "foo", Execute: []config.MechanismConfig{{"authenticator": "bar"}}}},
+			},
+			version: CurrentRuleSetVersion,
+			assert: func(t *testing.T, err error, org, converted *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, org, converted)
+				assert.NotSame(t, org, converted)
+			},
+		},
+		{
+			uc: "upgrade from 1alpha3",
+			ruleSet: &RuleSet{
+				Version:  RuleSetVersion1Alpha3,
+				Name:     "test",
+				Priority: 10,
+				Rules: []Rule{
+					{
+						ID:          "foo",
+						RuleMatcher: Matcher{URL: "http://foo.bar/<**>", Strategy: "glob"},
+						Execute:     []config.MechanismConfig{{"authenticator": "bar"}},
+					},
+				},
+			},
+			version: RuleSetVersion1Alpha4,
+			assert: func(t *testing.T, err error, org, converted *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, RuleSetVersion1Alpha3, org.Version)
+				assert.Equal(t, RuleSetVersion1Alpha4, converted.Version)
+				assert.Equal(t, org.Name, converted.Name)
+				assert.Equal(t, org.Priority, converted.Priority)
+				assert.Equal(t, org.Rules, converted.Rules)
+			},
+		},
+		{
+			uc: "converted rule set does not share backends with the original one",
+			ruleSet: &RuleSet{
+				Version: RuleSetVersion1Alpha3,
+				Name:    "test",
+				Rules: []Rule{
+					{
+						ID:          "foo",
+						RuleMatcher: Matcher{URL: "http://foo.bar/<**>", Strategy: "glob"},
+						Backend:     &Backend{Host: "bar.foo", URLRewriter: &URLRewriter{PathPrefixToCut: "/foo"}},
+						Execute:     []config.MechanismConfig{{"authenticator": "bar"}},
+					},
+				},
+			},
+			version: RuleSetVersion1Alpha4,
+			assert: func(t *testing.T, err error, org, converted *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, org.Rules[0].Backend, converted.Rules[0].Backend)
+				assert.NotSame(t, org.Rules[0].Backend, converted.Rules[0].Backend)
+
+				converted.Rules[0].Backend.Host = "baz.foo"
+				converted.Rules[0].Backend.URLRewriter.PathPrefixToCut = "/bar"
+
+				assert.Equal(t, "bar.foo", org.Rules[0].Backend.Host)
+				assert.Equal(t, PrefixCutter("/foo"), org.Rules[0].Backend.URLRewriter.PathPrefixToCut)
+			},
+		},
+		{
+			uc: "downgrade to 1alpha3 resolving defaults and fragments",
+			ruleSet: &RuleSet{
+				Version: RuleSetVersion1Alpha4,
+				Name:    "test",
+				Defaults: &RuleDefaults{
+					Methods: []string{"GET"},
+					Execute: []config.MechanismConfig{{"fragment": "authn"}},
+				},
+				Fragments: map[string][]config.MechanismConfig{
+					"authn": {{"authenticator": "foo"}, {"authenticator": "bar"}},
+				},
+				Rules: []Rule{
+					{ID: "foo", Execute: []config.MechanismConfig{{"authorizer": "baz"}}},
+					{ID: "bar", Methods: []string{"POST"}},
+				},
+			},
+			version: RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, org, converted *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, RuleSetVersion1Alpha3, converted.Version)
+				assert.Nil(t, converted.Defaults)
+				assert.Nil(t, converted.Fragments)
+				require.Len(t, converted.Rules, 2)
+				assert.Equal(t, Rule{
+					ID:      "foo",
+					Methods: []string{"GET"},
+					Execute: []config.MechanismConfig{
+						{"authenticator": "foo"}, {"authenticator": "bar"}, {"authorizer": "baz"},
+					},
+				}, converted.Rules[0])
+				assert.Equal(t, Rule{
+					ID:      "bar",
+					Methods: []string{"POST"},
+					Execute: []config.MechanismConfig{{"authenticator": "foo"}, {"authenticator": "bar"}},
+				}, converted.Rules[1])
+
+				// the original rule set is not modified
+				assert.NotNil(t, org.Defaults)
+				assert.NotNil(t, org.Fragments)
+				assert.Equal(t, []config.MechanismConfig{{"authorizer": "baz"}}, org.Rules[0].Execute)
+			},
+		},
+		{
+			uc: "downgrade to 1alpha3 with request conditions",
+			ruleSet: &RuleSet{
+				Version: RuleSetVersion1Alpha4,
+				Rules:   []Rule{{ID: "foo", RuleMatcher: Matcher{URL: "/foo", Scheme: "https"}}},
+			},
+			version: RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, _, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "request conditions")
+			},
+		},
+		{
+			uc: "downgrade to 1alpha3 with canary",
+			ruleSet: &RuleSet{
+				Version: RuleSetVersion1Alpha4,
+				Rules:   []Rule{{ID: "foo", Canary: &Canary{Percentage: 10}}},
+			},
+			version: RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, _, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "canary")
+			},
+		},
+		{
+			uc: "downgrade to 1alpha3 with rule set priority",
+			ruleSet: &RuleSet{
+				Version:  RuleSetVersion1Alpha4,
+				Priority: 10,
+				Rules:    []Rule{{ID: "foo"}},
+			},
+			version: RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, _, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "rule set defines a priority")
+			},
+		},
+		{
+			uc: "downgrade to 1alpha3 with rule priority",
+			ruleSet: &RuleSet{
+				Version: RuleSetVersion1Alpha4,
+				Rules:   []Rule{{ID: "foo", Priority: new(int)}},
+			},
+			version: RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, _, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "rule ID=foo makes use of a priority")
+			},
+		},
+		{
+			uc: "downgrade to 1alpha3 with rule mode",
+			ruleSet: &RuleSet{
+				Version: RuleSetVersion1Alpha4,
+				Rules:   []Rule{{ID: "foo", Mode: ExecutionModeShadow}},
+			},
+			version: RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, _, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "rule ID=foo makes use of execution modes")
+			},
+		},
+		{
+			uc: "downgrade to 1alpha3 with pipeline step mode defined in a fragment",
+			ruleSet: &RuleSet{
+				Version:   RuleSetVersion1Alpha4,
+				Fragments: map[string][]config.MechanismConfig{"bar": {{"authorizer": "bar", "mode": "shadow"}}},
+				Rules:     []Rule{{ID: "foo", Execute: []config.MechanismConfig{{"fragment": "bar"}}}},
+			},
+			version: RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, _, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "rule ID=foo makes use of execution modes")
+			},
+		},
+		{
+			uc: "downgrade to 1alpha3 with unknown fragment",
+			ruleSet: &RuleSet{
+				Version:   RuleSetVersion1Alpha4,
+				Fragments: map[string][]config.MechanismConfig{"bar": {{"authenticator": "bar"}}},
+				Rules:     []Rule{{ID: "foo", Execute: []config.MechanismConfig{{"fragment": "foo"}}}},
+			},
+			version: RuleSetVersion1Alpha3,
+			assert: func(t *testing.T, err error, _, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unknown fragment")
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			converted, err := ConvertRuleSet(tc.ruleSet, tc.version)
+
+			// THEN
+			tc.assert(t, err, tc.ruleSet, converted)
+		})
+	}
+}
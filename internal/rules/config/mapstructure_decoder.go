@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/go-viper/mapstructure/v2"
+
 	"github.com/dadrus/heimdall/internal/x"
 )
 
@@ -74,8 +76,28 @@ func matcherDecodeHookFunc(from reflect.Type, to reflect.Type, data any) (any, e
 		}
 	}
 
-	return Matcher{
-		URL:      urlValue,
-		Strategy: x.IfThenElse(strategyPresent, strategyValue, "glob"),
-	}, nil
+	// the remaining properties are decoded by making use of a type without this
+	// hook registered to avoid endless recursion
+	type matcherProperties Matcher
+
+	var properties matcherProperties
+
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &properties,
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		TagName:          "json",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dec.Decode(values); err != nil {
+		return nil, err
+	}
+
+	properties.URL = urlValue
+	properties.Strategy = x.IfThenElse(strategyPresent, strategyValue, "glob")
+
+	return Matcher(properties), nil
 }
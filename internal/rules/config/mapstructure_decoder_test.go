@@ -146,6 +146,62 @@ match:
 				assert.Equal(t, "regex", matcher.Strategy)
 			},
 		},
+		{
+			uc: "specified as structured type with request conditions",
+			config: []byte(`
+match:
+  url: foo.bar
+  strategy: regex
+  scheme: https
+  hosts:
+    - foo.bar
+    - <.*>.bar.foo
+  headers:
+    X-Api-Version: 2
+  query_parameters:
+    foo: <ba[rz]>
+`),
+			assert: func(t *testing.T, err error, matcher *Matcher) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo.bar", matcher.URL)
+				assert.Equal(t, "regex", matcher.Strategy)
+				assert.Equal(t, "https", matcher.Scheme)
+				assert.Equal(t, []string{"foo.bar", "<.*>.bar.foo"}, matcher.Hosts)
+				assert.Equal(t, map[string]string{"X-Api-Version": "2"}, matcher.Headers)
+				assert.Equal(t, map[string]string{"foo": "<ba[rz]>"}, matcher.QueryParameters)
+				assert.True(t, matcher.HasRequestConditions())
+			},
+		},
+		{
+			uc: "specified as structured type with unsupported scheme",
+			config: []byte(`
+match:
+  url: foo.bar
+  scheme: ftp
+`),
+			assert: func(t *testing.T, err error, _ *Matcher) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "must be one of [http https]")
+			},
+		},
+		{
+			uc: "specified as structured type with unknown property",
+			config: []byte(`
+match:
+  url: foo.bar
+  foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *Matcher) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "foo")
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -17,14 +17,43 @@
 package config
 
 import (
+	"maps"
+
 	"github.com/goccy/go-json"
 
 	"github.com/dadrus/heimdall/internal/x/stringx"
 )
 
 type Matcher struct {
-	URL      string `json:"url"      yaml:"url"`
-	Strategy string `json:"strategy" yaml:"strategy"`
+	URL             string            `json:"url"                        yaml:"url"`
+	Strategy        string            `json:"strategy"                   yaml:"strategy,omitempty"`
+	Scheme          string            `json:"scheme,omitempty"           yaml:"scheme,omitempty"           validate:"omitempty,oneof=http https"`          //nolint:lll,tagalign
+	Hosts           []string          `json:"hosts,omitempty"            yaml:"hosts,omitempty"            validate:"dive,required"`                       //nolint:lll,tagalign
+	Headers         map[string]string `json:"headers,omitempty"          yaml:"headers,omitempty"          validate:"dive,keys,required,endkeys,required"` //nolint:lll,tagalign
+	QueryParameters map[string]string `json:"query_parameters,omitempty" yaml:"query_parameters,omitempty" validate:"dive,keys,required,endkeys,required"` //nolint:lll,tagalign
+}
+
+// HasRequestConditions returns true if the matcher defines conditions beyond the
+// url pattern, which are only supported since rule set version 1alpha4.
+func (m *Matcher) HasRequestConditions() bool {
+	return len(m.Scheme) != 0 || len(m.Hosts) != 0 || len(m.Headers) != 0 || len(m.QueryParameters) != 0
+}
+
+func (m *Matcher) DeepCopyInto(out *Matcher) {
+	*out = *m
+
+	if m.Hosts != nil {
+		out.Hosts = make([]string, len(m.Hosts))
+		copy(out.Hosts, m.Hosts)
+	}
+
+	if m.Headers != nil {
+		out.Headers = maps.Clone(m.Headers)
+	}
+
+	if m.QueryParameters != nil {
+		out.QueryParameters = maps.Clone(m.QueryParameters)
+	}
 }
 
 func (m *Matcher) UnmarshalJSON(data []byte) error {
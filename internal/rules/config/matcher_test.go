@@ -90,6 +90,29 @@ func TestMatcherUnmarshalJSON(t *testing.T) {
 				assert.Equal(t, "glob", matcher.Strategy)
 			},
 		},
+		{
+			uc: "specified as structured type with request conditions",
+			config: []byte(`{
+"match": {
+  "url": "foo.bar",
+  "scheme": "http",
+  "hosts": ["foo.bar"],
+  "headers": { "X-Api-Version": "2" },
+  "query_parameters": { "foo": "bar" }
+}
+}`),
+			assert: func(t *testing.T, err error, matcher *Matcher) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo.bar", matcher.URL)
+				assert.Equal(t, "glob", matcher.Strategy)
+				assert.Equal(t, "http", matcher.Scheme)
+				assert.Equal(t, []string{"foo.bar"}, matcher.Hosts)
+				assert.Equal(t, map[string]string{"X-Api-Version": "2"}, matcher.Headers)
+				assert.Equal(t, map[string]string{"foo": "bar"}, matcher.QueryParameters)
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			var typ Typ
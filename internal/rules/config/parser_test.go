@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 )
 
@@ -203,6 +204,90 @@ rules:
 				assert.Equal(t, "bar", ruleSet.Rules[0].ID)
 			},
 		},
+		{
+			uc: "valid rule set spec with priority, defaults and fragments",
+			conf: []byte(`
+version: "1alpha4"
+priority: 10
+defaults:
+  methods: [ GET ]
+  forward_to:
+    host: foo.bar
+  execute:
+  - fragment: authn
+  on_error:
+  - error_handler: foo
+fragments:
+  authn:
+  - authenticator: foo
+  - authenticator: bar
+rules:
+- id: bar
+  priority: 20
+`),
+			assert: func(t *testing.T, err error, ruleSet *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, ruleSet)
+				assert.Equal(t, 10, ruleSet.Priority)
+				require.NotNil(t, ruleSet.Defaults)
+				assert.Equal(t, []string{"GET"}, ruleSet.Defaults.Methods)
+				assert.Equal(t, &Backend{Host: "foo.bar"}, ruleSet.Defaults.Backend)
+				assert.Equal(t, []config.MechanismConfig{{"fragment": "authn"}}, ruleSet.Defaults.Execute)
+				assert.Equal(t, []config.MechanismConfig{{"error_handler": "foo"}}, ruleSet.Defaults.ErrorHandler)
+				assert.Equal(t, map[string][]config.MechanismConfig{
+					"authn": {{"authenticator": "foo"}, {"authenticator": "bar"}},
+				}, ruleSet.Fragments)
+				require.Len(t, ruleSet.Rules, 1)
+				require.NotNil(t, ruleSet.Rules[0].Priority)
+				assert.Equal(t, 20, *ruleSet.Rules[0].Priority)
+			},
+		},
+		{
+			uc: "valid rule set spec with canary",
+			conf: []byte(`
+version: "1alpha4"
+rules:
+- id: bar
+  canary:
+    percentage: 10
+    sticky_by: header
+    header: X-Session
+    execute:
+    - authorizer: foo
+`),
+			assert: func(t *testing.T, err error, ruleSet *RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, ruleSet)
+				require.Len(t, ruleSet.Rules, 1)
+				assert.Equal(t, &Canary{
+					Percentage: 10,
+					StickyBy:   CanaryStickyByHeader,
+					Header:     "X-Session",
+					Execute:    []config.MechanismConfig{{"authorizer": "foo"}},
+				}, ruleSet.Rules[0].Canary)
+			},
+		},
+		{
+			uc: "rule set spec with canary sticky by header without header",
+			conf: []byte(`
+version: "1alpha4"
+rules:
+- id: bar
+  canary:
+    percentage: 10
+    sticky_by: header
+`),
+			assert: func(t *testing.T, err error, _ *RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
 		{
 			uc:           "valid rule set spec with invalid env spec",
 			envSupported: true,
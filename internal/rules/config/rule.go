@@ -28,24 +28,40 @@ const (
 	EncodedSlashesNoDecode EncodedSlashesHandling = "no_decode"
 )
 
+type ExecutionMode string
+
+const (
+	ExecutionModeEnforce ExecutionMode = "enforce"
+	ExecutionModeShadow  ExecutionMode = "shadow"
+)
+
 type Rule struct {
 	ID                     string                   `json:"id"                    yaml:"id"`
-	EncodedSlashesHandling EncodedSlashesHandling   `json:"allow_encoded_slashes" yaml:"allow_encoded_slashes" validate:"omitempty,oneof=off on no_decode"` //nolint:lll,tagalign
+	Priority               *int                     `json:"priority"              yaml:"priority,omitempty"`
+	Mode                   ExecutionMode            `json:"mode"                  yaml:"mode,omitempty"                  validate:"omitempty,oneof=enforce shadow"`   //nolint:lll,tagalign
+	EncodedSlashesHandling EncodedSlashesHandling   `json:"allow_encoded_slashes" yaml:"allow_encoded_slashes,omitempty" validate:"omitempty,oneof=off on no_decode"` //nolint:lll,tagalign
 	RuleMatcher            Matcher                  `json:"match"                 yaml:"match"`
-	Backend                *Backend                 `json:"forward_to"            yaml:"forward_to"`
-	Methods                []string                 `json:"methods"               yaml:"methods"`
+	Backend                *Backend                 `json:"forward_to"            yaml:"forward_to,omitempty"`
+	Methods                []string                 `json:"methods"               yaml:"methods,omitempty"`
 	Execute                []config.MechanismConfig `json:"execute"               yaml:"execute"`
-	ErrorHandler           []config.MechanismConfig `json:"on_error"              yaml:"on_error"`
+	ErrorHandler           []config.MechanismConfig `json:"on_error"              yaml:"on_error,omitempty"`
+	Canary                 *Canary                  `json:"canary"                yaml:"canary,omitempty"`
 }
 
 func (in *Rule) DeepCopyInto(out *Rule) {
 	*out = *in
-	out.RuleMatcher = in.RuleMatcher
+	in.RuleMatcher.DeepCopyInto(&out.RuleMatcher)
 
-	if in.Backend != nil {
-		in, out := in.Backend, out.Backend
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
 
-		in.DeepCopyInto(out)
+		*out = new(int)
+		**out = **in
+	}
+
+	if in.Backend != nil {
+		out.Backend = new(Backend)
+		in.Backend.DeepCopyInto(out.Backend)
 	}
 
 	if in.Methods != nil {
@@ -72,6 +88,11 @@ func (in *Rule) DeepCopyInto(out *Rule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+
+	if in.Canary != nil {
+		out.Canary = new(Canary)
+		in.Canary.DeepCopyInto(out.Canary)
+	}
 }
 
 func (in *Rule) DeepCopy() *Rule {
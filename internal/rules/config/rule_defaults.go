@@ -0,0 +1,181 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"maps"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// fragmentKey is the key used in execute and on_error pipeline steps to reference
+// a pipeline fragment defined in the rule set.
+const fragmentKey = "fragment"
+
+// RuleDefaults holds the settings applied to all rules of a rule set.
+type RuleDefaults struct {
+	Backend      *Backend                 `json:"forward_to" yaml:"forward_to,omitempty"`
+	Methods      []string                 `json:"methods"    yaml:"methods,omitempty"`
+	Execute      []config.MechanismConfig `json:"execute"    yaml:"execute,omitempty"`
+	ErrorHandler []config.MechanismConfig `json:"on_error"   yaml:"on_error,omitempty"`
+}
+
+func (in *RuleDefaults) DeepCopyInto(out *RuleDefaults) {
+	*out = *in
+
+	if in.Backend != nil {
+		out.Backend = new(Backend)
+		in.Backend.DeepCopyInto(out.Backend)
+	}
+
+	if in.Methods != nil {
+		out.Methods = make([]string, len(in.Methods))
+		copy(out.Methods, in.Methods)
+	}
+
+	if in.Execute != nil {
+		in, out := &in.Execute, &out.Execute
+
+		*out = make([]config.MechanismConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+
+	if in.ErrorHandler != nil {
+		in, out := &in.ErrorHandler, &out.ErrorHandler
+
+		*out = make([]config.MechanismConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// ExpandRule returns a copy of the given rule with the defaults of the rule set applied
+// and the pipeline fragments referenced by it resolved. The steps of the default execute
+// pipeline precede the steps of the rule. The default error pipeline, methods and
+// forward_to settings are only used if the rule does not define these.
+func (rs RuleSet) ExpandRule(rule Rule) (Rule, error) {
+	if rs.Defaults == nil && len(rs.Fragments) == 0 {
+		return rule, nil
+	}
+
+	if rs.Version == RuleSetVersion1Alpha3 {
+		return Rule{}, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"defaults and fragments require at least rule set version %s", RuleSetVersion1Alpha4)
+	}
+
+	var (
+		defaults RuleDefaults
+		err      error
+	)
+
+	// the slices and the backend of the copy are replaced, but never modified. So
+	// a shallow copy is sufficient
+	expanded := rule
+
+	if rs.Defaults != nil {
+		defaults = *rs.Defaults
+	}
+
+	execute := make([]config.MechanismConfig, 0, len(defaults.Execute)+len(rule.Execute))
+	execute = append(execute, defaults.Execute...)
+	execute = append(execute, rule.Execute...)
+
+	if expanded.Execute, err = rs.expandFragments(rule.ID, execute); err != nil {
+		return Rule{}, err
+	}
+
+	if len(rule.ErrorHandler) == 0 {
+		expanded.ErrorHandler = defaults.ErrorHandler
+	}
+
+	if expanded.ErrorHandler, err = rs.expandFragments(rule.ID, expanded.ErrorHandler); err != nil {
+		return Rule{}, err
+	}
+
+	if len(rule.Methods) == 0 && len(defaults.Methods) != 0 {
+		expanded.Methods = make([]string, len(defaults.Methods))
+		copy(expanded.Methods, defaults.Methods)
+	}
+
+	if rule.Backend == nil && defaults.Backend != nil {
+		expanded.Backend = &Backend{}
+		defaults.Backend.DeepCopyInto(expanded.Backend)
+	}
+
+	// the canary variant takes the settings it does not define from the expanded rule.
+	// So, only the fragments referenced by it need to be resolved
+	if rule.Canary != nil {
+		canary := *rule.Canary
+
+		if canary.Execute, err = rs.expandFragments(rule.ID, canary.Execute); err != nil {
+			return Rule{}, err
+		}
+
+		if canary.ErrorHandler, err = rs.expandFragments(rule.ID, canary.ErrorHandler); err != nil {
+			return Rule{}, err
+		}
+
+		expanded.Canary = &canary
+	}
+
+	return expanded, nil
+}
+
+func (rs RuleSet) expandFragments(ruleID string, steps []config.MechanismConfig) ([]config.MechanismConfig, error) {
+	if len(steps) == 0 {
+		return nil, nil
+	}
+
+	expanded := make([]config.MechanismConfig, 0, len(steps))
+
+	for _, step := range steps {
+		name, ok := step[fragmentKey]
+		if !ok {
+			expanded = append(expanded, maps.Clone(step))
+
+			continue
+		}
+
+		fragmentName, isString := name.(string)
+		if !isString || len(step) != 1 {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"malformed fragment reference in rule ID=%s", ruleID)
+		}
+
+		fragment, found := rs.Fragments[fragmentName]
+		if !found {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"unknown fragment '%s' referenced in rule ID=%s", fragmentName, ruleID)
+		}
+
+		for _, fragmentStep := range fragment {
+			if _, nested := fragmentStep[fragmentKey]; nested {
+				return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"fragment '%s' references another fragment, which is not supported", fragmentName)
+			}
+
+			expanded = append(expanded, maps.Clone(fragmentStep))
+		}
+	}
+
+	return expanded, nil
+}
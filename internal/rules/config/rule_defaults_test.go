@@ -0,0 +1,225 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func TestRuleSetExpandRule(t *testing.T) {
+	t.Parallel()
+
+	defaults := &RuleDefaults{
+		Backend:      &Backend{Host: "foo.bar"},
+		Methods:      []string{http.MethodGet},
+		Execute:      []config.MechanismConfig{{"authenticator": "foo"}},
+		ErrorHandler: []config.MechanismConfig{{"error_handler": "foo"}},
+	}
+
+	for _, tc := range []struct {
+		uc      string
+		ruleSet RuleSet
+		rule    Rule
+		assert  func(t *testing.T, err error, rule Rule)
+	}{
+		{
+			uc:      "without defaults and fragments",
+			ruleSet: RuleSet{Version: CurrentRuleSetVersion},
+			rule:    Rule{ID: "foo", Execute: []config.MechanismConfig{{"authenticator": "bar"}}},
+			assert: func(t *testing.T, err error, rule Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, Rule{ID: "foo", Execute: []config.MechanismConfig{{"authenticator": "bar"}}}, rule)
+			},
+		},
+		{
+			uc:      "with defaults in an unsupported rule set version",
+			ruleSet: RuleSet{Version: RuleSetVersion1Alpha3, Defaults: defaults},
+			rule:    Rule{ID: "foo"},
+			assert: func(t *testing.T, err error, _ Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "require at least rule set version")
+			},
+		},
+		{
+			uc:      "rule without own settings",
+			ruleSet: RuleSet{Version: CurrentRuleSetVersion, Defaults: defaults},
+			rule:    Rule{ID: "foo"},
+			assert: func(t *testing.T, err error, rule Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", rule.ID)
+				assert.Equal(t, defaults.Backend, rule.Backend)
+				assert.NotSame(t, defaults.Backend, rule.Backend)
+				assert.Equal(t, defaults.Methods, rule.Methods)
+				assert.Equal(t, defaults.Execute, rule.Execute)
+				assert.Equal(t, defaults.ErrorHandler, rule.ErrorHandler)
+			},
+		},
+		{
+			uc:      "rule with own settings",
+			ruleSet: RuleSet{Version: CurrentRuleSetVersion, Defaults: defaults},
+			rule: Rule{
+				ID:           "foo",
+				Backend:      &Backend{Host: "bar.foo"},
+				Methods:      []string{http.MethodPost},
+				Execute:      []config.MechanismConfig{{"authorizer": "bar"}},
+				ErrorHandler: []config.MechanismConfig{{"error_handler": "bar"}},
+			},
+			assert: func(t *testing.T, err error, rule Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &Backend{Host: "bar.foo"}, rule.Backend)
+				assert.Equal(t, []string{http.MethodPost}, rule.Methods)
+				assert.Equal(t, []config.MechanismConfig{
+					{"authenticator": "foo"},
+					{"authorizer": "bar"},
+				}, rule.Execute)
+				assert.Equal(t, []config.MechanismConfig{{"error_handler": "bar"}}, rule.ErrorHandler)
+			},
+		},
+		{
+			uc: "rule and defaults referencing fragments",
+			ruleSet: RuleSet{
+				Version: CurrentRuleSetVersion,
+				Defaults: &RuleDefaults{
+					Execute: []config.MechanismConfig{{"fragment": "authn"}},
+				},
+				Fragments: map[string][]config.MechanismConfig{
+					"authn":    {{"authenticator": "foo"}, {"authenticator": "bar"}},
+					"errors":   {{"error_handler": "foo"}},
+					"finalize": {{"finalizer": "baz"}},
+				},
+			},
+			rule: Rule{
+				ID:           "foo",
+				Execute:      []config.MechanismConfig{{"authorizer": "bar"}, {"fragment": "finalize"}},
+				ErrorHandler: []config.MechanismConfig{{"fragment": "errors"}},
+			},
+			assert: func(t *testing.T, err error, rule Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []config.MechanismConfig{
+					{"authenticator": "foo"},
+					{"authenticator": "bar"},
+					{"authorizer": "bar"},
+					{"finalizer": "baz"},
+				}, rule.Execute)
+				assert.Equal(t, []config.MechanismConfig{{"error_handler": "foo"}}, rule.ErrorHandler)
+			},
+		},
+		{
+			uc: "canary variant referencing fragments",
+			ruleSet: RuleSet{
+				Version:  CurrentRuleSetVersion,
+				Defaults: &RuleDefaults{Execute: []config.MechanismConfig{{"authenticator": "foo"}}},
+				Fragments: map[string][]config.MechanismConfig{
+					"authz":  {{"authorizer": "foo"}},
+					"errors": {{"error_handler": "foo"}},
+				},
+			},
+			rule: Rule{
+				ID: "foo",
+				Canary: &Canary{
+					Percentage:   10,
+					Execute:      []config.MechanismConfig{{"fragment": "authz"}},
+					ErrorHandler: []config.MechanismConfig{{"fragment": "errors"}},
+				},
+			},
+			assert: func(t *testing.T, err error, rule Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []config.MechanismConfig{{"authenticator": "foo"}}, rule.Execute)
+				require.NotNil(t, rule.Canary)
+				assert.Equal(t, 10, rule.Canary.Percentage)
+				assert.Equal(t, []config.MechanismConfig{{"authorizer": "foo"}}, rule.Canary.Execute)
+				assert.Equal(t, []config.MechanismConfig{{"error_handler": "foo"}}, rule.Canary.ErrorHandler)
+			},
+		},
+		{
+			uc: "rule referencing an unknown fragment",
+			ruleSet: RuleSet{
+				Version:   CurrentRuleSetVersion,
+				Fragments: map[string][]config.MechanismConfig{"authn": {{"authenticator": "foo"}}},
+			},
+			rule: Rule{ID: "foo", Execute: []config.MechanismConfig{{"fragment": "bar"}}},
+			assert: func(t *testing.T, err error, _ Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unknown fragment 'bar'")
+			},
+		},
+		{
+			uc: "rule with malformed fragment reference",
+			ruleSet: RuleSet{
+				Version:   CurrentRuleSetVersion,
+				Fragments: map[string][]config.MechanismConfig{"authn": {{"authenticator": "foo"}}},
+			},
+			rule: Rule{ID: "foo", Execute: []config.MechanismConfig{{"fragment": "authn", "config": "bar"}}},
+			assert: func(t *testing.T, err error, _ Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "malformed fragment reference")
+			},
+		},
+		{
+			uc: "fragment referencing another fragment",
+			ruleSet: RuleSet{
+				Version: CurrentRuleSetVersion,
+				Fragments: map[string][]config.MechanismConfig{
+					"authn": {{"fragment": "other"}},
+					"other": {{"authenticator": "foo"}},
+				},
+			},
+			rule: Rule{ID: "foo", Execute: []config.MechanismConfig{{"fragment": "authn"}}},
+			assert: func(t *testing.T, err error, _ Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "references another fragment")
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			rule, err := tc.ruleSet.ExpandRule(tc.rule)
+
+			// THEN
+			tc.assert(t, err, rule)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
@@ -31,11 +32,14 @@ type MetaData struct {
 }
 
 type RuleSet struct {
-	MetaData
+	MetaData `yaml:",inline"`
 
-	Version string `json:"version" yaml:"version"`
-	Name    string `json:"name"    yaml:"name"`
-	Rules   []Rule `json:"rules"   validate:"dive" yaml:"rules"`
+	Version   string                              `json:"version"   yaml:"version"`
+	Name      string                              `json:"name"      yaml:"name,omitempty"`
+	Priority  int                                 `json:"priority"  yaml:"priority,omitempty"`
+	Defaults  *RuleDefaults                       `json:"defaults"  yaml:"defaults,omitempty"`
+	Fragments map[string][]config.MechanismConfig `json:"fragments" yaml:"fragments,omitempty"`
+	Rules     []Rule                              `json:"rules"     validate:"dive" yaml:"rules"`
 }
 
 func (rs RuleSet) VerifyPathPrefix(prefix string) error {
@@ -53,3 +57,48 @@ func (rs RuleSet) VerifyPathPrefix(prefix string) error {
 
 	return nil
 }
+
+func (rs *RuleSet) DeepCopyInto(out *RuleSet) {
+	*out = *rs
+
+	if rs.Hash != nil {
+		out.Hash = make([]byte, len(rs.Hash))
+		copy(out.Hash, rs.Hash)
+	}
+
+	if rs.Defaults != nil {
+		out.Defaults = new(RuleDefaults)
+		rs.Defaults.DeepCopyInto(out.Defaults)
+	}
+
+	if rs.Fragments != nil {
+		out.Fragments = make(map[string][]config.MechanismConfig, len(rs.Fragments))
+
+		for name, steps := range rs.Fragments {
+			fragment := make([]config.MechanismConfig, len(steps))
+			for i := range steps {
+				steps[i].DeepCopyInto(&fragment[i])
+			}
+
+			out.Fragments[name] = fragment
+		}
+	}
+
+	if rs.Rules != nil {
+		out.Rules = make([]Rule, len(rs.Rules))
+		for i := range rs.Rules {
+			rs.Rules[i].DeepCopyInto(&out.Rules[i])
+		}
+	}
+}
+
+func (rs *RuleSet) DeepCopy() *RuleSet {
+	if rs == nil {
+		return nil
+	}
+
+	out := new(RuleSet)
+	rs.DeepCopyInto(out)
+
+	return out
+}
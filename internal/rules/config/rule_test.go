@@ -31,11 +31,18 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 	// GIVEN
 	var out Rule
 
+	priority := 10
+
 	in := Rule{
-		ID: "foo",
+		ID:       "foo",
+		Priority: &priority,
 		RuleMatcher: Matcher{
-			URL:      "bar",
-			Strategy: "glob",
+			URL:             "bar",
+			Strategy:        "glob",
+			Scheme:          "https",
+			Hosts:           []string{"foo.bar"},
+			Headers:         map[string]string{"X-Foo": "bar"},
+			QueryParameters: map[string]string{"foo": "bar"},
 		},
 		Backend: &Backend{
 			Host: "baz",
@@ -49,6 +56,13 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 		Methods:      []string{"GET", "PATCH"},
 		Execute:      []config.MechanismConfig{{"foo": "bar"}},
 		ErrorHandler: []config.MechanismConfig{{"bar": "foo"}},
+		Canary: &Canary{
+			Percentage: 10,
+			StickyBy:   CanaryStickyByHeader,
+			Header:     "X-Session",
+			Backend:    &Backend{Host: "zab"},
+			Execute:    []config.MechanismConfig{{"baz": "bar"}},
+		},
 	}
 
 	// WHEN
@@ -56,12 +70,30 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 
 	// THEN
 	assert.Equal(t, in.ID, out.ID)
-	assert.Equal(t, in.RuleMatcher.URL, out.RuleMatcher.URL)
+	assert.Equal(t, in.Priority, out.Priority)
+	assert.NotSame(t, in.Priority, out.Priority)
+	assert.Equal(t, in.RuleMatcher, out.RuleMatcher)
 	assert.Equal(t, in.Backend, out.Backend)
-	assert.Equal(t, in.RuleMatcher.Strategy, out.RuleMatcher.Strategy)
+	assert.NotSame(t, in.Backend, out.Backend)
 	assert.Equal(t, in.Methods, out.Methods)
 	assert.Equal(t, in.Execute, out.Execute)
 	assert.Equal(t, in.ErrorHandler, out.ErrorHandler)
+	assert.Equal(t, in.Canary, out.Canary)
+	assert.NotSame(t, in.Canary, out.Canary)
+	assert.NotSame(t, in.Canary.Backend, out.Canary.Backend)
+
+	// modifications of the copy do not affect the original
+	out.RuleMatcher.Hosts[0] = "bar.foo"
+	out.RuleMatcher.Headers["X-Foo"] = "baz"
+	out.RuleMatcher.QueryParameters["foo"] = "baz"
+	out.Backend.Host = "zab"
+	out.Backend.URLRewriter.PathPrefixToCut = "/baz"
+
+	assert.Equal(t, []string{"foo.bar"}, in.RuleMatcher.Hosts)
+	assert.Equal(t, map[string]string{"X-Foo": "bar"}, in.RuleMatcher.Headers)
+	assert.Equal(t, map[string]string{"foo": "bar"}, in.RuleMatcher.QueryParameters)
+	assert.Equal(t, "baz", in.Backend.Host)
+	assert.Equal(t, PrefixCutter("/foo"), in.Backend.URLRewriter.PathPrefixToCut)
 }
 
 func TestRuleConfigDeepCopy(t *testing.T) {
@@ -71,8 +103,12 @@ func TestRuleConfigDeepCopy(t *testing.T) {
 	in := Rule{
 		ID: "foo",
 		RuleMatcher: Matcher{
-			URL:      "bar",
-			Strategy: "glob",
+			URL:             "bar",
+			Strategy:        "glob",
+			Scheme:          "https",
+			Hosts:           []string{"foo.bar"},
+			Headers:         map[string]string{"X-Foo": "bar"},
+			QueryParameters: map[string]string{"foo": "bar"},
 		},
 		Backend: &Backend{
 			Host: "baz",
@@ -97,9 +133,8 @@ func TestRuleConfigDeepCopy(t *testing.T) {
 
 	// but same contents
 	assert.Equal(t, in.ID, out.ID)
-	assert.Equal(t, in.RuleMatcher.URL, out.RuleMatcher.URL)
+	assert.Equal(t, in.RuleMatcher, out.RuleMatcher)
 	assert.Equal(t, in.Backend, out.Backend)
-	assert.Equal(t, in.RuleMatcher.Strategy, out.RuleMatcher.Strategy)
 	assert.Equal(t, in.Methods, out.Methods)
 	assert.Equal(t, in.Execute, out.Execute)
 	assert.Equal(t, in.ErrorHandler, out.ErrorHandler)
@@ -16,4 +16,14 @@
 
 package config
 
-const CurrentRuleSetVersion = "1alpha3"
+const (
+	// RuleSetVersion1Alpha4 adds request conditions to the rule matcher, as well as rule set
+	// defaults, pipeline fragments and canary variants.
+	RuleSetVersion1Alpha4 = "1alpha4"
+
+	// RuleSetVersion1Alpha3 is the previous rule set version. It is still supported,
+	// but does not allow the usage of request conditions in the rule matcher.
+	RuleSetVersion1Alpha3 = "1alpha3"
+
+	CurrentRuleSetVersion = RuleSetVersion1Alpha4
+)
@@ -0,0 +1,156 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"maps"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(ctx CreationContext, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorAPIKey {
+				return false, nil, nil
+			}
+
+			auth, err := newAPIKeyAuthenticator(ctx, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type apiKeyAuthenticator struct {
+	id                   string
+	store                *apiKeyStore
+	ads                  extractors.AuthDataExtractStrategy
+	allowFallbackOnError bool
+}
+
+func newAPIKeyAuthenticator(ctx CreationContext, id string, rawConfig map[string]any) (*apiKeyAuthenticator, error) {
+	type KeyStore struct {
+		Path string `mapstructure:"path" validate:"required"`
+	}
+
+	type Config struct {
+		KeyStore             KeyStore                            `mapstructure:"key_store"               validate:"required"`
+		AuthDataSource       extractors.CompositeExtractStrategy `mapstructure:"key_source"`
+		AllowFallbackOnError bool                                `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorAPIKey, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	store, err := newAPIKeyStore(conf.KeyStore.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if cw := ctx.Watcher(); cw != nil {
+		if err = cw.Add(store.path, store); err != nil {
+			return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+				"failed registering api key store for updates").CausedBy(err)
+		}
+	}
+
+	return &apiKeyAuthenticator{
+		id:    id,
+		store: store,
+		ads: x.IfThenElseExec(conf.AuthDataSource == nil,
+			func() extractors.AuthDataExtractStrategy {
+				return extractors.HeaderValueExtractStrategy{Name: "X-API-Key"}
+			},
+			func() extractors.AuthDataExtractStrategy { return conf.AuthDataSource },
+		),
+		allowFallbackOnError: conf.AllowFallbackOnError,
+	}, nil
+}
+
+func (a *apiKeyAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using api_key authenticator")
+
+	key, err := a.ads.GetAuthData(ctx)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no api key present").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	entry, err := a.store.lookup(key, time.Now())
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "api key verification failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	attributes := make(map[string]any)
+	maps.Copy(attributes, entry.Attributes)
+	attributes["key_id"] = entry.ID
+	attributes["owner"] = entry.Owner
+	attributes["scopes"] = x.IfThenElse(entry.Scopes != nil, entry.Scopes, []string{})
+
+	return &subject.Subject{ID: entry.Subject, Attributes: attributes}, nil
+}
+
+func (a *apiKeyAuthenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	// this authenticator allows only the fallback setting to be redefined on the rule level
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		AllowFallbackOnError *bool `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorAPIKey, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	return &apiKeyAuthenticator{
+		id:    a.id,
+		store: a.store,
+		ads:   a.ads,
+		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+			func() bool { return *conf.AllowFallbackOnError },
+			func() bool { return a.allowFallbackOnError }),
+	}, nil
+}
+
+func (a *apiKeyAuthenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *apiKeyAuthenticator) ID() string {
+	return a.id
+}
@@ -0,0 +1,576 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/watcher"
+	mocks2 "github.com/dadrus/heimdall/internal/watcher/mocks"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func sha256APIKeyHash(salt, key string) string {
+	md := sha256.Sum256([]byte(salt + key))
+
+	return "$sha256$" + salt + "$" + hex.EncodeToString(md[:])
+}
+
+func writeAPIKeyStore(t *testing.T, path, contents string) {
+	t.Helper()
+
+	err := os.WriteFile(path, []byte(contents), 0o600)
+	require.NoError(t, err)
+}
+
+func TestCreateAPIKeyAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+
+	validStore := filepath.Join(testDir, "valid.yaml")
+	writeAPIKeyStore(t, validStore, `
+keys:
+- id: foo
+  hash: `+sha256APIKeyHash("salt", "foo.secret")+`
+  subject: foo-service
+`)
+
+	invalidHashStore := filepath.Join(testDir, "invalid_hash.yaml")
+	writeAPIKeyStore(t, invalidHashStore, `
+keys:
+- id: foo
+  hash: $md5$foo
+  subject: foo-service
+`)
+
+	duplicateIDStore := filepath.Join(testDir, "duplicate_id.yaml")
+	writeAPIKeyStore(t, duplicateIDStore, `
+keys:
+- id: foo
+  hash: `+sha256APIKeyHash("salt", "foo.secret")+`
+  subject: foo-service
+- id: foo
+  hash: `+sha256APIKeyHash("salt", "foo.other")+`
+  subject: bar-service
+`)
+
+	invalidIDStore := filepath.Join(testDir, "invalid_id.yaml")
+	writeAPIKeyStore(t, invalidIDStore, `
+keys:
+- id: foo.bar
+  hash: `+sha256APIKeyHash("salt", "foo.bar.secret")+`
+  subject: foo-service
+`)
+
+	noSubjectStore := filepath.Join(testDir, "no_subject.yaml")
+	writeAPIKeyStore(t, noSubjectStore, `
+keys:
+- id: foo
+  hash: `+sha256APIKeyHash("salt", "foo.secret")+`
+`)
+
+	for _, tc := range []struct {
+		uc        string
+		config    []byte
+		configure func(t *testing.T, ctx *CreationContextMock)
+		assert    func(t *testing.T, err error, auth *apiKeyAuthenticator)
+	}{
+		{
+			uc:     "without key store",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, _ *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "key_store")
+			},
+		},
+		{
+			uc: "with unsupported properties",
+			config: []byte(`
+key_store:
+  path: ` + validStore + `
+foo: bar`),
+			assert: func(t *testing.T, err error, _ *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc: "with not existing key store file",
+			config: []byte(`
+key_store:
+  path: ` + filepath.Join(testDir, "foo.yaml")),
+			assert: func(t *testing.T, err error, _ *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorIs(t, err, os.ErrNotExist)
+			},
+		},
+		{
+			uc: "with unsupported hash in key store",
+			config: []byte(`
+key_store:
+  path: ` + invalidHashStore),
+			assert: func(t *testing.T, err error, _ *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed parsing hash")
+			},
+		},
+		{
+			uc: "with duplicate key ids in key store",
+			config: []byte(`
+key_store:
+  path: ` + duplicateIDStore),
+			assert: func(t *testing.T, err error, _ *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "multiple times")
+			},
+		},
+		{
+			uc: "with invalid key id in key store",
+			config: []byte(`
+key_store:
+  path: ` + invalidIDStore),
+			assert: func(t *testing.T, err error, _ *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "invalid id")
+			},
+		},
+		{
+			uc: "with key store entry without subject",
+			config: []byte(`
+key_store:
+  path: ` + noSubjectStore),
+			assert: func(t *testing.T, err error, _ *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no subject")
+			},
+		},
+		{
+			uc: "with failing registration for updates",
+			config: []byte(`
+key_store:
+  path: ` + validStore),
+			configure: func(t *testing.T, ctx *CreationContextMock) {
+				t.Helper()
+
+				wm := mocks2.NewWatcherMock(t)
+				wm.EXPECT().Add(validStore, mock.Anything).Return(errors.New("test error"))
+
+				ctx.EXPECT().Watcher().Return(wm)
+			},
+			assert: func(t *testing.T, err error, _ *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "test error")
+			},
+		},
+		{
+			uc: "with minimal valid configuration",
+			config: []byte(`
+key_store:
+  path: ` + validStore),
+			configure: func(t *testing.T, ctx *CreationContextMock) {
+				t.Helper()
+
+				wm := mocks2.NewWatcherMock(t)
+				wm.EXPECT().Add(validStore, mock.Anything).Return(nil)
+
+				ctx.EXPECT().Watcher().Return(wm)
+			},
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "auth1", auth.ID())
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+				assert.Equal(t, extractors.HeaderValueExtractStrategy{Name: "X-API-Key"}, auth.ads)
+				assert.Len(t, auth.store.entries, 1)
+			},
+		},
+		{
+			uc: "with full valid configuration and without watcher",
+			config: []byte(`
+key_store:
+  path: ` + validStore + `
+key_source:
+  - header: Authorization
+    scheme: ApiKey
+  - query_parameter: api_key
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+				assert.Equal(t, extractors.CompositeExtractStrategy{
+					&extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "ApiKey"},
+					&extractors.QueryParameterExtractStrategy{Name: "api_key"},
+				}, auth.ads)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			configure := x.IfThenElse(tc.configure != nil,
+				tc.configure,
+				func(t *testing.T, ctx *CreationContextMock) {
+					t.Helper()
+
+					ctx.EXPECT().Watcher().Return(nil).Maybe()
+				})
+
+			ctx := NewCreationContextMock(t)
+			configure(t, ctx)
+
+			// WHEN
+			auth, err := newAPIKeyAuthenticator(ctx, "auth1", conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateAPIKeyAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *apiKeyAuthenticator, configured *apiKeyAuthenticator)
+	}{
+		{
+			uc: "without target config",
+			assert: func(t *testing.T, err error, prototype *apiKeyAuthenticator, configured *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with fallback setting",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *apiKeyAuthenticator, configured *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.id, configured.id)
+				assert.Equal(t, prototype.store, configured.store)
+				assert.Equal(t, prototype.ads, configured.ads)
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with key store redefinition",
+			config: []byte(`
+key_store:
+  path: /foo/bar.yaml`),
+			assert: func(t *testing.T, err error, _ *apiKeyAuthenticator, _ *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype := &apiKeyAuthenticator{
+				id:    "auth1",
+				store: &apiKeyStore{path: "/foo/bar.yaml"},
+				ads:   extractors.HeaderValueExtractStrategy{Name: "X-API-Key"},
+			}
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				configured *apiKeyAuthenticator
+				ok         bool
+			)
+
+			if err == nil {
+				configured, ok = auth.(*apiKeyAuthenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestAPIKeyAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("bar.secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	storeFile := filepath.Join(t.TempDir(), "keys.yaml")
+	writeAPIKeyStore(t, storeFile, `
+keys:
+- id: foo
+  hash: `+sha256APIKeyHash("salt", "foo.secret")+`
+  subject: foo-service
+  owner: team-foo
+  scopes: [read, write]
+  attributes:
+    tier: gold
+- id: bar
+  hash: `+string(bcryptHash)+`
+  subject: bar-service
+- id: expired
+  hash: `+sha256APIKeyHash("salt", "expired.secret")+`
+  subject: expired-service
+  expires_at: 2020-01-01T00:00:00Z
+- id: revoked
+  hash: `+sha256APIKeyHash("salt", "revoked.secret")+`
+  subject: revoked-service
+  revoked: true
+`)
+
+	store, err := newAPIKeyStore(storeFile)
+	require.NoError(t, err)
+
+	auth := &apiKeyAuthenticator{
+		id:    "auth1",
+		store: store,
+		ads:   extractors.HeaderValueExtractStrategy{Name: "X-API-Key"},
+	}
+
+	for _, tc := range []struct {
+		uc     string
+		key    string
+		assert func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc: "no api key present",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no api key")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth1", identifier.ID())
+			},
+		},
+		{
+			uc:  "malformed api key",
+			key: "secret",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errMalformedAPIKey)
+			},
+		},
+		{
+			uc:  "unknown api key",
+			key: "baz.secret",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errUnknownAPIKey)
+			},
+		},
+		{
+			uc:  "wrong secret",
+			key: "foo.wrong",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errInvalidAPIKey)
+			},
+		},
+		{
+			uc:  "expired api key",
+			key: "expired.secret",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errExpiredAPIKey)
+			},
+		},
+		{
+			uc:  "revoked api key",
+			key: "revoked.secret",
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errRevokedAPIKey)
+			},
+		},
+		{
+			uc:  "valid api key with sha256 hash",
+			key: "foo.secret",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "foo-service", sub.ID)
+				assert.Equal(t, "foo", sub.Attributes["key_id"])
+				assert.Equal(t, "team-foo", sub.Attributes["owner"])
+				assert.Equal(t, []string{"read", "write"}, sub.Attributes["scopes"])
+				assert.Equal(t, "gold", sub.Attributes["tier"])
+			},
+		},
+		{
+			uc:  "valid api key with bcrypt hash",
+			key: "bar.secret",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "bar-service", sub.ID)
+				assert.Equal(t, "bar", sub.Attributes["key_id"])
+				assert.Equal(t, "", sub.Attributes["owner"])
+				assert.Equal(t, []string{}, sub.Attributes["scopes"])
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Header("X-API-Key").Return(tc.key)
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(context.Background())
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
+
+func TestAPIKeyStoreReload(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	storeFile := filepath.Join(t.TempDir(), "keys.yaml")
+	writeAPIKeyStore(t, storeFile, `
+keys:
+- id: foo
+  hash: `+sha256APIKeyHash("salt", "foo.secret")+`
+  subject: foo-service
+`)
+
+	store, err := newAPIKeyStore(storeFile)
+	require.NoError(t, err)
+
+	var listener watcher.ChangeListener = store
+
+	entry, err := store.lookup("foo.secret", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "foo-service", entry.Subject)
+	assert.Len(t, store.verified, 1)
+
+	// WHEN
+	writeAPIKeyStore(t, storeFile, `
+keys:
+- id: foo
+  hash: `+sha256APIKeyHash("salt", "foo.secret")+`
+  subject: foo-service
+  revoked: true
+`)
+	listener.OnChanged(log.Logger)
+
+	// THEN
+	assert.Empty(t, store.verified)
+
+	_, err = store.lookup("foo.secret", time.Now())
+	require.ErrorIs(t, err, errRevokedAPIKey)
+
+	// WHEN
+	writeAPIKeyStore(t, storeFile, `keys: foo`)
+	listener.OnChanged(log.Logger)
+
+	// THEN the previous state is kept
+	_, err = store.lookup("foo.secret", time.Now())
+	require.ErrorIs(t, err, errRevokedAPIKey)
+}
@@ -0,0 +1,194 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/hashx"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+var (
+	errUnknownAPIKey   = errors.New("unknown api key")
+	errInvalidAPIKey   = errors.New("invalid api key")
+	errExpiredAPIKey   = errors.New("api key expired")
+	errRevokedAPIKey   = errors.New("api key revoked")
+	errMalformedAPIKey = errors.New("malformed api key")
+)
+
+type apiKeyEntry struct {
+	ID         string         `yaml:"id"`
+	Hash       string         `yaml:"hash"`
+	Subject    string         `yaml:"subject"`
+	Owner      string         `yaml:"owner"`
+	Scopes     []string       `yaml:"scopes"`
+	Attributes map[string]any `yaml:"attributes"`
+	ExpiresAt  *time.Time     `yaml:"expires_at"`
+	Revoked    bool           `yaml:"revoked"`
+
+	hash hashx.Hash
+}
+
+func (e *apiKeyEntry) check(now time.Time) error {
+	if e.Revoked {
+		return errRevokedAPIKey
+	}
+
+	if e.ExpiresAt != nil && !now.Before(*e.ExpiresAt) {
+		return errExpiredAPIKey
+	}
+
+	return nil
+}
+
+// apiKeyStore holds the hashes of the api keys loaded from a YAML or JSON file. Api keys are
+// expected to have the form <key id>.<secret>. The key id is used to look up the entry, the
+// hash of which is then verified against the entire key. The file is reloaded on changes, so
+// that new, expired or revoked keys take effect without a restart.
+type apiKeyStore struct {
+	path string
+
+	entries  map[string]*apiKeyEntry
+	verified map[string]string
+	mut      sync.RWMutex
+}
+
+func newAPIKeyStore(path string) (*apiKeyStore, error) {
+	store := &apiKeyStore{path: path}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *apiKeyStore) load() error {
+	type KeyFile struct {
+		Keys []*apiKeyEntry `yaml:"keys"`
+	}
+
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed reading api key store").CausedBy(err)
+	}
+
+	var kf KeyFile
+	if err = yaml.Unmarshal(raw, &kf); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed parsing api key store").CausedBy(err)
+	}
+
+	entries := make(map[string]*apiKeyEntry, len(kf.Keys))
+
+	for idx, entry := range kf.Keys {
+		if len(entry.ID) == 0 || strings.Contains(entry.ID, ".") {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"api key entry %d has no or an invalid id", idx)
+		}
+
+		if len(entry.Subject) == 0 {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"api key entry '%s' has no subject", entry.ID)
+		}
+
+		if _, exists := entries[entry.ID]; exists {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"api key entry '%s' is defined multiple times", entry.ID)
+		}
+
+		entry.hash, err = hashx.Parse(entry.Hash)
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed parsing hash of api key entry '%s'", entry.ID).CausedBy(err)
+		}
+
+		entries[entry.ID] = entry
+	}
+
+	s.mut.Lock()
+	s.entries = entries
+	s.verified = make(map[string]string)
+	s.mut.Unlock()
+
+	return nil
+}
+
+// lookup returns the entry for the given api key. Successful verifications are remembered
+// by the SHA-256 value of the key, so that expensive hash functions, like argon2 or bcrypt,
+// do not need to be evaluated on each request. Expiry and revocation are checked every time.
+func (s *apiKeyStore) lookup(key string, now time.Time) (*apiKeyEntry, error) {
+	keyID, _, found := strings.Cut(key, ".")
+	if !found || len(keyID) == 0 {
+		return nil, errMalformedAPIKey
+	}
+
+	digest := sha256.Sum256(stringx.ToBytes(key))
+	fingerprint := hex.EncodeToString(digest[:])
+
+	s.mut.RLock()
+	entry, known := s.entries[keyID]
+	verifiedID, verified := s.verified[fingerprint]
+	s.mut.RUnlock()
+
+	if !known {
+		return nil, errUnknownAPIKey
+	}
+
+	if !verified || verifiedID != keyID {
+		if !entry.hash.Matches(stringx.ToBytes(key)) {
+			return nil, errInvalidAPIKey
+		}
+
+		s.mut.Lock()
+		// the entries might have been reloaded in between
+		if s.entries[keyID] == entry {
+			s.verified[fingerprint] = keyID
+		}
+		s.mut.Unlock()
+	}
+
+	if err := entry.check(now); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *apiKeyStore) OnChanged(log zerolog.Logger) {
+	err := s.load()
+	if err != nil {
+		log.Warn().Err(err).
+			Str("_file", s.path).
+			Msg("API key store reload failed")
+	} else {
+		log.Info().
+			Str("_file", s.path).
+			Msg("API key store reloaded")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/dadrus/heimdall/internal/watcher"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
@@ -31,7 +32,17 @@ var (
 	authenticatorTypeFactoriesMu sync.RWMutex               //nolint:gochecknoglobals
 )
 
-type AuthenticatorTypeFactory func(id string, typ string, config map[string]any) (bool, Authenticator, error)
+//go:generate mockery --name CreationContext --structname CreationContextMock --inpackage --testonly
+
+// CreationContext provides access to services, authenticators might require during their creation.
+type CreationContext interface {
+	// Watcher returns the watcher to be used to reload files, like key stores. Can be nil.
+	Watcher() watcher.Watcher
+}
+
+type AuthenticatorTypeFactory func(
+	ctx CreationContext, id string, typ string, config map[string]any,
+) (bool, Authenticator, error)
 
 func registerTypeFactory(factory AuthenticatorTypeFactory) {
 	authenticatorTypeFactoriesMu.Lock()
@@ -44,12 +55,12 @@ func registerTypeFactory(factory AuthenticatorTypeFactory) {
 	authenticatorTypeFactories = append(authenticatorTypeFactories, factory)
 }
 
-func CreatePrototype(id string, typ string, config map[string]any) (Authenticator, error) {
+func CreatePrototype(ctx CreationContext, id string, typ string, config map[string]any) (Authenticator, error) {
 	authenticatorTypeFactoriesMu.RLock()
 	defer authenticatorTypeFactoriesMu.RUnlock()
 
 	for _, create := range authenticatorTypeFactories {
-		if ok, at, err := create(id, typ, config); ok {
+		if ok, at, err := create(ctx, id, typ, config); ok {
 			return at, err
 		}
 	}
@@ -27,7 +27,7 @@ func TestCreateAuthenticatorPrototype(t *testing.T) {
 	t.Parallel()
 
 	// there are seven authenticators implemented, which should have been registered
-	require.Len(t, authenticatorTypeFactories, 6)
+	require.Len(t, authenticatorTypeFactories, 10)
 
 	for _, tc := range []struct {
 		uc     string
@@ -57,7 +57,7 @@ func TestCreateAuthenticatorPrototype(t *testing.T) {
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// WHEN
-			auth, err := CreatePrototype("foo", tc.typ, nil)
+			auth, err := CreatePrototype(NewCreationContextMock(t), "foo", tc.typ, nil)
 
 			// THEN
 			tc.assert(t, err, auth)
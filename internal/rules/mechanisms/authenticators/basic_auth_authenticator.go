@@ -18,6 +18,7 @@ package authenticators
 
 import (
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
 	"strings"
@@ -41,12 +42,12 @@ const (
 //nolint:gochecknoinits
 func init() {
 	registerTypeFactory(
-		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+		func(ctx CreationContext, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
 			if typ != AuthenticatorBasicAuth {
 				return false, nil, nil
 			}
 
-			auth, err := newBasicAuthAuthenticator(id, conf)
+			auth, err := newBasicAuthAuthenticator(ctx, id, conf)
 
 			return true, auth, err
 		})
@@ -56,13 +57,19 @@ type basicAuthAuthenticator struct {
 	id                   string
 	userID               string
 	password             string
+	htpasswd             *htpasswdFile
 	allowFallbackOnError bool
 }
 
-func newBasicAuthAuthenticator(id string, rawConfig map[string]any) (*basicAuthAuthenticator, error) {
+func newBasicAuthAuthenticator(
+	ctx CreationContext,
+	id string,
+	rawConfig map[string]any,
+) (*basicAuthAuthenticator, error) {
 	type Config struct {
-		UserID               string `mapstructure:"user_id"                 validate:"required"`
-		Password             string `mapstructure:"password"                validate:"required"`
+		UserID               string `mapstructure:"user_id"                 validate:"required_without=HtpasswdFile,excluded_with=HtpasswdFile"` //nolint:lll,tagalign
+		Password             string `mapstructure:"password"                validate:"required_without=HtpasswdFile,excluded_with=HtpasswdFile"` //nolint:lll,tagalign
+		HtpasswdFile         string `mapstructure:"htpasswd_file"`
 		AllowFallbackOnError bool   `mapstructure:"allow_fallback_on_error"`
 	}
 
@@ -76,6 +83,24 @@ func newBasicAuthAuthenticator(id string, rawConfig map[string]any) (*basicAuthA
 		allowFallbackOnError: conf.AllowFallbackOnError,
 	}
 
+	if len(conf.HtpasswdFile) != 0 {
+		htpasswd, err := newHtpasswdFile(conf.HtpasswdFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if cw := ctx.Watcher(); cw != nil {
+			if err = cw.Add(htpasswd.path, htpasswd); err != nil {
+				return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+					"failed registering htpasswd file for updates").CausedBy(err)
+			}
+		}
+
+		auth.htpasswd = htpasswd
+
+		return &auth, nil
+	}
+
 	// rewrite user id and password as hashes to mitigate potential side-channel attacks
 	// during credentials check
 	md := sha256.New()
@@ -117,18 +142,10 @@ func (a *basicAuthAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject
 			WithErrorContext(a)
 	}
 
-	md := sha256.New()
-	md.Write(stringx.ToBytes(userIDAndPassword[0]))
-	userID := hex.EncodeToString(md.Sum(nil))
-
-	md.Reset()
-	md.Write(stringx.ToBytes(userIDAndPassword[1]))
-	password := hex.EncodeToString(md.Sum(nil))
+	if !a.verify(userIDAndPassword[0], userIDAndPassword[1]) {
+		logger.Info().Str("_id", a.id).Str("_user", userIDAndPassword[0]).
+			Msg("Authentication failed due to invalid user credentials")
 
-	userIDOK := userID == a.userID
-	passwordOK := password == a.password
-
-	if !(userIDOK && passwordOK) {
 		return nil, errorchain.
 			NewWithMessage(heimdall.ErrAuthentication, "invalid user credentials").
 			WithErrorContext(a)
@@ -137,6 +154,26 @@ func (a *basicAuthAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject
 	return &subject.Subject{ID: userIDAndPassword[0], Attributes: make(map[string]any)}, nil
 }
 
+func (a *basicAuthAuthenticator) verify(userID, password string) bool {
+	if a.htpasswd != nil {
+		return a.htpasswd.verify(userID, password)
+	}
+
+	md := sha256.New()
+	md.Write(stringx.ToBytes(userID))
+	userIDHash := hex.EncodeToString(md.Sum(nil))
+
+	md.Reset()
+	md.Write(stringx.ToBytes(password))
+	passwordHash := hex.EncodeToString(md.Sum(nil))
+
+	// both comparisons are done to not leak which one failed
+	userIDOK := subtle.ConstantTimeCompare(stringx.ToBytes(userIDHash), stringx.ToBytes(a.userID)) == 1
+	passwordOK := subtle.ConstantTimeCompare(stringx.ToBytes(passwordHash), stringx.ToBytes(a.password)) == 1
+
+	return userIDOK && passwordOK
+}
+
 func (a *basicAuthAuthenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
 	// this authenticator allows full redefinition on the rule level
 	if len(rawConfig) == 0 {
@@ -154,6 +191,11 @@ func (a *basicAuthAuthenticator) WithConfig(rawConfig map[string]any) (Authentic
 		return nil, err
 	}
 
+	if a.htpasswd != nil && (len(conf.UserID) == 0) != (len(conf.Password) == 0) {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"both, user_id and password are required to redefine the htpasswd file based configuration")
+	}
+
 	return &basicAuthAuthenticator{
 		id: a.id,
 		userID: x.IfThenElseExec(len(conf.UserID) != 0,
@@ -177,6 +219,7 @@ func (a *basicAuthAuthenticator) WithConfig(rawConfig map[string]any) (Authentic
 		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
 			func() bool { return *conf.AllowFallbackOnError },
 			func() bool { return a.allowFallbackOnError }),
+		htpasswd: x.IfThenElse(len(conf.UserID) != 0, nil, a.htpasswd),
 	}, nil
 }
 
@@ -21,14 +21,19 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/heimdall/mocks"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	mocks2 "github.com/dadrus/heimdall/internal/watcher/mocks"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
 
@@ -140,7 +145,7 @@ foo: bar`),
 			require.NoError(t, err)
 
 			// WHEN
-			auth, err := newBasicAuthAuthenticator(tc.id, conf)
+			auth, err := newBasicAuthAuthenticator(NewCreationContextMock(t), tc.id, conf)
 
 			// THEN
 			tc.assert(t, err, auth)
@@ -310,7 +315,7 @@ password: baz`),
 			conf, err := testsupport.DecodeTestConfig(tc.config)
 			require.NoError(t, err)
 
-			prototype, err := newBasicAuthAuthenticator(tc.id, pc)
+			prototype, err := newBasicAuthAuthenticator(NewCreationContextMock(t), tc.id, pc)
 			require.NoError(t, err)
 
 			// WHEN
@@ -501,7 +506,7 @@ password: bar`))
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
-			auth, err := newBasicAuthAuthenticator(tc.id, conf)
+			auth, err := newBasicAuthAuthenticator(NewCreationContextMock(t), tc.id, conf)
 			require.NoError(t, err)
 
 			ctx := mocks.NewContextMock(t)
@@ -516,3 +521,172 @@ password: bar`))
 		})
 	}
 }
+
+func TestBasicAuthAuthenticatorWithHtpasswdFile(t *testing.T) {
+	t.Parallel()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("bar"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	htpasswdFile := filepath.Join(t.TempDir(), ".htpasswd")
+	err = os.WriteFile(htpasswdFile, []byte("foo:"+string(bcryptHash)+"\n"), 0o600)
+	require.NoError(t, err)
+
+	basicAuth := func(userID, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(userID+":"+password))
+	}
+
+	for _, tc := range []struct {
+		uc        string
+		config    []byte
+		ruleConf  []byte
+		header    string
+		configure func(t *testing.T, ctx *CreationContextMock)
+		assert    func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc: "htpasswd file and user_id configured",
+			config: []byte(`
+htpasswd_file: ` + htpasswdFile + `
+user_id: foo`),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc:     "not existing htpasswd file",
+			config: []byte(`htpasswd_file: /does/not/exist`),
+			configure: func(t *testing.T, _ *CreationContextMock) {
+				t.Helper()
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorIs(t, err, os.ErrNotExist)
+			},
+		},
+		{
+			uc:       "rule configuration redefines only the password",
+			config:   []byte(`htpasswd_file: ` + htpasswdFile),
+			ruleConf: []byte(`password: baz`),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "both")
+			},
+		},
+		{
+			uc:     "unknown user",
+			config: []byte(`htpasswd_file: ` + htpasswdFile),
+			header: basicAuth("baz", "bar"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+			},
+		},
+		{
+			uc:     "invalid password",
+			config: []byte(`htpasswd_file: ` + htpasswdFile),
+			header: basicAuth("foo", "baz"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+			},
+		},
+		{
+			uc:     "valid credentials",
+			config: []byte(`htpasswd_file: ` + htpasswdFile),
+			header: basicAuth("foo", "bar"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+				assert.Equal(t, "foo", sub.ID)
+			},
+		},
+		{
+			uc:       "valid credentials from the rule configuration",
+			config:   []byte(`htpasswd_file: ` + htpasswdFile),
+			ruleConf: []byte("user_id: baz\npassword: qux"),
+			header:   basicAuth("baz", "qux"),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+				assert.Equal(t, "baz", sub.ID)
+			},
+		},
+		{
+			uc:       "htpasswd credentials not accepted if redefined in the rule configuration",
+			config:   []byte(`htpasswd_file: ` + htpasswdFile),
+			ruleConf: []byte("user_id: baz\npassword: qux"),
+			header:   basicAuth("foo", "bar"),
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			ruleConf, err := testsupport.DecodeTestConfig(tc.ruleConf)
+			require.NoError(t, err)
+
+			cctx := NewCreationContextMock(t)
+
+			if tc.configure != nil {
+				tc.configure(t, cctx)
+			} else {
+				wm := mocks2.NewWatcherMock(t)
+				wm.EXPECT().Add(htpasswdFile, mock.Anything).Return(nil).Maybe()
+
+				cctx.EXPECT().Watcher().Return(wm).Maybe()
+			}
+
+			// WHEN
+			sub, err := func() (*subject.Subject, error) {
+				prototype, err := newBasicAuthAuthenticator(cctx, "auth1", conf)
+				if err != nil {
+					return nil, err
+				}
+
+				auth, err := prototype.WithConfig(ruleConf)
+				if err != nil {
+					return nil, err
+				}
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("Authorization").Return(tc.header)
+
+				ctx := mocks.NewContextMock(t)
+				ctx.EXPECT().AppContext().Return(context.Background())
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+				return auth.Execute(ctx)
+			}()
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
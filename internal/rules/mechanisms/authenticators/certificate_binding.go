@@ -0,0 +1,88 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var errCertificateBindingMissing = errors.New("access token is not certificate bound")
+
+// certificateBinding verifies access tokens bound to the client certificate according to RFC 8705.
+type certificateBinding struct {
+	Required            bool                        `mapstructure:"required"`
+	ForwardedClientCert *forwardedClientCertificate `mapstructure:"forwarded_client_cert"`
+}
+
+func (b *certificateBinding) init() {
+	if b.ForwardedClientCert != nil && len(b.ForwardedClientCert.Format) == 0 {
+		b.ForwardedClientCert.Format = forwardedClientCertificateFormatPEM
+	}
+}
+
+// verify verifies the access token represented by its claims, respectively the introspection
+// response, is bound to the client certificate used for the request. If the access token does
+// not contain the x5t#S256 confirmation claim, the certificate is only required if configured.
+func (b *certificateBinding) verify(ctx heimdall.Context, exp *oauth2.Expectation, tokenClaims []byte) error {
+	var claims struct {
+		Confirmation *oauth2.Confirmation `json:"cnf"`
+	}
+
+	if err := json.Unmarshal(tokenClaims, &claims); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to unmarshal confirmation claim").
+			CausedBy(err)
+	}
+
+	if claims.Confirmation == nil || len(claims.Confirmation.X509CertificateSHA256Thumbprint) == 0 {
+		if b.Required {
+			return errCertificateBindingMissing
+		}
+
+		return nil
+	}
+
+	cert, err := b.clientCertificate(ctx)
+	if err != nil {
+		return err
+	}
+
+	return exp.AssertCertificateBinding(claims.Confirmation, cert)
+}
+
+func (b *certificateBinding) clientCertificate(ctx heimdall.Context) (*x509.Certificate, error) {
+	if b.ForwardedClientCert != nil {
+		certs, err := b.ForwardedClientCert.certificates(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return certs[0], nil
+	}
+
+	if certs := ctx.Request().ClientCertificates; len(certs) != 0 {
+		return certs[0], nil
+	}
+
+	return nil, errNoClientCertificate
+}
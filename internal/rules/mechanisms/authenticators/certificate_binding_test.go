@@ -0,0 +1,232 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCertificateBindingInit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	withoutFormat := &certificateBinding{ForwardedClientCert: &forwardedClientCertificate{Name: "X-Client-Cert"}}
+	withFormat := &certificateBinding{
+		ForwardedClientCert: &forwardedClientCertificate{Name: "X-Client-Cert", Format: "xfcc"},
+	}
+
+	// WHEN
+	withoutFormat.init()
+	withFormat.init()
+
+	// THEN
+	assert.Equal(t, forwardedClientCertificateFormatPEM, withoutFormat.ForwardedClientCert.Format)
+	assert.Equal(t, forwardedClientCertificateFormatXFCC, withFormat.ForwardedClientCert.Format)
+}
+
+func TestCertificateBindingVerify(t *testing.T) {
+	t.Parallel()
+
+	ca1, err := testsupport.NewRootCA("Test CA 1", time.Hour)
+	require.NoError(t, err)
+
+	ca2, err := testsupport.NewRootCA("Test CA 2", time.Hour)
+	require.NoError(t, err)
+
+	thumbprint := sha256.Sum256(ca1.Certificate.Raw)
+	boundClaims := []byte(`{"sub":"foo","cnf":{"x5t#S256":"` +
+		base64.RawURLEncoding.EncodeToString(thumbprint[:]) + `"}}`)
+	forwardedCert := url.PathEscape(string(pem.EncodeToMemory(
+		&pem.Block{Type: "CERTIFICATE", Bytes: ca1.Certificate.Raw})))
+
+	for _, tc := range []struct {
+		uc               string
+		binding          *certificateBinding
+		tokenClaims      []byte
+		fromTrustedProxy bool
+		configureFn      func(t *testing.T, fnt *mocks.RequestFunctionsMock) []*x509.Certificate
+		assert           func(t *testing.T, err error)
+	}{
+		{
+			uc:          "token not bound and binding not required",
+			binding:     &certificateBinding{},
+			tokenClaims: []byte(`{"sub":"foo","cnf":{"jkt":"bar"}}`),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:          "token not bound but binding required",
+			binding:     &certificateBinding{Required: true},
+			tokenClaims: []byte(`{"sub":"foo"}`),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errCertificateBindingMissing)
+			},
+		},
+		{
+			uc:          "bound token without client certificate",
+			binding:     &certificateBinding{},
+			tokenClaims: boundClaims,
+			configureFn: func(t *testing.T, _ *mocks.RequestFunctionsMock) []*x509.Certificate {
+				t.Helper()
+
+				return nil
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errNoClientCertificate)
+			},
+		},
+		{
+			uc:          "bound token with different client certificate",
+			binding:     &certificateBinding{},
+			tokenClaims: boundClaims,
+			configureFn: func(t *testing.T, _ *mocks.RequestFunctionsMock) []*x509.Certificate {
+				t.Helper()
+
+				return []*x509.Certificate{ca2.Certificate}
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, oauth2.ErrAssertion)
+				require.ErrorContains(t, err, "different certificate")
+			},
+		},
+		{
+			uc:          "bound token with matching client certificate",
+			binding:     &certificateBinding{Required: true},
+			tokenClaims: boundClaims,
+			configureFn: func(t *testing.T, _ *mocks.RequestFunctionsMock) []*x509.Certificate {
+				t.Helper()
+
+				return []*x509.Certificate{ca1.Certificate, ca2.Certificate}
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc: "bound token with forwarded client certificate header missing",
+			binding: &certificateBinding{
+				ForwardedClientCert: &forwardedClientCertificate{Name: "X-Client-Cert", Format: "pem"},
+			},
+			tokenClaims:      boundClaims,
+			fromTrustedProxy: true,
+			configureFn: func(t *testing.T, fnt *mocks.RequestFunctionsMock) []*x509.Certificate {
+				t.Helper()
+
+				fnt.EXPECT().Header("X-Client-Cert").Return("")
+
+				return []*x509.Certificate{ca1.Certificate}
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errNoClientCertificate)
+			},
+		},
+		{
+			uc: "bound token with matching forwarded client certificate",
+			binding: &certificateBinding{
+				ForwardedClientCert: &forwardedClientCertificate{Name: "X-Client-Cert", Format: "pem"},
+			},
+			tokenClaims:      boundClaims,
+			fromTrustedProxy: true,
+			configureFn: func(t *testing.T, fnt *mocks.RequestFunctionsMock) []*x509.Certificate {
+				t.Helper()
+
+				fnt.EXPECT().Header("X-Client-Cert").Return(forwardedCert)
+
+				return nil
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc: "bound token with forwarded client certificate not received from a trusted proxy",
+			binding: &certificateBinding{
+				ForwardedClientCert: &forwardedClientCertificate{Name: "X-Client-Cert", Format: "pem"},
+			},
+			tokenClaims: boundClaims,
+			configureFn: func(t *testing.T, fnt *mocks.RequestFunctionsMock) []*x509.Certificate {
+				t.Helper()
+
+				fnt.EXPECT().Header("X-Client-Cert").Return(forwardedCert).Maybe()
+
+				return nil
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errUntrustedClientCertificate)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureFn := x.IfThenElse(tc.configureFn != nil,
+				tc.configureFn,
+				func(t *testing.T, _ *mocks.RequestFunctionsMock) []*x509.Certificate {
+					t.Helper()
+
+					return nil
+				})
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			certs := configureFn(t, fnt)
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().Request().Return(&heimdall.Request{
+				RequestFunctions:   fnt,
+				ClientCertificates: certs,
+				FromTrustedProxy:   tc.fromTrustedProxy,
+			}).Maybe()
+
+			// WHEN
+			err := tc.binding.verify(ctx, &oauth2.Expectation{}, tc.tokenClaims)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
@@ -23,4 +23,8 @@ const (
 	AuthenticatorOAuth2Introspection = "oauth2_introspection"
 	AuthenticatorJwt                 = "jwt"
 	AuthenticatorGeneric             = "generic"
+	AuthenticatorX509                = "x509"
+	AuthenticatorAPIKey              = "api_key"
+	AuthenticatorHTTPSignature       = "http_signature"
+	AuthenticatorOIDCSession         = "oidc_session"
 )
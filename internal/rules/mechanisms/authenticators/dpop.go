@@ -0,0 +1,237 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/rs/zerolog"
+	"github.com/tidwall/gjson"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	headerDPoP             = "DPoP"
+	dpopProofType          = "dpop+jwt"
+	defaultDPoPProofMaxAge = 1 * time.Minute
+	// dpopClockSkew defines the time deviation accepted for the iat claim of a proof.
+	dpopClockSkew = 10 * time.Second
+)
+
+var (
+	errDPoPProofMissing   = errors.New("DPoP proof missing")
+	errDPoPProofInvalid   = errors.New("DPoP proof invalid")
+	errDPoPProofReplayed  = errors.New("DPoP proof replayed")
+	errDPoPBindingMissing = errors.New("access token is not DPoP bound")
+)
+
+// dpopVerifier verifies DPoP proofs according to RFC 9449 for access tokens.
+type dpopVerifier struct {
+	Required          bool           `mapstructure:"required"`
+	AllowedAlgorithms []string       `mapstructure:"allowed_algorithms"`
+	MaxAge            *time.Duration `mapstructure:"max_age"`
+}
+
+type dpopProofClaims struct {
+	ID              string           `json:"jti"`
+	HTTPMethod      string           `json:"htm"`
+	HTTPURI         string           `json:"htu"`
+	IssuedAt        *jwt.NumericDate `json:"iat"`
+	AccessTokenHash string           `json:"ath"`
+}
+
+// withDPoPTokenSource extends the default token sources by the Authorization header with the DPoP scheme.
+func withDPoPTokenSource(ads extractors.CompositeExtractStrategy) extractors.CompositeExtractStrategy {
+	return append(
+		extractors.CompositeExtractStrategy{extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "DPoP"}},
+		ads...,
+	)
+}
+
+func (v *dpopVerifier) init() {
+	if len(v.AllowedAlgorithms) == 0 {
+		v.AllowedAlgorithms = append(defaultAllowedAlgorithms(), string(jose.EdDSA))
+	}
+
+	if v.MaxAge == nil {
+		maxAge := defaultDPoPProofMaxAge
+		v.MaxAge = &maxAge
+	}
+}
+
+// verify verifies the DPoP proof sent with the request for the given access token. tokenClaims are the
+// claims of the access token, respectively the introspection response, which may contain the jkt
+// confirmation claim. If the access token is not DPoP bound, a proof is only required if configured.
+func (v *dpopVerifier) verify(ctx heimdall.Context, accessToken string, tokenClaims []byte) error {
+	jkt := gjson.GetBytes(tokenClaims, "cnf.jkt").String()
+	if len(jkt) == 0 {
+		if v.Required {
+			return errDPoPBindingMissing
+		}
+
+		return nil
+	}
+
+	req := ctx.Request()
+
+	rawProof := req.Header(headerDPoP)
+	if len(rawProof) == 0 {
+		return errDPoPProofMissing
+	}
+
+	// multiple DPoP headers are joined with a comma, which is not part of a compact JWS
+	if strings.Contains(rawProof, ",") {
+		return errorchain.NewWithMessage(errDPoPProofInvalid, "multiple DPoP proofs present")
+	}
+
+	jwk, claims, err := v.parseProof(rawProof)
+	if err != nil {
+		return err
+	}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return errorchain.NewWithMessage(errDPoPProofInvalid, "failed to calculate jwk thumbprint").CausedBy(err)
+	}
+
+	if subtle.ConstantTimeCompare(stringx.ToBytes(base64.RawURLEncoding.EncodeToString(thumbprint)),
+		stringx.ToBytes(jkt)) != 1 {
+		return errorchain.NewWithMessage(errDPoPProofInvalid, "proof key does not match the token binding")
+	}
+
+	if err = v.verifyClaims(req, claims, accessToken); err != nil {
+		return err
+	}
+
+	return v.preventReplay(ctx, jkt, claims.ID)
+}
+
+func (v *dpopVerifier) parseProof(rawProof string) (*jose.JSONWebKey, *dpopProofClaims, error) {
+	algorithms := make([]jose.SignatureAlgorithm, len(v.AllowedAlgorithms))
+	for idx, alg := range v.AllowedAlgorithms {
+		algorithms[idx] = jose.SignatureAlgorithm(alg)
+	}
+
+	proof, err := jwt.ParseSigned(rawProof, algorithms)
+	if err != nil {
+		return nil, nil, errorchain.NewWithMessage(errDPoPProofInvalid, "failed to parse proof").CausedBy(err)
+	}
+
+	header := proof.Headers[0]
+
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != dpopProofType {
+		return nil, nil, errorchain.NewWithMessagef(errDPoPProofInvalid, "unexpected proof type '%s'", typ)
+	}
+
+	if header.JSONWebKey == nil || !header.JSONWebKey.IsPublic() || !header.JSONWebKey.Valid() {
+		return nil, nil, errorchain.NewWithMessage(errDPoPProofInvalid, "proof does not contain a valid public key")
+	}
+
+	var claims dpopProofClaims
+	if err = proof.Claims(header.JSONWebKey.Key, &claims); err != nil {
+		return nil, nil, errorchain.NewWithMessage(errDPoPProofInvalid,
+			"failed to verify proof signature").CausedBy(err)
+	}
+
+	return header.JSONWebKey, &claims, nil
+}
+
+func (v *dpopVerifier) verifyClaims(req *heimdall.Request, claims *dpopProofClaims, accessToken string) error {
+	now := time.Now()
+
+	switch {
+	case len(claims.ID) == 0:
+		return errorchain.NewWithMessage(errDPoPProofInvalid, "proof has no jti claim")
+	case claims.HTTPMethod != req.Method:
+		return errorchain.NewWithMessagef(errDPoPProofInvalid,
+			"htm claim '%s' does not match the request method", claims.HTTPMethod)
+	case !dpopURIMatches(claims.HTTPURI, req):
+		return errorchain.NewWithMessagef(errDPoPProofInvalid,
+			"htu claim '%s' does not match the request uri", claims.HTTPURI)
+	case claims.IssuedAt == nil:
+		return errorchain.NewWithMessage(errDPoPProofInvalid, "proof has no iat claim")
+	case claims.IssuedAt.Time().After(now.Add(dpopClockSkew)):
+		return errorchain.NewWithMessage(errDPoPProofInvalid, "proof is issued in the future")
+	case now.Sub(claims.IssuedAt.Time()) > *v.MaxAge:
+		return errorchain.NewWithMessage(errDPoPProofInvalid, "proof is too old")
+	}
+
+	ath := sha256.Sum256(stringx.ToBytes(accessToken))
+	if subtle.ConstantTimeCompare(stringx.ToBytes(base64.RawURLEncoding.EncodeToString(ath[:])),
+		stringx.ToBytes(claims.AccessTokenHash)) != 1 {
+		return errorchain.NewWithMessage(errDPoPProofInvalid, "ath claim does not match the access token")
+	}
+
+	return nil
+}
+
+// preventReplay makes sure a proof is accepted only once within the time frame it is accepted at all.
+func (v *dpopVerifier) preventReplay(ctx heimdall.Context, jkt, jti string) error {
+	cch := cache.Ctx(ctx.AppContext())
+	cacheKey := dpopReplayCacheKey(jkt, jti)
+
+	stored, err := cch.SetIfAbsent(ctx.AppContext(), cacheKey, []byte{1}, *v.MaxAge+2*dpopClockSkew)
+	if err != nil {
+		zerolog.Ctx(ctx.AppContext()).Warn().Err(err).Msg("Failed to cache DPoP proof id")
+
+		return nil
+	}
+
+	if !stored {
+		return errDPoPProofReplayed
+	}
+
+	return nil
+}
+
+func dpopReplayCacheKey(jkt, jti string) string {
+	digest := sha256.Sum256(stringx.ToBytes(jkt + ":" + jti))
+
+	return "dpop:" + hex.EncodeToString(digest[:])
+}
+
+// dpopURIMatches compares the htu claim with the request uri ignoring query and fragment parts
+// according to RFC 9449, section 4.3.
+func dpopURIMatches(htu string, req *heimdall.Request) bool {
+	htuURL, err := url.Parse(htu)
+	if err != nil || !htuURL.IsAbs() {
+		return false
+	}
+
+	reqURL := req.URL.URL
+
+	return strings.EqualFold(htuURL.Scheme, reqURL.Scheme) &&
+		strings.EqualFold(htuURL.Host, reqURL.Host) &&
+		x.IfThenElse(len(htuURL.EscapedPath()) == 0, "/", htuURL.EscapedPath()) ==
+			x.IfThenElse(len(reqURL.EscapedPath()) == 0, "/", reqURL.EscapedPath())
+}
@@ -0,0 +1,433 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+)
+
+func createDPoPProof(t *testing.T, key *ecdsa.PrivateKey, typ string, claims map[string]any) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{EmbedJWK: true}).WithType(jose.ContentType(typ)),
+	)
+	require.NoError(t, err)
+
+	proof, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+
+	return proof
+}
+
+func dpopThumbprint(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+
+	jwk := jose.JSONWebKey{Key: key}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint)
+}
+
+func TestDPoPVerifierInit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	maxAge := 5 * time.Minute
+	configured := &dpopVerifier{AllowedAlgorithms: []string{"ES256"}, MaxAge: &maxAge}
+	defaulted := &dpopVerifier{}
+
+	// WHEN
+	configured.init()
+	defaulted.init()
+
+	// THEN
+	assert.Equal(t, []string{"ES256"}, configured.AllowedAlgorithms)
+	assert.Equal(t, maxAge, *configured.MaxAge)
+	assert.ElementsMatch(t, append(defaultAllowedAlgorithms(), string(jose.EdDSA)), defaulted.AllowedAlgorithms)
+	assert.Equal(t, defaultDPoPProofMaxAge, *defaulted.MaxAge)
+}
+
+func TestWithDPoPTokenSource(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	ads := withDPoPTokenSource(extractors.CompositeExtractStrategy{
+		extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "Bearer"},
+	})
+
+	// THEN
+	assert.Equal(t, extractors.CompositeExtractStrategy{
+		extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "DPoP"},
+		extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "Bearer"},
+	}, ads)
+}
+
+func TestDPoPVerifierVerify(t *testing.T) {
+	t.Parallel()
+
+	proofKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	reqURL, err := url.Parse("https://example.com/foo?bar=baz")
+	require.NoError(t, err)
+
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	accessToken := "some.access.token"
+	ath := sha256.Sum256([]byte(accessToken))
+	jkt := dpopThumbprint(t, proofKey.Public())
+	boundClaims := []byte(`{"sub":"foo","cnf":{"jkt":"` + jkt + `"}}`)
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"jti": "some-id",
+			"htm": http.MethodPost,
+			"htu": "https://example.com/foo",
+			"iat": time.Now().Unix(),
+			"ath": base64.RawURLEncoding.EncodeToString(ath[:]),
+		}
+	}
+
+	replayedProof := createDPoPProof(t, proofKey, dpopProofType, func() map[string]any {
+		claims := validClaims()
+		claims["jti"] = "replayed-id"
+
+		return claims
+	}())
+
+	for _, tc := range []struct {
+		uc          string
+		required    bool
+		tokenClaims []byte
+		proof       func(t *testing.T) string
+		assert      func(t *testing.T, err error)
+	}{
+		{
+			uc:          "token not bound and binding not required",
+			tokenClaims: []byte(`{"sub":"foo"}`),
+			proof:       func(*testing.T) string { return "" },
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:          "token not bound but binding required",
+			required:    true,
+			tokenClaims: []byte(`{"sub":"foo"}`),
+			proof:       func(*testing.T) string { return "" },
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPBindingMissing)
+			},
+		},
+		{
+			uc:          "bound token without proof",
+			tokenClaims: boundClaims,
+			proof:       func(*testing.T) string { return "" },
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofMissing)
+			},
+		},
+		{
+			uc:          "multiple proofs",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				proof := createDPoPProof(t, proofKey, dpopProofType, validClaims())
+
+				return proof + "," + proof
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "multiple")
+			},
+		},
+		{
+			uc:          "malformed proof",
+			tokenClaims: boundClaims,
+			proof:       func(*testing.T) string { return "foo.bar.baz" },
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "failed to parse")
+			},
+		},
+		{
+			uc:          "proof with wrong type",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				return createDPoPProof(t, proofKey, "JWT", validClaims())
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "unexpected proof type")
+			},
+		},
+		{
+			uc:          "proof signed with key not matching the binding",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				return createDPoPProof(t, otherKey, dpopProofType, validClaims())
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "does not match the token binding")
+			},
+		},
+		{
+			uc:          "proof with wrong htm claim",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["htm"] = http.MethodGet
+
+				return createDPoPProof(t, proofKey, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "htm claim")
+			},
+		},
+		{
+			uc:          "proof with wrong htu claim",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["htu"] = "https://example.com/bar"
+
+				return createDPoPProof(t, proofKey, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "htu claim")
+			},
+		},
+		{
+			uc:          "proof without jti claim",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				delete(claims, "jti")
+
+				return createDPoPProof(t, proofKey, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "jti")
+			},
+		},
+		{
+			uc:          "proof is too old",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["iat"] = time.Now().Add(-2 * time.Minute).Unix()
+
+				return createDPoPProof(t, proofKey, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "too old")
+			},
+		},
+		{
+			uc:          "proof issued in the future",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["iat"] = time.Now().Add(1 * time.Minute).Unix()
+
+				return createDPoPProof(t, proofKey, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "future")
+			},
+		},
+		{
+			uc:          "proof with wrong ath claim",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["ath"] = "foo"
+
+				return createDPoPProof(t, proofKey, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofInvalid)
+				require.ErrorContains(t, err, "ath claim")
+			},
+		},
+		{
+			uc:          "valid proof",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				claims := validClaims()
+				claims["jti"] = "unique-id"
+
+				return createDPoPProof(t, proofKey, dpopProofType, claims)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:          "replayed proof",
+			tokenClaims: boundClaims,
+			proof: func(t *testing.T) string {
+				t.Helper()
+
+				err := cch.Set(context.Background(), dpopReplayCacheKey(jkt, "replayed-id"), []byte{1}, time.Minute)
+				require.NoError(t, err)
+
+				return replayedProof
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errDPoPProofReplayed)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			verifier := &dpopVerifier{Required: tc.required}
+			verifier.init()
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Header(headerDPoP).Return(tc.proof(t)).Maybe()
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch)).Maybe()
+			ctx.EXPECT().Request().Return(&heimdall.Request{
+				RequestFunctions: fnt,
+				Method:           http.MethodPost,
+				URL:              &heimdall.URL{URL: *reqURL},
+			}).Maybe()
+
+			// WHEN
+			err := verifier.verify(ctx, accessToken, tc.tokenClaims)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
+func TestDPoPURIMatches(t *testing.T) {
+	t.Parallel()
+
+	reqURL, err := url.Parse("https://Example.com/foo?bar=baz")
+	require.NoError(t, err)
+
+	req := &heimdall.Request{URL: &heimdall.URL{URL: *reqURL}}
+
+	for _, tc := range []struct {
+		htu     string
+		matches bool
+	}{
+		{htu: "https://example.com/foo", matches: true},
+		{htu: "https://example.com/foo?other=value#frag", matches: true},
+		{htu: "http://example.com/foo", matches: false},
+		{htu: "https://example.org/foo", matches: false},
+		{htu: "https://example.com/foo/bar", matches: false},
+		{htu: "/foo", matches: false},
+		{htu: "://", matches: false},
+	} {
+		t.Run(tc.htu, func(t *testing.T) {
+			// WHEN
+			matches := dpopURIMatches(tc.htu, req)
+
+			// THEN
+			assert.Equal(t, tc.matches, matches)
+		})
+	}
+}
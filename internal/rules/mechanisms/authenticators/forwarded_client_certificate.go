@@ -0,0 +1,196 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	// forwardedClientCertificateFormatXFCC is the format of the x-forwarded-client-cert header set by envoy.
+	forwardedClientCertificateFormatXFCC = "xfcc"
+	// forwardedClientCertificateFormatPEM is used for headers holding PEM encoded certificates, which
+	// can be url encoded, like e.g. set by nginx using the $ssl_client_escaped_cert variable.
+	forwardedClientCertificateFormatPEM = "pem"
+
+	pemCertificateBegin = "-----BEGIN CERTIFICATE-----"
+	pemCertificateEnd   = "-----END CERTIFICATE-----"
+)
+
+var (
+	errNoClientCertificate        = errors.New("no client certificate present")
+	errMalformedClientCertificate = errors.New("malformed client certificate")
+	errUntrustedClientCertificate = errors.New("untrusted client certificate source")
+)
+
+// forwardedClientCertificate describes the header, a proxy in front of heimdall uses to
+// forward the certificate chain presented by the client in the TLS handshake.
+type forwardedClientCertificate struct {
+	Name   string `mapstructure:"name"   validate:"required"`
+	Format string `mapstructure:"format" validate:"omitempty,oneof=xfcc pem"`
+}
+
+// certificates returns the certificates from the configured header. As anyone can set that header,
+// it is only taken into account if the request has been received from a trusted proxy.
+func (f *forwardedClientCertificate) certificates(ctx heimdall.Context) ([]*x509.Certificate, error) {
+	req := ctx.Request()
+	if !req.FromTrustedProxy {
+		return nil, errorchain.NewWithMessagef(errUntrustedClientCertificate,
+			"'%s' header not received from a trusted proxy", f.Name)
+	}
+
+	value := strings.TrimSpace(req.Header(f.Name))
+	if len(value) == 0 {
+		return nil, errorchain.NewWithMessagef(errNoClientCertificate, "'%s' header not present", f.Name)
+	}
+
+	if f.Format == forwardedClientCertificateFormatXFCC {
+		return certificatesFromXFCC(value)
+	}
+
+	return certificatesFromPEM(value)
+}
+
+// certificatesFromXFCC extracts the certificates from the last element of the given
+// x-forwarded-client-cert header value. That element is the one added by the proxy
+// directly in front of heimdall. The Chain key is preferred over the Cert one.
+func certificatesFromXFCC(value string) ([]*x509.Certificate, error) {
+	var cert, chain string
+
+	elements := splitUnquoted(value, ',')
+
+	for _, pair := range splitUnquoted(elements[len(elements)-1], ';') {
+		key, val, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "cert":
+			cert = unquote(val)
+		case "chain":
+			chain = unquote(val)
+		}
+	}
+
+	if len(chain) != 0 {
+		return certificatesFromPEM(chain)
+	}
+
+	if len(cert) != 0 {
+		return certificatesFromPEM(cert)
+	}
+
+	return nil, errorchain.NewWithMessage(errNoClientCertificate,
+		"no certificate in the forwarded client certificate header")
+}
+
+// certificatesFromPEM parses the certificates from the given, optionally url encoded, PEM
+// value. Line breaks may have been replaced by proxies with white spaces, respectively
+// may be followed by tabs. So all white spaces are removed from the certificate contents.
+func certificatesFromPEM(value string) ([]*x509.Certificate, error) {
+	decoded, err := url.PathUnescape(value)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(errMalformedClientCertificate,
+			"failed to url decode the forwarded client certificate").CausedBy(err)
+	}
+
+	var certs []*x509.Certificate
+
+	for {
+		_, rest, found := strings.Cut(decoded, pemCertificateBegin)
+		if !found {
+			break
+		}
+
+		contents, next, found := strings.Cut(rest, pemCertificateEnd)
+		if !found {
+			return nil, errorchain.NewWithMessage(errMalformedClientCertificate, "incomplete PEM block")
+		}
+
+		der, err := base64.StdEncoding.DecodeString(strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+
+			return r
+		}, contents))
+		if err != nil {
+			return nil, errorchain.NewWithMessage(errMalformedClientCertificate,
+				"failed to decode the forwarded client certificate").CausedBy(err)
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, errorchain.NewWithMessage(errMalformedClientCertificate,
+				"failed to parse the forwarded client certificate").CausedBy(err)
+		}
+
+		certs = append(certs, cert)
+		decoded = next
+	}
+
+	if len(certs) == 0 {
+		return nil, errorchain.NewWithMessage(errMalformedClientCertificate, "no PEM encoded certificate found")
+	}
+
+	return certs, nil
+}
+
+// splitUnquoted splits the given value at the given separator if it does not appear in a quoted string.
+func splitUnquoted(value string, sep rune) []string {
+	var (
+		parts   []string
+		quoted  bool
+		escaped bool
+		start   int
+	)
+
+	for idx, r := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == sep && !quoted:
+			parts = append(parts, value[start:idx])
+			start = idx + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+	}
+
+	return value
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestForwardedClientCertificateParsing(t *testing.T) {
+	t.Parallel()
+
+	ca1, err := testsupport.NewRootCA("Test CA 1", time.Hour)
+	require.NoError(t, err)
+
+	ca2, err := testsupport.NewRootCA("Test CA 2", time.Hour)
+	require.NoError(t, err)
+
+	pem1 := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca1.Certificate.Raw}))
+	pem2 := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca2.Certificate.Raw}))
+
+	for _, tc := range []struct {
+		uc     string
+		format string
+		value  string
+		assert func(t *testing.T, err error, certs []*x509.Certificate)
+	}{
+		{
+			uc:     "url encoded pem",
+			format: forwardedClientCertificateFormatPEM,
+			value:  url.PathEscape(pem1 + pem2),
+			assert: func(t *testing.T, err error, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, certs, 2)
+				assert.True(t, ca1.Certificate.Equal(certs[0]))
+				assert.True(t, ca2.Certificate.Equal(certs[1]))
+			},
+		},
+		{
+			uc:     "pem with line breaks replaced by white spaces",
+			format: forwardedClientCertificateFormatPEM,
+			value:  strings.ReplaceAll(pem1, "\n", " \t"),
+			assert: func(t *testing.T, err error, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, certs, 1)
+				assert.True(t, ca1.Certificate.Equal(certs[0]))
+			},
+		},
+		{
+			uc:     "not url decodable pem",
+			format: forwardedClientCertificateFormatPEM,
+			value:  "%zz",
+			assert: func(t *testing.T, err error, _ []*x509.Certificate) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errMalformedClientCertificate)
+			},
+		},
+		{
+			uc:     "incomplete pem block",
+			format: forwardedClientCertificateFormatPEM,
+			value:  pem1[:len(pem1)-20],
+			assert: func(t *testing.T, err error, _ []*x509.Certificate) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errMalformedClientCertificate)
+				assert.Contains(t, err.Error(), "incomplete")
+			},
+		},
+		{
+			uc:     "pem block with invalid contents",
+			format: forwardedClientCertificateFormatPEM,
+			value:  pemCertificateBegin + "Zm9vYmFy" + pemCertificateEnd,
+			assert: func(t *testing.T, err error, _ []*x509.Certificate) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errMalformedClientCertificate)
+				assert.Contains(t, err.Error(), "failed to parse")
+			},
+		},
+		{
+			uc:     "no pem at all",
+			format: forwardedClientCertificateFormatPEM,
+			value:  "foobar",
+			assert: func(t *testing.T, err error, _ []*x509.Certificate) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errMalformedClientCertificate)
+			},
+		},
+		{
+			uc:     "xfcc with cert only",
+			format: forwardedClientCertificateFormatXFCC,
+			value:  `By=spiffe://example.org/foo;Cert="` + url.PathEscape(pem1) + `";URI=spiffe://example.org/bar`,
+			assert: func(t *testing.T, err error, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, certs, 1)
+				assert.True(t, ca1.Certificate.Equal(certs[0]))
+			},
+		},
+		{
+			uc:     "xfcc with cert and chain",
+			format: forwardedClientCertificateFormatXFCC,
+			value:  `Cert=` + url.PathEscape(pem1) + `;Chain=` + url.PathEscape(pem1+pem2),
+			assert: func(t *testing.T, err error, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, certs, 2)
+			},
+		},
+		{
+			uc:     "xfcc with multiple elements and quoted separators",
+			format: forwardedClientCertificateFormatXFCC,
+			value: `Cert="` + url.PathEscape(pem1) + `";Subject="CN=foo,O=\"Foo;Bar\""` +
+				`,Subject="CN=bar,O=Bar";Cert="` + url.PathEscape(pem2) + `"`,
+			assert: func(t *testing.T, err error, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, certs, 1)
+				assert.True(t, ca2.Certificate.Equal(certs[0]))
+			},
+		},
+		{
+			uc:     "xfcc without certificate",
+			format: forwardedClientCertificateFormatXFCC,
+			value:  `By=spiffe://example.org/foo;Hash=abc;Subject="CN=foo"`,
+			assert: func(t *testing.T, err error, _ []*x509.Certificate) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errNoClientCertificate)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			var (
+				certs []*x509.Certificate
+				err   error
+			)
+
+			// WHEN
+			if tc.format == forwardedClientCertificateFormatXFCC {
+				certs, err = certificatesFromXFCC(tc.value)
+			} else {
+				certs, err = certificatesFromPEM(tc.value)
+			}
+
+			// THEN
+			tc.assert(t, err, certs)
+		})
+	}
+}
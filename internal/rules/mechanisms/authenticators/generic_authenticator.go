@@ -17,6 +17,7 @@
 package authenticators
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -27,6 +28,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/heimdall"
@@ -37,14 +39,17 @@ import (
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/stringx"
+	"github.com/dadrus/heimdall/internal/x/syncx"
 )
 
+const sessionRefreshTimeout = 10 * time.Second
+
 // by intention. Used only during application bootstrap
 //
 //nolint:gochecknoinits
 func init() {
 	registerTypeFactory(
-		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+		func(_ CreationContext, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
 			if typ != AuthenticatorGeneric {
 				return false, nil, nil
 			}
@@ -65,7 +70,14 @@ type genericAuthenticator struct {
 	sf                   SubjectFactory
 	ttl                  time.Duration
 	sessionLifespanConf  *SessionLifespanConfig
+	sessionRefresh       *SessionRefreshConfig
 	allowFallbackOnError bool
+	group                *singleflight.Group
+}
+
+type sessionRefreshResult struct {
+	payload []byte
+	cookies []*http.Cookie
 }
 
 func newGenericAuthenticator(id string, rawConfig map[string]any) (*genericAuthenticator, error) {
@@ -77,6 +89,7 @@ func newGenericAuthenticator(id string, rawConfig map[string]any) (*genericAuthe
 		ForwardCookies        []string                            `mapstructure:"forward_cookies"`
 		Payload               template.Template                   `mapstructure:"payload"`
 		SessionLifespanConfig *SessionLifespanConfig              `mapstructure:"session_lifespan"`
+		SessionRefreshConfig  *SessionRefreshConfig               `mapstructure:"session_refresh"`
 		CacheTTL              *time.Duration                      `mapstructure:"cache_ttl"`
 		AllowFallbackOnError  bool                                `mapstructure:"allow_fallback_on_error"`
 	}
@@ -86,6 +99,17 @@ func newGenericAuthenticator(id string, rawConfig map[string]any) (*genericAuthe
 		return nil, err
 	}
 
+	if conf.SessionRefreshConfig != nil {
+		if conf.SessionLifespanConfig == nil || len(conf.SessionLifespanConfig.NotAfterField) == 0 {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"session_refresh requires session_lifespan with not_after to be configured")
+		}
+
+		if conf.SessionRefreshConfig.RefreshBefore <= 0 {
+			conf.SessionRefreshConfig.RefreshBefore = defaultSessionRefreshThreshold
+		}
+	}
+
 	return &genericAuthenticator{
 		id:         id,
 		e:          conf.Endpoint,
@@ -99,6 +123,8 @@ func newGenericAuthenticator(id string, rawConfig map[string]any) (*genericAuthe
 			func() time.Duration { return 0 }),
 		allowFallbackOnError: conf.AllowFallbackOnError,
 		sessionLifespanConf:  conf.SessionLifespanConfig,
+		sessionRefresh:       conf.SessionRefreshConfig,
+		group:                &singleflight.Group{},
 	}, nil
 }
 
@@ -161,6 +187,8 @@ func (a *genericAuthenticator) WithConfig(config map[string]any) (Authenticator,
 			func() bool { return *conf.AllowFallbackOnError },
 			func() bool { return a.allowFallbackOnError }),
 		sessionLifespanConf: a.sessionLifespanConf,
+		sessionRefresh:      a.sessionRefresh,
+		group:               a.group,
 	}, nil
 }
 
@@ -176,10 +204,7 @@ func (a *genericAuthenticator) getSubjectInformation(ctx heimdall.Context, authD
 	logger := zerolog.Ctx(ctx.AppContext())
 	cch := cache.Ctx(ctx.AppContext())
 
-	var (
-		cacheKey string
-		session  *SessionLifespan
-	)
+	var cacheKey string
 
 	if a.ttl > 0 {
 		cacheKey = a.calculateCacheKey(authData)
@@ -195,17 +220,9 @@ func (a *genericAuthenticator) getSubjectInformation(ctx heimdall.Context, authD
 		return nil, err
 	}
 
-	if a.sessionLifespanConf != nil {
-		session, err = a.sessionLifespanConf.CreateSessionLifespan(payload)
-		if err != nil {
-			return nil, errorchain.New(heimdall.ErrInternal).WithErrorContext(a).CausedBy(err)
-		}
-
-		if session != nil {
-			if err = session.Assert(); err != nil {
-				return nil, errorchain.New(heimdall.ErrAuthentication).WithErrorContext(a).CausedBy(err)
-			}
-		}
+	payload, session, err := a.verifySession(ctx, authData, payload)
+	if err != nil {
+		return nil, err
 	}
 
 	if cacheTTL := a.getCacheTTL(session); cacheTTL > 0 {
@@ -217,36 +234,140 @@ func (a *genericAuthenticator) getSubjectInformation(ctx heimdall.Context, authD
 	return payload, nil
 }
 
+func (a *genericAuthenticator) verifySession(
+	ctx heimdall.Context, authData string, payload []byte,
+) ([]byte, *SessionLifespan, error) {
+	if a.sessionLifespanConf == nil {
+		return payload, nil, nil
+	}
+
+	session, err := a.assertSession(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if a.sessionRefresh == nil || session == nil || !session.expiresWithin(a.sessionRefresh.RefreshBefore) {
+		return payload, session, nil
+	}
+
+	refreshedPayload, refreshedSession, err := a.refreshSession(ctx, authData)
+	if err != nil {
+		// the session is still valid, so there is no reason to reject the request
+		zerolog.Ctx(ctx.AppContext()).Warn().Err(err).Msg("Failed to refresh session")
+
+		return payload, session, nil
+	}
+
+	return refreshedPayload, refreshedSession, nil
+}
+
+func (a *genericAuthenticator) assertSession(payload []byte) (*SessionLifespan, error) {
+	session, err := a.sessionLifespanConf.CreateSessionLifespan(payload)
+	if err != nil {
+		return nil, errorchain.New(heimdall.ErrInternal).WithErrorContext(a).CausedBy(err)
+	}
+
+	if session != nil {
+		if err = session.Assert(); err != nil {
+			return nil, errorchain.New(heimdall.ErrAuthentication).WithErrorContext(a).CausedBy(err)
+		}
+	}
+
+	return session, nil
+}
+
+func (a *genericAuthenticator) refreshSession(
+	ctx heimdall.Context, authData string,
+) ([]byte, *SessionLifespan, error) {
+	zerolog.Ctx(ctx.AppContext()).Debug().Msg("Session is about to expire. Refreshing it")
+
+	req, err := a.createRequest(ctx, a.sessionRefresh.Endpoint, a.sessionRefresh.Payload, authData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// concurrent requests for the same session result in a single call to the refresh endpoint
+	res, err := syncx.DoDetached(ctx.AppContext(), a.group, a.calculateCacheKey(authData), sessionRefreshTimeout,
+		func(refreshCtx context.Context) (*sessionRefreshResult, error) {
+			return a.callSessionRefreshEndpoint(req.WithContext(refreshCtx))
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := a.assertSession(res.payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the cookies set by the refresh endpoint are forwarded to the upstream
+	// and sent to the client as well, so that the renewed session is used
+	// for subsequent requests
+	for _, cookie := range res.cookies {
+		ctx.AddCookieForUpstream(cookie.Name, cookie.Value)
+		ctx.AddCookieForClient(cookie)
+	}
+
+	return res.payload, session, nil
+}
+
+func (a *genericAuthenticator) callSessionRefreshEndpoint(req *http.Request) (*sessionRefreshResult, error) {
+	resp, err := a.sendRequest(a.sessionRefresh.Endpoint, req, "refresh the session")
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	payload, err := a.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sessionRefreshResult{payload: payload, cookies: resp.Cookies()}, nil
+}
+
 func (a *genericAuthenticator) fetchSubjectInformation(ctx heimdall.Context, authData string) ([]byte, error) {
-	req, err := a.createRequest(ctx, authData)
+	req, err := a.createRequest(ctx, a.e, a.payload, authData)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := a.e.CreateClient(req.URL.Hostname()).Do(req)
+	resp, err := a.sendRequest(a.e, req, "get information about the user")
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return a.readResponse(resp)
+}
+
+func (a *genericAuthenticator) sendRequest(
+	ep endpoint.Endpoint, req *http.Request, purpose string,
+) (*http.Response, error) {
+	resp, err := ep.CreateClient(req.URL.Hostname()).Do(req)
 	if err != nil {
 		var clientErr *url.Error
 		if errors.As(err, &clientErr) && clientErr.Timeout() {
 			return nil, errorchain.
-				NewWithMessage(heimdall.ErrCommunicationTimeout,
-					"request to the endpoint to get information about the user timed out").
+				NewWithMessagef(heimdall.ErrCommunicationTimeout, "request to the endpoint to %s timed out", purpose).
 				WithErrorContext(a).
 				CausedBy(err)
 		}
 
 		return nil, errorchain.
-			NewWithMessage(heimdall.ErrCommunication,
-				"request to the endpoint to get information about the user failed").
+			NewWithMessagef(heimdall.ErrCommunication, "request to the endpoint to %s failed", purpose).
 			WithErrorContext(a).
 			CausedBy(err)
 	}
 
-	defer resp.Body.Close()
-
-	return a.readResponse(resp)
+	return resp, nil
 }
 
-func (a *genericAuthenticator) createRequest(ctx heimdall.Context, authData string) (*http.Request, error) {
+func (a *genericAuthenticator) createRequest(
+	ctx heimdall.Context, ep endpoint.Endpoint, payload template.Template, authData string,
+) (*http.Request, error) {
 	logger := zerolog.Ctx(ctx.AppContext())
 
 	var body io.Reader
@@ -255,8 +376,8 @@ func (a *genericAuthenticator) createRequest(ctx heimdall.Context, authData stri
 		"AuthenticationData": authData,
 	}
 
-	if a.payload != nil {
-		value, err := a.payload.Render(templateData)
+	if payload != nil {
+		value, err := payload.Render(templateData)
 		if err != nil {
 			return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
 				"failed to render payload for the authenticator endpoint").
@@ -266,7 +387,7 @@ func (a *genericAuthenticator) createRequest(ctx heimdall.Context, authData stri
 		body = strings.NewReader(value)
 	}
 
-	req, err := a.e.CreateRequest(ctx.AppContext(), body,
+	req, err := ep.CreateRequest(ctx.AppContext(), body,
 		endpoint.RenderFunc(func(value string) (string, error) {
 			tpl, err := template.New(value)
 			if err != nil {
@@ -337,6 +458,13 @@ func (a *genericAuthenticator) getCacheTTL(sessionLifespan *SessionLifespan) tim
 	// (if this information is available)
 	if sessionLifespan != nil && !sessionLifespan.exp.Equal(time.Time{}) {
 		expiresIn := sessionLifespan.exp.Unix() - time.Now().Unix() - timeLeeway
+
+		// if the session can be refreshed, the cached subject information must not be used
+		// once the session is about to expire. Otherwise, the refresh would never happen.
+		if a.sessionRefresh != nil {
+			expiresIn -= int64(a.sessionRefresh.RefreshBefore.Seconds())
+		}
+
 		expirationTTL := x.IfThenElse(expiresIn > 0, time.Duration(expiresIn)*time.Second, 0)
 
 		return min(a.ttl, expirationTTL)
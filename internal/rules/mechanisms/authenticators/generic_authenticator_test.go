@@ -29,6 +29,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/cache/mocks"
@@ -299,9 +300,135 @@ session_lifespan:
 				assert.Equal(t, "zab", auth.sessionLifespanConf.NotAfterField)
 				assert.Equal(t, "foo bar", auth.sessionLifespanConf.TimeFormat)
 				assert.Equal(t, 2*time.Second, auth.sessionLifespanConf.ValidityLeeway)
+				assert.Nil(t, auth.sessionRefresh)
 				assert.Equal(t, "auth1", auth.ID())
 			},
 		},
+		{
+			uc: "with session refresh config, but without session lifespan config",
+			id: "auth1",
+			config: []byte(`
+identity_info_endpoint:
+  url: http://test.com
+authentication_data_source:
+  - cookie: foo-cookie
+subject:
+  id: some_template
+session_refresh:
+  endpoint:
+    url: http://test.com/refresh`),
+			assertError: func(t *testing.T, err error, _ *genericAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires session_lifespan with not_after")
+			},
+		},
+		{
+			uc: "with session refresh config, but without not_after in session lifespan config",
+			id: "auth1",
+			config: []byte(`
+identity_info_endpoint:
+  url: http://test.com
+authentication_data_source:
+  - cookie: foo-cookie
+subject:
+  id: some_template
+session_lifespan:
+  active: foo
+session_refresh:
+  endpoint:
+    url: http://test.com/refresh`),
+			assertError: func(t *testing.T, err error, _ *genericAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires session_lifespan with not_after")
+			},
+		},
+		{
+			uc: "with session refresh config without endpoint",
+			id: "auth1",
+			config: []byte(`
+identity_info_endpoint:
+  url: http://test.com
+authentication_data_source:
+  - cookie: foo-cookie
+subject:
+  id: some_template
+session_lifespan:
+  not_after: exp
+session_refresh:
+  refresh_before: 5m`),
+			assertError: func(t *testing.T, err error, _ *genericAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'session_refresh'.'endpoint'")
+			},
+		},
+		{
+			uc: "with session refresh config using defaults",
+			id: "auth1",
+			config: []byte(`
+identity_info_endpoint:
+  url: http://test.com
+authentication_data_source:
+  - cookie: foo-cookie
+forward_cookies:
+  - foo-cookie
+subject:
+  id: some_template
+session_lifespan:
+  not_after: exp
+session_refresh:
+  endpoint:
+    url: http://test.com/refresh
+    method: POST`),
+			assertError: func(t *testing.T, err error, auth *genericAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, auth.sessionRefresh)
+				assert.Equal(t, "http://test.com/refresh", auth.sessionRefresh.Endpoint.URL)
+				assert.Equal(t, http.MethodPost, auth.sessionRefresh.Endpoint.Method)
+				assert.Nil(t, auth.sessionRefresh.Payload)
+				assert.Equal(t, defaultSessionRefreshThreshold, auth.sessionRefresh.RefreshBefore)
+				assert.NotNil(t, auth.group)
+			},
+		},
+		{
+			uc: "with full session refresh config",
+			id: "auth1",
+			config: []byte(`
+identity_info_endpoint:
+  url: http://test.com
+authentication_data_source:
+  - cookie: foo-cookie
+subject:
+  id: some_template
+session_lifespan:
+  not_after: exp
+session_refresh:
+  endpoint:
+    url: http://test.com/refresh
+  payload: "token={{ .AuthenticationData }}"
+  refresh_before: 5m`),
+			assertError: func(t *testing.T, err error, auth *genericAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, auth.sessionRefresh)
+				assert.Equal(t, "http://test.com/refresh", auth.sessionRefresh.Endpoint.URL)
+				assert.NotNil(t, auth.sessionRefresh.Payload)
+				assert.Equal(t, 5*time.Minute, auth.sessionRefresh.RefreshBefore)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			conf, err := testsupport.DecodeTestConfig(tc.config)
@@ -536,6 +663,36 @@ session_lifespan:
 				assert.Equal(t, "auth2", configured.ID())
 			},
 		},
+		{
+			uc: "prototype with session refresh config and target config",
+			id: "auth2",
+			prototypeConfig: []byte(`
+identity_info_endpoint:
+  url: http://test.com
+authentication_data_source:
+  - header: foo-header
+subject:
+  id: some_template
+session_lifespan:
+  not_after: exp
+session_refresh:
+  endpoint:
+    url: http://test.com/refresh`),
+			config: []byte(`cache_ttl: 5s`),
+			assert: func(t *testing.T, err error, prototype *genericAuthenticator,
+				configured *genericAuthenticator,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, 5*time.Second, configured.ttl)
+				assert.Equal(t, prototype.sessionLifespanConf, configured.sessionLifespanConf)
+				assert.Equal(t, prototype.sessionRefresh, configured.sessionRefresh)
+				assert.Same(t, prototype.group, configured.group)
+			},
+		},
 		{
 			uc: "reconfiguration of identity_info_endpoint not possible",
 			prototypeConfig: []byte(`
@@ -771,6 +928,36 @@ func TestGenericAuthenticatorExecute(t *testing.T) {
 	}))
 	defer srv.Close()
 
+	var (
+		refreshEndpointCalled  bool
+		checkRefreshRequest    func(req *http.Request)
+		refreshResponseCookies []*http.Cookie
+		refreshResponseContent []byte
+		refreshResponseCode    int
+	)
+
+	refreshSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshEndpointCalled = true
+
+		checkRefreshRequest(r)
+
+		for _, cookie := range refreshResponseCookies {
+			http.SetCookie(w, cookie)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(refreshResponseCode)
+
+		_, err := w.Write(refreshResponseContent)
+		require.NoError(t, err)
+	}))
+	defer refreshSrv.Close()
+
+	sessionPayload := func(validFor time.Duration, attributes string) []byte {
+		return []byte(`{ "user_id": "barbar", "exp": ` +
+			strconv.FormatInt(time.Now().Add(validFor).Unix(), 10) + attributes + ` }`)
+	}
+
 	for _, tc := range []struct {
 		uc             string
 		authenticator  *genericAuthenticator
@@ -1335,6 +1522,216 @@ func TestGenericAuthenticatorExecute(t *testing.T) {
 				assert.Len(t, sub.Attributes, 2)
 			},
 		},
+		{
+			uc: "execution with session about to expire, which is successfully refreshed",
+			authenticator: &genericAuthenticator{
+				id:                  "auth3",
+				e:                   endpoint.Endpoint{URL: srv.URL, Method: http.MethodGet},
+				sf:                  &SubjectInfo{IDFrom: "user_id"},
+				fwdCookies:          []string{"session"},
+				sessionLifespanConf: &SessionLifespanConfig{NotAfterField: "exp"},
+				sessionRefresh: &SessionRefreshConfig{
+					Endpoint:      endpoint.Endpoint{URL: refreshSrv.URL, Method: http.MethodPost},
+					RefreshBefore: time.Minute,
+				},
+				group: &singleflight.Group{},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *genericAuthenticator,
+			) {
+				t.Helper()
+
+				reqFuns := heimdallmocks.NewRequestFunctionsMock(t)
+				reqFuns.EXPECT().Cookie("session").Return("old-session")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqFuns})
+				ctx.EXPECT().AddCookieForUpstream("session", "new-session")
+				ctx.EXPECT().AddCookieForClient(mock.MatchedBy(func(cookie *http.Cookie) bool {
+					return cookie.Name == "session" && cookie.Value == "new-session" &&
+						cookie.Path == "/" && cookie.HttpOnly
+				}))
+
+				ads.EXPECT().GetAuthData(ctx).Return("old-session", nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseCode = http.StatusOK
+				responseContent = sessionPayload(30*time.Second, "")
+				responseContentType = "application/json"
+
+				checkRefreshRequest = func(req *http.Request) {
+					t.Helper()
+
+					assert.Equal(t, http.MethodPost, req.Method)
+
+					cookie, err := req.Cookie("session")
+					require.NoError(t, err)
+					assert.Equal(t, "old-session", cookie.Value)
+				}
+
+				refreshResponseCode = http.StatusOK
+				refreshResponseContent = sessionPayload(time.Hour, `, "refreshed": true`)
+				refreshResponseCookies = []*http.Cookie{
+					{Name: "session", Value: "new-session", Path: "/", HttpOnly: true},
+				}
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+				assert.True(t, refreshEndpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, "barbar", sub.ID)
+				assert.Equal(t, true, sub.Attributes["refreshed"])
+			},
+		},
+		{
+			uc: "execution with session about to expire, which could not be refreshed",
+			authenticator: &genericAuthenticator{
+				id:                  "auth3",
+				e:                   endpoint.Endpoint{URL: srv.URL, Method: http.MethodGet},
+				sf:                  &SubjectInfo{IDFrom: "user_id"},
+				sessionLifespanConf: &SessionLifespanConfig{NotAfterField: "exp"},
+				sessionRefresh: &SessionRefreshConfig{
+					Endpoint: endpoint.Endpoint{
+						URL:     refreshSrv.URL + "?token={{ .AuthenticationData }}",
+						Method:  http.MethodPost,
+						Headers: map[string]string{"Accept": "application/json"},
+					},
+					RefreshBefore: time.Minute,
+				},
+				group: &singleflight.Group{},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *genericAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("session_token", nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseCode = http.StatusOK
+				responseContent = sessionPayload(30*time.Second, "")
+				responseContentType = "application/json"
+
+				checkRefreshRequest = func(req *http.Request) {
+					t.Helper()
+
+					assert.Equal(t, "session_token", req.URL.Query().Get("token"))
+					assert.Equal(t, "application/json", req.Header.Get("Accept"))
+				}
+
+				refreshResponseCode = http.StatusInternalServerError
+				refreshResponseCookies = []*http.Cookie{{Name: "session", Value: "new-session"}}
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+				assert.True(t, refreshEndpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, "barbar", sub.ID)
+				assert.NotContains(t, sub.Attributes, "refreshed")
+			},
+		},
+		{
+			uc: "execution with session not about to expire",
+			authenticator: &genericAuthenticator{
+				id:                  "auth3",
+				e:                   endpoint.Endpoint{URL: srv.URL, Method: http.MethodGet},
+				sf:                  &SubjectInfo{IDFrom: "user_id"},
+				sessionLifespanConf: &SessionLifespanConfig{NotAfterField: "exp"},
+				sessionRefresh: &SessionRefreshConfig{
+					Endpoint:      endpoint.Endpoint{URL: refreshSrv.URL},
+					RefreshBefore: time.Minute,
+				},
+				group: &singleflight.Group{},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *genericAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("session_token", nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseCode = http.StatusOK
+				responseContent = sessionPayload(time.Hour, "")
+				responseContentType = "application/json"
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+				assert.False(t, refreshEndpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, "barbar", sub.ID)
+			},
+		},
+		{
+			uc: "execution with already expired session, which is not refreshed",
+			authenticator: &genericAuthenticator{
+				id:                  "auth3",
+				e:                   endpoint.Endpoint{URL: srv.URL, Method: http.MethodGet},
+				sf:                  &SubjectInfo{IDFrom: "user_id"},
+				sessionLifespanConf: &SessionLifespanConfig{NotAfterField: "exp"},
+				sessionRefresh: &SessionRefreshConfig{
+					Endpoint:      endpoint.Endpoint{URL: refreshSrv.URL},
+					RefreshBefore: time.Minute,
+				},
+				group: &singleflight.Group{},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *genericAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("session_token", nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseCode = http.StatusOK
+				responseContent = sessionPayload(-time.Minute, "")
+				responseContentType = "application/json"
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+				assert.False(t, refreshEndpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "expired")
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -1345,6 +1742,13 @@ func TestGenericAuthenticatorExecute(t *testing.T) {
 
 			checkRequest = func(*http.Request) { t.Helper() }
 
+			refreshEndpointCalled = false
+			refreshResponseCookies = nil
+			refreshResponseContent = nil
+			refreshResponseCode = http.StatusOK
+
+			checkRefreshRequest = func(*http.Request) { t.Helper() }
+
 			instructServer := x.IfThenElse(tc.instructServer != nil,
 				tc.instructServer,
 				func(t *testing.T) { t.Helper() })
@@ -1379,6 +1783,61 @@ func TestGenericAuthenticatorExecute(t *testing.T) {
 	}
 }
 
+func TestGenericAuthenticatorRefreshSessionWithCanceledCaller(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var calls int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+
+		select {
+		case <-req.Context().Done():
+			return
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+
+		_, err := rw.Write([]byte(`{ "user_id": "barbar", "exp": ` +
+			strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10) + ` }`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	auth := &genericAuthenticator{
+		id:                  "auth3",
+		sessionLifespanConf: &SessionLifespanConfig{NotAfterField: "exp"},
+		sessionRefresh: &SessionRefreshConfig{
+			Endpoint:      endpoint.Endpoint{URL: srv.URL, Method: http.MethodPost},
+			RefreshBefore: time.Minute,
+		},
+		group: &singleflight.Group{},
+	}
+
+	canceledCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	ctx1 := heimdallmocks.NewContextMock(t)
+	ctx1.EXPECT().AppContext().Return(canceledCtx)
+
+	ctx2 := heimdallmocks.NewContextMock(t)
+	ctx2.EXPECT().AppContext().Return(context.Background())
+
+	// WHEN
+	_, _, err1 := auth.refreshSession(ctx1, "session_token")
+	payload, session, err2 := auth.refreshSession(ctx2, "session_token")
+
+	// THEN
+	require.ErrorIs(t, err1, context.DeadlineExceeded)
+	require.NoError(t, err2)
+	require.NotNil(t, session)
+	assert.Contains(t, string(payload), "barbar")
+	assert.Equal(t, 1, calls)
+}
+
 func TestGenericAuthenticatorGetCacheTTL(t *testing.T) {
 	t.Parallel()
 
@@ -1438,6 +1897,20 @@ func TestGenericAuthenticatorGetCacheTTL(t *testing.T) {
 				assert.Equal(t, 20*time.Second, ttl) // leeway of 10 sec considered
 			},
 		},
+		{
+			uc: "cache enabled, session lifespan available with not_after set to a date so that the configured ttl " +
+				"would exceed the time left until the session is refreshed",
+			authenticator: &genericAuthenticator{
+				ttl:            10 * time.Minute,
+				sessionRefresh: &SessionRefreshConfig{RefreshBefore: time.Minute},
+			},
+			sessionLifespan: &SessionLifespan{exp: time.Now().Add(5 * time.Minute)},
+			assert: func(t *testing.T, ttl time.Duration) {
+				t.Helper()
+
+				assert.Equal(t, 3*time.Minute+50*time.Second, ttl) // leeway of 10 sec considered
+			},
+		},
 		{
 			uc:              "cache enabled, session lifespan available with not_after set to a date which disables ttl",
 			authenticator:   &genericAuthenticator{ttl: 5 * time.Minute},
@@ -0,0 +1,141 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/hashx"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// htpasswdFile holds the users and their password hashes loaded from a file in the htpasswd
+// format, which is one <user>:<hash> entry per line. The file is reloaded on changes.
+type htpasswdFile struct {
+	path string
+
+	users map[string]hashx.Hash
+	// dummy is used to verify passwords of unknown users. That way, the time required to
+	// verify the credentials does not reveal whether a user exists.
+	dummy hashx.Hash
+	mut   sync.RWMutex
+}
+
+func newHtpasswdFile(path string) (*htpasswdFile, error) {
+	file := &htpasswdFile{path: path}
+
+	if err := file.load(); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (f *htpasswdFile) load() error {
+	raw, err := os.ReadFile(f.path)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed reading htpasswd file").CausedBy(err)
+	}
+
+	var (
+		users   = make(map[string]hashx.Hash)
+		dummy   hashx.Hash
+		lineNum int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		user, encoded, found := strings.Cut(line, ":")
+		if !found || len(user) == 0 {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"malformed entry in line %d of the htpasswd file", lineNum)
+		}
+
+		if _, exists := users[user]; exists {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"user '%s' is defined multiple times in the htpasswd file", user)
+		}
+
+		hash, err := hashx.Parse(encoded)
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed parsing password hash of user '%s' from the htpasswd file", user).CausedBy(err)
+		}
+
+		if dummy == nil {
+			dummy = hash
+		}
+
+		users[user] = hash
+	}
+
+	if err = scanner.Err(); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed reading htpasswd file").CausedBy(err)
+	}
+
+	f.mut.Lock()
+	f.users = users
+	f.dummy = dummy
+	f.mut.Unlock()
+
+	return nil
+}
+
+func (f *htpasswdFile) verify(userID, password string) bool {
+	f.mut.RLock()
+	hash, known := f.users[userID]
+	dummy := f.dummy
+	f.mut.RUnlock()
+
+	if !known {
+		if dummy != nil {
+			dummy.Matches(stringx.ToBytes(password))
+		}
+
+		return false
+	}
+
+	return hash.Matches(stringx.ToBytes(password))
+}
+
+func (f *htpasswdFile) OnChanged(log zerolog.Logger) {
+	err := f.load()
+	if err != nil {
+		log.Warn().Err(err).
+			Str("_file", f.path).
+			Msg("htpasswd file reload failed")
+	} else {
+		log.Info().
+			Str("_file", f.path).
+			Msg("htpasswd file reloaded")
+	}
+}
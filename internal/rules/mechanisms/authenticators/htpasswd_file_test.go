@@ -0,0 +1,168 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func TestNewHtpasswdFile(t *testing.T) {
+	t.Parallel()
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("foo-pass"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	sha1Value := sha1.Sum([]byte("bar-pass")) //nolint:gosec
+	sha1Hash := "{SHA}" + base64.StdEncoding.EncodeToString(sha1Value[:])
+
+	salt := []byte("0123456789abcdef")
+	argon2Hash := fmt.Sprintf("$argon2id$v=%d$m=1024,t=1,p=1$%s$%s", argon2.Version,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(argon2.IDKey([]byte("baz-pass"), salt, 1, 1024, 1, 32)))
+
+	for _, tc := range []struct {
+		uc       string
+		contents string
+		assert   func(t *testing.T, err error, file *htpasswdFile)
+	}{
+		{
+			uc:       "malformed entry",
+			contents: "foo\n",
+			assert: func(t *testing.T, err error, _ *htpasswdFile) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "line 1")
+			},
+		},
+		{
+			uc:       "unsupported hash",
+			contents: "# comment\nfoo:$apr1$foo$bar\n",
+			assert: func(t *testing.T, err error, _ *htpasswdFile) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'foo'")
+			},
+		},
+		{
+			uc:       "duplicate user",
+			contents: "foo:" + sha1Hash + "\nfoo:" + sha1Hash + "\n",
+			assert: func(t *testing.T, err error, _ *htpasswdFile) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "multiple times")
+			},
+		},
+		{
+			uc:       "empty file",
+			contents: "",
+			assert: func(t *testing.T, err error, file *htpasswdFile) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, file.users)
+				assert.False(t, file.verify("foo", "foo-pass"))
+			},
+		},
+		{
+			uc: "entries with different hash types",
+			contents: "# users\n\nfoo:" + string(bcryptHash) + "\n" +
+				"bar:" + sha1Hash + "\n" +
+				"baz:" + argon2Hash + "\n",
+			assert: func(t *testing.T, err error, file *htpasswdFile) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Len(t, file.users, 3)
+				assert.True(t, file.verify("foo", "foo-pass"))
+				assert.True(t, file.verify("bar", "bar-pass"))
+				assert.True(t, file.verify("baz", "baz-pass"))
+				assert.False(t, file.verify("foo", "bar-pass"))
+				assert.False(t, file.verify("qux", "foo-pass"))
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			path := filepath.Join(t.TempDir(), ".htpasswd")
+			err := os.WriteFile(path, []byte(tc.contents), 0o600)
+			require.NoError(t, err)
+
+			// WHEN
+			file, err := newHtpasswdFile(path)
+
+			// THEN
+			tc.assert(t, err, file)
+		})
+	}
+}
+
+func TestHtpasswdFileReload(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	hash := func(password string) string {
+		value := sha1.Sum([]byte(password)) //nolint:gosec
+
+		return "{SHA}" + base64.StdEncoding.EncodeToString(value[:])
+	}
+
+	path := filepath.Join(t.TempDir(), ".htpasswd")
+	err := os.WriteFile(path, []byte("foo:"+hash("foo-pass")+"\n"), 0o600)
+	require.NoError(t, err)
+
+	file, err := newHtpasswdFile(path)
+	require.NoError(t, err)
+	require.True(t, file.verify("foo", "foo-pass"))
+
+	// WHEN
+	err = os.WriteFile(path, []byte("bar:"+hash("bar-pass")+"\n"), 0o600)
+	require.NoError(t, err)
+
+	file.OnChanged(log.Logger)
+
+	// THEN
+	assert.False(t, file.verify("foo", "foo-pass"))
+	assert.True(t, file.verify("bar", "bar-pass"))
+
+	// WHEN
+	err = os.WriteFile(path, []byte("foo"), 0o600)
+	require.NoError(t, err)
+
+	file.OnChanged(log.Logger)
+
+	// THEN the previous state is kept
+	assert.True(t, file.verify("bar", "bar-pass"))
+}
@@ -0,0 +1,519 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/httpsig"
+)
+
+const (
+	defaultHTTPSignatureMaxAge = 5 * time.Minute
+	// httpSignatureClockSkew defines the time deviation accepted for the created parameter of a signature.
+	httpSignatureClockSkew = 10 * time.Second
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(ctx CreationContext, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorHTTPSignature {
+				return false, nil, nil
+			}
+
+			auth, err := newHTTPSignatureAuthenticator(ctx, id, conf)
+
+			return true, auth, err
+		})
+}
+
+type httpSignatureAuthenticator struct {
+	id                   string
+	ep                   *endpoint.Endpoint
+	store                *publicKeyStore
+	ttl                  *time.Duration
+	components           []httpsig.Component
+	label                string
+	maxAge               time.Duration
+	sf                   SubjectFactory
+	allowFallbackOnError bool
+}
+
+func newHTTPSignatureAuthenticator(
+	ctx CreationContext, id string, rawConfig map[string]any,
+) (*httpSignatureAuthenticator, error) {
+	type KeyStore struct {
+		Path string `mapstructure:"path" validate:"required"`
+	}
+
+	type Config struct {
+		JWKSEndpoint         *endpoint.Endpoint `mapstructure:"jwks_endpoint"           validate:"required_without=KeyStore,excluded_with=KeyStore"`         //nolint:lll,tagalign
+		KeyStore             *KeyStore          `mapstructure:"key_store"               validate:"required_without=JWKSEndpoint,excluded_with=JWKSEndpoint"` //nolint:lll,tagalign
+		Components           []string           `mapstructure:"components"`
+		SignatureLabel       string             `mapstructure:"signature_label"`
+		MaxAge               *time.Duration     `mapstructure:"max_age"`
+		CacheTTL             *time.Duration     `mapstructure:"cache_ttl"`
+		SubjectInfo          SubjectInfo        `mapstructure:"subject"                 validate:"-"`
+		AllowFallbackOnError bool               `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorHTTPSignature, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	components, err := httpsig.ParseComponents(conf.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = "key_id"
+	}
+
+	auth := &httpSignatureAuthenticator{
+		id:         id,
+		ttl:        conf.CacheTTL,
+		components: components,
+		label:      conf.SignatureLabel,
+		maxAge: x.IfThenElseExec(conf.MaxAge != nil,
+			func() time.Duration { return *conf.MaxAge },
+			func() time.Duration { return defaultHTTPSignatureMaxAge }),
+		sf:                   &conf.SubjectInfo,
+		allowFallbackOnError: conf.AllowFallbackOnError,
+	}
+
+	if conf.JWKSEndpoint != nil {
+		auth.ep = conf.JWKSEndpoint
+
+		if auth.ep.Headers == nil {
+			auth.ep.Headers = make(map[string]string)
+		}
+
+		if _, ok := auth.ep.Headers["Accept"]; !ok {
+			auth.ep.Headers["Accept"] = "application/json"
+		}
+
+		if len(auth.ep.Method) == 0 {
+			auth.ep.Method = http.MethodGet
+		}
+
+		return auth, nil
+	}
+
+	if auth.store, err = newPublicKeyStore(conf.KeyStore.Path); err != nil {
+		return nil, err
+	}
+
+	if cw := ctx.Watcher(); cw != nil {
+		if err = cw.Add(auth.store.path, auth.store); err != nil {
+			return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+				"failed registering public key store for updates").CausedBy(err)
+		}
+	}
+
+	return auth, nil
+}
+
+func (a *httpSignatureAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using http_signature authenticator")
+
+	req := ctx.Request()
+
+	sig, err := a.selectSignature(
+		req.Header(httpsig.HeaderSignatureInput),
+		req.Header(httpsig.HeaderSignature),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err = a.verifyTimestamps(sig, now); err != nil {
+		return nil, err
+	}
+
+	key, alg, err := a.resolveKey(ctx, sig.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = sig.Verify(httpsig.NewRequestMessage(req), alg, key); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to verify http message signature").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	// the signature covers only the digest. So it must be verified the digest matches the body
+	if sig.Params.Covers(httpsig.Component{Name: httpsig.ComponentContentDigest}) {
+		if err = httpsig.VerifyContentDigest(req.HeaderValues(httpsig.HeaderContentDigest), req.RawBody()); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "failed to verify the content digest").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+	}
+
+	if err = a.preventReplay(ctx, sig, now); err != nil {
+		return nil, err
+	}
+
+	return a.createSubject(sig, alg)
+}
+
+func (a *httpSignatureAuthenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	// this authenticator allows the covered components, the subject and the fallback
+	// setting to be redefined on the rule level
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Components           []string     `mapstructure:"components"`
+		SubjectInfo          *SubjectInfo `mapstructure:"subject"`
+		AllowFallbackOnError *bool        `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorHTTPSignature, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	components := a.components
+
+	if len(conf.Components) != 0 {
+		var err error
+
+		if components, err = httpsig.ParseComponents(conf.Components); err != nil {
+			return nil, err
+		}
+	}
+
+	return &httpSignatureAuthenticator{
+		id:         a.id,
+		ep:         a.ep,
+		store:      a.store,
+		ttl:        a.ttl,
+		components: components,
+		label:      a.label,
+		maxAge:     a.maxAge,
+		sf: x.IfThenElseExec(conf.SubjectInfo != nil,
+			func() SubjectFactory { return conf.SubjectInfo },
+			func() SubjectFactory { return a.sf }),
+		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+			func() bool { return *conf.AllowFallbackOnError },
+			func() bool { return a.allowFallbackOnError }),
+	}, nil
+}
+
+func (a *httpSignatureAuthenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *httpSignatureAuthenticator) ID() string {
+	return a.id
+}
+
+func (a *httpSignatureAuthenticator) selectSignature(input, value string) (*httpsig.Signature, error) {
+	if len(input) == 0 || len(value) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no http message signature present").
+			WithErrorContext(a)
+	}
+
+	signatures, err := httpsig.ParseSignatures(input, value)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to parse http message signature").
+			WithErrorContext(a).
+			CausedBy(heimdall.ErrArgument).
+			CausedBy(err)
+	}
+
+	for _, sig := range signatures {
+		if len(a.label) != 0 && sig.Label != a.label {
+			continue
+		}
+
+		if comp, covered := a.coversRequiredComponents(sig); !covered {
+			if len(a.label) == 0 {
+				// without a configured label any of the present signatures may qualify
+				continue
+			}
+
+			return nil, errorchain.
+				NewWithMessagef(heimdall.ErrAuthentication,
+					"http message signature '%s' does not cover the required component %s", sig.Label, comp).
+				WithErrorContext(a)
+		}
+
+		return sig, nil
+	}
+
+	if len(a.label) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication,
+				"no http message signature covering the required components present").
+			WithErrorContext(a)
+	}
+
+	return nil, errorchain.
+		NewWithMessagef(heimdall.ErrAuthentication, "no http message signature with label '%s' present", a.label).
+		WithErrorContext(a)
+}
+
+func (a *httpSignatureAuthenticator) coversRequiredComponents(sig *httpsig.Signature) (httpsig.Component, bool) {
+	for _, comp := range a.components {
+		if !sig.Params.Covers(comp) {
+			return comp, false
+		}
+	}
+
+	return httpsig.Component{}, true
+}
+
+func (a *httpSignatureAuthenticator) verifyTimestamps(sig *httpsig.Signature, now time.Time) error {
+	created := sig.Params.Created
+	expires := sig.Params.Expires
+
+	switch {
+	case created.IsZero():
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "http message signature has no created parameter").
+			WithErrorContext(a)
+	case created.After(now.Add(httpSignatureClockSkew)):
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "http message signature is created in the future").
+			WithErrorContext(a)
+	case now.Sub(created) > a.maxAge:
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "http message signature is too old").
+			WithErrorContext(a)
+	case !expires.IsZero() && !now.Before(expires):
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "http message signature is expired").
+			WithErrorContext(a)
+	}
+
+	return nil
+}
+
+func (a *httpSignatureAuthenticator) resolveKey(
+	ctx heimdall.Context, params httpsig.SignatureParams,
+) (any, string, error) {
+	keyID := params.KeyID
+	if len(keyID) == 0 {
+		return nil, "", errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "http message signature does not reference a key").
+			WithErrorContext(a)
+	}
+
+	var (
+		key    any
+		jwsAlg string
+	)
+
+	if a.store != nil {
+		var ok bool
+
+		key, ok = a.store.key(keyID)
+		if !ok {
+			return nil, "", errorchain.
+				NewWithMessagef(heimdall.ErrAuthentication, "no key found for keyid=%s", keyID).
+				WithErrorContext(a)
+		}
+	} else {
+		jwk, err := a.getKey(ctx, keyID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		key, jwsAlg = jwk.Key, jwk.Algorithm
+	}
+
+	alg, err := signatureAlgorithm(key, jwsAlg, params.Algorithm)
+	if err != nil {
+		return nil, "", errorchain.
+			NewWithMessagef(heimdall.ErrAuthentication, "key for keyid=%s cannot be used", keyID).
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return key, alg, nil
+}
+
+// signatureAlgorithm returns the algorithm to verify the signature with. If the key does not
+// determine it, like an RSA key without a JWS alg, the alg parameter of the signature is
+// used as long as it fits the key type.
+func signatureAlgorithm(key any, jwsAlg, sigAlg string) (string, error) {
+	alg, err := httpsig.AlgorithmFor(key, jwsAlg)
+	if err == nil || len(jwsAlg) != 0 || len(sigAlg) == 0 {
+		return alg, err
+	}
+
+	if !slices.Contains(httpsig.KeyAlgorithms(key), sigAlg) {
+		return "", errorchain.NewWithMessagef(httpsig.ErrUnsupportedAlgorithm,
+			"signature algorithm '%s' cannot be used with the key", sigAlg)
+	}
+
+	return sigAlg, nil
+}
+
+func (a *httpSignatureAuthenticator) getKey(ctx heimdall.Context, keyID string) (*jose.JSONWebKey, error) {
+	cch := cache.Ctx(ctx.AppContext())
+	logger := zerolog.Ctx(ctx.AppContext())
+
+	req, err := a.ep.CreateRequest(ctx.AppContext(), nil, nil)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed creating request").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	cacheKey := jwkCacheKey(a.ep, req.URL.String(), keyID)
+	cacheTTL := x.IfThenElseExec(a.ttl != nil,
+		func() time.Duration { return *a.ttl },
+		func() time.Duration { return defaultJWTAuthenticatorTTL })
+
+	if cacheTTL > 0 {
+		if entry, err := cch.Get(ctx.AppContext(), cacheKey); err == nil {
+			var jwk jose.JSONWebKey
+
+			if err = json.Unmarshal(entry, &jwk); err == nil {
+				logger.Debug().Msg("Reusing JWK from cache")
+
+				return &jwk, nil
+			}
+		}
+	}
+
+	jwks, err := fetchJWKS(ctx.AppContext(), a.ep.CreateClient(req.URL.Hostname()), req, a)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := jwks.Key(keyID)
+	if len(keys) != 1 {
+		return nil, errorchain.
+			NewWithMessagef(heimdall.ErrAuthentication, "no (unique) key found for keyid=%s", keyID).
+			WithErrorContext(a)
+	}
+
+	jwk := &keys[0]
+
+	if cacheTTL > 0 {
+		data, _ := json.Marshal(jwk)
+
+		if err = cch.Set(ctx.AppContext(), cacheKey, data, cacheTTL); err != nil {
+			logger.Warn().Err(err).Msg("Failed to cache JWK")
+		}
+	}
+
+	return jwk, nil
+}
+
+// preventReplay makes sure a signature is accepted only once within its validity period.
+func (a *httpSignatureAuthenticator) preventReplay(ctx heimdall.Context, sig *httpsig.Signature, now time.Time) error {
+	cch := cache.Ctx(ctx.AppContext())
+	digest := sha256.Sum256(sig.Value)
+	cacheKey := "http_signature:" + hex.EncodeToString(digest[:])
+
+	validUntil := sig.Params.Created.Add(a.maxAge)
+	if !sig.Params.Expires.IsZero() && sig.Params.Expires.Before(validUntil) {
+		validUntil = sig.Params.Expires
+	}
+
+	stored, err := cch.SetIfAbsent(ctx.AppContext(), cacheKey, []byte{1}, validUntil.Sub(now)+httpSignatureClockSkew)
+	if err != nil {
+		zerolog.Ctx(ctx.AppContext()).Warn().Err(err).Msg("Failed to cache http message signature")
+
+		return nil
+	}
+
+	if !stored {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "http message signature has already been used").
+			WithErrorContext(a)
+	}
+
+	return nil
+}
+
+func (a *httpSignatureAuthenticator) createSubject(sig *httpsig.Signature, alg string) (*subject.Subject, error) {
+	components := make([]string, len(sig.Params.Components))
+	for idx, comp := range sig.Params.Components {
+		components[idx] = comp.String()
+	}
+
+	data := map[string]any{
+		"key_id":     sig.Params.KeyID,
+		"algorithm":  alg,
+		"label":      sig.Label,
+		"created":    sig.Params.Created.Unix(),
+		"components": components,
+	}
+
+	if !sig.Params.Expires.IsZero() {
+		data["expires"] = sig.Params.Expires.Unix()
+	}
+
+	if len(sig.Params.Nonce) != 0 {
+		data["nonce"] = sig.Params.Nonce
+	}
+
+	if len(sig.Params.Tag) != 0 {
+		data["tag"] = sig.Params.Tag
+	}
+
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to marshal signature data").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	sub, err := a.sf.CreateSubject(rawData)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to extract subject information from signature").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return sub, nil
+}
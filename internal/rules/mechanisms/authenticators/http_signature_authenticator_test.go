@@ -0,0 +1,995 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	mocks2 "github.com/dadrus/heimdall/internal/watcher/mocks"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/httpsig"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func writePublicKeyStore(t *testing.T, path string, keys map[string]any) {
+	t.Helper()
+
+	var contents []byte
+
+	for kid, key := range keys {
+		raw, err := x509.MarshalPKIXPublicKey(key)
+		require.NoError(t, err)
+
+		contents = append(contents, pem.EncodeToMemory(&pem.Block{
+			Type:    "PUBLIC KEY",
+			Headers: map[string]string{"X-Key-ID": kid},
+			Bytes:   raw,
+		})...)
+	}
+
+	err := os.WriteFile(path, contents, 0o600)
+	require.NoError(t, err)
+}
+
+type testSignedMessage struct {
+	method string
+	url    *url.URL
+	header http.Header
+}
+
+func (m *testSignedMessage) Method() string              { return m.method }
+func (m *testSignedMessage) URL() *url.URL               { return m.url }
+func (m *testSignedMessage) Header(name string) []string { return m.header.Values(name) }
+
+func TestCreateHTTPSignatureAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testDir := t.TempDir()
+
+	keyStore := filepath.Join(testDir, "keys.pem")
+	writePublicKeyStore(t, keyStore, map[string]any{"foo": pub})
+
+	emptyKeyStore := filepath.Join(testDir, "empty.pem")
+	err = os.WriteFile(emptyKeyStore, []byte("foo"), 0o600)
+	require.NoError(t, err)
+
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	unusableKeyStore := filepath.Join(testDir, "unusable.pem")
+	writePublicKeyStore(t, unusableKeyStore, map[string]any{"foo": pub, "bar": &p521Key.PublicKey})
+
+	for _, tc := range []struct {
+		uc        string
+		config    []byte
+		configure func(t *testing.T, ctx *CreationContextMock)
+		assert    func(t *testing.T, err error, auth *httpSignatureAuthenticator)
+	}{
+		{
+			uc:     "without key source",
+			config: []byte(`max_age: 1m`),
+			assert: func(t *testing.T, err error, _ *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "jwks_endpoint")
+			},
+		},
+		{
+			uc: "with jwks endpoint and key store",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+key_store:
+  path: ` + keyStore),
+			assert: func(t *testing.T, err error, _ *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc: "with unsupported properties",
+			config: []byte(`
+key_store:
+  path: ` + keyStore + `
+foo: bar`),
+			assert: func(t *testing.T, err error, _ *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc: "with invalid component",
+			config: []byte(`
+key_store:
+  path: ` + keyStore + `
+components:
+  - "@query-param"`),
+			assert: func(t *testing.T, err error, _ *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "invalid component")
+			},
+		},
+		{
+			uc: "with key store without keys",
+			config: []byte(`
+key_store:
+  path: ` + emptyKeyStore),
+			assert: func(t *testing.T, err error, _ *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "does not contain any keys")
+			},
+		},
+		{
+			uc: "with key store containing a key not usable for http message signatures",
+			config: []byte(`
+key_store:
+  path: ` + unusableKeyStore),
+			assert: func(t *testing.T, err error, _ *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "cannot be used for http message signatures")
+			},
+		},
+		{
+			uc: "with failing registration for updates",
+			config: []byte(`
+key_store:
+  path: ` + keyStore),
+			configure: func(t *testing.T, ctx *CreationContextMock) {
+				t.Helper()
+
+				wm := mocks2.NewWatcherMock(t)
+				wm.EXPECT().Add(keyStore, mock.Anything).Return(errors.New("test error"))
+
+				ctx.EXPECT().Watcher().Return(wm)
+			},
+			assert: func(t *testing.T, err error, _ *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "test error")
+			},
+		},
+		{
+			uc: "with minimal valid configuration using key store",
+			config: []byte(`
+key_store:
+  path: ` + keyStore),
+			configure: func(t *testing.T, ctx *CreationContextMock) {
+				t.Helper()
+
+				wm := mocks2.NewWatcherMock(t)
+				wm.EXPECT().Add(keyStore, mock.Anything).Return(nil)
+
+				ctx.EXPECT().Watcher().Return(wm)
+			},
+			assert: func(t *testing.T, err error, auth *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "auth1", auth.ID())
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+				assert.Nil(t, auth.ep)
+				require.NotNil(t, auth.store)
+				assert.Len(t, auth.store.keys, 1)
+				assert.Equal(t, []httpsig.Component{
+					{Name: httpsig.ComponentMethod},
+					{Name: httpsig.ComponentAuthority},
+					{Name: httpsig.ComponentPath},
+				}, auth.components)
+				assert.Equal(t, defaultHTTPSignatureMaxAge, auth.maxAge)
+				assert.Empty(t, auth.label)
+				assert.Equal(t, &SubjectInfo{IDFrom: "key_id"}, auth.sf)
+			},
+		},
+		{
+			uc: "with full valid configuration using jwks endpoint",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+components:
+  - "@method"
+  - "@target-uri"
+  - content-digest
+  - "@query-param;name=\"foo\""
+signature_label: sig1
+max_age: 1m
+cache_ttl: 5s
+subject:
+  id: tag
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+				assert.Nil(t, auth.store)
+				require.NotNil(t, auth.ep)
+				assert.Equal(t, "http://test.com", auth.ep.URL)
+				assert.Equal(t, http.MethodGet, auth.ep.Method)
+				assert.Equal(t, "application/json", auth.ep.Headers["Accept"])
+				assert.Equal(t, []httpsig.Component{
+					{Name: httpsig.ComponentMethod},
+					{Name: httpsig.ComponentTargetURI},
+					{Name: "content-digest"},
+					{Name: httpsig.ComponentQueryParam, ParamName: "foo"},
+				}, auth.components)
+				assert.Equal(t, "sig1", auth.label)
+				assert.Equal(t, 1*time.Minute, auth.maxAge)
+				assert.Equal(t, 5*time.Second, *auth.ttl)
+				assert.Equal(t, &SubjectInfo{IDFrom: "tag"}, auth.sf)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			configure := x.IfThenElse(tc.configure != nil,
+				tc.configure,
+				func(t *testing.T, ctx *CreationContextMock) {
+					t.Helper()
+
+					ctx.EXPECT().Watcher().Return(nil).Maybe()
+				})
+
+			ctx := NewCreationContextMock(t)
+			configure(t, ctx)
+
+			// WHEN
+			auth, err := newHTTPSignatureAuthenticator(ctx, "auth1", conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateHTTPSignatureAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *httpSignatureAuthenticator,
+			configured *httpSignatureAuthenticator)
+	}{
+		{
+			uc: "without target config",
+			assert: func(t *testing.T, err error, prototype *httpSignatureAuthenticator,
+				configured *httpSignatureAuthenticator,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc: "with components, subject and fallback setting",
+			config: []byte(`
+components: ["@method", "@path", "content-digest"]
+subject:
+  id: nonce
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *httpSignatureAuthenticator,
+				configured *httpSignatureAuthenticator,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.id, configured.id)
+				assert.Equal(t, prototype.store, configured.store)
+				assert.Equal(t, prototype.maxAge, configured.maxAge)
+				assert.Equal(t, []httpsig.Component{
+					{Name: httpsig.ComponentMethod},
+					{Name: httpsig.ComponentPath},
+					{Name: "content-digest"},
+				}, configured.components)
+				assert.Equal(t, &SubjectInfo{IDFrom: "nonce"}, configured.sf)
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc:     "with invalid component",
+			config: []byte(`components: ["@foo;bar=1"]`),
+			assert: func(t *testing.T, err error, _ *httpSignatureAuthenticator, _ *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc:     "with max age redefinition",
+			config: []byte(`max_age: 1h`),
+			assert: func(t *testing.T, err error, _ *httpSignatureAuthenticator, _ *httpSignatureAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype := &httpSignatureAuthenticator{
+				id:         "auth1",
+				store:      &publicKeyStore{path: "/foo/bar.pem"},
+				components: []httpsig.Component{{Name: httpsig.ComponentMethod}},
+				maxAge:     defaultHTTPSignatureMaxAge,
+				sf:         &SubjectInfo{IDFrom: "key_id"},
+			}
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				configured *httpSignatureAuthenticator
+				ok         bool
+			)
+
+			if err == nil {
+				configured, ok = auth.(*httpSignatureAuthenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestHTTPSignatureAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	storeFile := filepath.Join(t.TempDir(), "keys.pem")
+	writePublicKeyStore(t, storeFile, map[string]any{"ed-key": edPub, "rsa-key": &rsaPriv.PublicKey})
+
+	store, err := newPublicKeyStore(storeFile)
+	require.NoError(t, err)
+
+	var jwksRequests int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		jwksRequests++
+
+		data, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: ecPriv.Public(), KeyID: "ec-key", Algorithm: string(jose.ES256), Use: "sig"},
+		}})
+		require.NoError(t, err)
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, err = rw.Write(data)
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	reqURL, err := url.Parse("https://example.com/foo?bar=baz")
+	require.NoError(t, err)
+
+	sign := func(t *testing.T, params httpsig.SignatureParams, alg string, key any) (string, string) {
+		t.Helper()
+
+		msg := &testSignedMessage{method: http.MethodPost, url: reqURL, header: http.Header{}}
+
+		input, signature, err := httpsig.Sign(msg, "sig1", params, alg, key)
+		require.NoError(t, err)
+
+		return input, signature
+	}
+
+	defaultComponents := []httpsig.Component{
+		{Name: httpsig.ComponentMethod},
+		{Name: httpsig.ComponentAuthority},
+		{Name: httpsig.ComponentPath},
+	}
+
+	storeAuth := &httpSignatureAuthenticator{
+		id:         "auth1",
+		store:      store,
+		components: defaultComponents,
+		maxAge:     defaultHTTPSignatureMaxAge,
+		sf:         &SubjectInfo{IDFrom: "key_id"},
+	}
+
+	labeledAuth := &httpSignatureAuthenticator{
+		id:         "auth1",
+		store:      store,
+		components: defaultComponents,
+		label:      "sig1",
+		maxAge:     defaultHTTPSignatureMaxAge,
+		sf:         &SubjectInfo{IDFrom: "key_id"},
+	}
+
+	jwksAuth := &httpSignatureAuthenticator{
+		id:         "auth2",
+		ep:         &endpoint.Endpoint{URL: srv.URL, Method: http.MethodGet},
+		components: defaultComponents,
+		maxAge:     defaultHTTPSignatureMaxAge,
+		sf:         &SubjectInfo{IDFrom: "key_id"},
+	}
+
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	replayedInput, replayedSignature := sign(t, httpsig.SignatureParams{
+		Components: defaultComponents, Created: time.Now(), KeyID: "ed-key",
+	}, httpsig.AlgEd25519, edPriv)
+
+	digestComponents := append(slices.Clone(defaultComponents), httpsig.Component{Name: httpsig.ComponentContentDigest})
+	signWithDigest := func(t *testing.T, digest string) (string, string) {
+		t.Helper()
+
+		msg := &testSignedMessage{
+			method: http.MethodPost,
+			url:    reqURL,
+			header: http.Header{httpsig.HeaderContentDigest: []string{digest}},
+		}
+
+		input, signature, err := httpsig.Sign(msg, "sig1", httpsig.SignatureParams{
+			Components: digestComponents, Created: time.Now(), KeyID: "ed-key", Nonce: digest,
+		}, httpsig.AlgEd25519, edPriv)
+		require.NoError(t, err)
+
+		return input, signature
+	}
+
+	for _, tc := range []struct {
+		uc             string
+		auth           *httpSignatureAuthenticator
+		signature      func(t *testing.T) (string, string)
+		configureMocks func(t *testing.T, fnt *mocks.RequestFunctionsMock)
+		assert         func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc:   "no signature present",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return "", ""
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no http message signature present")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth1", identifier.ID())
+			},
+		},
+		{
+			uc:   "malformed signature",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return `sig1=("@method"`, "sig1=:Zm9v:"
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorIs(t, err, httpsig.ErrMalformedSignature)
+			},
+		},
+		{
+			uc:   "signature not covering required components",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: []httpsig.Component{{Name: httpsig.ComponentMethod}},
+					Created:    time.Now(),
+					KeyID:      "ed-key",
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no http message signature covering the required components present")
+			},
+		},
+		{
+			uc:   "signature with configured label not covering required components",
+			auth: labeledAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: []httpsig.Component{{Name: httpsig.ComponentMethod}},
+					Created:    time.Now(),
+					KeyID:      "ed-key",
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "does not cover the required component")
+			},
+		},
+		{
+			uc:   "only one of multiple signatures covers required components",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				msg := &testSignedMessage{method: http.MethodPost, url: reqURL, header: http.Header{}}
+
+				input1, signature1, err := httpsig.Sign(msg, "sig1", httpsig.SignatureParams{
+					Components: []httpsig.Component{{Name: httpsig.ComponentMethod}},
+					Created:    time.Now(),
+					KeyID:      "ed-key",
+				}, httpsig.AlgEd25519, edPriv)
+				require.NoError(t, err)
+
+				input2, signature2, err := httpsig.Sign(msg, "sig2", httpsig.SignatureParams{
+					Components: defaultComponents, Created: time.Now(), KeyID: "ed-key", Nonce: "baz",
+				}, httpsig.AlgEd25519, edPriv)
+				require.NoError(t, err)
+
+				return input1 + ", " + input2, signature1 + ", " + signature2
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "ed-key", sub.ID)
+				assert.Equal(t, "sig2", sub.Attributes["label"])
+			},
+		},
+		{
+			uc:   "signature without created parameter",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents, KeyID: "ed-key",
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no created parameter")
+			},
+		},
+		{
+			uc:   "too old signature",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents, Created: time.Now().Add(-10 * time.Minute), KeyID: "ed-key",
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "too old")
+			},
+		},
+		{
+			uc:   "signature created in the future",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents, Created: time.Now().Add(time.Minute), KeyID: "ed-key",
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "in the future")
+			},
+		},
+		{
+			uc:   "expired signature",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents,
+					Created:    time.Now().Add(-time.Minute),
+					Expires:    time.Now().Add(-time.Second),
+					KeyID:      "ed-key",
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "expired")
+			},
+		},
+		{
+			uc:   "signature referencing unknown key",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents, Created: time.Now(), KeyID: "foo",
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no key found for keyid=foo")
+			},
+		},
+		{
+			uc:   "signature created with another key",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+				require.NoError(t, err)
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents, Created: time.Now(), KeyID: "ed-key",
+				}, httpsig.AlgEd25519, otherKey)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, httpsig.ErrInvalidSignature)
+			},
+		},
+		{
+			uc:   "valid signature verified with key from key store",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents,
+					Created:    time.Now(),
+					Expires:    time.Now().Add(time.Minute),
+					Nonce:      "foo",
+					Tag:        "bar",
+					KeyID:      "ed-key",
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "ed-key", sub.ID)
+				assert.Equal(t, httpsig.AlgEd25519, sub.Attributes["algorithm"])
+				assert.Equal(t, "sig1", sub.Attributes["label"])
+				assert.Equal(t, "foo", sub.Attributes["nonce"])
+				assert.Equal(t, "bar", sub.Attributes["tag"])
+				assert.Contains(t, sub.Attributes, "created")
+				assert.Contains(t, sub.Attributes, "expires")
+				assert.Equal(t, []any{`"@method"`, `"@authority"`, `"@path"`}, sub.Attributes["components"])
+			},
+		},
+		{
+			uc:   "rsa signature without algorithm parameter",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents, Created: time.Now(), KeyID: "rsa-key",
+				}, httpsig.AlgRSAPSSSHA512, rsaPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, httpsig.ErrUnsupportedAlgorithm)
+				assert.Contains(t, err.Error(), "key for keyid=rsa-key cannot be used")
+			},
+		},
+		{
+			uc:   "rsa signature with algorithm parameter not fitting the key",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents,
+					Created:    time.Now(),
+					KeyID:      "rsa-key",
+					Algorithm:  httpsig.AlgEd25519,
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, httpsig.ErrUnsupportedAlgorithm)
+				assert.Contains(t, err.Error(), "cannot be used with the key")
+			},
+		},
+		{
+			uc:   "valid rsa signature verified using the algorithm parameter",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents,
+					Created:    time.Now(),
+					KeyID:      "rsa-key",
+					Algorithm:  httpsig.AlgRSAv15SHA256,
+				}, httpsig.AlgRSAv15SHA256, rsaPriv)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "rsa-key", sub.ID)
+				assert.Equal(t, httpsig.AlgRSAv15SHA256, sub.Attributes["algorithm"])
+			},
+		},
+		{
+			uc:   "first use of a signature",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return replayedInput, replayedSignature
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "ed-key", sub.ID)
+			},
+		},
+		{
+			uc:   "replayed signature",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return replayedInput, replayedSignature
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "already been used")
+			},
+		},
+		{
+			uc:   "signed content digest not matching the body",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return signWithDigest(t, httpsig.ContentDigest([]byte("foo")))
+			},
+			configureMocks: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().HeaderValues(httpsig.ComponentContentDigest).
+					Return([]string{httpsig.ContentDigest([]byte("foo"))})
+				fnt.EXPECT().HeaderValues(httpsig.HeaderContentDigest).
+					Return([]string{httpsig.ContentDigest([]byte("foo"))})
+				fnt.EXPECT().RawBody().Return([]byte("bar"))
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, httpsig.ErrContentDigestMismatch)
+				assert.Contains(t, err.Error(), "failed to verify the content digest")
+			},
+		},
+		{
+			uc:   "signed content digest matching the body",
+			auth: storeAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return signWithDigest(t, httpsig.ContentDigest([]byte("bar")))
+			},
+			configureMocks: func(t *testing.T, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().HeaderValues(httpsig.ComponentContentDigest).
+					Return([]string{httpsig.ContentDigest([]byte("bar"))})
+				fnt.EXPECT().HeaderValues(httpsig.HeaderContentDigest).
+					Return([]string{httpsig.ContentDigest([]byte("bar"))})
+				fnt.EXPECT().RawBody().Return([]byte("bar"))
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "ed-key", sub.ID)
+			},
+		},
+		{
+			uc:   "signature algorithm not matching the key",
+			auth: jwksAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents,
+					Created:    time.Now(),
+					KeyID:      "ec-key",
+					Algorithm:  httpsig.AlgEd25519,
+				}, httpsig.AlgEd25519, edPriv)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, httpsig.ErrUnsupportedAlgorithm)
+			},
+		},
+		{
+			uc:   "valid signature verified with key from jwks endpoint",
+			auth: jwksAuth,
+			signature: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return sign(t, httpsig.SignatureParams{
+					Components: defaultComponents, Created: time.Now(), KeyID: "ec-key",
+				}, httpsig.AlgECDSAP256SHA256, ecPriv)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "ec-key", sub.ID)
+				assert.Equal(t, httpsig.AlgECDSAP256SHA256, sub.Attributes["algorithm"])
+				// the key is taken from the cache since the first test case using the jwks endpoint
+				assert.Equal(t, 1, jwksRequests)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			input, signature := tc.signature(t)
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Header(httpsig.HeaderSignatureInput).Return(input)
+			fnt.EXPECT().Header(httpsig.HeaderSignature).Return(signature)
+
+			if tc.configureMocks != nil {
+				tc.configureMocks(t, fnt)
+			}
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch))
+			ctx.EXPECT().Request().Return(&heimdall.Request{
+				RequestFunctions: fnt,
+				Method:           http.MethodPost,
+				URL:              &heimdall.URL{URL: *reqURL},
+			})
+
+			// WHEN
+			sub, err := tc.auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+var (
+	errJWENotEncrypted      = errors.New("JWT is not encrypted")
+	errJWENoDecryptionKey   = errors.New("no suitable decryption key available")
+	errJWEDecryptionFailure = errors.New("failed to decrypt JWT")
+)
+
+// jweDecrypter decrypts nested JWTs, which are signed and then encrypted, using the keys from
+// the configured key store. Only the algorithms configured are accepted for the outer JWE.
+type jweDecrypter struct {
+	KeyStore struct {
+		Path     string `mapstructure:"path"     validate:"required"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"key_store"`
+	KeyAlgorithms               []string `mapstructure:"key_algorithms"                validate:"dive,oneof=RSA-OAEP RSA-OAEP-256 ECDH-ES ECDH-ES+A128KW ECDH-ES+A192KW ECDH-ES+A256KW"` //nolint:lll,tagalign
+	ContentEncryptionAlgorithms []string `mapstructure:"content_encryption_algorithms" validate:"dive,oneof=A128GCM A192GCM A256GCM A128CBC-HS256 A192CBC-HS384 A256CBC-HS512"`          //nolint:lll,tagalign
+	Required                    bool     `mapstructure:"required"`
+
+	ks keystore.KeyStore
+}
+
+func (d *jweDecrypter) init() error {
+	ks, err := keystore.NewKeyStoreFromPEMFile(d.KeyStore.Path, d.KeyStore.Password)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed loading decryption key store").
+			CausedBy(err)
+	}
+
+	if len(d.KeyAlgorithms) == 0 {
+		// RSA PKCS v1.5 is not allowed by intention
+		d.KeyAlgorithms = []string{
+			string(jose.RSA_OAEP), string(jose.RSA_OAEP_256),
+			string(jose.ECDH_ES), string(jose.ECDH_ES_A128KW), string(jose.ECDH_ES_A192KW), string(jose.ECDH_ES_A256KW),
+		}
+	}
+
+	if len(d.ContentEncryptionAlgorithms) == 0 {
+		d.ContentEncryptionAlgorithms = []string{
+			string(jose.A128GCM), string(jose.A192GCM), string(jose.A256GCM),
+			string(jose.A128CBC_HS256), string(jose.A192CBC_HS384), string(jose.A256CBC_HS512),
+		}
+	}
+
+	d.ks = ks
+
+	return nil
+}
+
+// parse parses the given raw token. Encrypted tokens are decrypted and the nested signed JWT is
+// returned. Signed tokens are parsed as is, unless encryption is required.
+func (d *jweDecrypter) parse(rawToken string) (*jwt.JSONWebToken, error) {
+	// a JWE in compact serialization has five parts, a JWS three
+	if strings.Count(rawToken, ".") != 4 { //nolint:gomnd
+		if d.Required {
+			return nil, errJWENotEncrypted
+		}
+
+		return jwt.ParseSigned(rawToken, supportedAlgorithms())
+	}
+
+	keyAlgorithms := make([]jose.KeyAlgorithm, len(d.KeyAlgorithms))
+	for idx, alg := range d.KeyAlgorithms {
+		keyAlgorithms[idx] = jose.KeyAlgorithm(alg)
+	}
+
+	contentEncryption := make([]jose.ContentEncryption, len(d.ContentEncryptionAlgorithms))
+	for idx, enc := range d.ContentEncryptionAlgorithms {
+		contentEncryption[idx] = jose.ContentEncryption(enc)
+	}
+
+	// the jwt package does not support asymmetric key management algorithms, as everyone can create
+	// such tokens. Since the nested JWT is signed, this is not an issue here.
+	jwe, err := jose.ParseEncryptedCompact(rawToken, keyAlgorithms, contentEncryption)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType, _ := jwe.Header.ExtraHeaders[jose.HeaderContentType].(string); !strings.EqualFold(contentType, "JWT") {
+		return nil, jwt.ErrInvalidContentType
+	}
+
+	payload, err := d.decrypt(jwe)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwt.ParseSigned(stringx.ToString(payload), supportedAlgorithms())
+}
+
+func (d *jweDecrypter) decrypt(jwe *jose.JSONWebEncryption) ([]byte, error) {
+	var entries []*keystore.Entry
+
+	if kid := jwe.Header.KeyID; len(kid) != 0 {
+		entry, err := d.ks.GetKey(kid)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(errJWENoDecryptionKey, "no key with key_id=%s", kid).
+				CausedBy(err)
+		}
+
+		entries = []*keystore.Entry{entry}
+	} else {
+		entries = d.ks.Entries()
+	}
+
+	var err error
+
+	for _, entry := range entries {
+		var payload []byte
+
+		// the error is the same for all keys not matching
+		if payload, err = jwe.Decrypt(entry.PrivateKey); err == nil {
+			return payload, nil
+		}
+	}
+
+	return nil, errorchain.New(errJWEDecryptionFailure).CausedBy(err)
+}
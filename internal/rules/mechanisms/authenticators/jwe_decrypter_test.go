@@ -0,0 +1,299 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+)
+
+func createNestedJWT(
+	t *testing.T, sigKey *ecdsa.PrivateKey, keyAlg jose.KeyAlgorithm, encKey any, kid, contentType string,
+) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: sigKey}, nil)
+	require.NoError(t, err)
+
+	opts := &jose.EncrypterOptions{}
+	if len(contentType) != 0 {
+		opts = opts.WithContentType(jose.ContentType(contentType))
+	}
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: keyAlg, Key: encKey, KeyID: kid}, opts)
+	require.NoError(t, err)
+
+	signed, err := jwt.Signed(signer).Claims(jwt.Claims{Subject: "foo"}).Serialize()
+	require.NoError(t, err)
+
+	jwe, err := encrypter.Encrypt([]byte(signed))
+	require.NoError(t, err)
+
+	token, err := jwe.CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestJWEDecrypterInit(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	validPEM, err := pemx.BuildPEM(pemx.WithRSAPrivateKey(rsaKey, pemx.WithHeader("X-Key-ID", "enc")))
+	require.NoError(t, err)
+
+	validFile := filepath.Join(testDir, "valid.pem")
+	require.NoError(t, os.WriteFile(validFile, validPEM, 0o600))
+
+	for _, tc := range []struct {
+		uc        string
+		decrypter *jweDecrypter
+		assert    func(t *testing.T, err error, decrypter *jweDecrypter)
+	}{
+		{
+			uc: "not existing key store",
+			decrypter: func() *jweDecrypter {
+				dec := &jweDecrypter{}
+				dec.KeyStore.Path = filepath.Join(testDir, "missing.pem")
+
+				return dec
+			}(),
+			assert: func(t *testing.T, err error, _ *jweDecrypter) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed loading decryption key store")
+			},
+		},
+		{
+			uc: "valid key store and default algorithms",
+			decrypter: func() *jweDecrypter {
+				dec := &jweDecrypter{}
+				dec.KeyStore.Path = validFile
+
+				return dec
+			}(),
+			assert: func(t *testing.T, err error, decrypter *jweDecrypter) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, decrypter.ks)
+				assert.Len(t, decrypter.ks.Entries(), 1)
+				assert.ElementsMatch(t, []string{
+					"RSA-OAEP", "RSA-OAEP-256", "ECDH-ES", "ECDH-ES+A128KW", "ECDH-ES+A192KW", "ECDH-ES+A256KW",
+				}, decrypter.KeyAlgorithms)
+				assert.ElementsMatch(t, []string{
+					"A128GCM", "A192GCM", "A256GCM", "A128CBC-HS256", "A192CBC-HS384", "A256CBC-HS512",
+				}, decrypter.ContentEncryptionAlgorithms)
+			},
+		},
+		{
+			uc: "valid key store and configured algorithms",
+			decrypter: func() *jweDecrypter {
+				dec := &jweDecrypter{
+					KeyAlgorithms:               []string{"RSA-OAEP-256"},
+					ContentEncryptionAlgorithms: []string{"A256GCM"},
+				}
+				dec.KeyStore.Path = validFile
+
+				return dec
+			}(),
+			assert: func(t *testing.T, err error, decrypter *jweDecrypter) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []string{"RSA-OAEP-256"}, decrypter.KeyAlgorithms)
+				assert.Equal(t, []string{"A256GCM"}, decrypter.ContentEncryptionAlgorithms)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			err := tc.decrypter.init()
+
+			// THEN
+			tc.assert(t, err, tc.decrypter)
+		})
+	}
+}
+
+func TestJWEDecrypterParse(t *testing.T) {
+	t.Parallel()
+
+	sigKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(
+		pemx.WithRSAPrivateKey(rsaKey, pemx.WithHeader("X-Key-ID", "rsa-enc")),
+		pemx.WithECDSAPrivateKey(ecKey, pemx.WithHeader("X-Key-ID", "ec-enc")),
+	)
+	require.NoError(t, err)
+
+	ksFile := filepath.Join(t.TempDir(), "keys.pem")
+	require.NoError(t, os.WriteFile(ksFile, pemBytes, 0o600))
+
+	newDecrypter := func(t *testing.T, required bool, keyAlgorithms ...string) *jweDecrypter {
+		t.Helper()
+
+		dec := &jweDecrypter{Required: required, KeyAlgorithms: keyAlgorithms}
+		dec.KeyStore.Path = ksFile
+		require.NoError(t, dec.init())
+
+		return dec
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: sigKey}, nil)
+	require.NoError(t, err)
+
+	signedJWT, err := jwt.Signed(signer).Claims(jwt.Claims{Subject: "foo"}).Serialize()
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc        string
+		decrypter func(t *testing.T) *jweDecrypter
+		token     string
+		assert    func(t *testing.T, err error, token *jwt.JSONWebToken)
+	}{
+		{
+			uc:        "signed JWT with encryption not required",
+			decrypter: func(t *testing.T) *jweDecrypter { t.Helper(); return newDecrypter(t, false) },
+			token:     signedJWT,
+			assert: func(t *testing.T, err error, token *jwt.JSONWebToken) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, token)
+			},
+		},
+		{
+			uc:        "signed JWT with encryption required",
+			decrypter: func(t *testing.T) *jweDecrypter { t.Helper(); return newDecrypter(t, true) },
+			token:     signedJWT,
+			assert: func(t *testing.T, err error, _ *jwt.JSONWebToken) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errJWENotEncrypted)
+			},
+		},
+		{
+			uc:        "nested JWT encrypted for rsa key referenced by kid",
+			decrypter: func(t *testing.T) *jweDecrypter { t.Helper(); return newDecrypter(t, true) },
+			token:     createNestedJWT(t, sigKey, jose.RSA_OAEP_256, &rsaKey.PublicKey, "rsa-enc", "JWT"),
+			assert: func(t *testing.T, err error, token *jwt.JSONWebToken) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				var claims jwt.Claims
+				require.NoError(t, token.Claims(&sigKey.PublicKey, &claims))
+				assert.Equal(t, "foo", claims.Subject)
+			},
+		},
+		{
+			uc:        "nested JWT encrypted for ec key without kid",
+			decrypter: func(t *testing.T) *jweDecrypter { t.Helper(); return newDecrypter(t, false) },
+			token:     createNestedJWT(t, sigKey, jose.ECDH_ES_A256KW, &ecKey.PublicKey, "", "JWT"),
+			assert: func(t *testing.T, err error, token *jwt.JSONWebToken) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				var claims jwt.Claims
+				require.NoError(t, token.Claims(&sigKey.PublicKey, &claims))
+				assert.Equal(t, "foo", claims.Subject)
+			},
+		},
+		{
+			uc:        "nested JWT encrypted with not allowed key algorithm",
+			decrypter: func(t *testing.T) *jweDecrypter { t.Helper(); return newDecrypter(t, false, "ECDH-ES") },
+			token:     createNestedJWT(t, sigKey, jose.RSA_OAEP_256, &rsaKey.PublicKey, "rsa-enc", "JWT"),
+			assert: func(t *testing.T, err error, _ *jwt.JSONWebToken) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorContains(t, err, "unexpected key algorithm")
+			},
+		},
+		{
+			uc:        "nested JWT without JWT content type",
+			decrypter: func(t *testing.T) *jweDecrypter { t.Helper(); return newDecrypter(t, false) },
+			token:     createNestedJWT(t, sigKey, jose.RSA_OAEP_256, &rsaKey.PublicKey, "rsa-enc", ""),
+			assert: func(t *testing.T, err error, _ *jwt.JSONWebToken) {
+				t.Helper()
+
+				require.ErrorIs(t, err, jwt.ErrInvalidContentType)
+			},
+		},
+		{
+			uc:        "nested JWT referencing unknown key",
+			decrypter: func(t *testing.T) *jweDecrypter { t.Helper(); return newDecrypter(t, false) },
+			token:     createNestedJWT(t, sigKey, jose.ECDH_ES, &otherKey.PublicKey, "unknown", "JWT"),
+			assert: func(t *testing.T, err error, _ *jwt.JSONWebToken) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errJWENoDecryptionKey)
+			},
+		},
+		{
+			uc:        "nested JWT encrypted for not available key",
+			decrypter: func(t *testing.T) *jweDecrypter { t.Helper(); return newDecrypter(t, false) },
+			token:     createNestedJWT(t, sigKey, jose.ECDH_ES, &otherKey.PublicKey, "", "JWT"),
+			assert: func(t *testing.T, err error, _ *jwt.JSONWebToken) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errJWEDecryptionFailure)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			decrypter := tc.decrypter(t)
+
+			// WHEN
+			token, err := decrypter.parse(tc.token)
+
+			// THEN
+			tc.assert(t, err, token)
+		})
+	}
+}
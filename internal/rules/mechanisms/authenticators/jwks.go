@@ -0,0 +1,92 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+func fetchJWKS(
+	ctx context.Context, client *http.Client, req *http.Request, errCtx any,
+) (*jose.JSONWebKeySet, error) {
+	logger := zerolog.Ctx(ctx)
+
+	logger.Debug().Msg("Retrieving JWKS from configured endpoint")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var clientErr *url.Error
+		if errors.As(err, &clientErr) && clientErr.Timeout() {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrCommunicationTimeout, "request to JWKS endpoint timed out").
+				WithErrorContext(errCtx).
+				CausedBy(err)
+		}
+
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "request to JWKS endpoint failed").
+			WithErrorContext(errCtx).
+			CausedBy(err)
+	}
+
+	defer resp.Body.Close()
+
+	return readJWKS(resp, errCtx)
+}
+
+func readJWKS(resp *http.Response, errCtx any) (*jose.JSONWebKeySet, error) {
+	if !(resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices) {
+		return nil, errorchain.
+			NewWithMessagef(heimdall.ErrCommunication, "unexpected response. code: %v", resp.StatusCode).
+			WithErrorContext(errCtx)
+	}
+
+	// unmarshal the received key set
+	var jwks jose.JSONWebKeySet
+
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to unmarshal received jwks").
+			WithErrorContext(errCtx).
+			CausedBy(err)
+	}
+
+	return &jwks, nil
+}
+
+func jwkCacheKey(ep *endpoint.Endpoint, renderedURL, reference string) string {
+	digest := sha256.New()
+	digest.Write(ep.Hash())
+	digest.Write(stringx.ToBytes(renderedURL))
+	digest.Write(stringx.ToBytes(reference))
+
+	return hex.EncodeToString(digest.Sum(nil))
+}
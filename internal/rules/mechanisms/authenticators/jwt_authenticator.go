@@ -18,12 +18,9 @@ package authenticators
 
 import (
 	"context"
-	"crypto/sha256"
 	"crypto/x509"
-	"encoding/hex"
 	"errors"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
@@ -43,7 +40,6 @@ import (
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/pkix"
-	"github.com/dadrus/heimdall/internal/x/stringx"
 )
 
 const defaultJWTAuthenticatorTTL = 10 * time.Minute
@@ -53,7 +49,7 @@ const defaultJWTAuthenticatorTTL = 10 * time.Minute
 //nolint:gochecknoinits
 func init() {
 	registerTypeFactory(
-		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+		func(_ CreationContext, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
 			if typ != AuthenticatorJwt {
 				return false, nil, nil
 			}
@@ -74,19 +70,26 @@ type jwtAuthenticator struct {
 	allowFallbackOnError bool
 	trustStore           truststore.TrustStore
 	validateJWKCert      bool
+	dpop                 *dpopVerifier
+	cb                   *certificateBinding
+	jwe                  *jweDecrypter
 }
 
 func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator, error) { // nolint: funlen
 	type Config struct {
-		JWKSEndpoint         *endpoint.Endpoint                  `mapstructure:"jwks_endpoint"        validate:"required_without=MetadataEndpoint,excluded_with=MetadataEndpoint"` //nolint:lll,tagalign
-		MetadataEndpoint     *oauth2.MetadataEndpoint            `mapstructure:"metadata_endpoint"    validate:"required_without=JWKSEndpoint,excluded_with=JWKSEndpoint"`         //nolint:lll,tagalign
-		Assertions           oauth2.Expectation                  `mapstructure:"assertions"           validate:"required_with=JWKSEndpoint"`                                       //nolint:lll,tagalign
-		SubjectInfo          SubjectInfo                         `mapstructure:"subject"              validate:"-"`                                                                //nolint:lll,tagalign
+		JWKSEndpoint         *endpoint.Endpoint                  `mapstructure:"jwks_endpoint"        validate:"required_without_all=MetadataEndpoint IssuerDiscovery,excluded_with=MetadataEndpoint IssuerDiscovery"` //nolint:lll,tagalign
+		MetadataEndpoint     *oauth2.MetadataEndpoint            `mapstructure:"metadata_endpoint"    validate:"required_without_all=JWKSEndpoint IssuerDiscovery,excluded_with=JWKSEndpoint IssuerDiscovery"`         //nolint:lll,tagalign
+		IssuerDiscovery      *oauth2.IssuerDiscovery             `mapstructure:"issuer_discovery"     validate:"excluded_with=JWKSEndpoint MetadataEndpoint"`                                                          //nolint:lll,tagalign
+		Assertions           oauth2.Expectation                  `mapstructure:"assertions"           validate:"required_with=JWKSEndpoint"`                                                                           //nolint:lll,tagalign
+		SubjectInfo          SubjectInfo                         `mapstructure:"subject"              validate:"-"`                                                                                                    //nolint:lll,tagalign
 		AuthDataSource       extractors.CompositeExtractStrategy `mapstructure:"jwt_source"`
 		CacheTTL             *time.Duration                      `mapstructure:"cache_ttl"`
 		AllowFallbackOnError bool                                `mapstructure:"allow_fallback_on_error"`
 		ValidateJWK          *bool                               `mapstructure:"validate_jwk"`
 		TrustStore           truststore.TrustStore               `mapstructure:"trust_store"`
+		DPoP                 *dpopVerifier                       `mapstructure:"dpop"`
+		CertificateBinding   *certificateBinding                 `mapstructure:"certificate_binding"`
+		Decryption           *jweDecrypter                       `mapstructure:"decryption"`
 	}
 
 	var conf Config
@@ -126,30 +129,56 @@ func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator
 		func() extractors.CompositeExtractStrategy { return conf.AuthDataSource },
 	)
 
-	resolver := x.IfThenElseExec(conf.MetadataEndpoint != nil,
-		func() oauth2.ServerMetadataResolver { return conf.MetadataEndpoint },
-		func() oauth2.ServerMetadataResolver {
-			ep := conf.JWKSEndpoint
+	if conf.DPoP != nil {
+		conf.DPoP.init()
 
-			if ep.Headers == nil {
-				ep.Headers = make(map[string]string)
-			}
+		if conf.AuthDataSource == nil {
+			ads = withDPoPTokenSource(ads)
+		}
+	}
 
-			if _, ok := ep.Headers["Accept"]; !ok {
-				ep.Headers["Accept"] = "application/json"
-			}
+	if conf.CertificateBinding != nil {
+		conf.CertificateBinding.init()
+	}
 
-			if len(ep.Method) == 0 {
-				ep.Method = http.MethodGet
-			}
+	if conf.Decryption != nil {
+		if err := conf.Decryption.init(); err != nil {
+			return nil, err
+		}
+	}
 
-			return oauth2.ResolverAdapterFunc(
-				func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
-					return oauth2.ServerMetadata{JWKSEndpoint: ep}, nil
-				},
-			)
-		},
-	)
+	var resolver oauth2.ServerMetadataResolver
+
+	switch {
+	case conf.MetadataEndpoint != nil:
+		resolver = conf.MetadataEndpoint
+	case conf.IssuerDiscovery != nil:
+		if err := conf.IssuerDiscovery.Init(); err != nil {
+			return nil, err
+		}
+
+		resolver = conf.IssuerDiscovery
+	default:
+		ep := conf.JWKSEndpoint
+
+		if ep.Headers == nil {
+			ep.Headers = make(map[string]string)
+		}
+
+		if _, ok := ep.Headers["Accept"]; !ok {
+			ep.Headers["Accept"] = "application/json"
+		}
+
+		if len(ep.Method) == 0 {
+			ep.Method = http.MethodGet
+		}
+
+		resolver = oauth2.ResolverAdapterFunc(
+			func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
+				return oauth2.ServerMetadata{JWKSEndpoint: ep}, nil
+			},
+		)
+	}
 
 	return &jwtAuthenticator{
 		id:                   id,
@@ -161,6 +190,9 @@ func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator
 		allowFallbackOnError: conf.AllowFallbackOnError,
 		validateJWKCert:      validateJWKCert,
 		trustStore:           conf.TrustStore,
+		dpop:                 conf.DPoP,
+		cb:                   conf.CertificateBinding,
+		jwe:                  conf.Decryption,
 	}, nil
 }
 
@@ -176,7 +208,7 @@ func (a *jwtAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, erro
 			CausedBy(err)
 	}
 
-	token, err := jwt.ParseSigned(jwtAd, supportedAlgorithms())
+	token, err := a.parseToken(jwtAd)
 	if err != nil {
 		return nil, errorchain.
 			NewWithMessage(heimdall.ErrAuthentication, "failed to parse JWT").
@@ -190,6 +222,24 @@ func (a *jwtAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, erro
 		return nil, err
 	}
 
+	if a.dpop != nil {
+		if err = a.dpop.verify(ctx, jwtAd, rawClaims); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "DPoP verification failed").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+	}
+
+	if a.cb != nil {
+		if err = a.cb.verify(ctx, &a.a, rawClaims); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "certificate binding verification failed").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+	}
+
 	sub, err := a.sf.CreateSubject(rawClaims)
 	if err != nil {
 		return nil, errorchain.
@@ -201,6 +251,14 @@ func (a *jwtAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, erro
 	return sub, nil
 }
 
+func (a *jwtAuthenticator) parseToken(rawToken string) (*jwt.JSONWebToken, error) {
+	if a.jwe != nil {
+		return a.jwe.parse(rawToken)
+	}
+
+	return jwt.ParseSigned(rawToken, supportedAlgorithms())
+}
+
 func (a *jwtAuthenticator) WithConfig(config map[string]any) (Authenticator, error) {
 	// this authenticator allows assertions and ttl to be redefined on the rule level
 	if len(config) == 0 {
@@ -230,6 +288,9 @@ func (a *jwtAuthenticator) WithConfig(config map[string]any) (Authenticator, err
 			func() bool { return a.allowFallbackOnError }),
 		validateJWKCert: a.validateJWKCert,
 		trustStore:      a.trustStore,
+		dpop:            a.dpop,
+		cb:              a.cb,
+		jwe:             a.jwe,
 	}, nil
 }
 
@@ -287,6 +348,11 @@ func (a *jwtAuthenticator) getCacheTTL(key *jose.JSONWebKey) time.Duration {
 func (a *jwtAuthenticator) serverMetadata(ctx heimdall.Context, claims map[string]any) (oauth2.ServerMetadata, error) {
 	metadata, err := a.r.Get(ctx.AppContext(), map[string]any{"TokenIssuer": claims["iss"]})
 	if err != nil {
+		if errors.Is(err, oauth2.ErrAssertion) {
+			return oauth2.ServerMetadata{}, errorchain.NewWithMessage(heimdall.ErrAuthentication,
+				"access token issuer is not trusted").CausedBy(err).WithErrorContext(a)
+		}
+
 		return oauth2.ServerMetadata{}, errorchain.NewWithMessage(heimdall.ErrInternal,
 			"failed retrieving oauth2 server metadata").CausedBy(err).WithErrorContext(a)
 	}
@@ -347,7 +413,7 @@ func (a *jwtAuthenticator) verifyTokenWithoutKID(
 		return nil, err
 	}
 
-	jwks, err := a.fetchJWKS(ctx.AppContext(), ep.CreateClient(req.URL.Hostname()), req)
+	jwks, err := fetchJWKS(ctx.AppContext(), ep.CreateClient(req.URL.Hostname()), req, a)
 	if err != nil {
 		return nil, err
 	}
@@ -407,7 +473,7 @@ func (a *jwtAuthenticator) getKey(
 		}
 	}
 
-	jwks, err = a.fetchJWKS(ctx.AppContext(), ep.CreateClient(req.URL.Hostname()), req)
+	jwks, err = fetchJWKS(ctx.AppContext(), ep.CreateClient(req.URL.Hostname()), req, a)
 	if err != nil {
 		return nil, err
 	}
@@ -439,34 +505,6 @@ func (a *jwtAuthenticator) getKey(
 	return jwk, nil
 }
 
-func (a *jwtAuthenticator) fetchJWKS(
-	ctx context.Context, client *http.Client, req *http.Request,
-) (*jose.JSONWebKeySet, error) {
-	logger := zerolog.Ctx(ctx)
-
-	logger.Debug().Msg("Retrieving JWKS from configured endpoint")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		var clientErr *url.Error
-		if errors.As(err, &clientErr) && clientErr.Timeout() {
-			return nil, errorchain.
-				NewWithMessage(heimdall.ErrCommunicationTimeout, "request to JWKS endpoint timed out").
-				WithErrorContext(a).
-				CausedBy(err)
-		}
-
-		return nil, errorchain.
-			NewWithMessage(heimdall.ErrCommunication, "request to JWKS endpoint failed").
-			WithErrorContext(a).
-			CausedBy(err)
-	}
-
-	defer resp.Body.Close()
-
-	return a.readJWKS(resp)
-}
-
 func (a *jwtAuthenticator) createRequest(
 	ctx context.Context, ep *endpoint.Endpoint, claims map[string]any,
 ) (*http.Request, error) {
@@ -494,26 +532,6 @@ func (a *jwtAuthenticator) createRequest(
 	return req, nil
 }
 
-func (a *jwtAuthenticator) readJWKS(resp *http.Response) (*jose.JSONWebKeySet, error) {
-	if !(resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices) {
-		return nil, errorchain.
-			NewWithMessagef(heimdall.ErrCommunication, "unexpected response. code: %v", resp.StatusCode).
-			WithErrorContext(a)
-	}
-
-	// unmarshal the received key set
-	var jwks jose.JSONWebKeySet
-
-	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
-		return nil, errorchain.
-			NewWithMessage(heimdall.ErrInternal, "failed to unmarshal received jwks").
-			WithErrorContext(a).
-			CausedBy(err)
-	}
-
-	return &jwks, nil
-}
-
 func (a *jwtAuthenticator) verifyTokenWithKey(
 	token *jwt.JSONWebToken, key *jose.JSONWebKey, assertions *oauth2.Expectation,
 ) (json.RawMessage, error) {
@@ -564,12 +582,7 @@ func (a *jwtAuthenticator) verifyTokenWithKey(
 }
 
 func (a *jwtAuthenticator) calculateCacheKey(ep *endpoint.Endpoint, renderedURL, reference string) string {
-	digest := sha256.New()
-	digest.Write(ep.Hash())
-	digest.Write(stringx.ToBytes(renderedURL))
-	digest.Write(stringx.ToBytes(reference))
-
-	return hex.EncodeToString(digest.Sum(nil))
+	return jwkCacheKey(ep, renderedURL, reference)
 }
 
 func (a *jwtAuthenticator) validateJWK(jwk *jose.JSONWebKey) error {
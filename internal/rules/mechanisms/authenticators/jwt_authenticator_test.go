@@ -29,6 +29,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -81,6 +82,16 @@ func TestJwtAuthenticatorCreate(t *testing.T) {
 
 	trustStorePath := file.Name()
 
+	// decryption key store
+	encKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keyStorePEM, err := pemx.BuildPEM(pemx.WithRSAPrivateKey(encKey, pemx.WithHeader("X-Key-ID", "enc")))
+	require.NoError(t, err)
+
+	keyStorePath := filepath.Join(t.TempDir(), "keys.pem")
+	require.NoError(t, os.WriteFile(keyStorePath, keyStorePEM, 0o600))
+
 	for _, tc := range []struct {
 		uc     string
 		id     string
@@ -442,6 +453,196 @@ cache_ttl: 5s`),
 				assert.Equal(t, "auth1", auth.ID())
 			},
 		},
+		{
+			uc: "jwks endpoint based configuration with dpop support",
+			id: "auth1",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+assertions:
+  issuers:
+    - foobar
+dpop:
+  required: true
+  allowed_algorithms:
+    - ES256
+  max_age: 30s`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				// token extractor settings
+				assert.Len(t, auth.ads, 4)
+				assert.Contains(t, auth.ads, extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "DPoP"})
+
+				// dpop settings
+				require.NotNil(t, auth.dpop)
+				assert.True(t, auth.dpop.Required)
+				assert.Equal(t, []string{"ES256"}, auth.dpop.AllowedAlgorithms)
+				assert.Equal(t, 30*time.Second, *auth.dpop.MaxAge)
+			},
+		},
+		{
+			uc: "jwks endpoint based configuration with certificate binding",
+			id: "auth1",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+assertions:
+  issuers:
+    - foobar
+certificate_binding:
+  required: true
+  forwarded_client_cert:
+    name: X-Client-Cert`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, auth.cb)
+				assert.True(t, auth.cb.Required)
+				assert.Equal(t, &forwardedClientCertificate{
+					Name:   "X-Client-Cert",
+					Format: forwardedClientCertificateFormatPEM,
+				}, auth.cb.ForwardedClientCert)
+				assert.Nil(t, auth.dpop)
+			},
+		},
+		{
+			uc: "issuer discovery together with jwks endpoint",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+issuer_discovery:
+  issuers:
+    - https://foo.example.com
+assertions:
+  issuers:
+    - foobar`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "issuer_discovery")
+			},
+		},
+		{
+			uc: "issuer discovery without issuers",
+			config: []byte(`
+issuer_discovery:
+  cache_size: 10`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "requires issuers or issuer_patterns")
+			},
+		},
+		{
+			uc: "issuer discovery based configuration",
+			id: "auth1",
+			config: []byte(`
+issuer_discovery:
+  issuers:
+    - https://foo.example.com
+  issuer_patterns:
+    - https://<*>.example.com
+  cache_size: 10
+  cache_ttl: 10m`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				discovery, ok := auth.r.(*oauth2.IssuerDiscovery)
+				require.True(t, ok)
+				assert.Equal(t, []string{"https://foo.example.com"}, discovery.Issuers)
+				assert.Equal(t, []string{"https://<*>.example.com"}, discovery.IssuerPatterns)
+				assert.Equal(t, 10, discovery.CacheSize)
+				assert.Equal(t, 10*time.Minute, *discovery.CacheTTL)
+
+				_, err = auth.r.Get(context.TODO(), map[string]any{"TokenIssuer": "https://bar.example.org"})
+				require.ErrorIs(t, err, oauth2.ErrAssertion)
+
+				assert.Empty(t, auth.a.TrustedIssuers)
+				assert.Equal(t, "auth1", auth.ID())
+			},
+		},
+		{
+			uc: "decryption with unsupported key algorithm",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+assertions:
+  issuers:
+    - foobar
+decryption:
+  key_store:
+    path: ` + keyStorePath + `
+  key_algorithms:
+    - RSA1_5`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "key_algorithms")
+			},
+		},
+		{
+			uc: "decryption with not existing key store",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+assertions:
+  issuers:
+    - foobar
+decryption:
+  key_store:
+    path: /does/not/exist.pem`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed loading decryption key store")
+			},
+		},
+		{
+			uc: "jwks endpoint based configuration with decryption",
+			id: "auth1",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+assertions:
+  issuers:
+    - foobar
+decryption:
+  required: true
+  key_store:
+    path: ` + keyStorePath + `
+  key_algorithms:
+    - RSA-OAEP-256
+  content_encryption_algorithms:
+    - A256GCM`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, auth.jwe)
+				assert.True(t, auth.jwe.Required)
+				assert.Equal(t, []string{"RSA-OAEP-256"}, auth.jwe.KeyAlgorithms)
+				assert.Equal(t, []string{"A256GCM"}, auth.jwe.ContentEncryptionAlgorithms)
+				require.NotNil(t, auth.jwe.ks)
+				_, err = auth.jwe.ks.GetKey("enc")
+				require.NoError(t, err)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			conf, err := testsupport.DecodeTestConfig(tc.config)
@@ -870,6 +1071,15 @@ func TestJwtAuthenticatorExecute(t *testing.T) {
 
 	jwtSignedWithKeyOnlyJWK := createJWT(t, keyOnlyEntry, subjectID, issuer, audience, true)
 
+	encrypter, err := jose.NewEncrypter(jose.A256GCM,
+		jose.Recipient{Algorithm: jose.RSA_OAEP_256, Key: keyRSAEntry.PrivateKey.Public(), KeyID: kidRSAKey},
+		(&jose.EncrypterOptions{}).WithContentType("JWT"))
+	require.NoError(t, err)
+	jwe, err := encrypter.Encrypt([]byte(jwtSignedWithKeyOnlyJWK))
+	require.NoError(t, err)
+	jweWithJWTSignedWithKeyOnlyJWK, err := jwe.CompactSerialize()
+	require.NoError(t, err)
+
 	jwtSignedWithKeyAndCertJWK := createJWT(t, keyAndCertEntry, subjectID, issuer, audience, true)
 	jwtWithoutKIDSignedWithKeyAndCertJWK := createJWT(t, keyAndCertEntry, subjectID, issuer, audience, false)
 
@@ -1256,6 +1466,40 @@ func TestJwtAuthenticatorExecute(t *testing.T) {
 				assert.Equal(t, "auth3", identifier.ID())
 			},
 		},
+		{
+			uc: "with untrusted issuer using issuer discovery",
+			authenticator: func() *jwtAuthenticator {
+				discovery := &oauth2.IssuerDiscovery{IssuerPatterns: []string{"https://<*>.example.com"}}
+				require.NoError(t, discovery.Init())
+
+				return &jwtAuthenticator{id: "auth3", r: discovery, ttl: &disabledTTL}
+			}(),
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, jwksEndpointCalled)
+				assert.False(t, metadataEndpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, oauth2.ErrAssertion)
+				require.ErrorContains(t, err, "issuer is not trusted")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
 		{
 			uc: "with no entry for jwks_uri from the metadata endpoint",
 			authenticator: &jwtAuthenticator{
@@ -1538,6 +1782,69 @@ func TestJwtAuthenticatorExecute(t *testing.T) {
 			},
 		},
 
+		{
+			uc: "with DPoP binding required, but token not bound",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				r: oauth2.ResolverAdapterFunc(func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
+					return oauth2.ServerMetadata{
+						JWKSEndpoint: &endpoint.Endpoint{
+							URL:     jwksSrv.URL,
+							Headers: map[string]string{"Accept": "application/json"},
+						},
+					}, nil
+				}),
+				a: oauth2.Expectation{
+					AllowedAlgorithms: []string{"ES384"},
+					TrustedIssuers:    []string{issuer},
+					ScopesMatcher:     oauth2.ExactScopeStrategyMatcher{},
+				},
+				sf:   &SubjectInfo{IDFrom: "sub"},
+				ttl:  &tenSecondsTTL,
+				dpop: &dpopVerifier{Required: true},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				auth *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ep := &endpoint.Endpoint{
+					URL:     jwksSrv.URL,
+					Headers: map[string]string{"Accept": "application/json"},
+				}
+				cacheKey := auth.calculateCacheKey(ep, jwksSrv.URL, kidKeyWithoutCert)
+
+				var jwks jose.JSONWebKeySet
+				err := json.Unmarshal(jwksWithOneKeyOnlyEntry, &jwks)
+				require.NoError(t, err)
+
+				keys := jwks.Key(kidKeyWithoutCert)
+
+				rawKey, err := json.Marshal(&keys[0])
+				require.NoError(t, err)
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+				cch.EXPECT().Get(mock.Anything, cacheKey).Return(rawKey, nil)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, jwksEndpointCalled)
+				assert.False(t, metadataEndpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errDPoPBindingMissing)
+				require.ErrorContains(t, err, "DPoP verification failed")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
 		{
 			uc: "successful with positive cache hit",
 			authenticator: &jwtAuthenticator{
@@ -1605,6 +1912,108 @@ func TestJwtAuthenticatorExecute(t *testing.T) {
 				assert.Equal(t, subjectID, sub.Attributes["sub"])
 			},
 		},
+		{
+			uc: "with decryption required, but token not encrypted",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				a: oauth2.Expectation{
+					AllowedAlgorithms: []string{"ES384"},
+					TrustedIssuers:    []string{issuer},
+					ScopesMatcher:     oauth2.ExactScopeStrategyMatcher{},
+				},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &tenSecondsTTL,
+				jwe: &jweDecrypter{Required: true, ks: ks},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, jwksEndpointCalled)
+				assert.False(t, metadataEndpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errJWENotEncrypted)
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
+		{
+			uc: "successful with encrypted token and positive cache hit",
+			authenticator: &jwtAuthenticator{
+				r: oauth2.ResolverAdapterFunc(func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
+					return oauth2.ServerMetadata{
+						JWKSEndpoint: &endpoint.Endpoint{
+							URL:     jwksSrv.URL,
+							Headers: map[string]string{"Accept": "application/json"},
+						},
+					}, nil
+				}),
+				a: oauth2.Expectation{
+					AllowedAlgorithms: []string{"ES384"},
+					TrustedIssuers:    []string{issuer},
+					ScopesMatcher:     oauth2.ExactScopeStrategyMatcher{},
+				},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &tenSecondsTTL,
+				jwe: &jweDecrypter{
+					Required:                    true,
+					KeyAlgorithms:               []string{"RSA-OAEP-256"},
+					ContentEncryptionAlgorithms: []string{"A256GCM"},
+					ks:                          ks,
+				},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				auth *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ep := &endpoint.Endpoint{
+					URL:     jwksSrv.URL,
+					Headers: map[string]string{"Accept": "application/json"},
+				}
+				cacheKey := auth.calculateCacheKey(ep, jwksSrv.URL, kidKeyWithoutCert)
+
+				var jwks jose.JSONWebKeySet
+				err := json.Unmarshal(jwksWithOneKeyOnlyEntry, &jwks)
+				require.NoError(t, err)
+
+				keys := jwks.Key(kidKeyWithoutCert)
+
+				rawKey, err := json.Marshal(&keys[0])
+				require.NoError(t, err)
+
+				ads.EXPECT().GetAuthData(ctx).Return(jweWithJWTSignedWithKeyOnlyJWK, nil)
+				cch.EXPECT().Get(mock.Anything, cacheKey).Return(rawKey, nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, jwksEndpointCalled)
+				assert.False(t, metadataEndpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, subjectID, sub.ID)
+				assert.Equal(t, issuer, sub.Attributes["iss"])
+			},
+		},
 		{
 			uc: "successful without cache hit using key only",
 			authenticator: &jwtAuthenticator{
@@ -0,0 +1,175 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	contentTypeTokenIntrospectionJWT = "application/token-introspection+jwt"
+	tokenIntrospectionJWTType        = "token-introspection+jwt"
+)
+
+var errIntrospectionResponseInvalid = errors.New("introspection response invalid")
+
+// jwtIntrospectionResponse verifies JWT-formatted introspection responses according to RFC 9701.
+type jwtIntrospectionResponse struct {
+	Audience          string   `mapstructure:"audience"           validate:"required"`
+	AllowedAlgorithms []string `mapstructure:"allowed_algorithms"`
+}
+
+type jwtIntrospectionResponseClaims struct {
+	jwt.Claims
+
+	TokenIntrospection json.RawMessage `json:"token_introspection"`
+}
+
+func (r *jwtIntrospectionResponse) init() {
+	if len(r.AllowedAlgorithms) == 0 {
+		r.AllowedAlgorithms = defaultAllowedAlgorithms()
+	}
+}
+
+// verify verifies the signature of the given introspection response using the keys from the jwks_uri of
+// the authorization server, as well as its iss and aud claims. On success, the contents of the
+// token_introspection claim are returned.
+func (r *jwtIntrospectionResponse) verify(
+	ctx heimdall.Context, rawResp []byte, metadata *oauth2.ServerMetadata, errCtx any,
+) ([]byte, error) {
+	algorithms := make([]jose.SignatureAlgorithm, len(r.AllowedAlgorithms))
+	for idx, alg := range r.AllowedAlgorithms {
+		algorithms[idx] = jose.SignatureAlgorithm(alg)
+	}
+
+	token, err := jwt.ParseSigned(strings.TrimSpace(stringx.ToString(rawResp)), algorithms)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(errIntrospectionResponseInvalid, "failed to parse response").
+			CausedBy(err)
+	}
+
+	header := token.Headers[0]
+
+	typ, _ := header.ExtraHeaders[jose.HeaderType].(string)
+	if !strings.EqualFold(typ, tokenIntrospectionJWTType) && !strings.EqualFold(typ, contentTypeTokenIntrospectionJWT) {
+		return nil, errorchain.NewWithMessagef(errIntrospectionResponseInvalid, "unexpected response type '%s'", typ)
+	}
+
+	keys, err := r.keys(ctx, header.KeyID, metadata.JWKSEndpoint, errCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, errorchain.NewWithMessage(errIntrospectionResponseInvalid, "no keys available for verification")
+	}
+
+	var claims jwtIntrospectionResponseClaims
+
+	for idx := range keys {
+		if err = token.Claims(keys[idx].Key, &claims); err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		return nil, errorchain.NewWithMessage(errIntrospectionResponseInvalid,
+			"failed to verify response signature").CausedBy(err)
+	}
+
+	if err = claims.Validate(jwt.Expected{
+		Issuer:      metadata.Issuer,
+		AnyAudience: jwt.Audience{r.Audience},
+	}); err != nil {
+		return nil, errorchain.NewWithMessage(errIntrospectionResponseInvalid, "response claims are invalid").
+			CausedBy(err)
+	}
+
+	if len(claims.TokenIntrospection) == 0 {
+		return nil, errorchain.NewWithMessage(errIntrospectionResponseInvalid,
+			"response has no token_introspection claim")
+	}
+
+	return claims.TokenIntrospection, nil
+}
+
+// keys returns the key referenced by the given key id, or all keys from the JWKS if no key id is present.
+// Only referenced keys are cached.
+func (r *jwtIntrospectionResponse) keys(
+	ctx heimdall.Context, keyID string, ep *endpoint.Endpoint, errCtx any,
+) ([]jose.JSONWebKey, error) {
+	cch := cache.Ctx(ctx.AppContext())
+	logger := zerolog.Ctx(ctx.AppContext())
+
+	req, err := ep.CreateRequest(ctx.AppContext(), nil, nil)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed creating request").
+			WithErrorContext(errCtx).
+			CausedBy(err)
+	}
+
+	cacheKey := jwkCacheKey(ep, req.URL.String(), keyID)
+
+	if len(keyID) != 0 {
+		if entry, err := cch.Get(ctx.AppContext(), cacheKey); err == nil {
+			var jwk jose.JSONWebKey
+
+			if err = json.Unmarshal(entry, &jwk); err == nil {
+				logger.Debug().Msg("Reusing JWK from cache")
+
+				return []jose.JSONWebKey{jwk}, nil
+			}
+		}
+	}
+
+	jwks, err := fetchJWKS(ctx.AppContext(), ep.CreateClient(req.URL.Hostname()), req, errCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keyID) == 0 {
+		return jwks.Keys, nil
+	}
+
+	keys := jwks.Key(keyID)
+	if len(keys) != 1 {
+		return nil, errorchain.NewWithMessagef(errIntrospectionResponseInvalid,
+			"no (unique) key found for the keyID='%s' referenced in the response", keyID)
+	}
+
+	data, _ := json.Marshal(&keys[0])
+
+	if err = cch.Set(ctx.AppContext(), cacheKey, data, defaultJWTAuthenticatorTTL); err != nil {
+		logger.Warn().Err(err).Msg("Failed to cache JWK")
+	}
+
+	return keys, nil
+}
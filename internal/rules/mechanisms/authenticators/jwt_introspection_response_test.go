@@ -0,0 +1,357 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+)
+
+func createIntrospectionResponseJWT(
+	t *testing.T, key *ecdsa.PrivateKey, kid, typ string, claims map[string]any,
+) []byte {
+	t.Helper()
+
+	opts := (&jose.SignerOptions{}).WithType(jose.ContentType(typ))
+	if len(kid) != 0 {
+		opts = opts.WithHeader("kid", kid)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, opts)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+
+	return []byte(token)
+}
+
+func TestJWTIntrospectionResponseInit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	configured := &jwtIntrospectionResponse{Audience: "foo", AllowedAlgorithms: []string{"ES256"}}
+	defaulted := &jwtIntrospectionResponse{Audience: "foo"}
+
+	// WHEN
+	configured.init()
+	defaulted.init()
+
+	// THEN
+	assert.Equal(t, []string{"ES256"}, configured.AllowedAlgorithms)
+	assert.ElementsMatch(t, defaultAllowedAlgorithms(), defaulted.AllowedAlgorithms)
+}
+
+func TestJWTIntrospectionResponseVerify(t *testing.T) {
+	t.Parallel()
+
+	var jwksEndpointCalled bool
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: signingKey.Public(), KeyID: "sig", Algorithm: string(jose.ES256), Use: "sig"},
+	}})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		jwksEndpointCalled = true
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(jwks)
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss": "https://as.example.com",
+			"aud": "heimdall",
+			"iat": time.Now().Unix(),
+			"token_introspection": map[string]any{
+				"active": true,
+				"sub":    "foo",
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		uc       string
+		ep       *endpoint.Endpoint
+		response func(t *testing.T) []byte
+		assert   func(t *testing.T, err error, resp []byte)
+	}{
+		{
+			uc:       "response is not a JWT",
+			response: func(*testing.T) []byte { return []byte(`{"active":true}`) },
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errIntrospectionResponseInvalid)
+				require.ErrorContains(t, err, "failed to parse")
+				assert.False(t, jwksEndpointCalled)
+			},
+		},
+		{
+			uc: "response with unexpected type",
+			response: func(t *testing.T) []byte {
+				t.Helper()
+
+				return createIntrospectionResponseJWT(t, signingKey, "sig", "JWT", validClaims())
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errIntrospectionResponseInvalid)
+				require.ErrorContains(t, err, "unexpected response type")
+				assert.False(t, jwksEndpointCalled)
+			},
+		},
+		{
+			uc: "jwks endpoint not reachable",
+			ep: &endpoint.Endpoint{URL: "http://heimdall.test.local", Method: http.MethodGet},
+			response: func(t *testing.T) []byte {
+				t.Helper()
+
+				return createIntrospectionResponseJWT(t, signingKey, "sig", tokenIntrospectionJWTType, validClaims())
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				require.ErrorContains(t, err, "JWKS endpoint failed")
+			},
+		},
+		{
+			uc: "response referencing unknown key",
+			response: func(t *testing.T) []byte {
+				t.Helper()
+
+				return createIntrospectionResponseJWT(t, signingKey, "foo", tokenIntrospectionJWTType, validClaims())
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errIntrospectionResponseInvalid)
+				require.ErrorContains(t, err, "no (unique) key found")
+				assert.True(t, jwksEndpointCalled)
+			},
+		},
+		{
+			uc: "response signed with other key",
+			response: func(t *testing.T) []byte {
+				t.Helper()
+
+				return createIntrospectionResponseJWT(t, otherKey, "", tokenIntrospectionJWTType, validClaims())
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errIntrospectionResponseInvalid)
+				require.ErrorContains(t, err, "failed to verify response signature")
+				assert.True(t, jwksEndpointCalled)
+			},
+		},
+		{
+			uc: "response issued by other issuer",
+			response: func(t *testing.T) []byte {
+				t.Helper()
+
+				claims := validClaims()
+				claims["iss"] = "https://other.example.com"
+
+				return createIntrospectionResponseJWT(t, signingKey, "sig", tokenIntrospectionJWTType, claims)
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errIntrospectionResponseInvalid)
+				require.ErrorIs(t, err, jwt.ErrInvalidIssuer)
+			},
+		},
+		{
+			uc: "response issued for other audience",
+			response: func(t *testing.T) []byte {
+				t.Helper()
+
+				claims := validClaims()
+				claims["aud"] = "other"
+
+				return createIntrospectionResponseJWT(t, signingKey, "sig", tokenIntrospectionJWTType, claims)
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errIntrospectionResponseInvalid)
+				require.ErrorIs(t, err, jwt.ErrInvalidAudience)
+			},
+		},
+		{
+			uc: "response without token_introspection claim",
+			response: func(t *testing.T) []byte {
+				t.Helper()
+
+				claims := validClaims()
+				delete(claims, "token_introspection")
+
+				return createIntrospectionResponseJWT(t, signingKey, "sig", tokenIntrospectionJWTType, claims)
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errIntrospectionResponseInvalid)
+				require.ErrorContains(t, err, "no token_introspection claim")
+			},
+		},
+		{
+			uc: "valid response without key id",
+			response: func(t *testing.T) []byte {
+				t.Helper()
+
+				return createIntrospectionResponseJWT(t, signingKey, "", tokenIntrospectionJWTType, validClaims())
+			},
+			assert: func(t *testing.T, err error, resp []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, jwksEndpointCalled)
+				assert.JSONEq(t, `{"active":true,"sub":"foo"}`, string(resp))
+			},
+		},
+		{
+			uc: "valid response with media type as type and key id",
+			response: func(t *testing.T) []byte {
+				t.Helper()
+
+				return createIntrospectionResponseJWT(t, signingKey, "sig", contentTypeTokenIntrospectionJWT,
+					validClaims())
+			},
+			assert: func(t *testing.T, err error, resp []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, jwksEndpointCalled)
+				assert.JSONEq(t, `{"active":true,"sub":"foo"}`, string(resp))
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			jwksEndpointCalled = false
+
+			cch, err := memory.NewCache(nil, nil)
+			require.NoError(t, err)
+
+			ep := tc.ep
+			if ep == nil {
+				ep = &endpoint.Endpoint{URL: srv.URL, Method: http.MethodGet}
+			}
+
+			verifier := &jwtIntrospectionResponse{Audience: "heimdall"}
+			verifier.init()
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch)).Maybe()
+
+			// WHEN
+			resp, err := verifier.verify(ctx, tc.response(t), &oauth2.ServerMetadata{
+				Issuer:       "https://as.example.com",
+				JWKSEndpoint: ep,
+			}, nil)
+
+			// THEN
+			tc.assert(t, err, resp)
+		})
+	}
+}
+
+func TestJWTIntrospectionResponseVerifyReusesCachedKey(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var jwksEndpointCalls int
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: signingKey.Public(), KeyID: "sig", Algorithm: string(jose.ES256), Use: "sig"},
+	}})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		jwksEndpointCalls++
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(jwks)
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	cch, err := memory.NewCache(nil, nil)
+	require.NoError(t, err)
+
+	ctx := mocks.NewContextMock(t)
+	ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch))
+
+	verifier := &jwtIntrospectionResponse{Audience: "heimdall"}
+	verifier.init()
+
+	metadata := &oauth2.ServerMetadata{
+		Issuer:       "https://as.example.com",
+		JWKSEndpoint: &endpoint.Endpoint{URL: srv.URL, Method: http.MethodGet},
+	}
+
+	response := createIntrospectionResponseJWT(t, signingKey, "sig", tokenIntrospectionJWTType, map[string]any{
+		"iss":                 "https://as.example.com",
+		"aud":                 []string{"heimdall", "other"},
+		"token_introspection": map[string]any{"active": false},
+	})
+
+	// WHEN
+	_, err1 := verifier.verify(ctx, response, metadata, nil)
+	resp, err2 := verifier.verify(ctx, response, metadata, nil)
+
+	// THEN
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.JSONEq(t, `{"active":false}`, string(resp))
+	assert.Equal(t, 1, jwksEndpointCalls)
+}
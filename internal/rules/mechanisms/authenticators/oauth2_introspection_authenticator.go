@@ -17,7 +17,6 @@
 package authenticators
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -49,7 +48,7 @@ import (
 //nolint:gochecknoinits
 func init() {
 	registerTypeFactory(
-		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+		func(_ CreationContext, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
 			if typ != AuthenticatorOAuth2Introspection {
 				return false, nil, nil
 			}
@@ -68,6 +67,9 @@ type oauth2IntrospectionAuthenticator struct {
 	ads                  extractors.AuthDataExtractStrategy
 	ttl                  *time.Duration
 	allowFallbackOnError bool
+	dpop                 *dpopVerifier
+	cb                   *certificateBinding
+	jr                   *jwtIntrospectionResponse
 }
 
 func newOAuth2IntrospectionAuthenticator( // nolint: funlen
@@ -81,6 +83,9 @@ func newOAuth2IntrospectionAuthenticator( // nolint: funlen
 		AuthDataSource        extractors.CompositeExtractStrategy `mapstructure:"token_source"`
 		CacheTTL              *time.Duration                      `mapstructure:"cache_ttl"`
 		AllowFallbackOnError  bool                                `mapstructure:"allow_fallback_on_error"`
+		DPoP                  *dpopVerifier                       `mapstructure:"dpop"`
+		CertificateBinding    *certificateBinding                 `mapstructure:"certificate_binding"`
+		JWTResponse           *jwtIntrospectionResponse           `mapstructure:"jwt_response"            validate:"excluded_without=MetadataEndpoint"` //nolint:lll,tagalign
 	}
 
 	var conf Config
@@ -116,6 +121,22 @@ func newOAuth2IntrospectionAuthenticator( // nolint: funlen
 		func() extractors.CompositeExtractStrategy { return conf.AuthDataSource },
 	)
 
+	if conf.DPoP != nil {
+		conf.DPoP.init()
+
+		if conf.AuthDataSource == nil {
+			ads = withDPoPTokenSource(ads)
+		}
+	}
+
+	if conf.CertificateBinding != nil {
+		conf.CertificateBinding.init()
+	}
+
+	if conf.JWTResponse != nil {
+		conf.JWTResponse.init()
+	}
+
 	resolver := x.IfThenElseExec(conf.MetadataEndpoint != nil,
 		func() oauth2.ServerMetadataResolver { return conf.MetadataEndpoint },
 		func() oauth2.ServerMetadataResolver {
@@ -153,6 +174,9 @@ func newOAuth2IntrospectionAuthenticator( // nolint: funlen
 		sf:                   &conf.SubjectInfo,
 		ttl:                  conf.CacheTTL,
 		allowFallbackOnError: conf.AllowFallbackOnError,
+		dpop:                 conf.DPoP,
+		cb:                   conf.CertificateBinding,
+		jr:                   conf.JWTResponse,
 	}, nil
 }
 
@@ -173,6 +197,24 @@ func (a *oauth2IntrospectionAuthenticator) Execute(ctx heimdall.Context) (*subje
 		return nil, err
 	}
 
+	if a.dpop != nil {
+		if err = a.dpop.verify(ctx, accessToken, rawResp); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "DPoP verification failed").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+	}
+
+	if a.cb != nil {
+		if err = a.cb.verify(ctx, &a.a, rawResp); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "certificate binding verification failed").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+	}
+
 	sub, err := a.sf.CreateSubject(rawResp)
 	if err != nil {
 		return nil, errorchain.
@@ -212,6 +254,9 @@ func (a *oauth2IntrospectionAuthenticator) WithConfig(rawConfig map[string]any)
 		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
 			func() bool { return *conf.AllowFallbackOnError },
 			func() bool { return a.allowFallbackOnError }),
+		dpop: a.dpop,
+		cb:   a.cb,
+		jr:   a.jr,
 	}, nil
 }
 
@@ -244,6 +289,12 @@ func (a *oauth2IntrospectionAuthenticator) serverMetadata(
 			WithErrorContext(a)
 	}
 
+	if a.jr != nil && metadata.JWKSEndpoint == nil {
+		return oauth2.ServerMetadata{}, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"received server metadata does not contain the required jwks_uri").
+			WithErrorContext(a)
+	}
+
 	return metadata, nil
 }
 
@@ -289,7 +340,7 @@ func (a *oauth2IntrospectionAuthenticator) getSubjectInformation(ctx heimdall.Co
 		}
 	}
 
-	introspectResp, rawResp, err := a.fetchTokenIntrospectionResponse(
+	rawResp, err := a.fetchTokenIntrospectionResponse(
 		ctx,
 		metadata.IntrospectionEndpoint.CreateClient(req.URL.Hostname()),
 		req,
@@ -298,6 +349,24 @@ func (a *oauth2IntrospectionAuthenticator) getSubjectInformation(ctx heimdall.Co
 		return nil, err
 	}
 
+	if a.jr != nil {
+		if rawResp, err = a.jr.verify(ctx, rawResp, &metadata, a); err != nil {
+			if errors.Is(err, errIntrospectionResponseInvalid) {
+				return nil, errorchain.
+					NewWithMessage(heimdall.ErrInternal, "failed to verify introspection response").
+					WithErrorContext(a).
+					CausedBy(err)
+			}
+
+			return nil, err
+		}
+	}
+
+	introspectResp, err := a.decodeIntrospectionResponse(rawResp)
+	if err != nil {
+		return nil, err
+	}
+
 	// configured assertions take precedence over those available in the metadata
 	assertions := a.a.Merge(&oauth2.Expectation{
 		TrustedIssuers: []string{metadata.Issuer},
@@ -352,12 +421,16 @@ func (a *oauth2IntrospectionAuthenticator) createRequest(
 			CausedBy(err)
 	}
 
+	if a.jr != nil {
+		req.Header.Set("Accept", contentTypeTokenIntrospectionJWT)
+	}
+
 	return req, nil
 }
 
 func (a *oauth2IntrospectionAuthenticator) fetchTokenIntrospectionResponse(
 	ctx heimdall.Context, client *http.Client, req *http.Request,
-) (*oauth2.IntrospectionResponse, []byte, error) {
+) ([]byte, error) {
 	logger := zerolog.Ctx(ctx.AppContext())
 
 	logger.Debug().Msg("Retrieving information about the access token from the introspection endpoint")
@@ -366,14 +439,14 @@ func (a *oauth2IntrospectionAuthenticator) fetchTokenIntrospectionResponse(
 	if err != nil {
 		var clientErr *url.Error
 		if errors.As(err, &clientErr) && clientErr.Timeout() {
-			return nil, nil, errorchain.
+			return nil, errorchain.
 				NewWithMessage(heimdall.ErrCommunicationTimeout,
 					"request to the introspection endpoint timed out").
 				WithErrorContext(a).
 				CausedBy(err)
 		}
 
-		return nil, nil, errorchain.
+		return nil, errorchain.
 			NewWithMessage(heimdall.ErrCommunication, "request to the introspection endpoint failed").
 			WithErrorContext(a).
 			CausedBy(err)
@@ -384,28 +457,37 @@ func (a *oauth2IntrospectionAuthenticator) fetchTokenIntrospectionResponse(
 	return a.readIntrospectionResponse(resp)
 }
 
-func (a *oauth2IntrospectionAuthenticator) readIntrospectionResponse(
-	resp *http.Response,
-) (*oauth2.IntrospectionResponse, []byte, error) {
+func (a *oauth2IntrospectionAuthenticator) readIntrospectionResponse(resp *http.Response) ([]byte, error) {
 	if !(resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices) {
-		return nil, nil, errorchain.
+		return nil, errorchain.
 			NewWithMessagef(heimdall.ErrCommunication, "unexpected response code: %v", resp.StatusCode).
 			WithErrorContext(a)
 	}
 
-	var (
-		introspectionResponse oauth2.IntrospectionResponse
-		buf                   bytes.Buffer
-	)
+	rawResp, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "failed to read introspection response").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return rawResp, nil
+}
+
+func (a *oauth2IntrospectionAuthenticator) decodeIntrospectionResponse(
+	rawResp []byte,
+) (*oauth2.IntrospectionResponse, error) {
+	var introspectionResponse oauth2.IntrospectionResponse
 
-	if err := json.NewDecoder(io.TeeReader(resp.Body, &buf)).Decode(&introspectionResponse); err != nil {
-		return nil, nil, errorchain.
+	if err := json.Unmarshal(rawResp, &introspectionResponse); err != nil {
+		return nil, errorchain.
 			NewWithMessage(heimdall.ErrInternal, "failed to unmarshal received introspection response").
 			WithErrorContext(a).
 			CausedBy(err)
 	}
 
-	return &introspectionResponse, buf.Bytes(), nil
+	return &introspectionResponse, nil
 }
 
 func (a *oauth2IntrospectionAuthenticator) isCacheEnabled() bool {
@@ -18,6 +18,10 @@ package authenticators
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net/http"
@@ -26,6 +30,7 @@ import (
 	"time"
 
 	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -326,6 +331,127 @@ metadata_endpoint:
 				assert.Equal(t, "auth1", auth.ID())
 			},
 		},
+		{
+			uc: "with introspection endpoint based config and dpop support using defaults",
+			id: "auth1",
+			config: []byte(`
+introspection_endpoint:
+  url: http://foobar.local
+assertions:
+  issuers:
+    - foobar
+dpop: {}
+`),
+			assert: func(t *testing.T, err error, auth *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Len(t, auth.ads, 4)
+				assert.Contains(t, auth.ads, extractors.HeaderValueExtractStrategy{Name: "Authorization", Scheme: "DPoP"})
+
+				require.NotNil(t, auth.dpop)
+				assert.False(t, auth.dpop.Required)
+				assert.NotEmpty(t, auth.dpop.AllowedAlgorithms)
+				assert.Equal(t, defaultDPoPProofMaxAge, *auth.dpop.MaxAge)
+			},
+		},
+		{
+			uc: "with introspection endpoint based config and certificate binding using defaults",
+			id: "auth1",
+			config: []byte(`
+introspection_endpoint:
+  url: http://foobar.local
+assertions:
+  issuers:
+    - foobar
+certificate_binding: {}
+`),
+			assert: func(t *testing.T, err error, auth *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, auth.cb)
+				assert.False(t, auth.cb.Required)
+				assert.Nil(t, auth.cb.ForwardedClientCert)
+			},
+		},
+		{
+			uc: "with certificate binding using forwarded client certificate without header name",
+			id: "auth1",
+			config: []byte(`
+introspection_endpoint:
+  url: http://foobar.local
+assertions:
+  issuers:
+    - foobar
+certificate_binding:
+  forwarded_client_cert:
+    format: xfcc
+`),
+			assert: func(t *testing.T, err error, _ *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc: "with jwt response used together with introspection endpoint",
+			id: "auth1",
+			config: []byte(`
+introspection_endpoint:
+  url: http://foobar.local
+assertions:
+  issuers:
+    - foobar
+jwt_response:
+  audience: heimdall
+`),
+			assert: func(t *testing.T, err error, _ *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "jwt_response")
+			},
+		},
+		{
+			uc: "with jwt response without audience",
+			id: "auth1",
+			config: []byte(`
+metadata_endpoint:
+  url: http://foobar.local
+jwt_response: {}
+`),
+			assert: func(t *testing.T, err error, _ *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "audience")
+			},
+		},
+		{
+			uc: "with metadata endpoint based config and jwt response using defaults",
+			id: "auth1",
+			config: []byte(`
+metadata_endpoint:
+  url: http://foobar.local
+jwt_response:
+  audience: heimdall
+`),
+			assert: func(t *testing.T, err error, auth *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, auth.jr)
+				assert.Equal(t, "heimdall", auth.jr.Audience)
+				assert.ElementsMatch(t, defaultAllowedAlgorithms(), auth.jr.AllowedAlgorithms)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -662,8 +788,23 @@ func TestOauth2IntrospectionAuthenticatorExecute(t *testing.T) {
 		w.WriteHeader(metadataResponseCode)
 	}))
 
+	responseSigningKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: responseSigningKey.Public(), KeyID: "sig", Algorithm: string(jose.ES256), Use: "sig"},
+	}})
+	require.NoError(t, err)
+
+	jwksSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(jwks)
+		require.NoError(t, err)
+	}))
+
 	defer srv.Close()
 	defer oidcSrv.Close()
+	defer jwksSrv.Close()
 
 	for _, tc := range []struct {
 		uc             string
@@ -1564,6 +1705,228 @@ func TestOauth2IntrospectionAuthenticatorExecute(t *testing.T) {
 				assert.NotEmpty(t, sub.Attributes["exp"])
 			},
 		},
+		{
+			uc: "with disabled cache and certificate bound token presented with other certificate",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				r: oauth2.ResolverAdapterFunc(func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
+					return oauth2.ServerMetadata{
+						IntrospectionEndpoint: &endpoint.Endpoint{
+							URL:    srv.URL,
+							Method: http.MethodPost,
+							Headers: map[string]string{
+								"Content-Type": "application/x-www-form-urlencoded",
+								"Accept":       "application/json",
+							},
+						},
+					}, nil
+				}),
+				a: oauth2.Expectation{
+					TrustedIssuers: []string{"foobar"},
+					ScopesMatcher:  oauth2.ExactScopeStrategyMatcher{},
+				},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &zeroTTL,
+				cb:  &certificateBinding{},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{{Raw: []byte("bar")}},
+				})
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				rawIntrospectResponse, err := json.Marshal(map[string]any{
+					"active": true,
+					"sub":    "foo",
+					"iss":    "foobar",
+					"exp":    time.Now().Unix() + 30,
+					"cnf":    map[string]any{"x5t#S256": "bwcfgjkPx_Ji0Zfy-8w0YfeChEuxHVchZySw3RJaMBE"},
+				})
+				require.NoError(t, err)
+
+				introspectionResponseContentType = "application/json"
+				introspectionResponseContent = rawIntrospectResponse
+				introspectionResponseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, introspectionEndpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, oauth2.ErrAssertion)
+				require.ErrorContains(t, err, "certificate binding verification failed")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
+		{
+			uc: "with disabled cache and successful execution using jwt introspection response",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				r: oauth2.ResolverAdapterFunc(func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
+					return oauth2.ServerMetadata{
+						Issuer: "foobar",
+						IntrospectionEndpoint: &endpoint.Endpoint{
+							URL:    srv.URL,
+							Method: http.MethodPost,
+							Headers: map[string]string{
+								"Content-Type": "application/x-www-form-urlencoded",
+								"Accept":       "application/json",
+							},
+						},
+						JWKSEndpoint: &endpoint.Endpoint{URL: jwksSrv.URL, Method: http.MethodGet},
+					}, nil
+				}),
+				a:   oauth2.Expectation{ScopesMatcher: oauth2.ExactScopeStrategyMatcher{}},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &zeroTTL,
+				jr: &jwtIntrospectionResponse{
+					Audience:          "heimdall",
+					AllowedAlgorithms: []string{string(jose.ES256)},
+				},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				// JWK cache
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+				cch.EXPECT().Set(mock.Anything, mock.Anything, mock.Anything, defaultJWTAuthenticatorTTL).Return(nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				checkIntrospectionRequest = func(req *http.Request) {
+					t.Helper()
+
+					assert.Equal(t, "application/token-introspection+jwt", req.Header.Get("Accept"))
+					assert.Equal(t, http.MethodPost, req.Method)
+				}
+
+				signer, err := jose.NewSigner(
+					jose.SigningKey{Algorithm: jose.ES256, Key: responseSigningKey},
+					(&jose.SignerOptions{}).WithType("token-introspection+jwt").WithHeader("kid", "sig"),
+				)
+				require.NoError(t, err)
+
+				rawIntrospectResponse, err := jwt.Signed(signer).Claims(map[string]any{
+					"iss": "foobar",
+					"aud": "heimdall",
+					"iat": time.Now().Unix(),
+					"token_introspection": map[string]any{
+						"active":     true,
+						"scope":      "foo bar",
+						"token_type": "Bearer",
+						"sub":        "foo",
+						"iss":        "foobar",
+						"exp":        time.Now().Unix() + 30,
+					},
+				}).Serialize()
+				require.NoError(t, err)
+
+				introspectionResponseContentType = "application/token-introspection+jwt"
+				introspectionResponseContent = []byte(rawIntrospectResponse)
+				introspectionResponseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, introspectionEndpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, "foo", sub.ID)
+				require.Len(t, sub.Attributes, 6)
+				assert.Equal(t, "foo bar", sub.Attributes["scope"])
+				assert.Equal(t, "foobar", sub.Attributes["iss"])
+				assert.NotContains(t, sub.Attributes, "token_introspection")
+			},
+		},
+		{
+			uc: "with disabled cache and jwt introspection response issued for other audience",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				r: oauth2.ResolverAdapterFunc(func(_ context.Context, _ map[string]any) (oauth2.ServerMetadata, error) {
+					return oauth2.ServerMetadata{
+						Issuer:                "foobar",
+						IntrospectionEndpoint: &endpoint.Endpoint{URL: srv.URL, Method: http.MethodPost},
+						JWKSEndpoint:          &endpoint.Endpoint{URL: jwksSrv.URL, Method: http.MethodGet},
+					}, nil
+				}),
+				a:   oauth2.Expectation{ScopesMatcher: oauth2.ExactScopeStrategyMatcher{}},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &zeroTTL,
+				jr: &jwtIntrospectionResponse{
+					Audience:          "heimdall",
+					AllowedAlgorithms: []string{string(jose.ES256)},
+				},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				cch.EXPECT().Get(mock.Anything, mock.Anything).Return(nil, errors.New("no cache entry"))
+				cch.EXPECT().Set(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				signer, err := jose.NewSigner(
+					jose.SigningKey{Algorithm: jose.ES256, Key: responseSigningKey},
+					(&jose.SignerOptions{}).WithType("token-introspection+jwt").WithHeader("kid", "sig"),
+				)
+				require.NoError(t, err)
+
+				rawIntrospectResponse, err := jwt.Signed(signer).Claims(map[string]any{
+					"iss":                 "foobar",
+					"aud":                 "other",
+					"token_introspection": map[string]any{"active": true, "sub": "foo"},
+				}).Serialize()
+				require.NoError(t, err)
+
+				introspectionResponseContentType = "application/token-introspection+jwt"
+				introspectionResponseContent = []byte(rawIntrospectResponse)
+				introspectionResponseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, introspectionEndpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorIs(t, err, errIntrospectionResponseInvalid)
+				require.ErrorContains(t, err, "failed to verify introspection response")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
 		{
 			uc: "with default cache, with cache hit and successful execution",
 			authenticator: &oauth2IntrospectionAuthenticator{
@@ -0,0 +1,853 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/revocation"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/endpoint/authstrategy"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/oauth2/clientcredentials"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+	"github.com/dadrus/heimdall/internal/x/syncx"
+)
+
+const (
+	defaultOIDCSessionTTL       = 24 * time.Hour
+	oidcLoginStateTTL           = 10 * time.Minute
+	oidcTokenRefreshLeeway      = 30 * time.Second
+	oidcTokenRefreshTimeout     = 10 * time.Second
+	oidcBackChannelLogoutEvent  = "http://schemas.openid.net/event/backchannel-logout"
+	oidcRandomValueLength       = 32
+	oidcSessionCacheKeyPrefixID = "oidc_session"
+)
+
+var (
+	errNoOIDCSession     = errors.New("no valid session")
+	errNoVerificationKey = errors.New("no key found to verify the token")
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(_ CreationContext, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorOIDCSession {
+				return false, nil, nil
+			}
+
+			auth, err := newOIDCSessionAuthenticator(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type oidcLogout struct {
+	Path                  string `mapstructure:"path"                     validate:"required"`
+	PostLogoutRedirectURI string `mapstructure:"post_logout_redirect_uri" validate:"omitempty,url"`
+}
+
+type oidcBackChannelLogout struct {
+	Path string `mapstructure:"path" validate:"required"`
+}
+
+// oidcLoginState is held in the encrypted state cookie while the authorization code flow is ongoing.
+type oidcLoginState struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	TargetURL    string    `json:"target_url"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// oidcSessionReference is held in the encrypted session cookie and references the session in the cache.
+type oidcSessionReference struct {
+	ID string `json:"id"`
+}
+
+type oidcSession struct {
+	IDToken      string          `json:"id_token"`
+	AccessToken  string          `json:"access_token"`
+	RefreshToken string          `json:"refresh_token,omitempty"`
+	Expiry       time.Time       `json:"expiry"`
+	NotAfter     time.Time       `json:"not_after"`
+	Claims       json.RawMessage `json:"claims"`
+}
+
+type oidcTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type oidcSessionAuthenticator struct {
+	id                   string
+	r                    oauth2.ServerMetadataResolver
+	clientID             string
+	clientSecret         string
+	redirectURL          *url.URL
+	scopes               []string
+	cookie               *oidcSessionCookie
+	sessionTTL           time.Duration
+	logout               *oidcLogout
+	backChannelLogout    *oidcBackChannelLogout
+	algorithms           []jose.SignatureAlgorithm
+	sf                   SubjectFactory
+	allowFallbackOnError bool
+	group                *singleflight.Group
+}
+
+func newOIDCSessionAuthenticator( // nolint: funlen
+	id string, rawConfig map[string]any,
+) (*oidcSessionAuthenticator, error) {
+	type Config struct {
+		MetadataEndpoint     *oauth2.MetadataEndpoint `mapstructure:"metadata_endpoint"       validate:"required"`
+		ClientID             string                   `mapstructure:"client_id"               validate:"required"`
+		ClientSecret         string                   `mapstructure:"client_secret"`
+		RedirectURI          string                   `mapstructure:"redirect_uri"            validate:"required,url"`
+		Scopes               []string                 `mapstructure:"scopes"`
+		Cookie               oidcSessionCookie        `mapstructure:"cookie"`
+		SessionTTL           *time.Duration           `mapstructure:"session_ttl"`
+		Logout               *oidcLogout              `mapstructure:"logout"`
+		BackChannelLogout    *oidcBackChannelLogout   `mapstructure:"back_channel_logout"`
+		AllowedAlgorithms    []string                 `mapstructure:"allowed_algorithms"`
+		SubjectInfo          SubjectInfo              `mapstructure:"subject"                 validate:"-"`
+		AllowFallbackOnError bool                     `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorOIDCSession, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	redirectURL, err := url.Parse(conf.RedirectURI)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to parse redirect_uri").
+			CausedBy(err)
+	}
+
+	if conf.SessionTTL != nil && *conf.SessionTTL <= 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "session_ttl must be greater than 0")
+	}
+
+	if len(conf.AllowedAlgorithms) == 0 {
+		conf.AllowedAlgorithms = defaultAllowedAlgorithms()
+	}
+
+	if !slices.Contains(conf.Scopes, "openid") {
+		conf.Scopes = append([]string{"openid"}, conf.Scopes...)
+	}
+
+	if len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = "sub"
+	}
+
+	conf.Cookie.init()
+
+	algorithms := make([]jose.SignatureAlgorithm, len(conf.AllowedAlgorithms))
+	for idx, alg := range conf.AllowedAlgorithms {
+		algorithms[idx] = jose.SignatureAlgorithm(alg)
+	}
+
+	return &oidcSessionAuthenticator{
+		id:                id,
+		r:                 conf.MetadataEndpoint,
+		clientID:          conf.ClientID,
+		clientSecret:      conf.ClientSecret,
+		redirectURL:       redirectURL,
+		scopes:            conf.Scopes,
+		cookie:            &conf.Cookie,
+		logout:            conf.Logout,
+		backChannelLogout: conf.BackChannelLogout,
+		algorithms:        algorithms,
+		sf:                &conf.SubjectInfo,
+		sessionTTL: x.IfThenElseExec(conf.SessionTTL != nil,
+			func() time.Duration { return *conf.SessionTTL },
+			func() time.Duration { return defaultOIDCSessionTTL }),
+		allowFallbackOnError: conf.AllowFallbackOnError,
+		group:                &singleflight.Group{},
+	}, nil
+}
+
+func (a *oidcSessionAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using oidc_session authenticator")
+
+	metadata, err := a.serverMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch path := ctx.Request().URL.Path; {
+	case path == a.redirectURL.Path:
+		return nil, a.finishLogin(ctx, metadata)
+	case a.logout != nil && path == a.logout.Path:
+		return nil, a.endSession(ctx, metadata)
+	case a.backChannelLogout != nil && path == a.backChannelLogout.Path:
+		return nil, a.handleBackChannelLogout(ctx, metadata)
+	}
+
+	sess, err := a.currentSession(ctx, metadata)
+	if errors.Is(err, errNoOIDCSession) {
+		logger.Debug().Err(err).Msg("Starting login")
+
+		return nil, a.startLogin(ctx, metadata)
+	} else if err != nil {
+		return nil, err
+	}
+
+	sub, err := a.sf.CreateSubject(sess.Claims)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to extract subject information from id token").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return sub, nil
+}
+
+func (a *oidcSessionAuthenticator) WithConfig(config map[string]any) (Authenticator, error) {
+	// this authenticator allows only the fallback behavior to be redefined on the rule level
+	if len(config) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		AllowFallbackOnError *bool `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorOIDCSession, config, &conf); err != nil {
+		return nil, err
+	}
+
+	auth := *a
+	auth.allowFallbackOnError = x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+		func() bool { return *conf.AllowFallbackOnError },
+		func() bool { return a.allowFallbackOnError })
+
+	return &auth, nil
+}
+
+func (a *oidcSessionAuthenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *oidcSessionAuthenticator) ID() string {
+	return a.id
+}
+
+func (a *oidcSessionAuthenticator) serverMetadata(ctx heimdall.Context) (oauth2.ServerMetadata, error) {
+	metadata, err := a.r.Get(ctx.AppContext(), nil)
+	if err != nil {
+		return oauth2.ServerMetadata{}, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed retrieving oauth2 server metadata").CausedBy(err).WithErrorContext(a)
+	}
+
+	for name, ep := range map[string]*endpoint.Endpoint{
+		"authorization_endpoint": metadata.AuthorizationEndpoint,
+		"token_endpoint":         metadata.TokenEndpoint,
+		"jwks_uri":               metadata.JWKSEndpoint,
+	} {
+		if ep == nil {
+			return oauth2.ServerMetadata{}, errorchain.NewWithMessagef(heimdall.ErrInternal,
+				"received server metadata does not contain the required %s", name).
+				WithErrorContext(a)
+		}
+	}
+
+	return metadata, nil
+}
+
+// startLogin redirects the user agent to the authorization endpoint to start the authorization code
+// flow with PKCE. The state of the flow is held in an encrypted cookie until the callback is received.
+func (a *oidcSessionAuthenticator) startLogin(ctx heimdall.Context, metadata oauth2.ServerMetadata) error {
+	state := oidcLoginState{
+		State:        randomValue(),
+		Nonce:        randomValue(),
+		CodeVerifier: randomValue(),
+		TargetURL:    ctx.Request().URL.String(),
+		Expiry:       time.Now().Add(oidcLoginStateTTL),
+	}
+
+	stateCookieValue, err := a.cookie.seal(&state)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to create state cookie").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint.URL)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to parse authorization_endpoint").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	challenge := sha256.Sum256(stringx.ToBytes(state.CodeVerifier))
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", a.clientID)
+	query.Set("redirect_uri", a.redirectURL.String())
+	query.Set("scope", strings.Join(a.scopes, " "))
+	query.Set("state", state.State)
+	query.Set("nonce", state.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return &heimdall.RedirectError{
+		Message:    "authentication required",
+		Code:       http.StatusFound,
+		RedirectTo: authURL.String(),
+		Cookies:    []*http.Cookie{a.cookie.create(a.cookie.stateCookieName(), stateCookieValue, oidcLoginStateTTL)},
+	}
+}
+
+// finishLogin handles the callback from the authorization server, exchanges the received code for
+// tokens, creates the session and redirects the user agent to the url, which initiated the login.
+func (a *oidcSessionAuthenticator) finishLogin( // nolint: funlen, cyclop
+	ctx heimdall.Context, metadata oauth2.ServerMetadata,
+) error {
+	logger := zerolog.Ctx(ctx.AppContext())
+	req := ctx.Request()
+	query := req.URL.Query()
+
+	var state oidcLoginState
+
+	rawState := req.Cookie(a.cookie.stateCookieName())
+	if len(rawState) == 0 || a.cookie.open(rawState, &state) != nil || time.Now().After(state.Expiry) {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "no valid login state present").
+			WithErrorContext(a)
+	}
+
+	if errCode := query.Get("error"); len(errCode) != 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrAuthentication,
+			"authorization server responded with error: %s, error_description: %s",
+			errCode, query.Get("error_description")).
+			WithErrorContext(a)
+	}
+
+	if query.Get("state") != state.State {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "state mismatch").
+			WithErrorContext(a)
+	}
+
+	code := query.Get("code")
+	if len(code) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "no authorization code present").
+			WithErrorContext(a)
+	}
+
+	tokens, err := a.requestTokens(ctx.AppContext(), metadata, url.Values{
+		"grant_type":    []string{"authorization_code"},
+		"code":          []string{code},
+		"redirect_uri":  []string{a.redirectURL.String()},
+		"code_verifier": []string{state.CodeVerifier},
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(tokens.IDToken) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "token response contains no id token").
+			WithErrorContext(a)
+	}
+
+	claims, err := a.verifyIDToken(ctx.AppContext(), metadata, tokens.IDToken, state.Nonce)
+	if err != nil {
+		return err
+	}
+
+	sessionID := randomValue()
+	sess := &oidcSession{
+		IDToken:      tokens.IDToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		Expiry:       tokens.expiry(),
+		NotAfter:     time.Now().Add(a.sessionTTL),
+		Claims:       claims,
+	}
+
+	if err = a.storeSession(ctx.AppContext(), sessionID, sess); err != nil {
+		return err
+	}
+
+	sessionCookieValue, err := a.cookie.seal(&oidcSessionReference{ID: sessionID})
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to create session cookie").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	logger.Debug().Msg("Session created")
+
+	return &heimdall.RedirectError{
+		Message:    "authenticated",
+		Code:       http.StatusFound,
+		RedirectTo: state.TargetURL,
+		Cookies: []*http.Cookie{
+			a.cookie.create(a.cookie.Name, sessionCookieValue, a.sessionTTL),
+			a.cookie.remove(a.cookie.stateCookieName()),
+		},
+	}
+}
+
+// endSession terminates the current session and redirects the user agent to the end_session_endpoint
+// of the authorization server if available, or to the configured post_logout_redirect_uri otherwise.
+func (a *oidcSessionAuthenticator) endSession(ctx heimdall.Context, metadata oauth2.ServerMetadata) error {
+	target := x.IfThenElse(len(a.logout.PostLogoutRedirectURI) != 0, a.logout.PostLogoutRedirectURI, "/")
+
+	sessionID, sess := a.loadSession(ctx)
+	if sess != nil {
+		a.invalidateSession(ctx.AppContext(), sessionID, sess)
+	}
+
+	if metadata.EndSessionEndpoint != nil {
+		endSessionURL, err := url.Parse(metadata.EndSessionEndpoint.URL)
+		if err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to parse end_session_endpoint").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+
+		query := endSessionURL.Query()
+		query.Set("client_id", a.clientID)
+
+		if len(a.logout.PostLogoutRedirectURI) != 0 {
+			query.Set("post_logout_redirect_uri", a.logout.PostLogoutRedirectURI)
+		}
+
+		if sess != nil {
+			query.Set("id_token_hint", sess.IDToken)
+		}
+
+		endSessionURL.RawQuery = query.Encode()
+		target = endSessionURL.String()
+	}
+
+	return &heimdall.RedirectError{
+		Message:    "logged out",
+		Code:       http.StatusFound,
+		RedirectTo: target,
+		Cookies:    []*http.Cookie{a.cookie.remove(a.cookie.Name)},
+	}
+}
+
+// handleBackChannelLogout implements the back-channel logout endpoint. As the sessions cannot be looked
+// up by the sid or sub claim, these are revoked instead for the configured session ttl. Sessions affected
+// by the revocation are dropped on their next use, so that the user agent can log in again.
+func (a *oidcSessionAuthenticator) handleBackChannelLogout(
+	ctx heimdall.Context, metadata oauth2.ServerMetadata,
+) error {
+	if ctx.Request().Method != http.MethodPost {
+		return errorchain.NewWithMessage(heimdall.ErrArgument, "back-channel logout requires POST").
+			WithErrorContext(a)
+	}
+
+	rawToken, err := extractors.BodyParameterExtractStrategy{Name: "logout_token"}.GetAuthData(ctx)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrArgument, "no logout token present").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	var (
+		claims jwt.Claims
+		extra  struct {
+			SessionID string                     `json:"sid"`
+			Nonce     *string                    `json:"nonce"`
+			Events    map[string]json.RawMessage `json:"events"`
+		}
+	)
+
+	if err = a.verifyToken(ctx.AppContext(), metadata, rawToken, &claims, &extra); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrArgument, "invalid logout token").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	var violation string
+
+	_, hasEvent := extra.Events[oidcBackChannelLogoutEvent]
+
+	switch {
+	case claims.IssuedAt == nil:
+		violation = "iat claim is missing"
+	case !hasEvent:
+		violation = "backchannel-logout event is missing"
+	case extra.Nonce != nil:
+		violation = "nonce claim is present"
+	case len(extra.SessionID) == 0 && len(claims.Subject) == 0:
+		violation = "neither sid nor sub claim is present"
+	}
+
+	if len(violation) != 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrArgument, "invalid logout token: %s", violation).
+			WithErrorContext(a)
+	}
+
+	typ, value := revocation.TypeSessionID, extra.SessionID
+	if len(value) == 0 {
+		typ, value = revocation.TypeSubject, claims.Subject
+	}
+
+	if err = revocation.Revoke(ctx.AppContext(), cache.Ctx(ctx.AppContext()), typ, value, a.sessionTTL); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to revoke session").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return &heimdall.ResponseError{Message: "logged out", Code: http.StatusOK}
+}
+
+// currentSession returns the session referenced by the session cookie. If the access token is about
+// to expire, the tokens are refreshed. If there is no session, or the refresh is not possible,
+// errNoOIDCSession is returned.
+func (a *oidcSessionAuthenticator) currentSession(
+	ctx heimdall.Context, metadata oauth2.ServerMetadata,
+) (*oidcSession, error) {
+	sessionID, sess := a.loadSession(ctx)
+	if sess == nil {
+		return nil, errNoOIDCSession
+	}
+
+	// a session revoked e.g. by a back-channel logout is dropped to let the user agent log in again
+	if a.isRevoked(ctx.AppContext(), sess) {
+		a.invalidateSession(ctx.AppContext(), sessionID, sess)
+
+		return nil, errorchain.NewWithMessage(errNoOIDCSession, "session has been revoked")
+	}
+
+	if sess.Expiry.IsZero() || time.Until(sess.Expiry) > oidcTokenRefreshLeeway {
+		return sess, nil
+	}
+
+	if len(sess.RefreshToken) == 0 {
+		return nil, errorchain.NewWithMessage(errNoOIDCSession, "tokens expired and no refresh token available")
+	}
+
+	// concurrent requests of the same user agent should not result in multiple refresh requests
+	refreshed, err := syncx.DoDetached(ctx.AppContext(), a.group, sessionID, oidcTokenRefreshTimeout,
+		func(refreshCtx context.Context) (*oidcSession, error) {
+			return a.refreshSession(refreshCtx, metadata, sessionID, sess)
+		})
+	if err != nil {
+		if errors.Is(err, heimdall.ErrAuthentication) {
+			return nil, errorchain.NewWithMessage(errNoOIDCSession, "failed to refresh tokens").CausedBy(err)
+		}
+
+		return nil, err
+	}
+
+	return refreshed, nil
+}
+
+func (a *oidcSessionAuthenticator) loadSession(ctx heimdall.Context) (string, *oidcSession) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	cch := cache.Ctx(ctx.AppContext())
+
+	rawCookie := ctx.Request().Cookie(a.cookie.Name)
+	if len(rawCookie) == 0 {
+		return "", nil
+	}
+
+	var ref oidcSessionReference
+	if err := a.cookie.open(rawCookie, &ref); err != nil || len(ref.ID) == 0 {
+		logger.Debug().Err(err).Msg("Invalid session cookie")
+
+		return "", nil
+	}
+
+	entry, err := cch.Get(ctx.AppContext(), a.sessionCacheKey(ref.ID))
+	if err != nil {
+		logger.Debug().Err(err).Msg("Session not found")
+
+		return "", nil
+	}
+
+	var sess oidcSession
+	if err = json.Unmarshal(entry, &sess); err != nil || len(sess.IDToken) == 0 {
+		return "", nil
+	}
+
+	return ref.ID, &sess
+}
+
+func (a *oidcSessionAuthenticator) isRevoked(ctx context.Context, sess *oidcSession) bool {
+	var claims map[string]any
+	if err := json.Unmarshal(sess.Claims, &claims); err != nil {
+		return false
+	}
+
+	sub, _ := claims["sub"].(string)
+
+	return revocation.IsRevoked(ctx, cache.Ctx(ctx), &subject.Subject{ID: sub, Attributes: claims})
+}
+
+// invalidateSession overwrites the session with an invalid entry, as the cache does not support
+// deletion of entries. That entry lives as long as the original session would have lived.
+func (a *oidcSessionAuthenticator) invalidateSession(ctx context.Context, sessionID string, sess *oidcSession) {
+	if ttl := time.Until(sess.NotAfter); ttl > 0 {
+		if err := cache.Ctx(ctx).Set(ctx, a.sessionCacheKey(sessionID), []byte("{}"), ttl); err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("Failed to invalidate session")
+		}
+	}
+}
+
+func (a *oidcSessionAuthenticator) refreshSession(
+	ctx context.Context, metadata oauth2.ServerMetadata, sessionID string, sess *oidcSession,
+) (*oidcSession, error) {
+	tokens, err := a.requestTokens(ctx, metadata, url.Values{
+		"grant_type":    []string{"refresh_token"},
+		"refresh_token": []string{sess.RefreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed := *sess
+	refreshed.AccessToken = tokens.AccessToken
+	refreshed.Expiry = tokens.expiry()
+
+	if len(tokens.RefreshToken) != 0 {
+		refreshed.RefreshToken = tokens.RefreshToken
+	}
+
+	if len(tokens.IDToken) != 0 {
+		claims, err := a.verifyIDToken(ctx, metadata, tokens.IDToken, "")
+		if err != nil {
+			return nil, err
+		}
+
+		refreshed.IDToken = tokens.IDToken
+		refreshed.Claims = claims
+	}
+
+	if err = a.storeSession(ctx, sessionID, &refreshed); err != nil {
+		return nil, err
+	}
+
+	return &refreshed, nil
+}
+
+func (a *oidcSessionAuthenticator) storeSession(ctx context.Context, sessionID string, sess *oidcSession) error {
+	ttl := time.Until(sess.NotAfter)
+	if ttl <= 0 {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "session expired").
+			WithErrorContext(a)
+	}
+
+	data, _ := json.Marshal(sess)
+
+	if err := cache.Ctx(ctx).Set(ctx, a.sessionCacheKey(sessionID), data, ttl); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to store session").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return nil
+}
+
+func (a *oidcSessionAuthenticator) requestTokens(
+	ctx context.Context, metadata oauth2.ServerMetadata, params url.Values,
+) (*oidcTokenResponse, error) {
+	ept := *metadata.TokenEndpoint
+
+	if len(a.clientSecret) != 0 {
+		ept.AuthStrategy = &authstrategy.BasicAuth{
+			User:     url.QueryEscape(a.clientID),
+			Password: url.QueryEscape(a.clientSecret),
+		}
+	} else {
+		params.Set("client_id", a.clientID)
+	}
+
+	rawData, err := ept.SendRequest(ctx, strings.NewReader(params.Encode()), nil,
+		func(resp *http.Response) ([]byte, error) {
+			rawData, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+					"failed to read response").CausedBy(err)
+			}
+
+			switch {
+			case resp.StatusCode == http.StatusOK:
+				return rawData, nil
+			case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized:
+				var ter clientcredentials.TokenErrorResponse
+				if err = json.Unmarshal(rawData, &ter); err != nil {
+					return nil, errorchain.NewWithMessagef(heimdall.ErrAuthentication,
+						"failed to fetch tokens: %s", stringx.ToString(rawData))
+				}
+
+				return nil, errorchain.New(heimdall.ErrAuthentication).CausedBy(&ter)
+			default:
+				return nil, errorchain.NewWithMessagef(heimdall.ErrCommunication,
+					"unexpected response code: %v", resp.StatusCode)
+			}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens oidcTokenResponse
+	if err = json.Unmarshal(rawData, &tokens); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to unmarshal token response").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return &tokens, nil
+}
+
+// verifyIDToken verifies the given id token and returns its claims. The nonce is verified only if
+// expected, as id tokens issued on refresh do not contain it.
+func (a *oidcSessionAuthenticator) verifyIDToken(
+	ctx context.Context, metadata oauth2.ServerMetadata, rawToken, nonce string,
+) (json.RawMessage, error) {
+	var (
+		claims jwt.Claims
+		extra  struct {
+			Nonce string `json:"nonce"`
+		}
+		rawClaims map[string]any
+	)
+
+	if err := a.verifyToken(ctx, metadata, rawToken, &claims, &extra, &rawClaims); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "invalid id token").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if len(nonce) != 0 && extra.Nonce != nonce {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "id token nonce mismatch").
+			WithErrorContext(a)
+	}
+
+	data, err := json.Marshal(rawClaims)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to marshal id token claims").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return data, nil
+}
+
+// verifyToken verifies the signature of the given token using the keys from the jwks_uri of the
+// authorization server, as well as its iss, aud and time based claims.
+func (a *oidcSessionAuthenticator) verifyToken(
+	ctx context.Context, metadata oauth2.ServerMetadata, rawToken string, claims *jwt.Claims, extra ...any,
+) error {
+	token, err := jwt.ParseSigned(rawToken, a.algorithms)
+	if err != nil {
+		return err
+	}
+
+	ept := *metadata.JWKSEndpoint
+	ept.HTTPCache = &endpoint.HTTPCache{Enabled: true, DefaultTTL: defaultJWTAuthenticatorTTL}
+
+	req, err := ept.CreateRequest(ctx, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	jwks, err := fetchJWKS(ctx, ept.CreateClient(req.URL.Hostname()), req, a)
+	if err != nil {
+		return err
+	}
+
+	keys := jwks.Keys
+	if kid := token.Headers[0].KeyID; len(kid) != 0 {
+		keys = jwks.Key(kid)
+	}
+
+	dest := append([]any{claims}, extra...)
+	err = errNoVerificationKey
+
+	for idx := range keys {
+		if err = token.Claims(keys[idx].Key, dest...); err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return claims.Validate(jwt.Expected{
+		Issuer:      metadata.Issuer,
+		AnyAudience: jwt.Audience{a.clientID},
+	})
+}
+
+func (a *oidcSessionAuthenticator) sessionCacheKey(sessionID string) string {
+	digest := sha256.New()
+	digest.Write(stringx.ToBytes(oidcSessionCacheKeyPrefixID))
+	digest.Write(stringx.ToBytes(a.clientID))
+	digest.Write(stringx.ToBytes(sessionID))
+
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+func (r *oidcTokenResponse) expiry() time.Time {
+	if r.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(time.Duration(r.ExpiresIn) * time.Second)
+}
+
+func randomValue() string {
+	buf := make([]byte, oidcRandomValueLength)
+
+	// crypto/rand.Read never returns an error on supported platforms
+	_, _ = rand.Read(buf)
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,1310 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/revocation"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+const testOIDCCookieSecret = "a-very-secret-value-used-for-tests-only"
+
+func TestCreateOIDCSessionAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, auth *oidcSessionAuthenticator)
+	}{
+		{
+			uc: "without metadata endpoint",
+			config: []byte(`
+client_id: foo
+redirect_uri: https://app.example.com/oidc/callback
+cookie:
+  secret: ` + testOIDCCookieSecret),
+			assert: func(t *testing.T, err error, _ *oidcSessionAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'metadata_endpoint' is a required field")
+			},
+		},
+		{
+			uc: "without cookie secret",
+			config: []byte(`
+metadata_endpoint:
+  url: https://op.example.com/.well-known/openid-configuration
+client_id: foo
+redirect_uri: https://app.example.com/oidc/callback`),
+			assert: func(t *testing.T, err error, _ *oidcSessionAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'cookie'.'secret' is a required field")
+			},
+		},
+		{
+			uc: "with too short cookie secret",
+			config: []byte(`
+metadata_endpoint:
+  url: https://op.example.com/.well-known/openid-configuration
+client_id: foo
+redirect_uri: https://app.example.com/oidc/callback
+cookie:
+  secret: foo`),
+			assert: func(t *testing.T, err error, _ *oidcSessionAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'cookie'.'secret' must be at least 32")
+			},
+		},
+		{
+			uc: "with invalid session ttl",
+			config: []byte(`
+metadata_endpoint:
+  url: https://op.example.com/.well-known/openid-configuration
+client_id: foo
+redirect_uri: https://app.example.com/oidc/callback
+session_ttl: 0s
+cookie:
+  secret: ` + testOIDCCookieSecret),
+			assert: func(t *testing.T, err error, _ *oidcSessionAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "session_ttl must be greater than 0")
+			},
+		},
+		{
+			uc: "with logout without path",
+			config: []byte(`
+metadata_endpoint:
+  url: https://op.example.com/.well-known/openid-configuration
+client_id: foo
+redirect_uri: https://app.example.com/oidc/callback
+logout:
+  post_logout_redirect_uri: https://app.example.com
+cookie:
+  secret: ` + testOIDCCookieSecret),
+			assert: func(t *testing.T, err error, _ *oidcSessionAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "'logout'.'path' is a required field")
+			},
+		},
+		{
+			uc: "with minimal valid configuration",
+			config: []byte(`
+metadata_endpoint:
+  url: https://op.example.com/.well-known/openid-configuration
+client_id: foo
+redirect_uri: https://app.example.com/oidc/callback
+cookie:
+  secret: ` + testOIDCCookieSecret),
+			assert: func(t *testing.T, err error, auth *oidcSessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "auth1", auth.ID())
+				assert.Equal(t, "foo", auth.clientID)
+				assert.Empty(t, auth.clientSecret)
+				assert.Equal(t, "/oidc/callback", auth.redirectURL.Path)
+				assert.Equal(t, []string{"openid"}, auth.scopes)
+				assert.Equal(t, defaultOIDCSessionTTL, auth.sessionTTL)
+				assert.Nil(t, auth.logout)
+				assert.Nil(t, auth.backChannelLogout)
+				assert.Len(t, auth.algorithms, len(defaultAllowedAlgorithms()))
+				assert.Equal(t, &SubjectInfo{IDFrom: "sub"}, auth.sf)
+				assert.Equal(t, defaultOIDCSessionCookieName, auth.cookie.Name)
+				assert.Equal(t, "/", auth.cookie.Path)
+				assert.Equal(t, http.SameSiteLaxMode, auth.cookie.sameSite())
+				assert.True(t, *auth.cookie.Secure)
+				assert.Len(t, auth.cookie.key, 32)
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with full configuration",
+			config: []byte(`
+metadata_endpoint:
+  url: https://op.example.com/.well-known/openid-configuration
+client_id: foo
+client_secret: bar
+redirect_uri: https://app.example.com/oidc/callback
+scopes:
+  - profile
+session_ttl: 1h
+logout:
+  path: /oidc/logout
+  post_logout_redirect_uri: https://app.example.com
+back_channel_logout:
+  path: /oidc/backchannel-logout
+allowed_algorithms:
+  - ES256
+subject:
+  id: email
+cookie:
+  name: session
+  domain: example.com
+  path: /app
+  same_site: strict
+  secure: false
+  secret: ` + testOIDCCookieSecret + `
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *oidcSessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "bar", auth.clientSecret)
+				assert.Equal(t, []string{"openid", "profile"}, auth.scopes)
+				assert.Equal(t, time.Hour, auth.sessionTTL)
+				assert.Equal(t, &oidcLogout{Path: "/oidc/logout", PostLogoutRedirectURI: "https://app.example.com"},
+					auth.logout)
+				assert.Equal(t, &oidcBackChannelLogout{Path: "/oidc/backchannel-logout"}, auth.backChannelLogout)
+				assert.Equal(t, []jose.SignatureAlgorithm{jose.ES256}, auth.algorithms)
+				assert.Equal(t, &SubjectInfo{IDFrom: "email"}, auth.sf)
+				assert.Equal(t, "session", auth.cookie.Name)
+				assert.Equal(t, "session_state", auth.cookie.stateCookieName())
+				assert.Equal(t, "example.com", auth.cookie.Domain)
+				assert.Equal(t, "/app", auth.cookie.Path)
+				assert.Equal(t, http.SameSiteStrictMode, auth.cookie.sameSite())
+				assert.False(t, *auth.cookie.Secure)
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newOIDCSessionAuthenticator("auth1", conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateOIDCSessionAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+metadata_endpoint:
+  url: https://op.example.com/.well-known/openid-configuration
+client_id: foo
+redirect_uri: https://app.example.com/oidc/callback
+cookie:
+  secret: ` + testOIDCCookieSecret))
+	require.NoError(t, err)
+
+	prototype, err := newOIDCSessionAuthenticator("auth1", conf)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, configured Authenticator)
+	}{
+		{
+			uc: "without target config",
+			assert: func(t *testing.T, err error, configured Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with unsupported properties",
+			config: []byte(`client_id: bar`),
+			assert: func(t *testing.T, err error, _ Authenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc:     "with fallback on error",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, configured Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				auth, ok := configured.(*oidcSessionAuthenticator)
+				require.True(t, ok)
+
+				assert.NotEqual(t, prototype, auth)
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+				assert.Equal(t, prototype.ID(), auth.ID())
+				assert.Equal(t, prototype.cookie, auth.cookie)
+				assert.Equal(t, prototype.group, auth.group)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestOIDCSessionAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tokenEndpointCalled bool
+		handleTokenRequest  func(t *testing.T, rw http.ResponseWriter, req *http.Request)
+	)
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: signingKey.Public(), KeyID: "op", Algorithm: string(jose.ES256), Use: "sig"},
+	}})
+	require.NoError(t, err)
+
+	var currentTest *testing.T
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+
+	defer srv.Close()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+
+		err := json.NewEncoder(rw).Encode(map[string]any{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"jwks_uri":               srv.URL + "/jwks",
+			"end_session_endpoint":   srv.URL + "/logout",
+		})
+		require.NoError(currentTest, err)
+	})
+	mux.HandleFunc("/jwks", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+
+		_, err := rw.Write(jwks)
+		require.NoError(currentTest, err)
+	})
+	mux.HandleFunc("/token", func(rw http.ResponseWriter, req *http.Request) {
+		tokenEndpointCalled = true
+
+		handleTokenRequest(currentTest, rw, req)
+	})
+
+	createToken := func(t *testing.T, claims map[string]any) string {
+		t.Helper()
+
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: signingKey},
+			(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "op"))
+		require.NoError(t, err)
+
+		token, err := jwt.Signed(signer).Claims(claims).Serialize()
+		require.NoError(t, err)
+
+		return token
+	}
+
+	idTokenClaims := func(nonce string) map[string]any {
+		claims := map[string]any{
+			"iss": srv.URL,
+			"aud": "foo",
+			"sub": "bar",
+			"sid": "baz",
+			"iat": time.Now().Unix(),
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+
+		if len(nonce) != 0 {
+			claims["nonce"] = nonce
+		}
+
+		return claims
+	}
+
+	writeJSON := func(t *testing.T, rw http.ResponseWriter, code int, value any) {
+		t.Helper()
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(code)
+
+		err := json.NewEncoder(rw).Encode(value)
+		require.NoError(t, err)
+	}
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+metadata_endpoint:
+  url: ` + srv.URL + `/.well-known/openid-configuration
+client_id: foo
+client_secret: secret
+redirect_uri: https://app.example.com/oidc/callback
+logout:
+  path: /oidc/logout
+  post_logout_redirect_uri: https://app.example.com/
+back_channel_logout:
+  path: /oidc/backchannel-logout
+cookie:
+  secret: ` + testOIDCCookieSecret))
+	require.NoError(t, err)
+
+	auth, err := newOIDCSessionAuthenticator("auth1", conf)
+	require.NoError(t, err)
+
+	requestURL := func(t *testing.T, rawURL string) *heimdall.URL {
+		t.Helper()
+
+		reqURL, err := url.Parse(rawURL)
+		require.NoError(t, err)
+
+		return &heimdall.URL{URL: *reqURL}
+	}
+
+	createSession := func(t *testing.T, cch cache.Cache, sess *oidcSession) string {
+		t.Helper()
+
+		require.NoError(t, auth.storeSession(cache.WithContext(context.Background(), cch), "session-id", sess))
+
+		cookie, err := auth.cookie.seal(&oidcSessionReference{ID: "session-id"})
+		require.NoError(t, err)
+
+		return cookie
+	}
+
+	backChannelLogout := func(t *testing.T, cch cache.Cache) {
+		t.Helper()
+
+		claims := idTokenClaims("")
+		claims["events"] = map[string]any{oidcBackChannelLogoutEvent: map[string]any{}}
+
+		fnt := mocks.NewRequestFunctionsMock(t)
+		fnt.EXPECT().Body().Return(map[string]any{"logout_token": []string{createToken(t, claims)}})
+
+		ctx := mocks.NewContextMock(t)
+		ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch))
+		ctx.EXPECT().Request().Return(&heimdall.Request{
+			RequestFunctions: fnt,
+			Method:           http.MethodPost,
+			URL:              requestURL(t, "https://app.example.com/oidc/backchannel-logout"),
+		})
+
+		_, err := auth.Execute(ctx)
+
+		var responseErr *heimdall.ResponseError
+		require.ErrorAs(t, err, &responseErr)
+		require.Equal(t, http.StatusOK, responseErr.Code)
+	}
+
+	createLoginState := func(t *testing.T, state *oidcLoginState) string {
+		t.Helper()
+
+		cookie, err := auth.cookie.seal(state)
+		require.NoError(t, err)
+
+		return cookie
+	}
+
+	for _, tc := range []struct {
+		uc                 string
+		requestTimeout     time.Duration
+		configureContext   func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock)
+		handleTokenRequest func(t *testing.T, rw http.ResponseWriter, req *http.Request)
+		assert             func(t *testing.T, err error, sub *subject.Subject, cch cache.Cache)
+	}{
+		{
+			uc: "without session cookie starts login",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return("")
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo?bar=baz"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+
+				var redirectErr *heimdall.RedirectError
+				require.ErrorAs(t, err, &redirectErr)
+				assert.Equal(t, http.StatusFound, redirectErr.Code)
+
+				redirectURL, err := url.Parse(redirectErr.RedirectTo)
+				require.NoError(t, err)
+
+				query := redirectURL.Query()
+				assert.Equal(t, srv.URL+"/authorize", redirectURL.Scheme+"://"+redirectURL.Host+redirectURL.Path)
+				assert.Equal(t, "code", query.Get("response_type"))
+				assert.Equal(t, "foo", query.Get("client_id"))
+				assert.Equal(t, "https://app.example.com/oidc/callback", query.Get("redirect_uri"))
+				assert.Equal(t, "openid", query.Get("scope"))
+				assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+				require.Len(t, redirectErr.Cookies, 1)
+				cookie := redirectErr.Cookies[0]
+				assert.Equal(t, "heimdall_session_state", cookie.Name)
+				assert.True(t, cookie.HttpOnly)
+				assert.True(t, cookie.Secure)
+				assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+				assert.Equal(t, int(oidcLoginStateTTL.Seconds()), cookie.MaxAge)
+
+				var state oidcLoginState
+				require.NoError(t, auth.cookie.open(cookie.Value, &state))
+				assert.Equal(t, "https://app.example.com/foo?bar=baz", state.TargetURL)
+				assert.Equal(t, state.State, query.Get("state"))
+				assert.Equal(t, state.Nonce, query.Get("nonce"))
+
+				challenge := sha256.Sum256([]byte(state.CodeVerifier))
+				assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), query.Get("code_challenge"))
+			},
+		},
+		{
+			uc: "with invalid session cookie starts login",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return("foo")
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				require.ErrorIs(t, err, &heimdall.RedirectError{})
+			},
+		},
+		{
+			uc: "with valid session",
+			configureContext: func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				cookie := createSession(t, cch, &oidcSession{
+					IDToken:     "id-token",
+					AccessToken: "access-token",
+					Expiry:      time.Now().Add(time.Hour),
+					NotAfter:    time.Now().Add(time.Hour),
+					Claims:      []byte(`{"sub":"bar","sid":"baz"}`),
+				})
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return(cookie)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo"),
+				})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.False(t, tokenEndpointCalled)
+				assert.Equal(t, "bar", sub.ID)
+				assert.Equal(t, map[string]any{"sub": "bar", "sid": "baz"}, sub.Attributes)
+			},
+		},
+		{
+			uc: "with session revoked by a back-channel logout starts login",
+			configureContext: func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				cookie := createSession(t, cch, &oidcSession{
+					IDToken:     "id-token",
+					AccessToken: "access-token",
+					Expiry:      time.Now().Add(time.Hour),
+					NotAfter:    time.Now().Add(time.Hour),
+					Claims:      fmt.Appendf(nil, `{"sub":"bar","sid":"baz","iat":%d}`, time.Now().Add(-time.Minute).Unix()),
+				})
+
+				backChannelLogout(t, cch)
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return(cookie)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, cch cache.Cache) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+
+				var redirectErr *heimdall.RedirectError
+				require.ErrorAs(t, err, &redirectErr)
+				assert.True(t, strings.HasPrefix(redirectErr.RedirectTo, srv.URL+"/authorize"))
+
+				// the session is dropped
+				entry, err := cch.Get(context.Background(), auth.sessionCacheKey("session-id"))
+				require.NoError(t, err)
+				assert.JSONEq(t, "{}", string(entry))
+			},
+		},
+		{
+			uc: "with session having expired tokens, which are successfully refreshed",
+			configureContext: func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				cookie := createSession(t, cch, &oidcSession{
+					IDToken:      "id-token",
+					AccessToken:  "access-token",
+					RefreshToken: "refresh-token",
+					Expiry:       time.Now().Add(-time.Minute),
+					NotAfter:     time.Now().Add(time.Hour),
+					Claims:       []byte(`{"sub":"bar"}`),
+				})
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return(cookie)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo"),
+				})
+			},
+			handleTokenRequest: func(t *testing.T, rw http.ResponseWriter, req *http.Request) {
+				t.Helper()
+
+				user, password, ok := req.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "foo", user)
+				assert.Equal(t, "secret", password)
+
+				require.NoError(t, req.ParseForm())
+				assert.Equal(t, "refresh_token", req.PostForm.Get("grant_type"))
+				assert.Equal(t, "refresh-token", req.PostForm.Get("refresh_token"))
+
+				writeJSON(t, rw, http.StatusOK, map[string]any{
+					"access_token":  "new-access-token",
+					"refresh_token": "new-refresh-token",
+					"id_token":      createToken(t, idTokenClaims("")),
+					"expires_in":    300,
+				})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject, cch cache.Cache) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, tokenEndpointCalled)
+				assert.Equal(t, "bar", sub.ID)
+				assert.Equal(t, "baz", sub.Attributes["sid"])
+
+				entry, err := cch.Get(context.Background(), auth.sessionCacheKey("session-id"))
+				require.NoError(t, err)
+
+				var sess oidcSession
+				require.NoError(t, json.Unmarshal(entry, &sess))
+				assert.Equal(t, "new-access-token", sess.AccessToken)
+				assert.Equal(t, "new-refresh-token", sess.RefreshToken)
+				assert.True(t, sess.Expiry.After(time.Now()))
+			},
+		},
+		{
+			uc:             "with session having expired tokens, which are refreshed even if the request is canceled",
+			requestTimeout: 50 * time.Millisecond,
+			configureContext: func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				cookie := createSession(t, cch, &oidcSession{
+					IDToken:      "id-token",
+					AccessToken:  "access-token",
+					RefreshToken: "refresh-token",
+					Expiry:       time.Now().Add(-time.Minute),
+					NotAfter:     time.Now().Add(time.Hour),
+					Claims:       []byte(`{"sub":"bar"}`),
+				})
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return(cookie)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo"),
+				})
+			},
+			handleTokenRequest: func(t *testing.T, rw http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				time.Sleep(200 * time.Millisecond)
+
+				writeJSON(t, rw, http.StatusOK, map[string]any{
+					"access_token": "new-access-token",
+					"expires_in":   300,
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, cch cache.Cache) {
+				t.Helper()
+
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+
+				assert.Eventually(t, func() bool {
+					entry, err := cch.Get(context.Background(), auth.sessionCacheKey("session-id"))
+					if err != nil {
+						return false
+					}
+
+					var sess oidcSession
+					require.NoError(t, json.Unmarshal(entry, &sess))
+
+					return sess.AccessToken == "new-access-token"
+				}, time.Second, 20*time.Millisecond)
+			},
+		},
+		{
+			uc: "with session having expired tokens, which could not be refreshed",
+			configureContext: func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				cookie := createSession(t, cch, &oidcSession{
+					IDToken:      "id-token",
+					AccessToken:  "access-token",
+					RefreshToken: "refresh-token",
+					Expiry:       time.Now().Add(-time.Minute),
+					NotAfter:     time.Now().Add(time.Hour),
+					Claims:       []byte(`{"sub":"bar"}`),
+				})
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return(cookie)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo"),
+				})
+			},
+			handleTokenRequest: func(t *testing.T, rw http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				writeJSON(t, rw, http.StatusBadRequest, map[string]any{"error": "invalid_grant"})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, &heimdall.RedirectError{})
+			},
+		},
+		{
+			uc: "with session having expired tokens and token endpoint failing",
+			configureContext: func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				cookie := createSession(t, cch, &oidcSession{
+					IDToken:      "id-token",
+					AccessToken:  "access-token",
+					RefreshToken: "refresh-token",
+					Expiry:       time.Now().Add(-time.Minute),
+					NotAfter:     time.Now().Add(time.Hour),
+					Claims:       []byte(`{"sub":"bar"}`),
+				})
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return(cookie)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo"),
+				})
+			},
+			handleTokenRequest: func(t *testing.T, rw http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				rw.WriteHeader(http.StatusInternalServerError)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				require.ErrorContains(t, err, "unexpected response code: 500")
+			},
+		},
+		{
+			uc: "with session having expired tokens and no refresh token",
+			configureContext: func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				cookie := createSession(t, cch, &oidcSession{
+					IDToken:     "id-token",
+					AccessToken: "access-token",
+					Expiry:      time.Now().Add(-time.Minute),
+					NotAfter:    time.Now().Add(time.Hour),
+					Claims:      []byte(`{"sub":"bar"}`),
+				})
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return(cookie)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, &heimdall.RedirectError{})
+			},
+		},
+		{
+			uc: "callback without login state",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie("heimdall_session_state").Return("")
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/callback?code=foo&state=bar"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "no valid login state")
+			},
+		},
+		{
+			uc: "callback with expired login state",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie("heimdall_session_state").Return(createLoginState(t, &oidcLoginState{
+					State:  "bar",
+					Expiry: time.Now().Add(-time.Minute),
+				}))
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/callback?code=foo&state=bar"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "no valid login state")
+			},
+		},
+		{
+			uc: "callback with error from authorization server",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie("heimdall_session_state").Return(createLoginState(t, &oidcLoginState{
+					State:  "bar",
+					Expiry: time.Now().Add(time.Minute),
+				}))
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL: requestURL(t,
+						"https://app.example.com/oidc/callback?error=access_denied&error_description=denied&state=bar"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "error: access_denied, error_description: denied")
+			},
+		},
+		{
+			uc: "callback with state mismatch",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie("heimdall_session_state").Return(createLoginState(t, &oidcLoginState{
+					State:  "baz",
+					Expiry: time.Now().Add(time.Minute),
+				}))
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/callback?code=foo&state=bar"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "state mismatch")
+			},
+		},
+		{
+			uc: "callback with id token having unexpected nonce",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie("heimdall_session_state").Return(createLoginState(t, &oidcLoginState{
+					State:        "bar",
+					Nonce:        "nonce",
+					CodeVerifier: "verifier",
+					Expiry:       time.Now().Add(time.Minute),
+				}))
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/callback?code=foo&state=bar"),
+				})
+			},
+			handleTokenRequest: func(t *testing.T, rw http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				writeJSON(t, rw, http.StatusOK, map[string]any{
+					"access_token": "access-token",
+					"id_token":     createToken(t, idTokenClaims("other")),
+					"expires_in":   300,
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "nonce mismatch")
+			},
+		},
+		{
+			uc: "callback with id token issued for other client",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie("heimdall_session_state").Return(createLoginState(t, &oidcLoginState{
+					State:        "bar",
+					Nonce:        "nonce",
+					CodeVerifier: "verifier",
+					Expiry:       time.Now().Add(time.Minute),
+				}))
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/callback?code=foo&state=bar"),
+				})
+			},
+			handleTokenRequest: func(t *testing.T, rw http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				claims := idTokenClaims("nonce")
+				claims["aud"] = "other"
+
+				writeJSON(t, rw, http.StatusOK, map[string]any{
+					"access_token": "access-token",
+					"id_token":     createToken(t, claims),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, jwt.ErrInvalidAudience)
+			},
+		},
+		{
+			uc: "callback with rejected authorization code",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie("heimdall_session_state").Return(createLoginState(t, &oidcLoginState{
+					State:        "bar",
+					Nonce:        "nonce",
+					CodeVerifier: "verifier",
+					Expiry:       time.Now().Add(time.Minute),
+				}))
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/callback?code=foo&state=bar"),
+				})
+			},
+			handleTokenRequest: func(t *testing.T, rw http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				writeJSON(t, rw, http.StatusBadRequest, map[string]any{
+					"error":             "invalid_grant",
+					"error_description": "code expired",
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "code expired")
+			},
+		},
+		{
+			uc: "successful callback",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Cookie("heimdall_session_state").Return(createLoginState(t, &oidcLoginState{
+					State:        "bar",
+					Nonce:        "nonce",
+					CodeVerifier: "verifier",
+					TargetURL:    "https://app.example.com/foo",
+					Expiry:       time.Now().Add(time.Minute),
+				}))
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/callback?code=foo&state=bar"),
+				})
+			},
+			handleTokenRequest: func(t *testing.T, rw http.ResponseWriter, req *http.Request) {
+				t.Helper()
+
+				_, _, ok := req.BasicAuth()
+				assert.True(t, ok)
+
+				require.NoError(t, req.ParseForm())
+				assert.Equal(t, "authorization_code", req.PostForm.Get("grant_type"))
+				assert.Equal(t, "foo", req.PostForm.Get("code"))
+				assert.Equal(t, "verifier", req.PostForm.Get("code_verifier"))
+				assert.Equal(t, "https://app.example.com/oidc/callback", req.PostForm.Get("redirect_uri"))
+
+				writeJSON(t, rw, http.StatusOK, map[string]any{
+					"access_token":  "access-token",
+					"refresh_token": "refresh-token",
+					"id_token":      createToken(t, idTokenClaims("nonce")),
+					"expires_in":    300,
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, cch cache.Cache) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+
+				var redirectErr *heimdall.RedirectError
+				require.ErrorAs(t, err, &redirectErr)
+				assert.Equal(t, http.StatusFound, redirectErr.Code)
+				assert.Equal(t, "https://app.example.com/foo", redirectErr.RedirectTo)
+
+				require.Len(t, redirectErr.Cookies, 2)
+				assert.Equal(t, defaultOIDCSessionCookieName, redirectErr.Cookies[0].Name)
+				assert.Equal(t, int(defaultOIDCSessionTTL.Seconds()), redirectErr.Cookies[0].MaxAge)
+				assert.Equal(t, "heimdall_session_state", redirectErr.Cookies[1].Name)
+				assert.Equal(t, -1, redirectErr.Cookies[1].MaxAge)
+
+				var ref oidcSessionReference
+				require.NoError(t, auth.cookie.open(redirectErr.Cookies[0].Value, &ref))
+
+				entry, err := cch.Get(context.Background(), auth.sessionCacheKey(ref.ID))
+				require.NoError(t, err)
+
+				var sess oidcSession
+				require.NoError(t, json.Unmarshal(entry, &sess))
+				assert.Equal(t, "access-token", sess.AccessToken)
+				assert.Equal(t, "refresh-token", sess.RefreshToken)
+				assert.Contains(t, string(sess.Claims), `"sid":"baz"`)
+			},
+		},
+		{
+			uc: "successful callback after a back-channel logout",
+			configureContext: func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				backChannelLogout(t, cch)
+
+				fnt.EXPECT().Cookie("heimdall_session_state").Return(createLoginState(t, &oidcLoginState{
+					State:        "bar",
+					Nonce:        "nonce",
+					CodeVerifier: "verifier",
+					TargetURL:    "https://app.example.com/foo",
+					Expiry:       time.Now().Add(time.Minute),
+				}))
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/callback?code=foo&state=bar"),
+				})
+			},
+			handleTokenRequest: func(t *testing.T, rw http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				writeJSON(t, rw, http.StatusOK, map[string]any{
+					"access_token": "access-token",
+					"id_token":     createToken(t, idTokenClaims("nonce")),
+					"expires_in":   300,
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, cch cache.Cache) {
+				t.Helper()
+
+				var redirectErr *heimdall.RedirectError
+				require.ErrorAs(t, err, &redirectErr)
+				require.Len(t, redirectErr.Cookies, 2)
+
+				// the new session can be used
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return(redirectErr.Cookies[0].Value)
+
+				ctx := mocks.NewContextMock(t)
+				ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch))
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/foo"),
+				})
+
+				sub, err := auth.Execute(ctx)
+				require.NoError(t, err)
+				assert.Equal(t, "bar", sub.ID)
+				assert.False(t, revocation.IsRevoked(context.Background(), cch, sub))
+			},
+		},
+		{
+			uc: "logout",
+			configureContext: func(t *testing.T, cch cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				cookie := createSession(t, cch, &oidcSession{
+					IDToken:     "id-token",
+					AccessToken: "access-token",
+					NotAfter:    time.Now().Add(time.Hour),
+					Claims:      []byte(`{"sub":"bar"}`),
+				})
+
+				fnt.EXPECT().Cookie(defaultOIDCSessionCookieName).Return(cookie)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/logout"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, cch cache.Cache) {
+				t.Helper()
+
+				var redirectErr *heimdall.RedirectError
+				require.ErrorAs(t, err, &redirectErr)
+
+				redirectURL, err := url.Parse(redirectErr.RedirectTo)
+				require.NoError(t, err)
+
+				query := redirectURL.Query()
+				assert.Equal(t, "/logout", redirectURL.Path)
+				assert.Equal(t, "foo", query.Get("client_id"))
+				assert.Equal(t, "id-token", query.Get("id_token_hint"))
+				assert.Equal(t, "https://app.example.com/", query.Get("post_logout_redirect_uri"))
+
+				require.Len(t, redirectErr.Cookies, 1)
+				assert.Equal(t, defaultOIDCSessionCookieName, redirectErr.Cookies[0].Name)
+				assert.Equal(t, -1, redirectErr.Cookies[0].MaxAge)
+
+				entry, err := cch.Get(context.Background(), auth.sessionCacheKey("session-id"))
+				require.NoError(t, err)
+				assert.JSONEq(t, `{}`, string(entry))
+			},
+		},
+		{
+			uc: "back-channel logout using GET",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              requestURL(t, "https://app.example.com/oidc/backchannel-logout"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorContains(t, err, "requires POST")
+			},
+		},
+		{
+			uc: "back-channel logout with logout token containing nonce",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				claims := idTokenClaims("nonce")
+				claims["events"] = map[string]any{oidcBackChannelLogoutEvent: map[string]any{}}
+
+				fnt.EXPECT().Body().Return(map[string]any{"logout_token": []string{createToken(t, claims)}})
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodPost,
+					URL:              requestURL(t, "https://app.example.com/oidc/backchannel-logout"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorContains(t, err, "nonce claim is present")
+			},
+		},
+		{
+			uc: "back-channel logout with logout token without logout event",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				fnt.EXPECT().Body().Return(map[string]any{"logout_token": []string{createToken(t, idTokenClaims(""))}})
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodPost,
+					URL:              requestURL(t, "https://app.example.com/oidc/backchannel-logout"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ cache.Cache) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				require.ErrorContains(t, err, "backchannel-logout event is missing")
+			},
+		},
+		{
+			uc: "successful back-channel logout",
+			configureContext: func(t *testing.T, _ cache.Cache, ctx *mocks.ContextMock, fnt *mocks.RequestFunctionsMock) {
+				t.Helper()
+
+				claims := idTokenClaims("")
+				claims["events"] = map[string]any{oidcBackChannelLogoutEvent: map[string]any{}}
+
+				fnt.EXPECT().Body().Return(map[string]any{"logout_token": []string{createToken(t, claims)}})
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodPost,
+					URL:              requestURL(t, "https://app.example.com/oidc/backchannel-logout"),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, cch cache.Cache) {
+				t.Helper()
+
+				var responseErr *heimdall.ResponseError
+				require.ErrorAs(t, err, &responseErr)
+				assert.Equal(t, http.StatusOK, responseErr.Code)
+
+				assert.True(t, revocation.IsRevoked(context.Background(), cch, &subject.Subject{
+					ID:         "bar",
+					Attributes: map[string]any{"sid": "baz", "iat": float64(time.Now().Add(-time.Minute).Unix())},
+				}))
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			currentTest = t
+			tokenEndpointCalled = false
+			handleTokenRequest = func(t *testing.T, rw http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+
+				rw.WriteHeader(http.StatusInternalServerError)
+			}
+
+			if tc.handleTokenRequest != nil {
+				handleTokenRequest = tc.handleTokenRequest
+			}
+
+			cch, err := memory.NewCache(nil, nil)
+			require.NoError(t, err)
+
+			appCtx := cache.WithContext(context.Background(), cch)
+
+			if tc.requestTimeout != 0 {
+				var cancel context.CancelFunc
+
+				appCtx, cancel = context.WithTimeout(appCtx, tc.requestTimeout)
+				defer cancel()
+			}
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(appCtx)
+
+			tc.configureContext(t, cch, ctx, fnt)
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub, cch)
+		})
+	}
+}
+
+func TestOIDCSessionCookie(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cookie := &oidcSessionCookie{Secret: testOIDCCookieSecret}
+	cookie.init()
+
+	other := &oidcSessionCookie{Secret: testOIDCCookieSecret + "-other"}
+	other.init()
+
+	// WHEN
+	sealed, err := cookie.seal(&oidcSessionReference{ID: "foo"})
+
+	// THEN
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "foo")
+
+	var ref oidcSessionReference
+	require.NoError(t, cookie.open(sealed, &ref))
+	assert.Equal(t, "foo", ref.ID)
+
+	require.Error(t, other.open(sealed, &ref))
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	defaultOIDCSessionCookieName = "heimdall_session"
+	oidcLoginStateCookieSuffix   = "_state"
+)
+
+// oidcSessionCookie configures the cookies used by the oidc_session authenticator. The values of these
+// cookies are encrypted (JWE with dir and A256GCM) using a key derived from the configured secret.
+type oidcSessionCookie struct {
+	Name     string `mapstructure:"name"`
+	Domain   string `mapstructure:"domain"`
+	Path     string `mapstructure:"path"`
+	SameSite string `mapstructure:"same_site" validate:"omitempty,oneof=lax strict none"`
+	Secure   *bool  `mapstructure:"secure"`
+	Secret   string `mapstructure:"secret"    validate:"required,min=32"`
+
+	key []byte
+}
+
+func (c *oidcSessionCookie) init() {
+	if len(c.Name) == 0 {
+		c.Name = defaultOIDCSessionCookieName
+	}
+
+	if len(c.Path) == 0 {
+		c.Path = "/"
+	}
+
+	if len(c.SameSite) == 0 {
+		c.SameSite = "lax"
+	}
+
+	if c.Secure == nil {
+		secure := true
+		c.Secure = &secure
+	}
+
+	key := sha256.Sum256(stringx.ToBytes(c.Secret))
+	c.key = key[:]
+}
+
+func (c *oidcSessionCookie) stateCookieName() string { return c.Name + oidcLoginStateCookieSuffix }
+
+func (c *oidcSessionCookie) seal(value any) (string, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: jose.DIRECT, Key: c.key}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	jwe, err := encrypter.Encrypt(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return jwe.CompactSerialize()
+}
+
+func (c *oidcSessionCookie) open(rawValue string, value any) error {
+	jwe, err := jose.ParseEncryptedCompact(rawValue,
+		[]jose.KeyAlgorithm{jose.DIRECT}, []jose.ContentEncryption{jose.A256GCM})
+	if err != nil {
+		return err
+	}
+
+	payload, err := jwe.Decrypt(c.key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(payload, value)
+}
+
+func (c *oidcSessionCookie) create(name, value string, maxAge time.Duration) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   *c.Secure,
+		HttpOnly: true,
+		SameSite: c.sameSite(),
+	}
+}
+
+func (c *oidcSessionCookie) remove(name string) *http.Cookie {
+	cookie := c.create(name, "", 0)
+	cookie.MaxAge = -1
+
+	return cookie
+}
+
+func (c *oidcSessionCookie) sameSite() http.SameSite {
+	switch strings.ToLower(c.SameSite) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
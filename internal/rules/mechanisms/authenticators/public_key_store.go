@@ -0,0 +1,152 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/httpsig"
+)
+
+const (
+	pemBlockTypePublicKey   = "PUBLIC KEY"
+	pemBlockTypeCertificate = "CERTIFICATE"
+)
+
+// publicKeyStore holds the public keys loaded from a PEM file, which can contain PUBLIC KEY and
+// CERTIFICATE entries. The key id is taken from the X-Key-ID header of the PEM block. For
+// certificates without that header, the hex encoded subject key identifier is used.
+// The file is reloaded on changes.
+type publicKeyStore struct {
+	path string
+
+	keys map[string]any
+	mut  sync.RWMutex
+}
+
+func newPublicKeyStore(path string) (*publicKeyStore, error) {
+	store := &publicKeyStore{path: path}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *publicKeyStore) load() error {
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed reading public key store").CausedBy(err)
+	}
+
+	keys := make(map[string]any)
+
+	for idx := 1; ; idx++ {
+		var block *pem.Block
+
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			break
+		}
+
+		var (
+			key   any
+			keyID = block.Headers["X-Key-ID"]
+		)
+
+		switch block.Type {
+		case pemBlockTypePublicKey:
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case pemBlockTypeCertificate:
+			var cert *x509.Certificate
+
+			if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+				key = cert.PublicKey
+
+				if len(keyID) == 0 {
+					keyID = hex.EncodeToString(cert.SubjectKeyId)
+				}
+			}
+		default:
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"unsupported entry '%s' in the public key store", block.Type)
+		}
+
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed to parse %d entry in the public key store", idx).CausedBy(err)
+		}
+
+		if len(keyID) == 0 {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"no key id available for %d entry in the public key store", idx)
+		}
+
+		if len(httpsig.KeyAlgorithms(key)) == 0 {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"key of %d entry in the public key store cannot be used for http message signatures", idx)
+		}
+
+		if _, exists := keys[keyID]; exists {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"duplicate entry for key_id=%s found in the public key store", keyID)
+		}
+
+		keys[keyID] = key
+	}
+
+	if len(keys) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "public key store does not contain any keys")
+	}
+
+	s.mut.Lock()
+	s.keys = keys
+	s.mut.Unlock()
+
+	return nil
+}
+
+func (s *publicKeyStore) key(keyID string) (any, bool) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	key, ok := s.keys[keyID]
+
+	return key, ok
+}
+
+func (s *publicKeyStore) OnChanged(log zerolog.Logger) {
+	err := s.load()
+	if err != nil {
+		log.Warn().Err(err).
+			Str("_file", s.path).
+			Msg("Public key store reload failed")
+	} else {
+		log.Info().
+			Str("_file", s.path).
+			Msg("Public key store reloaded")
+	}
+}
@@ -76,3 +76,8 @@ func (s *SessionLifespan) assertIssuanceTime() error {
 
 	return nil
 }
+
+// expiresWithin returns true if the session has a not_after time, which is reached within the given duration.
+func (s *SessionLifespan) expiresWithin(duration time.Duration) bool {
+	return !s.exp.Equal(time.Time{}) && time.Until(s.exp) < duration
+}
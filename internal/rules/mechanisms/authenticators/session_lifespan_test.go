@@ -140,3 +140,34 @@ func TestSessionLifespanAssert(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionLifespanExpiresWithin(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		lifespan *SessionLifespan
+		expected bool
+	}{
+		{uc: "not_after not set", lifespan: &SessionLifespan{}},
+		{uc: "not_after after the given duration", lifespan: &SessionLifespan{exp: time.Now().Add(2 * time.Minute)}},
+		{
+			uc:       "not_after within the given duration",
+			lifespan: &SessionLifespan{exp: time.Now().Add(30 * time.Second)},
+			expected: true,
+		},
+		{
+			uc:       "not_after in the past",
+			lifespan: &SessionLifespan{exp: time.Now().Add(-30 * time.Second)},
+			expected: true,
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			result := tc.lifespan.expiresWithin(time.Minute)
+
+			// THEN
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
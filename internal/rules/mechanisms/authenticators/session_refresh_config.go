@@ -0,0 +1,32 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"time"
+
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+)
+
+const defaultSessionRefreshThreshold = 1 * time.Minute
+
+type SessionRefreshConfig struct {
+	Endpoint      endpoint.Endpoint `mapstructure:"endpoint"       validate:"required"`
+	Payload       template.Template `mapstructure:"payload"`
+	RefreshBefore time.Duration     `mapstructure:"refresh_before"`
+}
@@ -0,0 +1,228 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	pkix2 "github.com/dadrus/heimdall/internal/x/pkix"
+	"github.com/dadrus/heimdall/internal/x/slicex"
+)
+
+const spiffeScheme = "spiffe"
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(_ CreationContext, id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorX509 {
+				return false, nil, nil
+			}
+
+			auth, err := newX509Authenticator(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type x509Authenticator struct {
+	id                   string
+	trustStore           truststore.TrustStore
+	fcc                  *forwardedClientCertificate
+	sf                   SubjectFactory
+	allowFallbackOnError bool
+}
+
+func newX509Authenticator(id string, rawConfig map[string]any) (*x509Authenticator, error) {
+	type Config struct {
+		TrustStore           truststore.TrustStore       `mapstructure:"trust_store"             validate:"required,gt=0"`
+		ForwardedHeader      *forwardedClientCertificate `mapstructure:"forwarded_client_cert"`
+		SubjectInfo          SubjectInfo                 `mapstructure:"subject"                 validate:"-"`
+		AllowFallbackOnError bool                        `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorX509, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	if len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = "subject.common_name"
+	}
+
+	if conf.ForwardedHeader != nil && len(conf.ForwardedHeader.Format) == 0 {
+		conf.ForwardedHeader.Format = forwardedClientCertificateFormatPEM
+	}
+
+	return &x509Authenticator{
+		id:                   id,
+		trustStore:           conf.TrustStore,
+		fcc:                  conf.ForwardedHeader,
+		sf:                   &conf.SubjectInfo,
+		allowFallbackOnError: conf.AllowFallbackOnError,
+	}, nil
+}
+
+func (a *x509Authenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using x509 authenticator")
+
+	chain, err := a.clientCertificates(ctx)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no valid client certificate present").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if err = pkix2.ValidateCertificate(chain[0],
+		pkix2.WithRootCACertificates(a.trustStore),
+		pkix2.WithIntermediateCACertificates(chain[1:]),
+		pkix2.WithExtendedKeyUsage(x509.ExtKeyUsageClientAuth),
+		pkix2.WithCurrentTime(time.Now()),
+	); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "client certificate is not trusted").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	rawData, err := json.Marshal(certificateAttributes(chain[0]))
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to marshal client certificate attributes").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	sub, err := a.sf.CreateSubject(rawData)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication,
+				"failed to extract subject information from client certificate").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return sub, nil
+}
+
+func (a *x509Authenticator) clientCertificates(ctx heimdall.Context) ([]*x509.Certificate, error) {
+	if a.fcc != nil {
+		return a.fcc.certificates(ctx)
+	}
+
+	if certs := ctx.Request().ClientCertificates; len(certs) != 0 {
+		return certs, nil
+	}
+
+	return nil, errNoClientCertificate
+}
+
+func (a *x509Authenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	// this authenticator allows subject and fallback settings to be redefined on the rule level
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		SubjectInfo          *SubjectInfo `mapstructure:"subject"                 validate:"omitempty"`
+		AllowFallbackOnError *bool        `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorX509, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	return &x509Authenticator{
+		id:         a.id,
+		trustStore: a.trustStore,
+		fcc:        a.fcc,
+		sf: x.IfThenElseExec(conf.SubjectInfo != nil,
+			func() SubjectFactory { return conf.SubjectInfo },
+			func() SubjectFactory { return a.sf }),
+		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+			func() bool { return *conf.AllowFallbackOnError },
+			func() bool { return a.allowFallbackOnError }),
+	}, nil
+}
+
+func (a *x509Authenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *x509Authenticator) ID() string {
+	return a.id
+}
+
+func certificateAttributes(cert *x509.Certificate) map[string]any {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	var spiffeID string
+
+	for _, uri := range cert.URIs {
+		if uri.Scheme == spiffeScheme {
+			spiffeID = uri.String()
+
+			break
+		}
+	}
+
+	return map[string]any{
+		"subject":         nameAttributes(cert.Subject),
+		"issuer":          nameAttributes(cert.Issuer),
+		"serial_number":   cert.SerialNumber.String(),
+		"not_before":      cert.NotBefore.Unix(),
+		"not_after":       cert.NotAfter.Unix(),
+		"dns_names":       x.IfThenElse(cert.DNSNames != nil, cert.DNSNames, []string{}),
+		"email_addresses": x.IfThenElse(cert.EmailAddresses != nil, cert.EmailAddresses, []string{}),
+		"ip_addresses":    slicex.Map(cert.IPAddresses, func(ip net.IP) string { return ip.String() }),
+		"uris":            slicex.Map(cert.URIs, func(uri *url.URL) string { return uri.String() }),
+		"spiffe_id":       spiffeID,
+		"fingerprint":     hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+func nameAttributes(name pkix.Name) map[string]any {
+	return map[string]any{
+		"dn":                  name.String(),
+		"common_name":         name.CommonName,
+		"serial_number":       name.SerialNumber,
+		"organization":        x.IfThenElse(name.Organization != nil, name.Organization, []string{}),
+		"organizational_unit": x.IfThenElse(name.OrganizationalUnit != nil, name.OrganizationalUnit, []string{}),
+		"country":             x.IfThenElse(name.Country != nil, name.Country, []string{}),
+		"province":            x.IfThenElse(name.Province != nil, name.Province, []string{}),
+		"locality":            x.IfThenElse(name.Locality != nil, name.Locality, []string{}),
+	}
+}
@@ -0,0 +1,585 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateX509Authenticator(t *testing.T) {
+	t.Parallel()
+
+	ca, err := testsupport.NewRootCA("Test CA", time.Hour)
+	require.NoError(t, err)
+
+	trustStoreFile := filepath.Join(t.TempDir(), "ca.pem")
+	err = os.WriteFile(trustStoreFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate.Raw}), 0o600)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, auth *x509Authenticator)
+	}{
+		{
+			uc: "without trust store",
+			config: []byte(`
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "trust_store")
+			},
+		},
+		{
+			uc: "with unsupported forwarded client certificate format",
+			config: []byte(`
+trust_store: ` + trustStoreFile + `
+forwarded_client_cert:
+  name: X-Client-Cert
+  format: der`),
+			assert: func(t *testing.T, err error, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "format")
+			},
+		},
+		{
+			uc: "with unsupported property",
+			config: []byte(`
+trust_store: ` + trustStoreFile + `
+foo: bar`),
+			assert: func(t *testing.T, err error, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc: "with minimal valid configuration",
+			config: []byte(`
+trust_store: ` + trustStoreFile),
+			assert: func(t *testing.T, err error, auth *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, "auth1", auth.ID())
+				require.Len(t, auth.trustStore, 1)
+				assert.True(t, ca.Certificate.Equal(auth.trustStore[0]))
+				assert.Nil(t, auth.fcc)
+				assert.Equal(t, &SubjectInfo{IDFrom: "subject.common_name"}, auth.sf)
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with full configuration",
+			config: []byte(`
+trust_store: ` + trustStoreFile + `
+forwarded_client_cert:
+  name: X-Forwarded-Client-Cert
+  format: xfcc
+subject:
+  id: spiffe_id
+  attributes: subject
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, "auth1", auth.ID())
+				require.Len(t, auth.trustStore, 1)
+				assert.Equal(t, &forwardedClientCertificate{
+					Name:   "X-Forwarded-Client-Cert",
+					Format: forwardedClientCertificateFormatXFCC,
+				}, auth.fcc)
+				assert.Equal(t, &SubjectInfo{IDFrom: "spiffe_id", AttributesFrom: "subject"}, auth.sf)
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with forwarded client certificate without format",
+			config: []byte(`
+trust_store: ` + trustStoreFile + `
+forwarded_client_cert:
+  name: X-Client-Cert`),
+			assert: func(t *testing.T, err error, auth *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, &forwardedClientCertificate{
+					Name:   "X-Client-Cert",
+					Format: forwardedClientCertificateFormatPEM,
+				}, auth.fcc)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newX509Authenticator("auth1", conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateX509AuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	prototype := &x509Authenticator{
+		id:         "auth1",
+		trustStore: truststore.TrustStore{},
+		fcc:        &forwardedClientCertificate{Name: "X-Client-Cert", Format: forwardedClientCertificateFormatPEM},
+		sf:         &SubjectInfo{IDFrom: "subject.common_name"},
+	}
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *x509Authenticator, configured *x509Authenticator)
+	}{
+		{
+			uc: "without new configuration",
+			assert: func(t *testing.T, err error, prototype *x509Authenticator, configured *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc: "with not overridable property",
+			config: []byte(`
+trust_store: /foo/bar.pem`),
+			assert: func(t *testing.T, err error, _ *x509Authenticator, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc: "with subject and fallback reconfigured",
+			config: []byte(`
+subject:
+  id: subject.serial_number
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *x509Authenticator, configured *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, prototype.trustStore, configured.trustStore)
+				assert.Equal(t, prototype.fcc, configured.fcc)
+				assert.Equal(t, &SubjectInfo{IDFrom: "subject.serial_number"}, configured.sf)
+				assert.False(t, prototype.IsFallbackOnErrorAllowed())
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				configured *x509Authenticator
+				ok         bool
+			)
+
+			if err == nil {
+				configured, ok = auth.(*x509Authenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestX509AuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	rootCA, err := testsupport.NewRootCA("Test Root CA", time.Hour)
+	require.NoError(t, err)
+
+	intCAKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	intCACert, err := rootCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "Test Int CA", Organization: []string{"Test"}}),
+		testsupport.WithIsCA(),
+		testsupport.WithValidity(time.Now(), time.Hour),
+		testsupport.WithSubjectPubKey(&intCAKey.PublicKey, x509.ECDSAWithSHA384))
+	require.NoError(t, err)
+
+	intCA := testsupport.NewCA(intCAKey, intCACert)
+
+	eeKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	spiffeID, err := url.Parse("spiffe://example.org/ns/default/sa/foo")
+	require.NoError(t, err)
+
+	clientCert, err := intCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{
+			CommonName:   "foo",
+			Organization: []string{"Test"},
+			Country:      []string{"EU"},
+		}),
+		testsupport.WithValidity(time.Now(), time.Hour),
+		testsupport.WithSubjectPubKey(&eeKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithExtendedKeyUsage(x509.ExtKeyUsageClientAuth),
+		testsupport.WithEMailAddresses([]string{"foo@example.org"}),
+		testsupport.WithURIs([]*url.URL{spiffeID}))
+	require.NoError(t, err)
+
+	serverCert, err := intCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "bar"}),
+		testsupport.WithValidity(time.Now(), time.Hour),
+		testsupport.WithSubjectPubKey(&eeKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithExtendedKeyUsage(x509.ExtKeyUsageServerAuth))
+	require.NoError(t, err)
+
+	toPEM := func(certs ...*x509.Certificate) string {
+		var buf []byte
+
+		for _, cert := range certs {
+			buf = append(buf, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+
+		return string(buf)
+	}
+
+	for _, tc := range []struct {
+		uc               string
+		authenticator    *x509Authenticator
+		configureContext func(t *testing.T, ctx *mocks.ContextMock)
+		assert           func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc: "no client certificate presented",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{rootCA.Certificate},
+				sf:         &SubjectInfo{IDFrom: "subject.common_name"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errNoClientCertificate)
+				assert.Contains(t, err.Error(), "no valid client certificate")
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth1", identifier.ID())
+			},
+		},
+		{
+			uc: "configured forwarded client certificate header not present",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{rootCA.Certificate},
+				fcc:        &forwardedClientCertificate{Name: "X-Client-Cert", Format: "pem"},
+				sf:         &SubjectInfo{IDFrom: "subject.common_name"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("X-Client-Cert").Return("")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions:   fnt,
+					ClientCertificates: []*x509.Certificate{clientCert, intCACert},
+					FromTrustedProxy:   true,
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errNoClientCertificate)
+				assert.Contains(t, err.Error(), "X-Client-Cert")
+			},
+		},
+		{
+			uc: "forwarded client certificate header not received from a trusted proxy",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{rootCA.Certificate},
+				fcc:        &forwardedClientCertificate{Name: "X-Client-Cert", Format: "pem"},
+				sf:         &SubjectInfo{IDFrom: "subject.common_name"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("X-Client-Cert").Return(url.PathEscape(toPEM(clientCert, intCACert))).Maybe()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errUntrustedClientCertificate)
+				assert.Contains(t, err.Error(), "not received from a trusted proxy")
+			},
+		},
+		{
+			uc: "trust store holding the issuing intermediate ca",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{intCACert},
+				sf:         &SubjectInfo{IDFrom: "subject.common_name"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{clientCert},
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc: "client certificate chain not leading to a trusted ca",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{rootCA.Certificate},
+				sf:         &SubjectInfo{IDFrom: "subject.common_name"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{clientCert},
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "not trusted")
+			},
+		},
+		{
+			uc: "certificate not usable for client authentication",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{rootCA.Certificate},
+				sf:         &SubjectInfo{IDFrom: "subject.common_name"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{serverCert, intCACert},
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "not trusted")
+			},
+		},
+		{
+			uc: "subject id not present in the certificate",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{rootCA.Certificate},
+				sf:         &SubjectInfo{IDFrom: "subject.organizational_unit.0"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{clientCert, intCACert},
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "failed to extract subject")
+			},
+		},
+		{
+			uc: "certificate chain presented in the TLS handshake",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{rootCA.Certificate},
+				sf:         &SubjectInfo{IDFrom: "subject.common_name"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{clientCert, intCACert},
+				})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "foo", sub.ID)
+				assert.Equal(t, "spiffe://example.org/ns/default/sa/foo", sub.Attributes["spiffe_id"])
+				assert.Equal(t, []any{"foo@example.org"}, sub.Attributes["email_addresses"])
+				assert.Equal(t, []any{"spiffe://example.org/ns/default/sa/foo"}, sub.Attributes["uris"])
+				assert.Equal(t, []any{}, sub.Attributes["dns_names"])
+				assert.Equal(t, clientCert.SerialNumber.String(), sub.Attributes["serial_number"])
+				assert.InDelta(t, float64(clientCert.NotAfter.Unix()), sub.Attributes["not_after"], 0)
+				assert.Len(t, sub.Attributes["fingerprint"], 64)
+
+				subjectAttrs, ok := sub.Attributes["subject"].(map[string]any)
+				require.True(t, ok)
+				assert.Equal(t, "foo", subjectAttrs["common_name"])
+				assert.Equal(t, []any{"Test"}, subjectAttrs["organization"])
+				assert.Equal(t, clientCert.Subject.String(), subjectAttrs["dn"])
+
+				issuerAttrs, ok := sub.Attributes["issuer"].(map[string]any)
+				require.True(t, ok)
+				assert.Equal(t, "Test Int CA", issuerAttrs["common_name"])
+			},
+		},
+		{
+			uc: "url encoded certificate chain forwarded in a header",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{rootCA.Certificate},
+				fcc:        &forwardedClientCertificate{Name: "X-Client-Cert", Format: "pem"},
+				sf:         &SubjectInfo{IDFrom: "spiffe_id", AttributesFrom: "subject"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("X-Client-Cert").Return(url.PathEscape(toPEM(clientCert, intCACert)))
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt, FromTrustedProxy: true})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "spiffe://example.org/ns/default/sa/foo", sub.ID)
+				assert.Equal(t, "foo", sub.Attributes["common_name"])
+			},
+		},
+		{
+			uc: "certificate chain forwarded in a x-forwarded-client-cert header",
+			authenticator: &x509Authenticator{
+				id:         "auth1",
+				trustStore: truststore.TrustStore{rootCA.Certificate},
+				fcc:        &forwardedClientCertificate{Name: "X-Forwarded-Client-Cert", Format: "xfcc"},
+				sf:         &SubjectInfo{IDFrom: "subject.common_name"},
+			},
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").Return(
+					`By=spiffe://example.org/bar;Cert="` + url.PathEscape(toPEM(serverCert)) + `",` +
+						`By=spiffe://example.org/baz;Hash=abc;Chain="` +
+						url.PathEscape(toPEM(clientCert, intCACert)) + `";Subject="CN=foo,O=Test,C=EU"`)
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt, FromTrustedProxy: true})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "foo", sub.ID)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(context.Background())
+			tc.configureContext(t, ctx)
+
+			// WHEN
+			sub, err := tc.authenticator.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
@@ -17,6 +17,8 @@
 package cellib
 
 import (
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"net/http"
 	"net/url"
 	"testing"
@@ -52,8 +54,11 @@ func TestRequests(t *testing.T) {
 	req := &heimdall.Request{
 		RequestFunctions:  reqf,
 		Method:            http.MethodHead,
-		URL:               uri,
+		URL:               &heimdall.URL{URL: *uri, Captures: map[string]string{"foo": "bar"}},
 		ClientIPAddresses: []string{"127.0.0.1"},
+		ClientCertificates: []*x509.Certificate{
+			{Subject: pkix.Name{CommonName: "foo"}, DNSNames: []string{"foo.example.com"}},
+		},
 	}
 
 	for _, tc := range []struct {
@@ -61,6 +66,8 @@ func TestRequests(t *testing.T) {
 	}{
 		{expr: `Request.Method == "HEAD"`},
 		{expr: `Request.URL.String() == "` + rawURI + `"`},
+		{expr: `Request.URL.Path == "/foo/bar"`},
+		{expr: `Request.URL.Captures.foo == "bar"`},
 		{expr: `Request.Cookie("foo") == "bar"`},
 		{expr: `Request.Header("bar") == "baz"`},
 		{expr: `Request.Header("zab").contains("bar")`},
@@ -68,6 +75,8 @@ func TestRequests(t *testing.T) {
 		{expr: `["text/html", "application/xml", "application/json"].exists(v, Request.Header("accept").contains(v))`},
 		{expr: `Request.ClientIPAddresses in networks("127.0.0.0/24")`},
 		{expr: `Request.Body().foo[0] == "bar"`},
+		{expr: `Request.ClientCertificates[0].Subject.CommonName == "foo"`},
+		{expr: `"foo.example.com" in Request.ClientCertificates[0].DNSNames`},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			ast, iss := env.Compile(tc.expr)
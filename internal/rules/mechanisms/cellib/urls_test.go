@@ -22,6 +22,8 @@ import (
 
 	"github.com/google/cel-go/cel"
 	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
 )
 
 func TestUrls(t *testing.T) {
@@ -37,12 +39,16 @@ func TestUrls(t *testing.T) {
 	uri, err := url.Parse("http://localhost/foo/bar?foo=bar&foo=baz&bar=foo")
 	require.NoError(t, err)
 
+	value := &heimdall.URL{URL: *uri, Captures: map[string]string{"foo": "bar"}}
+
 	for _, tc := range []struct {
 		expr string
 	}{
 		{expr: `uri.String() == "` + rawURI + `"`},
 		{expr: `uri.Query() == {"foo":["bar", "baz"], "bar": ["foo"]}`},
 		{expr: `uri.Query().bar == ["foo"]`},
+		{expr: `uri.Host == "localhost"`},
+		{expr: `uri.Captures == {"foo": "bar"}`},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			ast, iss := env.Compile(tc.expr)
@@ -58,7 +64,7 @@ func TestUrls(t *testing.T) {
 			prg, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
 			require.NoError(t, err)
 
-			out, _, err := prg.Eval(map[string]any{"uri": uri})
+			out, _, err := prg.Eval(map[string]any{"uri": value})
 			require.NoError(t, err)
 			require.Equal(t, true, out.Value()) //nolint:testifylint
 		})
@@ -25,13 +25,14 @@ import (
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/contextualizers"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/finalizers"
+	"github.com/dadrus/heimdall/internal/watcher"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
-func NewFactory(conf *config.Configuration, logger zerolog.Logger) (Factory, error) {
+func NewFactory(conf *config.Configuration, logger zerolog.Logger, cw watcher.Watcher) (Factory, error) {
 	logger.Info().Msg("Loading pipeline definitions")
 
-	repository, err := newPrototypeRepository(conf, logger)
+	repository, err := newPrototypeRepository(conf, logger, cw)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed loading pipeline definitions")
 
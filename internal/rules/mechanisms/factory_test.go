@@ -551,7 +551,7 @@ func TestCreateHandlerFactory(t *testing.T) {
 			)
 
 			// WHEN
-			factory, err := NewFactory(tc.conf, log.Logger)
+			factory, err := NewFactory(tc.conf, log.Logger, nil)
 
 			// THEN
 			if err == nil {
@@ -22,4 +22,5 @@ const (
 	FinalizerHeader                  = "header"
 	FinalizerCookie                  = "cookie"
 	FinalizerOAuth2ClientCredentials = "oauth2_client_credentials" // nolint: gosec
+	FinalizerHTTPSignature           = "http_signature"
 )
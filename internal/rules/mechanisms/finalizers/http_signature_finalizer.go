@@ -0,0 +1,231 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package finalizers
+
+import (
+	"crypto"
+	"slices"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/httpsig"
+)
+
+const (
+	defaultHTTPSignatureTTL   = 1 * time.Minute
+	defaultHTTPSignatureLabel = "heimdall"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Finalizer, error) {
+			if typ != FinalizerHTTPSignature {
+				return false, nil, nil
+			}
+
+			finalizer, err := newHTTPSignatureFinalizer(id, conf)
+
+			return true, finalizer, err
+		})
+}
+
+type httpSignatureFinalizer struct {
+	id         string
+	key        *keystore.Entry
+	components []httpsig.Component
+	label      string
+	tag        string
+	ttl        time.Duration
+}
+
+func newHTTPSignatureFinalizer(id string, rawConfig map[string]any) (*httpSignatureFinalizer, error) {
+	type KeyStore struct {
+		Path     string `mapstructure:"path"     validate:"required"`
+		Password string `mapstructure:"password"`
+	}
+
+	type Config struct {
+		KeyStore       *KeyStore      `mapstructure:"key_store"`
+		KeyID          string         `mapstructure:"key_id"          validate:"excluded_without=KeyStore"`
+		Components     []string       `mapstructure:"components"`
+		SignatureLabel string         `mapstructure:"signature_label"`
+		Tag            string         `mapstructure:"tag"`
+		TTL            *time.Duration `mapstructure:"ttl"             validate:"omitempty,gt=1s"`
+	}
+
+	var conf Config
+	if err := decodeConfig(FinalizerHTTPSignature, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	components, err := httpsig.ParseComponents(conf.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *keystore.Entry
+
+	if conf.KeyStore != nil {
+		if entry, err = loadSigningKey(conf.KeyStore.Path, conf.KeyStore.Password, conf.KeyID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &httpSignatureFinalizer{
+		id:         id,
+		key:        entry,
+		components: components,
+		label:      x.IfThenElse(len(conf.SignatureLabel) != 0, conf.SignatureLabel, defaultHTTPSignatureLabel),
+		tag:        conf.Tag,
+		ttl: x.IfThenElseExec(conf.TTL != nil,
+			func() time.Duration { return *conf.TTL },
+			func() time.Duration { return defaultHTTPSignatureTTL }),
+	}, nil
+}
+
+func loadSigningKey(path, password, keyID string) (*keystore.Entry, error) {
+	ks, err := keystore.NewKeyStoreFromPEMFile(path, password)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed loading key store").
+			CausedBy(err)
+	}
+
+	var entry *keystore.Entry
+
+	if len(keyID) == 0 {
+		entry = ks.Entries()[0]
+	} else if entry, err = ks.GetKey(keyID); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed retrieving key from key store").CausedBy(err)
+	}
+
+	if _, err = httpsig.AlgorithmFor(entry.PrivateKey, string(entry.JOSEAlgorithm())); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"key with key_id=%s cannot be used for http message signatures", entry.KeyID).CausedBy(err)
+	}
+
+	return entry, nil
+}
+
+func (f *httpSignatureFinalizer) Execute(ctx heimdall.Context, _ *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", f.id).Msg("Finalizing using http_signature finalizer")
+
+	jwk, key := f.signingKey(ctx)
+
+	alg, err := httpsig.AlgorithmFor(key, jwk.Algorithm)
+	if err != nil {
+		return errorchain.
+			NewWithMessagef(heimdall.ErrInternal,
+				"key with key_id=%s cannot be used for http message signatures", jwk.KeyID).
+			WithErrorContext(f).
+			CausedBy(err)
+	}
+
+	req := ctx.Request()
+	now := time.Now()
+	msg := httpsig.NewRequestMessage(req)
+
+	if slices.Contains(f.components, httpsig.Component{Name: httpsig.ComponentContentDigest}) {
+		// the digest sent by the client is not trusted and is replaced by the computed one
+		digest := httpsig.ContentDigest(req.RawBody())
+		msg = httpsig.WithHeader(msg, httpsig.HeaderContentDigest, digest)
+
+		ctx.AddHeaderForUpstream(httpsig.HeaderContentDigest, digest)
+	}
+
+	input, signature, err := httpsig.Sign(msg, f.label, httpsig.SignatureParams{
+		Components: f.components,
+		Created:    now,
+		Expires:    now.Add(f.ttl),
+		Nonce:      uuid.NewString(),
+		Algorithm:  alg,
+		KeyID:      jwk.KeyID,
+		Tag:        f.tag,
+	}, alg, key)
+	if err != nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to sign request").
+			WithErrorContext(f).
+			CausedBy(err)
+	}
+
+	ctx.AddHeaderForUpstream(httpsig.HeaderSignatureInput, input)
+	ctx.AddHeaderForUpstream(httpsig.HeaderSignature, signature)
+
+	return nil
+}
+
+func (f *httpSignatureFinalizer) WithConfig(rawConfig map[string]any) (Finalizer, error) {
+	if len(rawConfig) == 0 {
+		return f, nil
+	}
+
+	type Config struct {
+		Components []string       `mapstructure:"components"`
+		TTL        *time.Duration `mapstructure:"ttl"        validate:"omitempty,gt=1s"`
+	}
+
+	var conf Config
+	if err := decodeConfig(FinalizerHTTPSignature, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	components := f.components
+
+	if len(conf.Components) != 0 {
+		var err error
+
+		if components, err = httpsig.ParseComponents(conf.Components); err != nil {
+			return nil, err
+		}
+	}
+
+	return &httpSignatureFinalizer{
+		id:         f.id,
+		key:        f.key,
+		components: components,
+		label:      f.label,
+		tag:        f.tag,
+		ttl: x.IfThenElseExec(conf.TTL != nil,
+			func() time.Duration { return *conf.TTL },
+			func() time.Duration { return f.ttl }),
+	}, nil
+}
+
+func (f *httpSignatureFinalizer) ID() string { return f.id }
+
+func (f *httpSignatureFinalizer) ContinueOnError() bool { return false }
+
+func (f *httpSignatureFinalizer) signingKey(ctx heimdall.Context) (jose.JSONWebKey, crypto.Signer) {
+	if f.key != nil {
+		return f.key.JWK(), f.key.PrivateKey
+	}
+
+	return ctx.Signer().SigningKey()
+}
@@ -0,0 +1,510 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package finalizers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/httpsig"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateHTTPSignatureFinalizer(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	ecP521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	testDir := t.TempDir()
+
+	keyFile := filepath.Join(testDir, "keys.pem")
+	pemBytes, err := pemx.BuildPEM(
+		pemx.WithECDSAPrivateKey(ecKey, pemx.WithHeader("X-Key-ID", "key1")),
+		pemx.WithRSAPrivateKey(rsaKey, pemx.WithHeader("X-Key-ID", "key2")),
+		pemx.WithECDSAPrivateKey(ecP521Key, pemx.WithHeader("X-Key-ID", "key3")),
+	)
+	require.NoError(t, err)
+
+	err = os.WriteFile(keyFile, pemBytes, 0o600)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, finalizer *httpSignatureFinalizer)
+	}{
+		{
+			uc: "without config",
+			assert: func(t *testing.T, err error, finalizer *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, finalizer)
+
+				assert.Equal(t, "sig", finalizer.ID())
+				assert.False(t, finalizer.ContinueOnError())
+				assert.Nil(t, finalizer.key)
+				assert.Equal(t, []httpsig.Component{
+					{Name: httpsig.ComponentMethod},
+					{Name: httpsig.ComponentAuthority},
+					{Name: httpsig.ComponentPath},
+				}, finalizer.components)
+				assert.Equal(t, defaultHTTPSignatureLabel, finalizer.label)
+				assert.Equal(t, defaultHTTPSignatureTTL, finalizer.ttl)
+				assert.Empty(t, finalizer.tag)
+			},
+		},
+		{
+			uc:     "with unsupported properties",
+			config: []byte(`foo: bar`),
+			assert: func(t *testing.T, err error, _ *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc:     "with too short ttl",
+			config: []byte(`ttl: 1s`),
+			assert: func(t *testing.T, err error, _ *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "ttl")
+			},
+		},
+		{
+			uc:     "with key id, but without key store",
+			config: []byte(`key_id: foo`),
+			assert: func(t *testing.T, err error, _ *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "key_id")
+			},
+		},
+		{
+			uc:     "with invalid component",
+			config: []byte(`components: ["@foo;bar=1"]`),
+			assert: func(t *testing.T, err error, _ *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "invalid component")
+			},
+		},
+		{
+			uc: "with not existing key store",
+			config: []byte(`
+key_store:
+  path: ` + filepath.Join(testDir, "foo.pem")),
+			assert: func(t *testing.T, err error, _ *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed loading key store")
+			},
+		},
+		{
+			uc: "with unknown key id",
+			config: []byte(`
+key_store:
+  path: ` + keyFile + `
+key_id: foo`),
+			assert: func(t *testing.T, err error, _ *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed retrieving key")
+			},
+		},
+		{
+			uc: "with key not usable for http message signatures",
+			config: []byte(`
+key_store:
+  path: ` + keyFile + `
+key_id: key3`),
+			assert: func(t *testing.T, err error, _ *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorIs(t, err, httpsig.ErrUnsupportedAlgorithm)
+			},
+		},
+		{
+			uc: "with key store without key id",
+			config: []byte(`
+key_store:
+  path: ` + keyFile),
+			assert: func(t *testing.T, err error, finalizer *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, finalizer.key)
+				assert.Equal(t, "key1", finalizer.key.KeyID)
+			},
+		},
+		{
+			uc: "with full configuration",
+			config: []byte(`
+key_store:
+  path: ` + keyFile + `
+key_id: key2
+components: ["@method", "@target-uri", "content-digest"]
+signature_label: foo
+tag: bar
+ttl: 10s`),
+			assert: func(t *testing.T, err error, finalizer *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, finalizer.key)
+
+				assert.Equal(t, "key2", finalizer.key.KeyID)
+				assert.Equal(t, []httpsig.Component{
+					{Name: httpsig.ComponentMethod},
+					{Name: httpsig.ComponentTargetURI},
+					{Name: "content-digest"},
+				}, finalizer.components)
+				assert.Equal(t, "foo", finalizer.label)
+				assert.Equal(t, "bar", finalizer.tag)
+				assert.Equal(t, 10*time.Second, finalizer.ttl)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			finalizer, err := newHTTPSignatureFinalizer("sig", conf)
+
+			// THEN
+			tc.assert(t, err, finalizer)
+		})
+	}
+}
+
+func TestCreateHTTPSignatureFinalizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *httpSignatureFinalizer, configured *httpSignatureFinalizer)
+	}{
+		{
+			uc: "without target config",
+			assert: func(t *testing.T, err error, prototype *httpSignatureFinalizer,
+				configured *httpSignatureFinalizer,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with components and ttl",
+			config: []byte(`{ components: ["@method", "authorization"], ttl: 30s }`),
+			assert: func(t *testing.T, err error, prototype *httpSignatureFinalizer,
+				configured *httpSignatureFinalizer,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, prototype.label, configured.label)
+				assert.Equal(t, prototype.key, configured.key)
+				assert.Equal(t, []httpsig.Component{
+					{Name: httpsig.ComponentMethod},
+					{Name: "authorization"},
+				}, configured.components)
+				assert.Equal(t, 30*time.Second, configured.ttl)
+			},
+		},
+		{
+			uc:     "with key store redefinition",
+			config: []byte(`key_store: { path: /foo/bar.pem }`),
+			assert: func(t *testing.T, err error, _ *httpSignatureFinalizer, _ *httpSignatureFinalizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newHTTPSignatureFinalizer("sig", nil)
+			require.NoError(t, err)
+
+			// WHEN
+			finalizer, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				configured *httpSignatureFinalizer
+				ok         bool
+			)
+
+			if err == nil {
+				configured, ok = finalizer.(*httpSignatureFinalizer)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestHTTPSignatureFinalizerExecute(t *testing.T) {
+	t.Parallel()
+
+	signerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	storeKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ks, err := keystore.NewKeyStoreFromKey(storeKey)
+	require.NoError(t, err)
+
+	reqURL, err := url.Parse("https://example.com/foo?bar=baz")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc             string
+		finalizer      *httpSignatureFinalizer
+		configureMocks func(t *testing.T, ctx *heimdallmocks.ContextMock, fnt *heimdallmocks.RequestFunctionsMock)
+		assert         func(t *testing.T, err error, sig *httpsig.Signature, msg httpsig.Message)
+	}{
+		{
+			uc: "with signer key not usable for http message signatures",
+			finalizer: &httpSignatureFinalizer{
+				id: "sig", components: []httpsig.Component{{Name: httpsig.ComponentMethod}},
+			},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, _ *heimdallmocks.RequestFunctionsMock) {
+				t.Helper()
+
+				signer := heimdallmocks.NewJWTSignerMock(t)
+				signer.EXPECT().SigningKey().Return(jose.JSONWebKey{KeyID: "foo", Algorithm: "ES512"}, signerKey)
+
+				ctx.EXPECT().Signer().Return(signer)
+			},
+			assert: func(t *testing.T, err error, _ *httpsig.Signature, _ httpsig.Message) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorIs(t, err, httpsig.ErrUnsupportedAlgorithm)
+
+				var identifier interface{ ID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "sig", identifier.ID())
+			},
+		},
+		{
+			uc: "with heimdall's signer key",
+			finalizer: &httpSignatureFinalizer{
+				id:    "sig",
+				label: "heimdall",
+				ttl:   time.Minute,
+				tag:   "foo",
+				components: []httpsig.Component{
+					{Name: httpsig.ComponentMethod},
+					{Name: httpsig.ComponentAuthority},
+					{Name: httpsig.ComponentPath},
+					{Name: httpsig.ComponentContentDigest},
+				},
+			},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, fnt *heimdallmocks.RequestFunctionsMock) {
+				t.Helper()
+
+				signer := heimdallmocks.NewJWTSignerMock(t)
+				signer.EXPECT().SigningKey().Return(
+					jose.JSONWebKey{KeyID: "signer", Algorithm: string(jose.ES256), Key: signerKey.Public()},
+					signerKey,
+				)
+
+				ctx.EXPECT().Signer().Return(signer)
+				ctx.EXPECT().AddHeaderForUpstream(httpsig.HeaderContentDigest, httpsig.ContentDigest([]byte("foo")))
+
+				// the digest sent by the client must not be used
+				fnt.EXPECT().HeaderValues("content-digest").Return([]string{"sha-256=:Zm9v:"}).Maybe()
+				fnt.EXPECT().RawBody().Return([]byte("foo"))
+			},
+			assert: func(t *testing.T, err error, sig *httpsig.Signature, msg httpsig.Message) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sig)
+
+				assert.Equal(t, "heimdall", sig.Label)
+				assert.Equal(t, "signer", sig.Params.KeyID)
+				assert.Equal(t, httpsig.AlgECDSAP256SHA256, sig.Params.Algorithm)
+				assert.Equal(t, "foo", sig.Params.Tag)
+				assert.NotEmpty(t, sig.Params.Nonce)
+				assert.Equal(t, time.Minute, sig.Params.Expires.Sub(sig.Params.Created))
+				assert.Equal(t, []httpsig.Component{
+					{Name: httpsig.ComponentMethod},
+					{Name: httpsig.ComponentAuthority},
+					{Name: httpsig.ComponentPath},
+					{Name: httpsig.ComponentContentDigest},
+				}, sig.Params.Components)
+
+				msg = httpsig.WithHeader(msg, httpsig.HeaderContentDigest, httpsig.ContentDigest([]byte("foo")))
+				require.NoError(t, sig.Verify(msg, httpsig.AlgECDSAP256SHA256, &signerKey.PublicKey))
+			},
+		},
+		{
+			uc: "with covered header not present in the request",
+			finalizer: &httpSignatureFinalizer{
+				id:    "sig",
+				label: "heimdall",
+				ttl:   time.Minute,
+				components: []httpsig.Component{
+					{Name: httpsig.ComponentMethod},
+					{Name: "x-foo"},
+				},
+			},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, fnt *heimdallmocks.RequestFunctionsMock) {
+				t.Helper()
+
+				signer := heimdallmocks.NewJWTSignerMock(t)
+				signer.EXPECT().SigningKey().Return(
+					jose.JSONWebKey{KeyID: "signer", Algorithm: string(jose.ES256), Key: signerKey.Public()},
+					signerKey,
+				)
+
+				ctx.EXPECT().Signer().Return(signer)
+
+				fnt.EXPECT().HeaderValues("x-foo").Return(nil)
+			},
+			assert: func(t *testing.T, err error, _ *httpsig.Signature, _ httpsig.Message) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorIs(t, err, httpsig.ErrMissingComponent)
+				require.ErrorContains(t, err, "failed to sign request")
+			},
+		},
+		{
+			uc: "with key from configured key store",
+			finalizer: &httpSignatureFinalizer{
+				id:         "sig",
+				key:        ks.Entries()[0],
+				label:      "foo",
+				ttl:        time.Minute,
+				components: []httpsig.Component{{Name: httpsig.ComponentMethod}, {Name: httpsig.ComponentTargetURI}},
+			},
+			assert: func(t *testing.T, err error, sig *httpsig.Signature, msg httpsig.Message) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sig)
+
+				assert.Equal(t, "foo", sig.Label)
+				assert.Equal(t, ks.Entries()[0].KeyID, sig.Params.KeyID)
+				assert.Equal(t, httpsig.AlgRSAPSSSHA512, sig.Params.Algorithm)
+
+				require.NoError(t, sig.Verify(msg, httpsig.AlgRSAPSSSHA512, &storeKey.PublicKey))
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			var input, signature string
+
+			fnt := heimdallmocks.NewRequestFunctionsMock(t)
+			req := &heimdall.Request{
+				RequestFunctions: fnt,
+				Method:           http.MethodPost,
+				URL:              &heimdall.URL{URL: *reqURL},
+			}
+
+			ctx := heimdallmocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(context.Background())
+			ctx.EXPECT().Request().Return(req).Maybe()
+			ctx.EXPECT().AddHeaderForUpstream(httpsig.HeaderSignatureInput, mock.Anything).
+				Run(func(_ string, value string) { input = value }).Maybe()
+			ctx.EXPECT().AddHeaderForUpstream(httpsig.HeaderSignature, mock.Anything).
+				Run(func(_ string, value string) { signature = value }).Maybe()
+
+			if tc.configureMocks != nil {
+				tc.configureMocks(t, ctx, fnt)
+			}
+
+			fnt.EXPECT().HeaderValues(mock.Anything).Return(nil).Maybe()
+
+			// WHEN
+			err := tc.finalizer.Execute(ctx, &subject.Subject{ID: "foo"})
+
+			// THEN
+			var sig *httpsig.Signature
+
+			if err == nil {
+				sigs, err := httpsig.ParseSignatures(input, signature)
+				require.NoError(t, err)
+				require.Len(t, sigs, 1)
+
+				sig = sigs[0]
+			}
+
+			tc.assert(t, err, sig, httpsig.NewRequestMessage(req))
+		})
+	}
+}
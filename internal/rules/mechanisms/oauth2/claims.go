@@ -22,15 +22,16 @@ import (
 
 // Claims represents public claim values (as specified in RFC 7519).
 type Claims struct {
-	Issuer    string       `json:"iss,omitempty"`
-	Subject   string       `json:"sub,omitempty"`
-	Audience  Audience     `json:"aud,omitempty"`
-	Scp       Scopes       `json:"scp,omitempty"`
-	Scope     Scopes       `json:"scope,omitempty"`
-	Expiry    *NumericDate `json:"exp,omitempty"`
-	NotBefore *NumericDate `json:"nbf,omitempty"`
-	IssuedAt  *NumericDate `json:"iat,omitempty"`
-	ID        string       `json:"jti,omitempty"`
+	Issuer       string        `json:"iss,omitempty"`
+	Subject      string        `json:"sub,omitempty"`
+	Audience     Audience      `json:"aud,omitempty"`
+	Scp          Scopes        `json:"scp,omitempty"`
+	Scope        Scopes        `json:"scope,omitempty"`
+	Expiry       *NumericDate  `json:"exp,omitempty"`
+	NotBefore    *NumericDate  `json:"nbf,omitempty"`
+	IssuedAt     *NumericDate  `json:"iat,omitempty"`
+	ID           string        `json:"jti,omitempty"`
+	Confirmation *Confirmation `json:"cnf,omitempty"`
 }
 
 func (c Claims) Validate(exp Expectation) error {
@@ -0,0 +1,28 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+// Confirmation represents the confirmation claim (RFC 7800), which binds a token to a key
+// or a certificate of the client.
+type Confirmation struct {
+	// X509CertificateSHA256Thumbprint is the base64url encoded SHA-256 thumbprint of the
+	// DER encoded client certificate as defined in RFC 8705.
+	X509CertificateSHA256Thumbprint string `json:"x5t#S256,omitempty"`
+	// JWKSHA256Thumbprint is the base64url encoded SHA-256 JWK thumbprint of the key
+	// used for DPoP proofs as defined in RFC 9449.
+	JWKSHA256Thumbprint string `json:"jkt,omitempty"`
+}
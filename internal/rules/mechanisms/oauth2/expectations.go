@@ -17,6 +17,10 @@
 package oauth2
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
 	"errors"
 	"slices"
 	"time"
@@ -109,4 +113,26 @@ func (e *Expectation) AssertIssuanceTime(issuedAt time.Time) error {
 	return nil
 }
 
+// AssertCertificateBinding asserts the token is bound to the given client certificate according
+// to RFC 8705, section 3.1.
+func (e *Expectation) AssertCertificateBinding(cnf *Confirmation, cert *x509.Certificate) error {
+	if cnf == nil || len(cnf.X509CertificateSHA256Thumbprint) == 0 {
+		return errorchain.NewWithMessage(ErrAssertion, "not bound to a certificate")
+	}
+
+	if cert == nil {
+		return errorchain.NewWithMessage(ErrAssertion, "no client certificate present")
+	}
+
+	thumbprint := sha256.Sum256(cert.Raw)
+
+	if subtle.ConstantTimeCompare(
+		[]byte(base64.RawURLEncoding.EncodeToString(thumbprint[:])),
+		[]byte(cnf.X509CertificateSHA256Thumbprint)) != 1 {
+		return errorchain.NewWithMessage(ErrAssertion, "bound to a different certificate")
+	}
+
+	return nil
+}
+
 func (e *Expectation) AssertScopes(scopes []string) error { return e.ScopesMatcher.Match(scopes) }
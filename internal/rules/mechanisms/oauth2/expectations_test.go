@@ -17,6 +17,9 @@
 package oauth2
 
 import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"testing"
 	"time"
 
@@ -524,6 +527,86 @@ func TestExpectationAssertScopes(t *testing.T) {
 	}
 }
 
+func TestExpectationAssertCertificateBinding(t *testing.T) {
+	t.Parallel()
+
+	cert := &x509.Certificate{Raw: []byte("foo")}
+	otherCert := &x509.Certificate{Raw: []byte("bar")}
+	thumbprint := sha256.Sum256(cert.Raw)
+	cnf := &Confirmation{X509CertificateSHA256Thumbprint: base64.RawURLEncoding.EncodeToString(thumbprint[:])}
+
+	for _, tc := range []struct {
+		uc     string
+		cnf    *Confirmation
+		cert   *x509.Certificate
+		assert func(t *testing.T, err error)
+	}{
+		{
+			uc:   "without confirmation claim",
+			cert: cert,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrAssertion)
+				require.ErrorContains(t, err, "not bound")
+			},
+		},
+		{
+			uc:   "without certificate thumbprint in the confirmation claim",
+			cnf:  &Confirmation{JWKSHA256Thumbprint: "foo"},
+			cert: cert,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrAssertion)
+				require.ErrorContains(t, err, "not bound")
+			},
+		},
+		{
+			uc:  "without client certificate",
+			cnf: cnf,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrAssertion)
+				require.ErrorContains(t, err, "no client certificate")
+			},
+		},
+		{
+			uc:   "with different client certificate",
+			cnf:  cnf,
+			cert: otherCert,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrAssertion)
+				require.ErrorContains(t, err, "different certificate")
+			},
+		},
+		{
+			uc:   "with matching client certificate",
+			cnf:  cnf,
+			cert: cert,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			exp := Expectation{}
+
+			// WHEN
+			err := exp.AssertCertificateBinding(tc.cnf, tc.cert)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
 func TestMergeExpectations(t *testing.T) {
 	t.Parallel()
 
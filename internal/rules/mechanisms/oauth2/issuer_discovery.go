@@ -0,0 +1,144 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/syncx"
+)
+
+const (
+	defaultIssuerDiscoveryCacheSize = 100
+	defaultIssuerDiscoveryCacheTTL  = 30 * time.Minute
+	issuerDiscoveryTimeout          = 10 * time.Second
+)
+
+// IssuerDiscovery resolves the server metadata dynamically for the issuer of the token to verify.
+// To prevent heimdall from communicating with arbitrary servers, the issuer must be either one of the
+// configured issuers, or match one of the configured glob patterns. The metadata is cached per issuer,
+// with the amount of cached entries being bounded by the configured cache size.
+type IssuerDiscovery struct {
+	Issuers        []string       `mapstructure:"issuers"`
+	IssuerPatterns []string       `mapstructure:"issuer_patterns"`
+	CacheSize      int            `mapstructure:"cache_size"      validate:"gte=0"`
+	CacheTTL       *time.Duration `mapstructure:"cache_ttl"`
+
+	matchers []patternmatcher.PatternMatcher
+	cache    *ttlcache.Cache[string, ServerMetadata]
+	group    singleflight.Group
+}
+
+func (d *IssuerDiscovery) Init() error {
+	if len(d.Issuers) == 0 && len(d.IssuerPatterns) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"issuer discovery requires issuers or issuer_patterns to be configured")
+	}
+
+	d.matchers = make([]patternmatcher.PatternMatcher, len(d.IssuerPatterns))
+
+	for idx, pattern := range d.IssuerPatterns {
+		matcher, err := patternmatcher.NewPatternMatcher("glob", pattern)
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed to compile issuer pattern '%s'", pattern).CausedBy(err)
+		}
+
+		d.matchers[idx] = matcher
+	}
+
+	size := x.IfThenElse(d.CacheSize != 0, d.CacheSize, defaultIssuerDiscoveryCacheSize)
+	ttl := x.IfThenElseExec(d.CacheTTL != nil,
+		func() time.Duration { return *d.CacheTTL },
+		func() time.Duration { return defaultIssuerDiscoveryCacheTTL })
+
+	d.cache = ttlcache.New[string, ServerMetadata](
+		ttlcache.WithCapacity[string, ServerMetadata](uint64(size)),
+		ttlcache.WithTTL[string, ServerMetadata](ttl),
+		ttlcache.WithDisableTouchOnHit[string, ServerMetadata](),
+	)
+
+	return nil
+}
+
+func (d *IssuerDiscovery) Get(ctx context.Context, args map[string]any) (ServerMetadata, error) {
+	issuer, _ := args["TokenIssuer"].(string)
+	if !d.isTrusted(issuer) {
+		return ServerMetadata{}, errorchain.NewWithMessagef(ErrAssertion, "issuer '%s' is not trusted", issuer)
+	}
+
+	if item := d.cache.Get(issuer); item != nil && !item.IsExpired() {
+		return item.Value(), nil
+	}
+
+	// the request is shared by all callers asking for the same issuer
+	return syncx.DoDetached(ctx, &d.group, issuer, issuerDiscoveryTimeout,
+		func(ctx context.Context) (ServerMetadata, error) {
+			ep := &MetadataEndpoint{
+				Endpoint: endpoint.Endpoint{
+					URL: strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration",
+					// caching happens on the issuer level
+					HTTPCache: &endpoint.HTTPCache{Enabled: false},
+				},
+			}
+
+			metadata, err := ep.Get(ctx, nil)
+			if err != nil {
+				return ServerMetadata{}, err
+			}
+
+			// OpenID Connect Discovery 1.0, section 4.3 requires both to be identical
+			if metadata.Issuer != issuer {
+				return ServerMetadata{}, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"issuer '%s' from the received metadata does not match the token issuer '%s'",
+					metadata.Issuer, issuer)
+			}
+
+			d.cache.Set(issuer, metadata, ttlcache.DefaultTTL)
+
+			return metadata, nil
+		})
+}
+
+func (d *IssuerDiscovery) isTrusted(issuer string) bool {
+	if len(issuer) == 0 {
+		return false
+	}
+
+	if slices.Contains(d.Issuers, issuer) {
+		return true
+	}
+
+	for _, matcher := range d.matchers {
+		if matcher.Match(issuer) {
+			return true
+		}
+	}
+
+	return false
+}
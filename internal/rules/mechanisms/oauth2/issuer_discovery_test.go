@@ -0,0 +1,311 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func TestIssuerDiscoveryInit(t *testing.T) {
+	t.Parallel()
+
+	ttl := 5 * time.Minute
+
+	for _, tc := range []struct {
+		uc        string
+		discovery *IssuerDiscovery
+		assert    func(t *testing.T, err error, discovery *IssuerDiscovery)
+	}{
+		{
+			uc:        "without issuers and issuer patterns",
+			discovery: &IssuerDiscovery{},
+			assert: func(t *testing.T, err error, _ *IssuerDiscovery) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "requires issuers or issuer_patterns")
+			},
+		},
+		{
+			uc:        "with malformed issuer pattern",
+			discovery: &IssuerDiscovery{IssuerPatterns: []string{"https://<*.example.com"}},
+			assert: func(t *testing.T, err error, _ *IssuerDiscovery) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "failed to compile issuer pattern")
+			},
+		},
+		{
+			uc:        "with issuers only and defaults",
+			discovery: &IssuerDiscovery{Issuers: []string{"https://foo.example.com"}},
+			assert: func(t *testing.T, err error, discovery *IssuerDiscovery) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, discovery.matchers)
+				require.NotNil(t, discovery.cache)
+			},
+		},
+		{
+			uc: "with issuer patterns and cache settings",
+			discovery: &IssuerDiscovery{
+				IssuerPatterns: []string{"https://<*>.example.com", "https://example.com/realms/<*>"},
+				CacheSize:      10,
+				CacheTTL:       &ttl,
+			},
+			assert: func(t *testing.T, err error, discovery *IssuerDiscovery) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Len(t, discovery.matchers, 2)
+				require.NotNil(t, discovery.cache)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			err := tc.discovery.Init()
+
+			// THEN
+			tc.assert(t, err, tc.discovery)
+		})
+	}
+}
+
+func TestIssuerDiscoveryIsTrusted(t *testing.T) {
+	t.Parallel()
+
+	discovery := &IssuerDiscovery{
+		Issuers:        []string{"https://foo.example.org"},
+		IssuerPatterns: []string{"https://<*>.example.com", "https://example.com/realms/<*>"},
+	}
+	require.NoError(t, discovery.Init())
+
+	for _, tc := range []struct {
+		issuer  string
+		trusted bool
+	}{
+		{issuer: "", trusted: false},
+		{issuer: "https://foo.example.org", trusted: true},
+		{issuer: "https://bar.example.org", trusted: false},
+		{issuer: "https://tenant1.example.com", trusted: true},
+		{issuer: "https://tenant1.evil.com/.example.com", trusted: false},
+		{issuer: "https://a.b.example.com", trusted: false},
+		{issuer: "https://example.com/realms/tenant1", trusted: true},
+		{issuer: "https://example.com/realms/tenant1/foo", trusted: false},
+	} {
+		t.Run(tc.issuer, func(t *testing.T) {
+			// WHEN
+			trusted := discovery.isTrusted(tc.issuer)
+
+			// THEN
+			assert.Equal(t, tc.trusted, trusted)
+		})
+	}
+}
+
+func TestIssuerDiscoveryGet(t *testing.T) {
+	t.Parallel()
+
+	var srv1Calls, srv2Calls int
+
+	newServer := func(calls *int) *httptest.Server {
+		var srv *httptest.Server
+
+		srv = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			*calls++
+
+			assert.Equal(t, "/.well-known/openid-configuration", req.URL.Path)
+
+			rw.Header().Set("Content-Type", "application/json")
+			err := json.NewEncoder(rw).Encode(map[string]any{
+				"issuer":   srv.URL,
+				"jwks_uri": srv.URL + "/jwks",
+			})
+			require.NoError(t, err)
+		}))
+
+		return srv
+	}
+
+	srv1 := newServer(&srv1Calls)
+	defer srv1.Close()
+
+	srv2 := newServer(&srv2Calls)
+	defer srv2.Close()
+
+	discovery := &IssuerDiscovery{
+		Issuers:        []string{srv1.URL, srv2.URL, srv2.URL + "/"},
+		IssuerPatterns: []string{"https://<*>.heimdall.test"},
+		CacheSize:      1,
+	}
+	require.NoError(t, discovery.Init())
+
+	for _, tc := range []struct {
+		uc     string
+		issuer any
+		assert func(t *testing.T, err error, sm ServerMetadata)
+	}{
+		{
+			uc: "without issuer",
+			assert: func(t *testing.T, err error, _ ServerMetadata) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrAssertion)
+				assert.Zero(t, srv1Calls+srv2Calls)
+			},
+		},
+		{
+			uc:     "with untrusted issuer",
+			issuer: "https://example.org",
+			assert: func(t *testing.T, err error, _ ServerMetadata) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrAssertion)
+				require.ErrorContains(t, err, "not trusted")
+				assert.Zero(t, srv1Calls+srv2Calls)
+			},
+		},
+		{
+			uc:     "with trusted issuer, which cannot be reached",
+			issuer: "https://tenant1.heimdall.test",
+			assert: func(t *testing.T, err error, _ ServerMetadata) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+			},
+		},
+		{
+			uc:     "with trusted issuer not matching the issuer from the received metadata",
+			issuer: srv2.URL + "/",
+			assert: func(t *testing.T, err error, _ ServerMetadata) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "does not match the token issuer")
+				assert.Equal(t, 1, srv2Calls)
+				assert.Zero(t, discovery.cache.Len())
+			},
+		},
+		{
+			uc:     "with trusted issuer resolved via the server",
+			issuer: srv1.URL,
+			assert: func(t *testing.T, err error, sm ServerMetadata) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, srv1.URL, sm.Issuer)
+				require.NotNil(t, sm.JWKSEndpoint)
+				assert.Equal(t, srv1.URL+"/jwks", sm.JWKSEndpoint.URL)
+				assert.Equal(t, 1, srv1Calls)
+			},
+		},
+		{
+			uc:     "with trusted issuer resolved from the cache",
+			issuer: srv1.URL,
+			assert: func(t *testing.T, err error, sm ServerMetadata) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, srv1.URL, sm.Issuer)
+				assert.Equal(t, 1, srv1Calls)
+			},
+		},
+		{
+			uc:     "with other trusted issuer evicting the cached one",
+			issuer: srv2.URL,
+			assert: func(t *testing.T, err error, sm ServerMetadata) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, srv2.URL, sm.Issuer)
+				assert.Equal(t, 2, srv2Calls)
+				assert.Equal(t, 1, discovery.cache.Len())
+			},
+		},
+		{
+			uc:     "with evicted issuer resolved via the server again",
+			issuer: srv1.URL,
+			assert: func(t *testing.T, err error, sm ServerMetadata) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, srv1.URL, sm.Issuer)
+				assert.Equal(t, 2, srv1Calls)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			sm, err := discovery.Get(context.Background(), map[string]any{"TokenIssuer": tc.issuer})
+
+			// THEN
+			tc.assert(t, err, sm)
+		})
+	}
+}
+
+func TestIssuerDiscoveryGetWithCanceledCaller(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var calls int
+
+	var srv *httptest.Server
+
+	srv = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+
+		select {
+		case <-req.Context().Done():
+			return
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(rw).Encode(map[string]any{"issuer": srv.URL, "jwks_uri": srv.URL + "/jwks"})
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	discovery := &IssuerDiscovery{Issuers: []string{srv.URL}}
+	require.NoError(t, discovery.Init())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// WHEN
+	_, err1 := discovery.Get(ctx, map[string]any{"TokenIssuer": srv.URL})
+	sm, err2 := discovery.Get(context.Background(), map[string]any{"TokenIssuer": srv.URL})
+
+	// THEN
+	require.ErrorIs(t, err1, context.DeadlineExceeded)
+	require.NoError(t, err2)
+	assert.Equal(t, srv.URL, sm.Issuer)
+	assert.Equal(t, 1, calls)
+}
@@ -95,6 +95,9 @@ func (e *MetadataEndpoint) decodeResponse(resp *http.Response) (ServerMetadata,
 		Issuer                   string `json:"issuer"`
 		JWKSEndpointURL          string `json:"jwks_uri"`
 		IntrospectionEndpointURL string `json:"introspection_endpoint"`
+		AuthorizationEndpointURL string `json:"authorization_endpoint"`
+		TokenEndpointURL         string `json:"token_endpoint"`
+		EndSessionEndpointURL    string `json:"end_session_endpoint"`
 	}
 
 	var spec metadata
@@ -103,21 +106,25 @@ func (e *MetadataEndpoint) decodeResponse(resp *http.Response) (ServerMetadata,
 			"failed to unmarshal received oauth2 server metadata document").CausedBy(err)
 	}
 
-	if strings.Contains(spec.JWKSEndpointURL, "{{") &&
-		strings.Contains(spec.JWKSEndpointURL, "}}") {
-		return ServerMetadata{}, errorchain.NewWithMessage(heimdall.ErrConfiguration,
-			"received jwks_uri contains a template, which is not allowed")
-	}
-
-	if strings.Contains(spec.IntrospectionEndpointURL, "{{") &&
-		strings.Contains(spec.IntrospectionEndpointURL, "}}") {
-		return ServerMetadata{}, errorchain.NewWithMessage(heimdall.ErrConfiguration,
-			"received introspection_endpoint contains a template, which is not allowed")
+	for name, value := range map[string]string{
+		"jwks_uri":               spec.JWKSEndpointURL,
+		"introspection_endpoint": spec.IntrospectionEndpointURL,
+		"authorization_endpoint": spec.AuthorizationEndpointURL,
+		"token_endpoint":         spec.TokenEndpointURL,
+		"end_session_endpoint":   spec.EndSessionEndpointURL,
+	} {
+		if strings.Contains(value, "{{") && strings.Contains(value, "}}") {
+			return ServerMetadata{}, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"received %s contains a template, which is not allowed", name)
+		}
 	}
 
 	var (
 		jwksEP          *endpoint.Endpoint
 		introspectionEP *endpoint.Endpoint
+		authorizationEP *endpoint.Endpoint
+		tokenEP         *endpoint.Endpoint
+		endSessionEP    *endpoint.Endpoint
 	)
 
 	if len(spec.JWKSEndpointURL) != 0 {
@@ -139,9 +146,31 @@ func (e *MetadataEndpoint) decodeResponse(resp *http.Response) (ServerMetadata,
 		}
 	}
 
+	if len(spec.AuthorizationEndpointURL) != 0 {
+		authorizationEP = &endpoint.Endpoint{URL: spec.AuthorizationEndpointURL, Method: http.MethodGet}
+	}
+
+	if len(spec.TokenEndpointURL) != 0 {
+		tokenEP = &endpoint.Endpoint{
+			URL:    spec.TokenEndpointURL,
+			Method: http.MethodPost,
+			Headers: map[string]string{
+				"Content-Type": "application/x-www-form-urlencoded",
+				"Accept":       "application/json",
+			},
+		}
+	}
+
+	if len(spec.EndSessionEndpointURL) != 0 {
+		endSessionEP = &endpoint.Endpoint{URL: spec.EndSessionEndpointURL, Method: http.MethodGet}
+	}
+
 	return ServerMetadata{
 		Issuer:                spec.Issuer,
 		JWKSEndpoint:          jwksEP,
 		IntrospectionEndpoint: introspectionEP,
+		AuthorizationEndpoint: authorizationEP,
+		TokenEndpoint:         tokenEP,
+		EndSessionEndpoint:    endSessionEP,
 	}, nil
 }
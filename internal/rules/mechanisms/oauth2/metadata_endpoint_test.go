@@ -22,6 +22,9 @@ func TestMetadataEndpointGet(t *testing.T) {
 		Issuer                             string   `json:"issuer"`
 		JWKSEndpointURL                    string   `json:"jwks_uri"`
 		IntrospectionEndpointURL           string   `json:"introspection_endpoint"`
+		AuthorizationEndpointURL           string   `json:"authorization_endpoint"`
+		TokenEndpointURL                   string   `json:"token_endpoint"`
+		EndSessionEndpointURL              string   `json:"end_session_endpoint"`
 		TokenEndpointAuthSigningAlgorithms []string `json:"token_endpoint_auth_signing_alg_values_supported"`
 	}
 
@@ -209,6 +212,39 @@ func TestMetadataEndpointGet(t *testing.T) {
 				require.ErrorContains(t, err, "introspection_endpoint contains a template")
 			},
 		},
+		{
+			uc: "server's response contains token_endpoint with template",
+			buildURL: func(t *testing.T, baseURL string) string {
+				t.Helper()
+
+				return baseURL
+			},
+			checkRequest: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				assert.Equal(t, "/", req.URL.Path)
+			},
+			createResponse: func(t *testing.T, rw http.ResponseWriter) {
+				t.Helper()
+
+				rw.Header().Set("Content-Type", "application/json")
+
+				err := json.NewEncoder(rw).Encode(metadata{
+					Issuer:           "heimdall.test",
+					JWKSEndpointURL:  "https://foo.bar/jwks",
+					TokenEndpointURL: "https://foo.bar/{{ .Foo }}/token",
+				})
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, endpointCalled bool, err error, _ ServerMetadata) {
+				t.Helper()
+
+				require.True(t, endpointCalled)
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "token_endpoint contains a template")
+			},
+		},
 		{
 			uc:   "valid server response for templated URL",
 			args: map[string]any{"Foo": "bar"},
@@ -233,6 +269,9 @@ func TestMetadataEndpointGet(t *testing.T) {
 					Issuer:                             srv.URL + "/bar",
 					JWKSEndpointURL:                    "https://foo.bar/jwks",
 					IntrospectionEndpointURL:           "https://foo.bar/introspection",
+					AuthorizationEndpointURL:           "https://foo.bar/authorize",
+					TokenEndpointURL:                   "https://foo.bar/token",
+					EndSessionEndpointURL:              "https://foo.bar/logout",
 					TokenEndpointAuthSigningAlgorithms: []string{"RS256", "PS384"},
 				})
 				require.NoError(t, err)
@@ -261,6 +300,22 @@ func TestMetadataEndpointGet(t *testing.T) {
 					},
 				}
 				assert.Equal(t, exp, *sm.IntrospectionEndpoint)
+
+				exp = endpoint.Endpoint{URL: "https://foo.bar/authorize", Method: http.MethodGet}
+				assert.Equal(t, exp, *sm.AuthorizationEndpoint)
+
+				exp = endpoint.Endpoint{
+					URL:    "https://foo.bar/token",
+					Method: http.MethodPost,
+					Headers: map[string]string{
+						"Content-Type": "application/x-www-form-urlencoded",
+						"Accept":       "application/json",
+					},
+				}
+				assert.Equal(t, exp, *sm.TokenEndpoint)
+
+				exp = endpoint.Endpoint{URL: "https://foo.bar/logout", Method: http.MethodGet}
+				assert.Equal(t, exp, *sm.EndSessionEndpoint)
 			},
 		},
 		{
@@ -13,6 +13,9 @@ type ServerMetadata struct {
 	Issuer                string
 	JWKSEndpoint          *endpoint.Endpoint
 	IntrospectionEndpoint *endpoint.Endpoint
+	AuthorizationEndpoint *endpoint.Endpoint
+	TokenEndpoint         *endpoint.Endpoint
+	EndSessionEndpoint    *endpoint.Endpoint
 }
 
 func (sm ServerMetadata) verify(usedMetadataURL string) error {
@@ -27,6 +27,7 @@ import (
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/contextualizers"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/finalizers"
+	"github.com/dadrus/heimdall/internal/watcher"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
@@ -35,11 +36,16 @@ var ErrNoSuchPipelineObject = errors.New("pipeline object not found")
 func newPrototypeRepository(
 	conf *config.Configuration,
 	logger zerolog.Logger,
+	cw watcher.Watcher,
 ) (*prototypeRepository, error) {
 	logger.Debug().Msg("Loading definitions for authenticators")
 
+	cc := &creationContext{w: cw}
+
 	authenticatorMap, err := createPipelineObjects(conf.Prototypes.Authenticators, logger,
-		authenticators.CreatePrototype)
+		func(id string, typ string, c map[string]any) (authenticators.Authenticator, error) {
+			return authenticators.CreatePrototype(cc, id, typ, c)
+		})
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed loading authenticators definitions")
 
@@ -119,6 +125,12 @@ func createPipelineObjects[T any](
 	return objects, nil
 }
 
+type creationContext struct {
+	w watcher.Watcher
+}
+
+func (c *creationContext) Watcher() watcher.Watcher { return c.w }
+
 type prototypeRepository struct {
 	authenticators  map[string]authenticators.Authenticator
 	authorizers     map[string]authorizers.Authorizer
@@ -40,9 +40,12 @@ func TestTemplateRender(t *testing.T) {
 
 	ctx := mocks.NewContextMock(t)
 	ctx.EXPECT().Request().Return(&heimdall.Request{
-		RequestFunctions:  reqf,
-		Method:            http.MethodPatch,
-		URL:               &url.URL{Scheme: "http", Host: "foobar.baz", Path: "zab", RawQuery: "my_query_param=query_value"},
+		RequestFunctions: reqf,
+		Method:           http.MethodPatch,
+		URL: &heimdall.URL{
+			URL:      url.URL{Scheme: "http", Host: "foobar.baz", Path: "zab", RawQuery: "my_query_param=query_value"},
+			Captures: map[string]string{"tenant": "acme"},
+		},
 		ClientIPAddresses: []string{"192.168.1.1"},
 	})
 
@@ -65,6 +68,7 @@ func TestTemplateRender(t *testing.T) {
 "my_header": {{ .Request.Header "X-My-Header" | quote }},
 "my_cookie": {{ .Request.Cookie "session_cookie" | quote }},
 "my_query_param": {{ index .Request.URL.Query.my_query_param 0 | quote }},
+"tenant": {{ quote .Request.URL.Captures.tenant }},
 "ips": {{ range $i, $el := .Request.ClientIPAddresses -}}{{ if $i }} {{ end }}{{ quote $el }}{{ end }},
 "values": [{{ quote .Values.key1 }}, {{ quote .Values.key2 }}]
 }`)
@@ -90,6 +94,7 @@ func TestTemplateRender(t *testing.T) {
 "my_header": "my-value",
 "my_cookie": "session-value",
 "my_query_param": "query_value",
+"tenant": "acme",
 "ips": "192.168.1.1",
 "values": ["foo", "bar"]
 }`, res)
@@ -19,6 +19,9 @@ package patternmatcher
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/gobwas/glob"
 )
@@ -28,14 +31,53 @@ var (
 	ErrNoGlobPatternDefined = errors.New("no glob pattern defined")
 )
 
+// namedCaptureExp matches delimited expressions defining a named capture, like <:tenant>.
+var namedCaptureExp = regexp.MustCompile(`^:([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// globSingleSegmentExp is the regular expression equivalent of the glob ? expression given
+// the separators used for glob compilation.
+const globSingleSegmentExp = `[^./]`
+
 type globMatcher struct {
 	compiled glob.Glob
+	// captures is only set if the pattern defines named captures. As glob expressions
+	// cannot extract values, such patterns are additionally translated to a regular
+	// expression, which is used to constrain the captured segments and to extract them.
+	captures *regexp.Regexp
+	prefix   string
 }
 
 func (m *globMatcher) Match(value string) bool {
-	return m.compiled.Match(value)
+	if !m.compiled.Match(value) {
+		return false
+	}
+
+	return m.captures == nil || m.captures.MatchString(value)
 }
 
+func (m *globMatcher) Captures(value string) map[string]string {
+	if m.captures == nil || !m.compiled.Match(value) {
+		return nil
+	}
+
+	match := m.captures.FindStringSubmatch(value)
+	if match == nil {
+		return nil
+	}
+
+	captures := make(map[string]string, len(match)-1)
+
+	for idx, name := range m.captures.SubexpNames() {
+		if len(name) != 0 {
+			captures[name] = match[idx]
+		}
+	}
+
+	return captures
+}
+
+func (m *globMatcher) Prefix() string { return m.prefix }
+
 func newGlobMatcher(pattern string) (*globMatcher, error) {
 	if len(pattern) == 0 {
 		return nil, ErrNoGlobPatternDefined
@@ -46,7 +88,12 @@ func newGlobMatcher(pattern string) (*globMatcher, error) {
 		return nil, err
 	}
 
-	return &globMatcher{compiled: compiled}, nil
+	captures, err := compileGlobCaptures(pattern, '<', '>')
+	if err != nil {
+		return nil, err
+	}
+
+	return &globMatcher{compiled: compiled, captures: captures, prefix: staticPrefix(pattern, '<')}, nil
 }
 
 func compileGlob(pattern string, delimiterStart, delimiterEnd rune) (glob.Glob, error) {
@@ -66,7 +113,13 @@ func compileGlob(pattern string, delimiterStart, delimiterEnd rune) (glob.Glob,
 		patt := pattern[idxs[ind]+1 : end-1]
 
 		buffer.WriteString(glob.QuoteMeta(raw))
-		buffer.WriteString(patt)
+
+		if namedCaptureExp.MatchString(patt) {
+			// the segment is constrained by the regular expression used for extraction
+			buffer.WriteString("**")
+		} else {
+			buffer.WriteString(patt)
+		}
 	}
 
 	// Add the remaining.
@@ -105,3 +158,109 @@ func delimiterIndices(value string, delimiterStart, delimiterEnd rune) ([]int, e
 
 	return idxs, nil
 }
+
+// compileGlobCaptures translates the given glob pattern into a regular expression, if it
+// defines named captures. Each of these matches a single non-empty path segment. It returns
+// nil if there are none.
+func compileGlobCaptures(pattern string, delimiterStart, delimiterEnd rune) (*regexp.Regexp, error) {
+	idxs, errBraces := delimiterIndices(pattern, delimiterStart, delimiterEnd)
+	if errBraces != nil {
+		return nil, errBraces
+	}
+
+	var (
+		buffer      strings.Builder
+		end         int
+		hasCaptures bool
+	)
+
+	buffer.WriteByte('^')
+
+	for ind := 0; ind < len(idxs); ind += 2 {
+		buffer.WriteString(regexp.QuoteMeta(pattern[end:idxs[ind]]))
+		end = idxs[ind+1]
+		patt := pattern[idxs[ind]+1 : end-1]
+
+		if match := namedCaptureExp.FindStringSubmatch(patt); match != nil {
+			fmt.Fprintf(&buffer, "(?P<%s>[^/]+)", match[1])
+
+			hasCaptures = true
+		} else {
+			buffer.WriteString("(?:")
+			buffer.WriteString(globToRegex(patt))
+			buffer.WriteString(")")
+		}
+	}
+
+	buffer.WriteString(regexp.QuoteMeta(pattern[end:]))
+	buffer.WriteByte('$')
+
+	if !hasCaptures {
+		return nil, nil //nolint:nilnil
+	}
+
+	return regexp.Compile(buffer.String())
+}
+
+// globToRegex translates the given glob expression into an equivalent regular expression.
+func globToRegex(expr string) string { //nolint:cyclop
+	var (
+		buffer strings.Builder
+		depth  int
+	)
+
+	for idx := 0; idx < len(expr); idx++ {
+		switch chr := expr[idx]; {
+		case chr == '\\' && idx+1 < len(expr):
+			idx++
+			buffer.WriteString(regexp.QuoteMeta(expr[idx : idx+1]))
+		case chr == '*' && idx+1 < len(expr) && expr[idx+1] == '*':
+			idx++
+			buffer.WriteString(".*")
+		case chr == '*':
+			buffer.WriteString(globSingleSegmentExp + "*")
+		case chr == '?':
+			buffer.WriteString(globSingleSegmentExp)
+		case chr == '[' && strings.IndexByte(expr[idx:], ']') > 1:
+			end := idx + strings.IndexByte(expr[idx:], ']')
+			buffer.WriteString(globCharClassToRegex(expr[idx+1 : end]))
+			idx = end
+		case chr == '{':
+			depth++
+			buffer.WriteString("(?:")
+		case chr == ',' && depth > 0:
+			buffer.WriteByte('|')
+		case chr == '}' && depth > 0:
+			depth--
+			buffer.WriteByte(')')
+		default:
+			buffer.WriteString(regexp.QuoteMeta(expr[idx : idx+1]))
+		}
+	}
+
+	return buffer.String()
+}
+
+func globCharClassToRegex(class string) string {
+	var buffer strings.Builder
+
+	buffer.WriteByte('[')
+
+	if strings.HasPrefix(class, "!") {
+		buffer.WriteByte('^')
+
+		class = class[1:]
+	}
+
+	for idx := range len(class) {
+		if strings.IndexByte(`\[]^`, class[idx]) != -1 {
+			buffer.WriteByte('\\')
+		}
+
+		buffer.WriteByte(class[idx])
+	}
+
+	buffer.WriteByte(']')
+
+	return buffer.String()
+}
@@ -17,9 +17,11 @@
 package patternmatcher
 
 import (
+	"regexp"
 	"strconv"
 	"testing"
 
+	"github.com/gobwas/glob"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -183,3 +185,82 @@ func TestIsMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestGlobToRegex(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		expr   string
+		values []string
+	}{
+		{expr: "a?", values: []string{"a", "ab", "abc", "a.", "a/"}},
+		{expr: "*", values: []string{"", "foo", "foo.bar", "foo/bar"}},
+		{expr: "**", values: []string{"", "foo", "foo.bar", "foo/bar"}},
+		{expr: "foo*", values: []string{"foo", "foobar", "barfoo", "foo/bar"}},
+		{expr: "m[a,o,u]n", values: []string{"man", "mon", "m,n", "moon", "min"}},
+		{expr: "m[!a,o,u]n", values: []string{"man", "min", "m/n"}},
+		{expr: "[a-c]", values: []string{"a", "b", "d", "-"}},
+		{expr: "{foo*,bar*}", values: []string{"foo", "foox", "barx", "baz", "foo/x"}},
+		{expr: "{foo,{bar,baz}}/**", values: []string{"foo/x", "baz/x/y", "bax/x"}},
+		{expr: "[!x]", values: []string{"a", "x", "/", ".", "ab"}},
+		{expr: "{a,b}", values: []string{"a", "b", "ab", "", "c"}},
+		{expr: "\\*+(", values: []string{"*+(", "a+(", "*+"}},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			// GIVEN
+			expected, err := glob.Compile(tc.expr, '.', '/')
+			require.NoError(t, err)
+
+			// WHEN
+			translated, err := regexp.Compile("^" + globToRegex(tc.expr) + "$")
+
+			// THEN
+			require.NoError(t, err)
+
+			for _, value := range tc.values {
+				assert.Equal(t, expected.Match(value), translated.MatchString(value), value)
+			}
+		})
+	}
+}
+
+func TestGlobMatcherWithCapturesParity(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		expr   string
+		values []string
+	}{
+		{expr: "*", values: []string{"", "foo", "foo.bar", "foo/bar"}},
+		{expr: "**", values: []string{"", "foo", "foo.bar", "foo/bar"}},
+		{expr: "{a,b}", values: []string{"a", "b", "ab", "", "c", "a/b"}},
+		{expr: "[!x]", values: []string{"a", "x", "/", ".", "ab"}},
+		{expr: "{foo,bar}/[!x]*", values: []string{"foo/a", "bar/abc", "foo/x", "baz/a", "foo/a/b"}},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			// GIVEN
+			matcher, err := newGlobMatcher("http://foo.bar/<:id>/<" + tc.expr + ">")
+			require.NoError(t, err)
+
+			expected, err := glob.Compile("http://foo.bar/42/"+tc.expr, '.', '/')
+			require.NoError(t, err)
+
+			for _, value := range tc.values {
+				value = "http://foo.bar/42/" + value
+
+				// WHEN
+				matched := matcher.Match(value)
+				captures := matcher.Captures(value)
+
+				// THEN
+				assert.Equal(t, expected.Match(value), matched, value)
+
+				if matched {
+					assert.Equal(t, map[string]string{"id": "42"}, captures, value)
+				} else {
+					assert.Nil(t, captures, value)
+				}
+			}
+		})
+	}
+}
@@ -18,12 +18,20 @@ package patternmatcher
 
 import (
 	"errors"
+	"strings"
 )
 
 var ErrUnsupportedPatternMatcher = errors.New("unsupported pattern matcher")
 
 type PatternMatcher interface {
 	Match(value string) bool
+	// Captures returns the values of the named captures defined by the pattern,
+	// extracted from the given value. It returns nil if the pattern doesn't define
+	// any named captures, or the value doesn't match.
+	Captures(value string) map[string]string
+	// Prefix returns the static part of the pattern preceding the first
+	// delimited (wildcard) expression. Every matched value starts with it.
+	Prefix() string
 }
 
 func NewPatternMatcher(typ, pattern string) (PatternMatcher, error) {
@@ -36,3 +44,11 @@ func NewPatternMatcher(typ, pattern string) (PatternMatcher, error) {
 		return nil, ErrUnsupportedPatternMatcher
 	}
 }
+
+func staticPrefix(pattern string, delimiterStart byte) string {
+	if idx := strings.IndexByte(pattern, delimiterStart); idx != -1 {
+		return pattern[:idx]
+	}
+
+	return pattern
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package patternmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternMatcherPrefix(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		strategy string
+		pattern  string
+		prefix   string
+	}{
+		{uc: "static glob", strategy: "glob", pattern: "http://foo.bar/baz", prefix: "http://foo.bar/baz"},
+		{uc: "glob with wildcard", strategy: "glob", pattern: "http://foo.bar/<**>", prefix: "http://foo.bar/"},
+		{uc: "glob starting with wildcard", strategy: "glob", pattern: "<{http,https}>://foo.bar/<**>"},
+		{
+			uc:       "glob with literal brackets",
+			strategy: "glob",
+			pattern:  "http://foo.bar/[id]/<*>",
+			prefix:   "http://foo.bar/[id]/",
+		},
+		{
+			uc:       "glob with named capture",
+			strategy: "glob",
+			pattern:  "http://foo.bar/tenants/<:tenant>/<**>",
+			prefix:   "http://foo.bar/tenants/",
+		},
+		{uc: "static regex", strategy: "regex", pattern: "http://foo.bar/baz", prefix: "http://foo.bar/baz"},
+		{uc: "regex with expression", strategy: "regex", pattern: "http://foo.bar/<.*>", prefix: "http://foo.bar/"},
+		{uc: "regex starting with expression", strategy: "regex", pattern: "<https?>://foo.bar/<.*>"},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			matcher, err := NewPatternMatcher(tc.strategy, tc.pattern)
+			require.NoError(t, err)
+
+			// WHEN
+			prefix := matcher.Prefix()
+
+			// THEN
+			assert.Equal(t, tc.prefix, prefix)
+		})
+	}
+}
+
+func TestPatternMatcherCaptures(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		strategy string
+		pattern  string
+		value    string
+		matches  bool
+		captures map[string]string
+	}{
+		{
+			uc:       "glob without named captures",
+			strategy: "glob",
+			pattern:  "http://foo.bar/<**>",
+			value:    "http://foo.bar/tenants/acme",
+			matches:  true,
+		},
+		{
+			uc:       "glob with named captures",
+			strategy: "glob",
+			pattern:  "http://foo.bar/tenants/<:tenant>/users/<:user><{,/**}>",
+			value:    "http://foo.bar/tenants/acme.corp/users/42/profile",
+			matches:  true,
+			captures: map[string]string{"tenant": "acme.corp", "user": "42"},
+		},
+		{
+			uc:       "glob with named capture not matching an empty segment",
+			strategy: "glob",
+			pattern:  "http://foo.bar/tenants/<:tenant>/<**>",
+			value:    "http://foo.bar/tenants//users",
+		},
+		{
+			uc:       "glob with named capture and not matching expression",
+			strategy: "glob",
+			pattern:  "http://foo.bar/tenants/<:tenant>/<{users,groups}>",
+			value:    "http://foo.bar/tenants/acme/roles",
+		},
+		{
+			uc:       "glob with named capture and single segment wildcard",
+			strategy: "glob",
+			pattern:  "<{http,https}>://<*>.bar/tenants/<:tenant>",
+			value:    "https://foo.bar/tenants/acme",
+			matches:  true,
+			captures: map[string]string{"tenant": "acme"},
+		},
+		{
+			uc:       "glob with colon prefixed segment is not a named capture",
+			strategy: "glob",
+			pattern:  "http://foo.bar/tenants/:tenant/<**>",
+			value:    "http://foo.bar/tenants/:tenant/users",
+			matches:  true,
+		},
+		{
+			uc:       "glob with colon prefixed segment matched literally",
+			strategy: "glob",
+			pattern:  "http://foo.bar/tenants/:tenant/<**>",
+			value:    "http://foo.bar/tenants/acme/users",
+		},
+		{
+			uc:       "regex without named groups",
+			strategy: "regex",
+			pattern:  "http://foo.bar/tenants/<[^/]+>",
+			value:    "http://foo.bar/tenants/acme",
+			matches:  true,
+		},
+		{
+			uc:       "regex with named groups",
+			strategy: "regex",
+			pattern:  "http://foo.bar/tenants/<(?P<tenant>[^/]+)>/users/<(?<user>[0-9]+)>",
+			value:    "http://foo.bar/tenants/acme/users/42",
+			matches:  true,
+			captures: map[string]string{"tenant": "acme", "user": "42"},
+		},
+		{
+			uc:       "regex with named groups not matching",
+			strategy: "regex",
+			pattern:  "http://foo.bar/tenants/<(?P<tenant>[^/]+)>/users/<(?<user>[0-9]+)>",
+			value:    "http://foo.bar/tenants/acme/users/foo",
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			matcher, err := NewPatternMatcher(tc.strategy, tc.pattern)
+			require.NoError(t, err)
+
+			// WHEN
+			matched := matcher.Match(tc.value)
+			captures := matcher.Captures(tc.value)
+
+			// THEN
+			assert.Equal(t, tc.matches, matched)
+			assert.Equal(t, tc.captures, captures)
+		})
+	}
+}
@@ -18,6 +18,7 @@ package patternmatcher
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/dlclark/regexp2"
 	"github.com/ory/ladon/compiler"
@@ -26,7 +27,9 @@ import (
 var ErrNoRegexPatternDefined = errors.New("no glob pattern defined")
 
 type regexpMatcher struct {
-	compiled *regexp2.Regexp
+	compiled   *regexp2.Regexp
+	groupNames []string
+	prefix     string
 }
 
 func newRegexMatcher(pattern string) (*regexpMatcher, error) {
@@ -39,7 +42,20 @@ func newRegexMatcher(pattern string) (*regexpMatcher, error) {
 		return nil, err
 	}
 
-	return &regexpMatcher{compiled: compiled}, nil
+	var groupNames []string
+
+	for _, name := range compiled.GetGroupNames() {
+		// unnamed groups are numbered
+		if _, err := strconv.Atoi(name); err != nil {
+			groupNames = append(groupNames, name)
+		}
+	}
+
+	return &regexpMatcher{
+		compiled:   compiled,
+		groupNames: groupNames,
+		prefix:     staticPrefix(pattern, '<'),
+	}, nil
 }
 
 func (m *regexpMatcher) Match(matchAgainst string) bool {
@@ -48,3 +64,26 @@ func (m *regexpMatcher) Match(matchAgainst string) bool {
 
 	return ok
 }
+
+func (m *regexpMatcher) Captures(value string) map[string]string {
+	if len(m.groupNames) == 0 {
+		return nil
+	}
+
+	match, err := m.compiled.FindStringMatch(value)
+	if err != nil || match == nil {
+		return nil
+	}
+
+	captures := make(map[string]string, len(m.groupNames))
+
+	for _, name := range m.groupNames {
+		if group := match.GroupByName(name); group != nil {
+			captures[name] = group.String()
+		}
+	}
+
+	return captures
+}
+
+func (m *regexpMatcher) Prefix() string { return m.prefix }
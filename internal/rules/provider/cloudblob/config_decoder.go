@@ -18,6 +18,8 @@ package cloudblob
 
 import (
 	"github.com/go-viper/mapstructure/v2"
+
+	"github.com/dadrus/heimdall/internal/truststore"
 )
 
 func decodeConfig(input any, output any) error {
@@ -26,6 +28,7 @@ func decodeConfig(input any, output any) error {
 			DecodeHook: mapstructure.ComposeDecodeHookFunc(
 				mapstructure.StringToTimeDurationHookFunc(),
 				urlDecodeHookFunc(),
+				truststore.DecodeTrustStoreHookFunc(),
 			),
 			Result:      output,
 			ErrorUnused: true,
@@ -31,6 +31,7 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	rule_config "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/provider/signature"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/slicex"
@@ -57,8 +58,9 @@ func newProvider(
 	}
 
 	type Config struct {
-		Buckets       []*ruleSetEndpoint `mapstructure:"buckets"`
-		WatchInterval *time.Duration     `mapstructure:"watch_interval"`
+		Buckets       []*ruleSetEndpoint  `mapstructure:"buckets"`
+		WatchInterval *time.Duration      `mapstructure:"watch_interval"`
+		Signature     *signature.Verifier `mapstructure:"signature"`
 	}
 
 	var providerConf Config
@@ -72,6 +74,11 @@ func newProvider(
 			"no buckets configured for cloud_blob rule provider")
 	}
 
+	if providerConf.Signature != nil && len(providerConf.Signature.TrustStore) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"no trust store configured for the verification of rule set signatures in cloud_blob rule provider")
+	}
+
 	logger = logger.With().Str("_provider_type", "cloud_blob").Logger()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -105,6 +112,8 @@ func newProvider(
 				"missing url for #%d bucket in cloud_blob rule provider configuration", idx)
 		}
 
+		bucket.verifier = providerConf.Signature
+
 		var definition gocron.JobDefinition
 
 		if providerConf.WatchInterval != nil && *providerConf.WatchInterval > 0 {
@@ -160,10 +169,17 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 			return nil
 		}
 
-		p.l.Warn().
-			Err(err).
-			Str("_endpoint", rsf.ID()).
-			Msg("Failed to fetch rule set")
+		if errors.Is(err, signature.ErrVerification) {
+			p.l.Error().
+				Err(err).
+				Str("_endpoint", rsf.ID()).
+				Msg("Rule set rejected")
+		} else {
+			p.l.Warn().
+				Err(err).
+				Str("_endpoint", rsf.ID()).
+				Msg("Failed to fetch rule set")
+		}
 
 		if errors.Is(err, heimdall.ErrInternal) || errors.Is(err, heimdall.ErrConfiguration) {
 			return err
@@ -17,11 +17,13 @@
 package cloudblob
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/url"
+	"strings"
 
 	"gocloud.dev/blob"
 	_ "gocloud.dev/blob/azureblob" // to support azure blobs
@@ -31,6 +33,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/provider/signature"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
@@ -38,6 +41,8 @@ type ruleSetEndpoint struct {
 	URL             *url.URL `mapstructure:"url"`
 	Prefix          string   `mapstructure:"prefix"`
 	RulesPathPrefix string   `mapstructure:"rule_path_match_prefix"`
+
+	verifier *signature.Verifier
 }
 
 func (e *ruleSetEndpoint) ID() string {
@@ -75,6 +80,11 @@ func (e *ruleSetEndpoint) readAllBlobs(ctx context.Context, bucket *blob.Bucket)
 			return nil, mapError(err, "failed iterate blobs")
 		}
 
+		// signatures are read together with the rule sets they belong to
+		if e.verifier != nil && strings.HasSuffix(obj.Key, signature.FileSuffix) {
+			continue
+		}
+
 		ruleSet, err := e.readRuleSet(ctx, bucket, obj.Key)
 		if err != nil {
 			if errors.Is(err, config.ErrEmptyRuleSet) {
@@ -118,7 +128,18 @@ func (e *ruleSetEndpoint) readRuleSet(ctx context.Context, bucket *blob.Bucket,
 
 	defer reader.Close()
 
-	contents, err := config.ParseRules(attrs.ContentType, reader, false)
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, mapError(err, "failed reading blob contents")
+	}
+
+	if e.verifier != nil {
+		if err = e.verifySignature(ctx, bucket, key, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	contents, err := config.ParseRules(attrs.ContentType, bytes.NewReader(raw), false)
 	if err != nil {
 		return nil, errorchain.
 			NewWithMessage(heimdall.ErrInternal, "failed to decode received rule set").
@@ -136,6 +157,18 @@ func (e *ruleSetEndpoint) readRuleSet(ctx context.Context, bucket *blob.Bucket,
 	return contents, nil
 }
 
+// verifySignature reads the detached signature of the rule set stored in the blob with the
+// given key. The signature is expected to be stored in a blob with the signature suffix appended
+// to that key.
+func (e *ruleSetEndpoint) verifySignature(ctx context.Context, bucket *blob.Bucket, key string, contents []byte) error {
+	sig, err := bucket.ReadAll(ctx, key+signature.FileSuffix)
+	if err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return mapError(err, "failed reading rule set signature")
+	}
+
+	return e.verifier.Verify(ctx, fmt.Sprintf("%s@%s", key, e.ID()), contents, sig)
+}
+
 func mapError(err error, message string) error {
 	// unfortunately some cloud provider SDKs don't implement error Is and/or As functions,
 	// so it is impossible to properly check for the actual underlying error.
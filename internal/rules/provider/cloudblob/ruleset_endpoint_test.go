@@ -23,7 +23,9 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-jose/go-jose/v4"
 	"github.com/johannesboyne/gofakes3"
 	"github.com/johannesboyne/gofakes3/backend/s3mem"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +33,10 @@ import (
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/provider/signature"
+	"github.com/dadrus/heimdall/internal/truststore"
 	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
 
 func TestFetchRuleSets(t *testing.T) {
@@ -50,6 +55,30 @@ func TestFetchRuleSets(t *testing.T) {
 
 	require.NoError(t, backend.CreateBucket(bucketName))
 
+	signedRuleSet := `
+version: "1alpha4"
+name: test
+rules:
+- id: foobar
+  match:
+    url: http://<**>/foo/bar/api1
+  execute:
+  - authenticator: foobar`
+
+	ca, err := testsupport.NewRootCA("Rule Set Signer", time.Hour*24)
+	require.NoError(t, err)
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES384, Key: ca.PrivKey}, nil)
+	require.NoError(t, err)
+
+	jws, err := signer.Sign([]byte(signedRuleSet))
+	require.NoError(t, err)
+
+	ruleSetSignature, err := jws.DetachedCompactSerialize()
+	require.NoError(t, err)
+
+	verifier := &signature.Verifier{TrustStore: truststore.TrustStore{ca.Certificate}}
+
 	clearBucket := func(t *testing.T) {
 		t.Helper()
 
@@ -425,6 +454,66 @@ rules:
 				assert.Equal(t, "foobar", ruleSets[0].Rules[0].ID)
 			},
 		},
+		{
+			uc: "signed rule set",
+			endpoint: ruleSetEndpoint{
+				URL: &url.URL{
+					Scheme:   "s3",
+					Host:     bucketName,
+					RawQuery: fmt.Sprintf("endpoint=%s&disableSSL=true&s3ForcePathStyle=true&region=eu-central-1", srv.URL),
+				},
+				verifier: verifier,
+			},
+			setup: func(t *testing.T) {
+				t.Helper()
+
+				_, err := backend.PutObject(bucketName, "ruleset",
+					map[string]string{"Content-Type": "application/yaml"},
+					strings.NewReader(signedRuleSet), int64(len(signedRuleSet)))
+				require.NoError(t, err)
+
+				_, err = backend.PutObject(bucketName, "ruleset.jws", map[string]string{},
+					strings.NewReader(ruleSetSignature), int64(len(ruleSetSignature)))
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, err error, ruleSets []*config.RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.Len(t, ruleSets, 1)
+				assert.Contains(t, ruleSets[0].Source, "ruleset")
+				assert.Len(t, ruleSets[0].Rules, 1)
+				assert.Equal(t, "foobar", ruleSets[0].Rules[0].ID)
+			},
+		},
+		{
+			uc: "rule set without signature",
+			endpoint: ruleSetEndpoint{
+				URL: &url.URL{
+					Scheme:   "s3",
+					Host:     bucketName,
+					RawQuery: fmt.Sprintf("endpoint=%s&disableSSL=true&s3ForcePathStyle=true&region=eu-central-1", srv.URL),
+				},
+				verifier: verifier,
+			},
+			setup: func(t *testing.T) {
+				t.Helper()
+
+				_, err := backend.PutObject(bucketName, "ruleset",
+					map[string]string{"Content-Type": "application/yaml"},
+					strings.NewReader(signedRuleSet), int64(len(signedRuleSet)))
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, err error, _ []*config.RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorIs(t, err, signature.ErrVerification)
+				assert.Contains(t, err.Error(), "not signed")
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -21,6 +21,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/rules/endpoint"
 	"github.com/dadrus/heimdall/internal/rules/endpoint/authstrategy"
+	"github.com/dadrus/heimdall/internal/truststore"
 )
 
 func decodeConfig(input any, output any) error {
@@ -30,6 +31,7 @@ func decodeConfig(input any, output any) error {
 				authstrategy.DecodeAuthenticationStrategyHookFunc(),
 				endpoint.DecodeEndpointHookFunc(),
 				mapstructure.StringToTimeDurationHookFunc(),
+				truststore.DecodeTrustStoreHookFunc(),
 			),
 			Result:      output,
 			ErrorUnused: true,
@@ -30,6 +30,7 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/provider/signature"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/validation"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
@@ -54,8 +55,9 @@ func newProvider(
 	}
 
 	type Config struct {
-		Endpoints     []*ruleSetEndpoint `mapstructure:"endpoints"      validate:"required,gt=0,dive"`
-		WatchInterval *time.Duration     `mapstructure:"watch_interval"`
+		Endpoints     []*ruleSetEndpoint  `mapstructure:"endpoints"      validate:"required,gt=0,dive"`
+		WatchInterval *time.Duration      `mapstructure:"watch_interval"`
+		Signature     *signature.Verifier `mapstructure:"signature"`
 	}
 
 	var providerConf Config
@@ -70,7 +72,7 @@ func newProvider(
 	}
 
 	for _, ep := range providerConf.Endpoints {
-		ep.init()
+		ep.init(providerConf.Signature)
 	}
 
 	logger = logger.With().Str("_provider_type", "http_endpoint").Logger()
@@ -157,9 +159,15 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 			return nil
 		}
 
-		p.l.Warn().Err(err).
-			Str("_endpoint", rsf.ID()).
-			Msg("Failed to fetch rule set")
+		if errors.Is(err, signature.ErrVerification) {
+			p.l.Error().Err(err).
+				Str("_endpoint", rsf.ID()).
+				Msg("Rule set rejected")
+		} else {
+			p.l.Warn().Err(err).
+				Str("_endpoint", rsf.ID()).
+				Msg("Failed to fetch rule set")
+		}
 
 		if !errors.Is(err, config2.ErrEmptyRuleSet) &&
 			(errors.Is(err, heimdall.ErrInternal) || errors.Is(err, heimdall.ErrConfiguration)) {
@@ -17,6 +17,7 @@
 package httpendpoint
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"errors"
@@ -28,6 +29,7 @@ import (
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/provider/signature"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
@@ -35,32 +37,16 @@ type ruleSetEndpoint struct {
 	endpoint.Endpoint `mapstructure:",squash"`
 
 	RulesPathPrefix string `mapstructure:"rule_path_match_prefix"`
+
+	verifier *signature.Verifier
 }
 
 func (e *ruleSetEndpoint) ID() string { return e.URL }
 
 func (e *ruleSetEndpoint) FetchRuleSet(ctx context.Context) (*config.RuleSet, error) {
-	req, err := e.CreateRequest(ctx, nil, nil)
-	if err != nil {
-		return nil, errorchain.
-			NewWithMessage(heimdall.ErrInternal, "failed creating request").
-			CausedBy(err)
-	}
-
-	client := e.CreateClient(req.URL.Hostname())
-
-	resp, err := client.Do(req)
+	resp, err := e.fetch(ctx, &e.Endpoint)
 	if err != nil {
-		var clientErr *url.Error
-		if errors.As(err, &clientErr) && clientErr.Timeout() {
-			return nil, errorchain.
-				NewWithMessage(heimdall.ErrCommunicationTimeout, "request to rule set endpoint timed out").
-				CausedBy(err)
-		}
-
-		return nil, errorchain.
-			NewWithMessage(heimdall.ErrCommunication, "request to rule set endpoint failed").
-			CausedBy(err)
+		return nil, err
 	}
 
 	defer resp.Body.Close()
@@ -70,9 +56,19 @@ func (e *ruleSetEndpoint) FetchRuleSet(ctx context.Context) (*config.RuleSet, er
 			"unexpected response code: %v", resp.StatusCode)
 	}
 
-	md := sha256.New()
+	contents, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrCommunication, "failed to read received rule set").
+			CausedBy(err)
+	}
+
+	if e.verifier != nil {
+		if err = e.verifySignature(ctx, contents); err != nil {
+			return nil, err
+		}
+	}
 
-	ruleSet, err := config.ParseRules(resp.Header.Get("Content-Type"), io.TeeReader(resp.Body, md), false)
+	ruleSet, err := config.ParseRules(resp.Header.Get("Content-Type"), bytes.NewReader(contents), false)
 	if err != nil {
 		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to parse received rule set").
 			CausedBy(err)
@@ -82,15 +78,87 @@ func (e *ruleSetEndpoint) FetchRuleSet(ctx context.Context) (*config.RuleSet, er
 		return nil, err
 	}
 
-	ruleSet.Hash = md.Sum(nil)
+	hash := sha256.Sum256(contents)
+
+	ruleSet.Hash = hash[:]
 	ruleSet.Source = "http_endpoint:" + e.ID()
 	ruleSet.ModTime = time.Now()
 
 	return ruleSet, nil
 }
 
-func (e *ruleSetEndpoint) init() {
+// verifySignature fetches the detached signature of the rule set, which is expected to be
+// available at the url of the rule set with the signature suffix appended to its path.
+func (e *ruleSetEndpoint) verifySignature(ctx context.Context, contents []byte) error {
+	signatureURL, err := url.Parse(e.URL)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to parse rule set endpoint url").
+			CausedBy(err)
+	}
+
+	signatureURL.Path += signature.FileSuffix
+	if len(signatureURL.RawPath) != 0 {
+		signatureURL.RawPath += signature.FileSuffix
+	}
+
+	ep := e.Endpoint
+	ep.URL = signatureURL.String()
+
+	resp, err := e.fetch(ctx, &ep)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	var sig []byte
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if sig, err = io.ReadAll(resp.Body); err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrCommunication,
+				"failed to read received rule set signature").CausedBy(err)
+		}
+	case http.StatusNotFound:
+		// rule set is not signed
+	default:
+		return errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"unexpected response code for rule set signature: %v", resp.StatusCode)
+	}
+
+	return e.verifier.Verify(ctx, "http_endpoint:"+e.ID(), contents, sig)
+}
+
+func (e *ruleSetEndpoint) fetch(ctx context.Context, ep *endpoint.Endpoint) (*http.Response, error) {
+	req, err := ep.CreateRequest(ctx, nil, nil)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed creating request").
+			CausedBy(err)
+	}
+
+	client := ep.CreateClient(req.URL.Hostname())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var clientErr *url.Error
+		if errors.As(err, &clientErr) && clientErr.Timeout() {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrCommunicationTimeout, "request to rule set endpoint timed out").
+				CausedBy(err)
+		}
+
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "request to rule set endpoint failed").
+			CausedBy(err)
+	}
+
+	return resp, nil
+}
+
+func (e *ruleSetEndpoint) init(verifier *signature.Verifier) {
 	e.Method = http.MethodGet
+	e.verifier = verifier
 
 	if e.HTTPCache == nil {
 		e.HTTPCache = &endpoint.HTTPCache{Enabled: true}
@@ -21,7 +21,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/go-jose/go-jose/v4"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,8 +35,11 @@ import (
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/provider/signature"
+	"github.com/dadrus/heimdall/internal/truststore"
 	"github.com/dadrus/heimdall/internal/x"
 	otelmock "github.com/dadrus/heimdall/internal/x/opentelemetry/mocks"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
 
 func TestRuleSetEndpointInit(t *testing.T) {
@@ -44,7 +49,7 @@ func TestRuleSetEndpointInit(t *testing.T) {
 	ep := &ruleSetEndpoint{Endpoint: endpoint.Endpoint{URL: "http://foo.bar"}}
 
 	// WHEN
-	ep.init()
+	ep.init(nil)
 
 	// THEN
 	assert.Equal(t, "http://foo.bar", ep.URL)
@@ -346,3 +351,101 @@ rules:
 		})
 	}
 }
+
+func TestRuleSetEndpointFetchSignedRuleSet(t *testing.T) {
+	t.Parallel()
+
+	contents := []byte(`
+version: "1alpha4"
+name: test
+rules:
+- id: foo
+`)
+
+	ca, err := testsupport.NewRootCA("Rule Set Signer", time.Hour*24)
+	require.NoError(t, err)
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES384, Key: ca.PrivKey}, nil)
+	require.NoError(t, err)
+
+	jws, err := signer.Sign(contents)
+	require.NoError(t, err)
+
+	validSignature, err := jws.DetachedCompactSerialize()
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc        string
+		signature string
+		assert    func(t *testing.T, err error, ruleSet *config.RuleSet)
+	}{
+		{
+			uc:        "with valid signature",
+			signature: validSignature,
+			assert: func(t *testing.T, err error, ruleSet *config.RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, ruleSet)
+				require.Len(t, ruleSet.Rules, 1)
+				assert.Equal(t, "foo", ruleSet.Rules[0].ID)
+			},
+		},
+		{
+			uc: "without signature",
+			assert: func(t *testing.T, err error, _ *config.RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorIs(t, err, signature.ErrVerification)
+				assert.Contains(t, err.Error(), "not signed")
+			},
+		},
+		{
+			uc:        "with bad signature",
+			signature: validSignature[:len(validSignature)-4] + "AAAA",
+			assert: func(t *testing.T, err error, _ *config.RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorIs(t, err, signature.ErrVerification)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/rules.yaml":
+					w.Header().Set("Content-Type", "application/yaml")
+					_, err := w.Write(contents)
+					assert.NoError(t, err)
+				case "/rules.yaml.jws":
+					if len(tc.signature) == 0 {
+						w.WriteHeader(http.StatusNotFound)
+
+						return
+					}
+
+					_, err := w.Write([]byte(tc.signature))
+					assert.NoError(t, err)
+				default:
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			}))
+			defer srv.Close()
+
+			ep := &ruleSetEndpoint{Endpoint: endpoint.Endpoint{URL: srv.URL + "/rules.yaml"}}
+			ep.init(&signature.Verifier{TrustStore: truststore.TrustStore{ca.Certificate}})
+			ep.HTTPCache.Enabled = false
+
+			// WHEN
+			ruleSet, err := ep.FetchRuleSet(context.Background())
+
+			// THEN
+			tc.assert(t, err, ruleSet)
+		})
+	}
+}
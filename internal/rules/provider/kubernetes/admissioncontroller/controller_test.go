@@ -310,7 +310,7 @@ func TestControllerLifecycle(t *testing.T) {
 			setupRuleFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
 
-				factory.EXPECT().CreateRule("1alpha3", mock.Anything, mock.Anything).
+				factory.EXPECT().CreateRule("1alpha4", mock.Anything, mock.Anything).
 					Once().Return(nil, errors.New("Test error"))
 			},
 			assert: func(t *testing.T, err error, resp *http.Response) {
@@ -403,7 +403,7 @@ func TestControllerLifecycle(t *testing.T) {
 			setupRuleFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
 
-				factory.EXPECT().CreateRule("1alpha3", mock.Anything, mock.Anything).
+				factory.EXPECT().CreateRule("1alpha4", mock.Anything, mock.Anything).
 					Once().Return(nil, nil)
 			},
 			assert: func(t *testing.T, err error, resp *http.Response) {
@@ -429,6 +429,167 @@ func TestControllerLifecycle(t *testing.T) {
 				assert.Contains(t, status.Message, "RuleSet valid")
 			},
 		},
+		{
+			uc:  "successful RuleSet validation with defaults and fragments",
+			tls: &config.TLS{KeyStore: config.KeyStore{Path: pemFile.Name()}},
+			request: func(t *testing.T, URL string) *http.Request {
+				t.Helper()
+
+				ruleSet := v1alpha3.RuleSet{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: fmt.Sprintf("%s/%s", v1alpha3.GroupName, v1alpha3.GroupVersion),
+						Kind:       "RuleSet",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-rule",
+						Namespace:         "foo",
+						ResourceVersion:   "702666",
+						UID:               "dfb2a2f1-1ad2-4d8c-8456-516fc94abb86",
+						Generation:        1,
+						CreationTimestamp: metav1.NewTime(time.Now()),
+					},
+					Spec: v1alpha3.RuleSetSpec{
+						AuthClassName: authClass,
+						Priority:      10,
+						Defaults: &config2.RuleDefaults{
+							Methods: []string{http.MethodGet},
+							Execute: []config.MechanismConfig{{"authenticator": "authn"}},
+						},
+						Fragments: map[string][]config.MechanismConfig{
+							"authz": {{"authorizer": "authz"}},
+						},
+						Rules: []config2.Rule{
+							{
+								ID:          "test",
+								RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+								Execute:     []config.MechanismConfig{{"fragment": "authz"}},
+							},
+						},
+					},
+				}
+				data, err := json.Marshal(&ruleSet)
+				require.NoError(t, err)
+
+				reviewReq.Request.Object.Raw = data
+
+				data, err = json.Marshal(&reviewReq)
+				require.NoError(t, err)
+
+				req, err := http.NewRequestWithContext(context.TODO(), http.MethodPost, URL, bytes.NewReader(data))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", "application/json")
+
+				return req
+			},
+			setupRuleFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().CreateRule("1alpha4", mock.Anything, mock.MatchedBy(func(rc config2.Rule) bool {
+					return assert.Equal(t, []string{http.MethodGet}, rc.Methods) &&
+						assert.Equal(t, []config.MechanismConfig{
+							{"authenticator": "authn"},
+							{"authorizer": "authz"},
+						}, rc.Execute)
+				})).Once().Return(nil, nil)
+			},
+			assert: func(t *testing.T, err error, resp *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+				var reviewResp admissionv1.AdmissionReview
+				err = json.NewDecoder(resp.Body).Decode(&reviewResp)
+				require.NoError(t, err)
+
+				vResp := reviewResp.Response
+				require.NotNil(t, vResp)
+				assert.True(t, vResp.Allowed)
+
+				status := vResp.Result
+				assert.NotNil(t, status)
+				assert.Equal(t, http.StatusOK, int(status.Code))
+				assert.Contains(t, status.Message, "RuleSet valid")
+			},
+		},
+		{
+			uc:  "RuleSet validation fails due to unknown fragment",
+			tls: &config.TLS{KeyStore: config.KeyStore{Path: pemFile.Name()}},
+			request: func(t *testing.T, URL string) *http.Request {
+				t.Helper()
+
+				ruleSet := v1alpha3.RuleSet{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: fmt.Sprintf("%s/%s", v1alpha3.GroupName, v1alpha3.GroupVersion),
+						Kind:       "RuleSet",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-rule",
+						Namespace:         "foo",
+						ResourceVersion:   "702666",
+						UID:               "dfb2a2f1-1ad2-4d8c-8456-516fc94abb86",
+						Generation:        1,
+						CreationTimestamp: metav1.NewTime(time.Now()),
+					},
+					Spec: v1alpha3.RuleSetSpec{
+						AuthClassName: authClass,
+						Priority:      10,
+						Defaults: &config2.RuleDefaults{
+							Methods: []string{http.MethodGet},
+							Execute: []config.MechanismConfig{{"authenticator": "authn"}},
+						},
+						Fragments: map[string][]config.MechanismConfig{
+							"authz": {{"authorizer": "authz"}},
+						},
+						Rules: []config2.Rule{
+							{
+								ID:          "test",
+								RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+								Execute:     []config.MechanismConfig{{"fragment": "foo"}},
+							},
+						},
+					},
+				}
+				data, err := json.Marshal(&ruleSet)
+				require.NoError(t, err)
+
+				reviewReq.Request.Object.Raw = data
+
+				data, err = json.Marshal(&reviewReq)
+				require.NoError(t, err)
+
+				req, err := http.NewRequestWithContext(context.TODO(), http.MethodPost, URL, bytes.NewReader(data))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", "application/json")
+
+				return req
+			},
+			assert: func(t *testing.T, err error, resp *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+				var reviewResp admissionv1.AdmissionReview
+				err = json.NewDecoder(resp.Body).Decode(&reviewResp)
+				require.NoError(t, err)
+
+				vResp := reviewResp.Response
+				require.NotNil(t, vResp)
+				assert.False(t, vResp.Allowed)
+
+				status := vResp.Result
+				assert.NotNil(t, status)
+				assert.Equal(t, http.StatusForbidden, int(status.Code))
+				assert.Contains(t, status.Message, "RuleSet invalid")
+				require.Len(t, status.Details.Causes, 1)
+				assert.Contains(t, status.Details.Causes[0].Message, "unknown fragment 'foo'")
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -67,15 +67,22 @@ func (rv *rulesetValidator) Handle(ctx context.Context, req *admission.Request)
 			Source:  fmt.Sprintf("%s:%s:%s", "kubernetes", rs.Namespace, rs.UID),
 			ModTime: time.Now(),
 		},
-		Version: rv.mapVersion(rs.APIVersion),
-		Name:    rs.Name,
-		Rules:   rs.Spec.Rules,
+		Version:   v1alpha3.RuleSetVersion(rs.APIVersion),
+		Name:      rs.Name,
+		Priority:  rs.Spec.Priority,
+		Defaults:  rs.Spec.Defaults,
+		Fragments: rs.Spec.Fragments,
+		Rules:     rs.Spec.Rules,
 	}
 
 	var errs []string
 
 	for _, rc := range ruleSet.Rules {
-		_, err = rv.f.CreateRule(ruleSet.Version, ruleSet.Source, rc)
+		rc, err = ruleSet.ExpandRule(rc)
+		if err == nil {
+			_, err = rv.f.CreateRule(ruleSet.Version, ruleSet.Source, rc)
+		}
+
 		if err != nil {
 			errs = append(errs, err.Error())
 		}
@@ -98,8 +105,3 @@ func (rv *rulesetValidator) ruleSetFrom(req *admission.Request) (*v1alpha3.RuleS
 
 	return p, err
 }
-
-func (rv *rulesetValidator) mapVersion(_ string) string {
-	// currently the only possible version is v1alpha3, which is mapped to the version "1alpha3" used internally
-	return "1alpha3"
-}
@@ -21,6 +21,7 @@ package v1alpha3
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	config2 "github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/rules/config"
 )
 
@@ -36,8 +37,11 @@ const (
 
 // +kubebuilder:object:generate=true
 type RuleSetSpec struct {
-	AuthClassName string        `json:"authClassName"` //nolint:tagliatelle
-	Rules         []config.Rule `json:"rules"`
+	AuthClassName string                               `json:"authClassName"` //nolint:tagliatelle
+	Priority      int                                  `json:"priority"`
+	Defaults      *config.RuleDefaults                 `json:"defaults,omitempty"`
+	Fragments     map[string][]config2.MechanismConfig `json:"fragments,omitempty"`
+	Rules         []config.Rule                        `json:"rules"`
 }
 
 // +kubebuilder:object:generate=true
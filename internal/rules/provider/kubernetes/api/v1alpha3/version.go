@@ -0,0 +1,51 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha3
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+)
+
+// ruleSetVersions holds the api versions of the RuleSet resource, which do not directly map to
+// the rule set version of the same name. The v1alpha3 resource supports request conditions in
+// the rule matcher, which are available since rule set version 1alpha4 only. As 1alpha4 is a
+// superset of 1alpha3, the resource is treated as such.
+var ruleSetVersions = map[string]string{ //nolint:gochecknoglobals
+	GroupVersion: config.RuleSetVersion1Alpha4,
+}
+
+// RuleSetVersion maps the api version of a RuleSet resource to the rule set version used
+// internally, e.g. "heimdall.dadrus.github.com/v1alpha3" to "1alpha4". If the api version is
+// not set, the version served by this package is assumed. Unsupported versions are not
+// filtered here, but result in the rejection of the rule set while loading it.
+func RuleSetVersion(apiVersion string) string {
+	version := GroupVersion
+
+	if gv, err := schema.ParseGroupVersion(apiVersion); err == nil && len(gv.Version) != 0 {
+		version = gv.Version
+	}
+
+	if rsVersion, ok := ruleSetVersions[version]; ok {
+		return rsVersion
+	}
+
+	return strings.TrimPrefix(version, "v")
+}
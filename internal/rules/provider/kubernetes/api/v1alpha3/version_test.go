@@ -0,0 +1,42 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleSetVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc         string
+		apiVersion string
+		expected   string
+	}{
+		{uc: "api version not set", expected: "1alpha4"},
+		{uc: "malformed api version", apiVersion: "foo/bar/baz", expected: "1alpha4"},
+		{uc: "api version of this package", apiVersion: GroupName + "/v1alpha3", expected: "1alpha4"},
+		{uc: "other api version", apiVersion: GroupName + "/v1alpha2", expected: "1alpha2"},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RuleSetVersion(tc.apiVersion))
+		})
+	}
+}
@@ -6,6 +6,7 @@
 package v1alpha3
 
 import (
+	internalconfig "github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/rules/config"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -73,6 +74,29 @@ func (in *RuleSetList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RuleSetSpec) DeepCopyInto(out *RuleSetSpec) {
 	*out = *in
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(config.RuleDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Fragments != nil {
+		in, out := &in.Fragments, &out.Fragments
+		*out = make(map[string][]internalconfig.MechanismConfig, len(*in))
+		for key, val := range *in {
+			var outVal []internalconfig.MechanismConfig
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]internalconfig.MechanismConfig, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.Rules != nil {
 		in, out := &in.Rules, &out.Rules
 		*out = make([]config.Rule, len(*in))
@@ -333,17 +333,15 @@ func (p *provider) toRuleSetConfiguration(rs *v1alpha3.RuleSet) *config2.RuleSet
 			Source:  fmt.Sprintf("%s:%s:%s", ProviderType, rs.Namespace, rs.UID),
 			ModTime: rs.CreationTimestamp.Time,
 		},
-		Version: p.mapVersion(rs.APIVersion),
-		Name:    rs.Name,
-		Rules:   rs.Spec.Rules,
+		Version:   v1alpha3.RuleSetVersion(rs.APIVersion),
+		Name:      rs.Name,
+		Priority:  rs.Spec.Priority,
+		Defaults:  rs.Spec.Defaults,
+		Fragments: rs.Spec.Fragments,
+		Rules:     rs.Spec.Rules,
 	}
 }
 
-func (p *provider) mapVersion(_ string) string {
-	// currently the only possible version is v1alpha3, which is mapped to the version "1alpha3" used internally
-	return "1alpha3"
-}
-
 func (p *provider) updateStatus(
 	ctx context.Context,
 	rs *v1alpha3.RuleSet,
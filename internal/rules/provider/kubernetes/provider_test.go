@@ -207,6 +207,11 @@ func (h *RuleSetResourceHandler) writeListResponse(t *testing.T, w http.Response
 		},
 		Spec: v1alpha3.RuleSetSpec{
 			AuthClassName: "bar",
+			Priority:      10,
+			Defaults:      &config2.RuleDefaults{Methods: []string{http.MethodPost}},
+			Fragments: map[string][]config.MechanismConfig{
+				"authz": {{"authorizer": "authz"}},
+			},
 			Rules: []config2.Rule{
 				{
 					ID: "test",
@@ -361,8 +366,14 @@ func TestProviderLifecycle(t *testing.T) {
 
 				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
 				assert.Contains(t, ruleSet.Source, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86")
-				assert.Equal(t, "1alpha3", ruleSet.Version)
+				assert.Equal(t, "1alpha4", ruleSet.Version)
 				assert.Equal(t, "test-rule", ruleSet.Name)
+				assert.Equal(t, 10, ruleSet.Priority)
+				require.NotNil(t, ruleSet.Defaults)
+				assert.Equal(t, []string{http.MethodPost}, ruleSet.Defaults.Methods)
+				assert.Equal(t, map[string][]config.MechanismConfig{
+					"authz": {{"authorizer": "authz"}},
+				}, ruleSet.Fragments)
 				assert.Len(t, ruleSet.Rules, 1)
 
 				rule := ruleSet.Rules[0]
@@ -460,7 +471,7 @@ func TestProviderLifecycle(t *testing.T) {
 
 				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
 				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86", ruleSet.Source)
-				assert.Equal(t, "1alpha3", ruleSet.Version)
+				assert.Equal(t, "1alpha4", ruleSet.Version)
 				assert.Equal(t, "test-rule", ruleSet.Name)
 				assert.Len(t, ruleSet.Rules, 1)
 
@@ -478,7 +489,7 @@ func TestProviderLifecycle(t *testing.T) {
 
 				ruleSet = mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor2").Value()
 				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86", ruleSet.Source)
-				assert.Equal(t, "1alpha3", ruleSet.Version)
+				assert.Equal(t, "1alpha4", ruleSet.Version)
 				assert.Equal(t, "test-rule", ruleSet.Name)
 
 				assert.Len(t, *statusList, 1)
@@ -523,7 +534,7 @@ func TestProviderLifecycle(t *testing.T) {
 
 				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
 				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86", ruleSet.Source)
-				assert.Equal(t, "1alpha3", ruleSet.Version)
+				assert.Equal(t, "1alpha4", ruleSet.Version)
 				assert.Equal(t, "test-rule", ruleSet.Name)
 				assert.Len(t, ruleSet.Rules, 1)
 
@@ -590,7 +601,7 @@ func TestProviderLifecycle(t *testing.T) {
 
 				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
 				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86", ruleSet.Source)
-				assert.Equal(t, "1alpha3", ruleSet.Version)
+				assert.Equal(t, "1alpha4", ruleSet.Version)
 				assert.Equal(t, "test-rule", ruleSet.Name)
 				assert.Len(t, ruleSet.Rules, 1)
 
@@ -718,7 +729,7 @@ func TestProviderLifecycle(t *testing.T) {
 
 				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
 				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86", ruleSet.Source)
-				assert.Equal(t, "1alpha3", ruleSet.Version)
+				assert.Equal(t, "1alpha4", ruleSet.Version)
 				assert.Equal(t, "test-rule", ruleSet.Name)
 				assert.Len(t, ruleSet.Rules, 1)
 
@@ -736,7 +747,7 @@ func TestProviderLifecycle(t *testing.T) {
 
 				ruleSet = mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor2").Value()
 				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86", ruleSet.Source)
-				assert.Equal(t, "1alpha3", ruleSet.Version)
+				assert.Equal(t, "1alpha4", ruleSet.Version)
 				assert.Equal(t, "test-rule", ruleSet.Name)
 				assert.Len(t, ruleSet.Rules, 1)
 
@@ -807,7 +818,7 @@ func TestProviderLifecycle(t *testing.T) {
 
 				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
 				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86", ruleSet.Source)
-				assert.Equal(t, "1alpha3", ruleSet.Version)
+				assert.Equal(t, "1alpha4", ruleSet.Version)
 				assert.Equal(t, "test-rule", ruleSet.Name)
 				assert.Len(t, ruleSet.Rules, 1)
 
@@ -825,7 +836,7 @@ func TestProviderLifecycle(t *testing.T) {
 
 				ruleSet = mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor2").Value()
 				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86", ruleSet.Source)
-				assert.Equal(t, "1alpha3", ruleSet.Version)
+				assert.Equal(t, "1alpha4", ruleSet.Version)
 				assert.Equal(t, "test-rule", ruleSet.Name)
 				assert.Len(t, ruleSet.Rules, 1)
 
@@ -0,0 +1,187 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/go-jose/go-jose/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// FileSuffix is appended to the location of a rule set to get the location of its
+// detached signature.
+const FileSuffix = ".jws"
+
+var ErrVerification = errors.New("rule set signature verification failed")
+
+//nolint:gochecknoglobals
+var (
+	supportedAlgorithms = []jose.SignatureAlgorithm{
+		jose.RS256, jose.RS384, jose.RS512,
+		jose.PS256, jose.PS384, jose.PS512,
+		jose.ES256, jose.ES384, jose.ES512,
+		jose.EdDSA,
+	}
+
+	rejections = sync.OnceValue(func() metric.Int64Counter {
+		counter, err := otel.Meter("github.com/dadrus/heimdall/internal/rules/provider/signature").Int64Counter(
+			"heimdall.rule_set.rejections",
+			metric.WithDescription("Number of rule sets rejected due to a missing or an invalid signature."),
+			metric.WithUnit("{rule_set}"),
+		)
+		if err != nil {
+			return noop.Int64Counter{}
+		}
+
+		return counter
+	})
+)
+
+// Verifier verifies detached JWS signatures (RFC 7515, Appendix F) of rule sets. The signer
+// is either identified by the certificate chain in the x5c header of the signature, which must
+// then lead to one of the certificates in the trust store, or is one of the certificates in the
+// trust store itself.
+type Verifier struct {
+	TrustStore truststore.TrustStore `mapstructure:"trust_store" validate:"required,gt=0"`
+}
+
+// Verify verifies the given detached signature over the given rule set contents. Each failed
+// verification is reported using the metric for rejected rule sets.
+func (v *Verifier) Verify(ctx context.Context, source string, contents, signature []byte) error {
+	err := v.verify(contents, signature)
+	if err != nil {
+		rejections().Add(ctx, 1, metric.WithAttributes(attribute.String("source", source)))
+	}
+
+	return err
+}
+
+func (v *Verifier) verify(contents, signature []byte) error {
+	if len(signature) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "rule set is not signed").
+			CausedBy(ErrVerification)
+	}
+
+	compact := string(bytes.TrimSpace(signature))
+
+	jws, err := jose.ParseDetached(compact, contents, supportedAlgorithms)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to parse rule set signature").
+			CausedBy(ErrVerification).CausedBy(err)
+	}
+
+	if len(jws.Signatures) != 1 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "exactly one signature is expected").
+			CausedBy(ErrVerification)
+	}
+
+	if !hasCertificateChain(compact) {
+		return v.verifyWithTrustedSigners(jws, contents)
+	}
+
+	signer, err := v.signerFromHeader(jws.Signatures[0].Protected)
+	if err != nil {
+		return err
+	}
+
+	if err = jws.DetachedVerify(contents, signer.PublicKey); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "bad rule set signature").
+			CausedBy(ErrVerification).CausedBy(err)
+	}
+
+	return nil
+}
+
+// signerFromHeader returns the end entity certificate from the x5c header after
+// validating its chain against the trust store.
+func (v *Verifier) signerFromHeader(header jose.Header) (*x509.Certificate, error) {
+	chains, err := header.Certificates(x509.VerifyOptions{
+		Roots:     v.TrustStore.CertPool(),
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"certificate of the rule set signer is not trusted").CausedBy(ErrVerification).CausedBy(err)
+	}
+
+	signer := chains[0][0]
+	if signer.KeyUsage != 0 && signer.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"certificate of the rule set signer is not allowed to create digital signatures").
+			CausedBy(ErrVerification)
+	}
+
+	return signer, nil
+}
+
+// verifyWithTrustedSigners verifies the signature using the keys of the certificates in the
+// trust store. The chain of the certificate, which key could verify the signature must be valid.
+func (v *Verifier) verifyWithTrustedSigners(jws *jose.JSONWebSignature, contents []byte) error {
+	for _, cert := range v.TrustStore {
+		if err := jws.DetachedVerify(contents, cert.PublicKey); err != nil {
+			continue
+		}
+
+		if err := keystore.ValidateChain(keystore.FindChain(cert.PublicKey, v.TrustStore)); err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"certificate of the rule set signer is not valid").CausedBy(ErrVerification).CausedBy(err)
+		}
+
+		return nil
+	}
+
+	return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+		"rule set signature could not be verified with any of the trusted certificates").
+		CausedBy(ErrVerification)
+}
+
+// hasCertificateChain returns whether the protected header of the given, already successfully
+// parsed signature contains the x5c parameter. go-jose does not expose that information otherwise.
+func hasCertificateChain(compact string) bool {
+	protected, _, _ := strings.Cut(compact, ".")
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(protected)
+	if err != nil {
+		return false
+	}
+
+	var header struct {
+		X5C []string `json:"x5c"`
+	}
+
+	if err = json.Unmarshal(rawHeader, &header); err != nil {
+		return false
+	}
+
+	return len(header.X5C) != 0
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func sign(t *testing.T, key crypto.Signer, contents []byte, chain ...*x509.Certificate) []byte {
+	t.Helper()
+
+	opts := &jose.SignerOptions{}
+
+	if len(chain) != 0 {
+		x5c := make([]string, len(chain))
+		for i, cert := range chain {
+			x5c[i] = base64.StdEncoding.EncodeToString(cert.Raw)
+		}
+
+		opts = opts.WithHeader("x5c", x5c)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES384, Key: key}, opts)
+	require.NoError(t, err)
+
+	jws, err := signer.Sign(contents)
+	require.NoError(t, err)
+
+	sig, err := jws.DetachedCompactSerialize()
+	require.NoError(t, err)
+
+	return []byte(sig)
+}
+
+func TestVerifierVerify(t *testing.T) {
+	t.Parallel()
+
+	contents := []byte("version: 1alpha4\nrules: []\n")
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	otherCA, err := testsupport.NewRootCA("Other Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	issue := func(t *testing.T, ca *testsupport.CA, usage x509.KeyUsage) (*ecdsa.PrivateKey, *x509.Certificate) {
+		t.Helper()
+
+		key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		cert, err := ca.IssueCertificate(
+			testsupport.WithSubject(pkix.Name{CommonName: "Rule Set Signer", Organization: []string{"Test"}}),
+			testsupport.WithValidity(time.Now(), time.Hour),
+			testsupport.WithSubjectPubKey(&key.PublicKey, x509.ECDSAWithSHA384),
+			testsupport.WithKeyUsage(usage),
+		)
+		require.NoError(t, err)
+
+		return key, cert
+	}
+
+	signerKey, signerCert := issue(t, rootCA, x509.KeyUsageDigitalSignature)
+	otherKey, otherCert := issue(t, otherCA, x509.KeyUsageDigitalSignature)
+	encKey, encCert := issue(t, rootCA, x509.KeyUsageKeyEncipherment)
+
+	for _, tc := range []struct {
+		uc         string
+		trustStore truststore.TrustStore
+		signature  func(t *testing.T) []byte
+		errMsg     string
+	}{
+		{
+			uc:         "rule set not signed",
+			trustStore: truststore.TrustStore{rootCA.Certificate},
+			signature:  func(t *testing.T) []byte { t.Helper(); return nil },
+			errMsg:     "not signed",
+		},
+		{
+			uc:         "malformed signature",
+			trustStore: truststore.TrustStore{rootCA.Certificate},
+			signature:  func(t *testing.T) []byte { t.Helper(); return []byte("foo.bar") },
+			errMsg:     "failed to parse",
+		},
+		{
+			uc:         "signature with trusted certificate chain",
+			trustStore: truststore.TrustStore{rootCA.Certificate},
+			signature: func(t *testing.T) []byte {
+				t.Helper()
+
+				return sign(t, signerKey, contents, signerCert)
+			},
+		},
+		{
+			uc:         "signature with not trusted certificate chain",
+			trustStore: truststore.TrustStore{rootCA.Certificate},
+			signature: func(t *testing.T) []byte {
+				t.Helper()
+
+				return sign(t, otherKey, contents, otherCert)
+			},
+			errMsg: "not trusted",
+		},
+		{
+			uc:         "signature with certificate not allowed to sign",
+			trustStore: truststore.TrustStore{rootCA.Certificate},
+			signature: func(t *testing.T) []byte {
+				t.Helper()
+
+				return sign(t, encKey, contents, encCert)
+			},
+			errMsg: "not allowed to create digital signatures",
+		},
+		{
+			uc:         "signature with certificate chain not matching the signing key",
+			trustStore: truststore.TrustStore{rootCA.Certificate},
+			signature: func(t *testing.T) []byte {
+				t.Helper()
+
+				return sign(t, otherKey, contents, signerCert)
+			},
+			errMsg: "bad rule set signature",
+		},
+		{
+			uc:         "signature over different contents",
+			trustStore: truststore.TrustStore{rootCA.Certificate},
+			signature: func(t *testing.T) []byte {
+				t.Helper()
+
+				return sign(t, signerKey, []byte("foo"), signerCert)
+			},
+			errMsg: "bad rule set signature",
+		},
+		{
+			uc:         "signature without certificate chain created by a trusted signer",
+			trustStore: truststore.TrustStore{rootCA.Certificate, signerCert},
+			signature: func(t *testing.T) []byte {
+				t.Helper()
+
+				return sign(t, signerKey, contents)
+			},
+		},
+		{
+			uc:         "signature without certificate chain created by an unknown signer",
+			trustStore: truststore.TrustStore{rootCA.Certificate, signerCert},
+			signature: func(t *testing.T) []byte {
+				t.Helper()
+
+				return sign(t, otherKey, contents)
+			},
+			errMsg: "could not be verified with any of the trusted certificates",
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			verifier := &Verifier{TrustStore: tc.trustStore}
+
+			// WHEN
+			err := verifier.Verify(context.Background(), "test", contents, tc.signature(t))
+
+			// THEN
+			if len(tc.errMsg) == 0 {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			require.ErrorIs(t, err, ErrVerification)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
@@ -18,17 +18,22 @@ package rules
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"net/url"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/event"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/radixtree"
 	"github.com/dadrus/heimdall/internal/x/slicex"
 )
 
@@ -42,38 +47,82 @@ func newRepository(
 			func() rule.Rule { return ruleFactory.DefaultRule() },
 			func() rule.Rule { return nil }),
 		logger: logger,
+		index:  radixtree.New[*indexEntry](),
 		queue:  queue,
 		quit:   make(chan bool),
 	}
 }
 
+// indexEntry holds a rule in the index together with the information defining the
+// order, the matching candidates are evaluated in. That is the priority of the rule,
+// the id of the rule set it is defined in and its position in that rule set.
+type indexEntry struct {
+	priority int
+	pos      int
+	rule     rule.Rule
+}
+
+// compare orders the entries by their priority in descending order. Entries with the
+// same priority are ordered by the ids of their rule sets and their positions in these.
+// That way, the order does neither depend on the order, the rule sets have been loaded
+// in, nor on the order of the rules in the repository.
+func (e *indexEntry) compare(other *indexEntry) int {
+	return cmp.Or(
+		cmp.Compare(other.priority, e.priority),
+		strings.Compare(e.rule.SrcID(), other.rule.SrcID()),
+		cmp.Compare(e.pos, other.pos),
+	)
+}
+
 type repository struct {
 	dr     rule.Rule
 	logger zerolog.Logger
 
 	rules []rule.Rule
+	index *radixtree.Tree[*indexEntry]
 	mutex sync.RWMutex
 
 	queue event.RuleSetChangedEventQueue
 	quit  chan bool
 }
 
-func (r *repository) FindRule(requestURL *url.URL) (rule.Rule, error) {
+func (r *repository) FindRule(req *heimdall.Request) (rule.Rule, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	for _, rul := range r.rules {
-		if rul.MatchesURL(requestURL) {
-			return rul, nil
+	// rules matching the request, but not its method are skipped to allow different
+	// rules for the same url, but different methods. The first one is remembered
+	// to be able to respond with "method not allowed" if no other rule applies
+	var methodMismatch rule.Rule
+
+	for _, entry := range r.candidates(&req.URL.URL) {
+		if !entry.rule.Matches(req) {
+			continue
 		}
+
+		if entry.rule.MatchesMethod(req.Method) {
+			return entry.rule, nil
+		}
+
+		if methodMismatch == nil {
+			methodMismatch = entry.rule
+		}
+	}
+
+	if methodMismatch != nil {
+		return nil, methodNotAllowedError(methodMismatch, req.Method)
 	}
 
-	if r.dr != nil {
-		return r.dr, nil
+	if r.dr == nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrNoRuleFound,
+			"no applicable rule found for %s", req.URL.String())
 	}
 
-	return nil, errorchain.NewWithMessagef(heimdall.ErrNoRuleFound,
-		"no applicable rule found for %s", requestURL.String())
+	if !r.dr.MatchesMethod(req.Method) {
+		return nil, methodNotAllowedError(r.dr, req.Method)
+	}
+
+	return r.dr, nil
 }
 
 func (r *repository) Start(_ context.Context) error {
@@ -127,6 +176,9 @@ func (r *repository) addRuleSet(srcID string, rules []rule.Rule) {
 
 	// add them
 	r.addRules(rules)
+
+	// and index them
+	r.indexRules(srcID, rules)
 }
 
 func (r *repository) updateRuleSet(srcID string, rules []rule.Rule) {
@@ -202,6 +254,9 @@ func (r *repository) updateRuleSet(srcID string, rules []rule.Rule) {
 
 		// add new rules
 		r.addRules(newRules)
+
+		// the positions of the rules in the rule set might have changed as well
+		r.indexRules(srcID, rules)
 	}()
 }
 
@@ -218,6 +273,24 @@ func (r *repository) deleteRuleSet(srcID string) {
 	r.removeRules(applicable)
 }
 
+// candidates returns the rules, which static url pattern prefix matches the given url
+// in the order these rules are to be evaluated in.
+func (r *repository) candidates(requestURL *url.URL) []*indexEntry {
+	value, _ := urlToMatch(requestURL, config.EncodedSlashesOn)
+	candidates := r.index.FindAll(value)
+
+	// rules configured to not decode url-encoded slashes see a different path
+	if strings.Contains(requestURL.RawPath, "%2F") {
+		if value, ok := urlToMatch(requestURL, config.EncodedSlashesNoDecode); ok {
+			candidates = append(candidates, r.index.FindAll(value)...)
+		}
+	}
+
+	slices.SortFunc(candidates, (*indexEntry).compare)
+
+	return slices.Compact(candidates)
+}
+
 func (r *repository) addRules(rules []rule.Rule) {
 	for _, rul := range rules {
 		r.rules = append(r.rules, rul)
@@ -226,6 +299,24 @@ func (r *repository) addRules(rules []rule.Rule) {
 	}
 }
 
+// indexRules (re-)indexes all rules of the given rule set. The order of the given rules
+// defines the positions of the rules in the rule set.
+func (r *repository) indexRules(srcID string, ruleSet []rule.Rule) {
+	positions := make(map[string]int, len(ruleSet))
+	for pos, rul := range ruleSet {
+		positions[rul.ID()] = pos
+	}
+
+	for _, rul := range r.rules {
+		if rul.SrcID() != srcID {
+			continue
+		}
+
+		r.index.Delete(indexKey(rul), isEntryFor(rul))
+		r.index.Add(indexKey(rul), &indexEntry{priority: priority(rul), pos: positions[rul.ID()], rule: rul})
+	}
+}
+
 func (r *repository) removeRules(rules []rule.Rule) {
 	// find all indexes for affected rules
 	var idxs []int
@@ -235,6 +326,8 @@ func (r *repository) removeRules(rules []rule.Rule) {
 			if rul.SrcID() == tbd.SrcID() && rul.ID() == tbd.ID() {
 				idxs = append(idxs, idx)
 
+				r.index.Delete(indexKey(rul), isEntryFor(rul))
+
 				r.logger.Debug().Str("_src", rul.SrcID()).Str("_id", rul.ID()).Msg("Rule removed")
 			}
 		}
@@ -269,6 +362,10 @@ func (r *repository) replaceRules(rules []rule.Rule) {
 			if updated.SrcID() == existing.SrcID() && existing.ID() == updated.ID() {
 				r.rules[idx] = updated
 
+				// the url pattern might have changed, so the outdated index entry is
+				// removed. The updated rule is indexed together with the other rules
+				r.index.Delete(indexKey(existing), isEntryFor(existing))
+
 				r.logger.Debug().
 					Str("_src", existing.SrcID()).
 					Str("_id", existing.ID()).
@@ -279,3 +376,33 @@ func (r *repository) replaceRules(rules []rule.Rule) {
 		}
 	}
 }
+
+// indexKey returns the static prefix of the url pattern of the given rule. Rules
+// without such a prefix are indexed with an empty key, making them candidates for
+// any request.
+func indexKey(rul rule.Rule) string {
+	if impl, ok := rul.(*ruleImpl); ok && impl.urlMatcher != nil {
+		return impl.urlMatcher.Prefix()
+	}
+
+	return ""
+}
+
+func priority(rul rule.Rule) int {
+	if impl, ok := rul.(*ruleImpl); ok {
+		return impl.priority
+	}
+
+	return 0
+}
+
+func isEntryFor(rul rule.Rule) func(entry *indexEntry) bool {
+	return func(entry *indexEntry) bool {
+		return entry.rule.SrcID() == rul.SrcID() && entry.rule.ID() == rul.ID()
+	}
+}
+
+func methodNotAllowedError(rul rule.Rule, method string) error {
+	return errorchain.NewWithMessagef(heimdall.ErrMethodNotAllowed,
+		"rule (id=%s, src=%s) doesn't match %s method", rul.ID(), rul.SrcID(), method)
+}
@@ -18,6 +18,8 @@ package rules
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"net/url"
 	"testing"
 	"time"
@@ -28,6 +30,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/event"
 	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
 	"github.com/dadrus/heimdall/internal/rules/rule"
@@ -62,8 +65,15 @@ func TestRepositoryAddAndRemoveRulesFromSameRuleSet(t *testing.T) {
 func TestRepositoryFindRule(t *testing.T) {
 	t.Parallel()
 
+	newRule := func(id, srcID, pattern string, methods ...string) *ruleImpl {
+		matcher, _ := patternmatcher.NewPatternMatcher("glob", pattern)
+
+		return &ruleImpl{id: id, srcID: srcID, urlMatcher: matcher, methods: methods}
+	}
+
 	for _, tc := range []struct {
 		uc               string
+		method           string
 		requestURL       *url.URL
 		addRules         func(t *testing.T, repo *repository)
 		configureFactory func(t *testing.T, factory *mocks.FactoryMock)
@@ -71,6 +81,7 @@ func TestRepositoryFindRule(t *testing.T) {
 	}{
 		{
 			uc:         "no matching rule without default rule",
+			method:     http.MethodGet,
 			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
 			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
@@ -86,22 +97,41 @@ func TestRepositoryFindRule(t *testing.T) {
 		},
 		{
 			uc:         "no matching rule with default rule",
+			method:     http.MethodGet,
 			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
 			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
 
 				factory.EXPECT().HasDefaultRule().Return(true)
-				factory.EXPECT().DefaultRule().Return(&ruleImpl{id: "test", isDefault: true})
+				factory.EXPECT().DefaultRule().Return(&ruleImpl{id: "test", isDefault: true, methods: []string{http.MethodGet}})
 			},
 			assert: func(t *testing.T, err error, rul rule.Rule) {
 				t.Helper()
 
 				require.NoError(t, err)
-				require.Equal(t, &ruleImpl{id: "test", isDefault: true}, rul)
+				require.Equal(t, &ruleImpl{id: "test", isDefault: true, methods: []string{http.MethodGet}}, rul)
+			},
+		},
+		{
+			uc:         "no matching rule with default rule not matching the method",
+			method:     http.MethodPost,
+			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(true)
+				factory.EXPECT().DefaultRule().Return(&ruleImpl{id: "test", isDefault: true, methods: []string{http.MethodGet}})
+			},
+			assert: func(t *testing.T, err error, _ rule.Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrMethodNotAllowed)
 			},
 		},
 		{
 			uc:         "matching rule",
+			method:     http.MethodGet,
 			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
 			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
@@ -111,28 +141,12 @@ func TestRepositoryFindRule(t *testing.T) {
 			addRules: func(t *testing.T, repo *repository) {
 				t.Helper()
 
-				repo.rules = append(repo.rules,
-					&ruleImpl{
-						id:    "test1",
-						srcID: "bar",
-						urlMatcher: func() patternmatcher.PatternMatcher {
-							matcher, _ := patternmatcher.NewPatternMatcher("glob",
-								"http://heimdall.test.local/baz")
-
-							return matcher
-						}(),
-					},
-					&ruleImpl{
-						id:    "test2",
-						srcID: "baz",
-						urlMatcher: func() patternmatcher.PatternMatcher {
-							matcher, _ := patternmatcher.NewPatternMatcher("glob",
-								"http://foo.bar/baz")
-
-							return matcher
-						}(),
-					},
-				)
+				repo.addRuleSet("bar", []rule.Rule{
+					newRule("test1", "bar", "http://heimdall.test.local/baz", http.MethodGet),
+				})
+				repo.addRuleSet("baz", []rule.Rule{
+					newRule("test2", "baz", "http://foo.bar/baz", http.MethodGet),
+				})
 			},
 			assert: func(t *testing.T, err error, rul rule.Rule) {
 				t.Helper()
@@ -146,6 +160,57 @@ func TestRepositoryFindRule(t *testing.T) {
 				require.Equal(t, "baz", impl.srcID)
 			},
 		},
+		{
+			uc:         "matching rule after falling through rules not matching the method",
+			method:     http.MethodPost,
+			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/items/1"},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(false)
+			},
+			addRules: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				repo.addRuleSet("bar", []rule.Rule{
+					newRule("test1", "bar", "http://foo.bar/api/items/<**>", http.MethodGet),
+					newRule("test2", "bar", "http://foo.bar/<**>", http.MethodGet, http.MethodDelete),
+					newRule("test3", "bar", "http://foo.bar/api/items/<**>", http.MethodPost),
+				})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Equal(t, "test3", rul.ID())
+			},
+		},
+		{
+			uc:         "matching rules, but none of them matches the method",
+			method:     http.MethodPatch,
+			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/items/1"},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(true)
+				factory.EXPECT().DefaultRule().Return(&ruleImpl{id: "test", isDefault: true, methods: []string{http.MethodPatch}})
+			},
+			addRules: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				repo.addRuleSet("bar", []rule.Rule{
+					newRule("test1", "bar", "http://foo.bar/api/items/<**>", http.MethodGet),
+					newRule("test2", "bar", "http://foo.bar/api/items/<**>", http.MethodPost),
+				})
+			},
+			assert: func(t *testing.T, err error, _ rule.Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrMethodNotAllowed)
+				assert.Contains(t, err.Error(), "id=test1")
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -161,7 +226,7 @@ func TestRepositoryFindRule(t *testing.T) {
 			addRules(t, repo)
 
 			// WHEN
-			rul, err := repo.FindRule(tc.requestURL)
+			rul, err := repo.FindRule(&heimdall.Request{Method: tc.method, URL: &heimdall.URL{URL: *tc.requestURL}})
 
 			// THEN
 			tc.assert(t, err, rul)
@@ -169,6 +234,169 @@ func TestRepositoryFindRule(t *testing.T) {
 	}
 }
 
+func TestRepositoryFindRuleUsesUpToDateIndex(t *testing.T) {
+	t.Parallel()
+
+	newRule := func(id, srcID, pattern string) *ruleImpl {
+		matcher, err := patternmatcher.NewPatternMatcher("glob", pattern)
+		require.NoError(t, err)
+
+		return &ruleImpl{
+			id:         id,
+			srcID:      srcID,
+			urlMatcher: matcher,
+			hash:       []byte(pattern),
+			methods:    []string{http.MethodGet},
+		}
+	}
+
+	// GIVEN
+	repo := newRepository(nil, &ruleFactory{}, *zerolog.Ctx(context.Background()))
+
+	repo.addRuleSet("test", []rule.Rule{
+		newRule("rule1", "test", "http://foo.bar/<**>"),
+		newRule("rule2", "test", "http://foo.bar/api/<**>"),
+		newRule("rule3", "test", "<**>/baz"),
+	})
+
+	req := &heimdall.Request{
+		Method: http.MethodGet,
+		URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/baz"}},
+	}
+
+	// WHEN
+	rul, err := repo.FindRule(req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "rule1", rul.ID())
+
+	// WHEN
+	repo.updateRuleSet("test", []rule.Rule{
+		newRule("rule1", "test", "http://foo.baz/<**>"),
+		newRule("rule2", "test", "http://foo.bar/api/<**>"),
+		newRule("rule3", "test", "<**>/baz"),
+	})
+	rul, err = repo.FindRule(req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "rule2", rul.ID())
+
+	// WHEN
+	repo.updateRuleSet("test", []rule.Rule{
+		newRule("rule1", "test", "http://foo.baz/<**>"),
+		newRule("rule3", "test", "<**>/baz"),
+	})
+	rul, err = repo.FindRule(req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "rule3", rul.ID())
+	assert.Equal(t, 2, repo.index.Len())
+
+	// WHEN
+	repo.deleteRuleSet("test")
+	_, err = repo.FindRule(req)
+
+	// THEN
+	require.ErrorIs(t, err, heimdall.ErrNoRuleFound)
+	assert.Equal(t, 0, repo.index.Len())
+}
+
+func TestRepositoryFindRuleHonorsEvaluationOrder(t *testing.T) {
+	t.Parallel()
+
+	newRule := func(id, srcID string, priority int) *ruleImpl {
+		matcher, err := patternmatcher.NewPatternMatcher("glob", "http://foo.bar/<**>")
+		require.NoError(t, err)
+
+		return &ruleImpl{
+			id:         id,
+			srcID:      srcID,
+			priority:   priority,
+			urlMatcher: matcher,
+			hash:       []byte(fmt.Sprintf("%s:%d", id, priority)),
+			methods:    []string{http.MethodGet},
+		}
+	}
+
+	// GIVEN
+	repo := newRepository(nil, &ruleFactory{}, *zerolog.Ctx(context.Background()))
+
+	req := &heimdall.Request{
+		Method: http.MethodGet,
+		URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"}},
+	}
+
+	// WHEN
+	repo.addRuleSet("src2", []rule.Rule{newRule("rule1", "src2", 0)})
+	repo.addRuleSet("src1", []rule.Rule{newRule("rule1", "src1", 0), newRule("rule2", "src1", 0)})
+	rul, err := repo.FindRule(req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "rule1", rul.ID())
+	assert.Equal(t, "src1", rul.SrcID())
+
+	// WHEN
+	repo.updateRuleSet("src1", []rule.Rule{newRule("rule2", "src1", 0), newRule("rule1", "src1", 0)})
+	rul, err = repo.FindRule(req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "rule2", rul.ID())
+	assert.Equal(t, "src1", rul.SrcID())
+
+	// WHEN
+	repo.updateRuleSet("src2", []rule.Rule{newRule("rule1", "src2", 10)})
+	rul, err = repo.FindRule(req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "rule1", rul.ID())
+	assert.Equal(t, "src2", rul.SrcID())
+
+	// WHEN
+	repo.updateRuleSet("src1", []rule.Rule{newRule("rule2", "src1", 0), newRule("rule1", "src1", 20)})
+	rul, err = repo.FindRule(req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "rule1", rul.ID())
+	assert.Equal(t, "src1", rul.SrcID())
+	assert.Equal(t, 3, repo.index.Len())
+}
+
+func TestRepositoryFindRuleWithEncodedSlashes(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	matcher, err := patternmatcher.NewPatternMatcher("glob", "http://foo.bar/foo%2Fbar/<**>")
+	require.NoError(t, err)
+
+	repo := newRepository(nil, &ruleFactory{}, *zerolog.Ctx(context.Background()))
+	repo.addRuleSet("test", []rule.Rule{
+		&ruleImpl{
+			id:                     "test",
+			srcID:                  "test",
+			encodedSlashesHandling: config.EncodedSlashesNoDecode,
+			urlMatcher:             matcher,
+			methods:                []string{http.MethodGet},
+		},
+	})
+
+	requestURL, err := url.Parse("http://foo.bar/foo%2Fbar/baz")
+	require.NoError(t, err)
+
+	// WHEN
+	rul, err := repo.FindRule(&heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: *requestURL}})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "test", rul.ID())
+}
+
 func TestRepositoryAddAndRemoveRulesFromDifferentRuleSets(t *testing.T) {
 	t.Parallel()
 
@@ -359,3 +587,53 @@ func TestRepositoryRuleSetLifecycleManagement(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkRepositoryFindRule(b *testing.B) {
+	for _, ruleCount := range []int{10, 100, 1000, 10000} {
+		// GIVEN
+		rules := make([]rule.Rule, ruleCount)
+
+		for idx := range ruleCount {
+			matcher, err := patternmatcher.NewPatternMatcher("glob",
+				fmt.Sprintf("http://service-%d.local/api/<**>", idx))
+			require.NoError(b, err)
+
+			rules[idx] = &ruleImpl{
+				id:         fmt.Sprintf("rule-%d", idx),
+				srcID:      "bench",
+				urlMatcher: matcher,
+				methods:    []string{http.MethodGet},
+			}
+		}
+
+		repo := newRepository(nil, &ruleFactory{}, zerolog.Nop())
+		repo.addRuleSet("bench", rules)
+
+		// the last added rule is the worst case for a linear scan
+		req := &heimdall.Request{
+			Method: http.MethodGet,
+			URL: &heimdall.URL{
+				URL: url.URL{
+					Scheme: "http",
+					Host:   fmt.Sprintf("service-%d.local", ruleCount-1),
+					Path:   "/api/v1/resources",
+				},
+			},
+		}
+
+		b.Run(fmt.Sprintf("rules=%d", ruleCount), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for range b.N {
+				// WHEN
+				rul, err := repo.FindRule(req)
+
+				// THEN
+				if err != nil || rul == nil {
+					b.Fatal("no rule found")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"net/http"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// requestMatcher checks the request conditions defined in a rule matcher in addition
+// to the url pattern. Its zero value matches any request.
+type requestMatcher struct {
+	scheme      string
+	hosts       []patternmatcher.PatternMatcher
+	headers     map[string]patternmatcher.PatternMatcher
+	queryParams map[string]patternmatcher.PatternMatcher
+}
+
+func newRequestMatcher(conf config.Matcher) (requestMatcher, error) {
+	var (
+		matcher requestMatcher
+		err     error
+	)
+
+	matcher.scheme = conf.Scheme
+
+	if len(conf.Hosts) != 0 {
+		matcher.hosts = make([]patternmatcher.PatternMatcher, len(conf.Hosts))
+
+		for idx, host := range conf.Hosts {
+			if matcher.hosts[idx], err = patternmatcher.NewPatternMatcher(conf.Strategy, host); err != nil {
+				return requestMatcher{}, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"bad host pattern '%s'", host).CausedBy(err)
+			}
+		}
+	}
+
+	if matcher.headers, err = newPatternMatchers(
+		"header", conf.Strategy, conf.Headers, http.CanonicalHeaderKey,
+	); err != nil {
+		return requestMatcher{}, err
+	}
+
+	if matcher.queryParams, err = newPatternMatchers(
+		"query parameter", conf.Strategy, conf.QueryParameters, func(name string) string { return name },
+	); err != nil {
+		return requestMatcher{}, err
+	}
+
+	return matcher, nil
+}
+
+func newPatternMatchers(
+	kind, strategy string, patterns map[string]string, keyFunc func(string) string,
+) (map[string]patternmatcher.PatternMatcher, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	matchers := make(map[string]patternmatcher.PatternMatcher, len(patterns))
+
+	for name, pattern := range patterns {
+		matcher, err := patternmatcher.NewPatternMatcher(strategy, pattern)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"bad pattern for %s '%s'", kind, name).CausedBy(err)
+		}
+
+		matchers[keyFunc(name)] = matcher
+	}
+
+	return matchers, nil
+}
+
+func (m requestMatcher) Matches(req *heimdall.Request) bool {
+	return m.matchesScheme(req) && m.matchesHost(req) && m.matchesHeaders(req) && m.matchesQueryParams(req)
+}
+
+func (m requestMatcher) matchesScheme(req *heimdall.Request) bool {
+	return len(m.scheme) == 0 || m.scheme == req.URL.Scheme
+}
+
+func (m requestMatcher) matchesHost(req *heimdall.Request) bool {
+	if len(m.hosts) == 0 {
+		return true
+	}
+
+	host := req.URL.Hostname()
+
+	for _, matcher := range m.hosts {
+		if matcher.Match(host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m requestMatcher) matchesHeaders(req *heimdall.Request) bool {
+	for name, matcher := range m.headers {
+		value := req.Header(name)
+		if len(value) == 0 || !matcher.Match(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m requestMatcher) matchesQueryParams(req *heimdall.Request) bool {
+	if len(m.queryParams) == 0 {
+		return true
+	}
+
+	query := req.URL.Query()
+
+	for name, matcher := range m.queryParams {
+		var matched bool
+
+		for _, value := range query[name] {
+			if matcher.Match(value) {
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/config"
+)
+
+func TestNewRequestMatcher(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		conf   config.Matcher
+		assert func(t *testing.T, err error)
+	}{
+		{
+			uc:   "without request conditions",
+			conf: config.Matcher{Strategy: "glob"},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:   "with bad host pattern",
+			conf: config.Matcher{Strategy: "regex", Hosts: []string{"<(foo>"}},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "bad host pattern")
+			},
+		},
+		{
+			uc:   "with bad header pattern",
+			conf: config.Matcher{Strategy: "regex", Headers: map[string]string{"X-Foo": "<(foo>"}},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "bad pattern for header 'X-Foo'")
+			},
+		},
+		{
+			uc:   "with bad query parameter pattern",
+			conf: config.Matcher{Strategy: "regex", QueryParameters: map[string]string{"foo": "<(foo>"}},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "bad pattern for query parameter 'foo'")
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			_, err := newRequestMatcher(tc.conf)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
+func TestRequestMatcherMatches(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc      string
+		conf    config.Matcher
+		url     string
+		headers map[string]string
+		matches bool
+	}{
+		{
+			uc:      "without request conditions",
+			conf:    config.Matcher{Strategy: "glob"},
+			url:     "http://foo.bar/baz",
+			matches: true,
+		},
+		{
+			uc:      "matching scheme",
+			conf:    config.Matcher{Strategy: "glob", Scheme: "https"},
+			url:     "https://foo.bar/baz",
+			matches: true,
+		},
+		{
+			uc:   "not matching scheme",
+			conf: config.Matcher{Strategy: "glob", Scheme: "https"},
+			url:  "http://foo.bar/baz",
+		},
+		{
+			uc:      "matching one of the host patterns",
+			conf:    config.Matcher{Strategy: "glob", Hosts: []string{"foo.baz", "<*>.bar"}},
+			url:     "http://foo.bar:8080/baz",
+			matches: true,
+		},
+		{
+			uc:   "not matching any of the host patterns",
+			conf: config.Matcher{Strategy: "glob", Hosts: []string{"foo.baz", "bar.<*>"}},
+			url:  "http://foo.bar/baz",
+		},
+		{
+			uc:      "matching headers",
+			conf:    config.Matcher{Strategy: "glob", Headers: map[string]string{"x-foo": "bar", "X-Bar": "<*>"}},
+			url:     "http://foo.bar/baz",
+			headers: map[string]string{"X-Foo": "bar", "X-Bar": "baz"},
+			matches: true,
+		},
+		{
+			uc:      "not matching header value",
+			conf:    config.Matcher{Strategy: "glob", Headers: map[string]string{"X-Foo": "bar"}},
+			url:     "http://foo.bar/baz",
+			headers: map[string]string{"X-Foo": "baz"},
+		},
+		{
+			uc:   "missing header",
+			conf: config.Matcher{Strategy: "glob", Headers: map[string]string{"X-Foo": "<*>"}},
+			url:  "http://foo.bar/baz",
+		},
+		{
+			uc:      "matching one of the query parameter values",
+			conf:    config.Matcher{Strategy: "regex", QueryParameters: map[string]string{"foo": "<[0-9]+>"}},
+			url:     "http://foo.bar/baz?foo=bar&foo=42",
+			matches: true,
+		},
+		{
+			uc:   "not matching query parameter value",
+			conf: config.Matcher{Strategy: "regex", QueryParameters: map[string]string{"foo": "<[0-9]+>"}},
+			url:  "http://foo.bar/baz?foo=bar",
+		},
+		{
+			uc:   "missing query parameter",
+			conf: config.Matcher{Strategy: "glob", QueryParameters: map[string]string{"foo": "<*>"}},
+			url:  "http://foo.bar/baz?bar=foo",
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			matcher, err := newRequestMatcher(tc.conf)
+			require.NoError(t, err)
+
+			requestURL, err := url.Parse(tc.url)
+			require.NoError(t, err)
+
+			reqf := heimdallmocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Header(mock.Anything).RunAndReturn(func(name string) string {
+				return tc.headers[name]
+			}).Maybe()
+
+			req := &heimdall.Request{RequestFunctions: reqf, URL: &heimdall.URL{URL: *requestURL}}
+
+			// WHEN
+			matched := matcher.Matches(req)
+
+			// THEN
+			assert.Equal(t, tc.matches, matched)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/revocation"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+type revocationAwareSubjectCreator struct {
+	sc subjectCreator
+}
+
+func (c *revocationAwareSubjectCreator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	sub, err := c.sc.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if revocation.IsRevoked(ctx.AppContext(), cache.Ctx(ctx.AppContext()), sub) {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication,
+			"subject or the credentials it has been created from are revoked")
+	}
+
+	return sub, nil
+}
+
+func (c *revocationAwareSubjectCreator) IsFallbackOnErrorAllowed() bool {
+	return c.sc.IsFallbackOnErrorAllowed()
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/revocation"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	rulemocks "github.com/dadrus/heimdall/internal/rules/mocks"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestRevocationAwareSubjectCreatorExecute(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc             string
+		configureMocks func(t *testing.T, ctx heimdall.Context, cch cache.Cache, sc *rulemocks.SubjectCreatorMock)
+		assert         func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc: "with failing subject creator",
+			configureMocks: func(t *testing.T, ctx heimdall.Context, _ cache.Cache, sc *rulemocks.SubjectCreatorMock) {
+				t.Helper()
+
+				sc.EXPECT().Execute(ctx).Return(nil, testsupport.ErrTestPurpose)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, testsupport.ErrTestPurpose)
+			},
+		},
+		{
+			uc: "with subject not being revoked",
+			configureMocks: func(t *testing.T, ctx heimdall.Context, _ cache.Cache, sc *rulemocks.SubjectCreatorMock) {
+				t.Helper()
+
+				sc.EXPECT().Execute(ctx).Return(&subject.Subject{ID: "foo"}, nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", sub.ID)
+			},
+		},
+		{
+			uc: "with revoked subject",
+			configureMocks: func(t *testing.T, ctx heimdall.Context, cch cache.Cache, sc *rulemocks.SubjectCreatorMock) {
+				t.Helper()
+
+				require.NoError(t, revocation.Revoke(context.Background(), cch, revocation.TypeSubject, "foo", time.Hour))
+
+				sc.EXPECT().Execute(ctx).Return(&subject.Subject{ID: "foo"}, nil)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorContains(t, err, "revoked")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			cch, err := memory.NewCache(nil, nil)
+			require.NoError(t, err)
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch)).Maybe()
+
+			sc := rulemocks.NewSubjectCreatorMock(t)
+			tc.configureMocks(t, ctx, cch, sc)
+
+			creator := &revocationAwareSubjectCreator{sc: sc}
+
+			// WHEN
+			sub, err := creator.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
+
+func TestRevocationAwareSubjectCreatorIsFallbackOnErrorAllowed(t *testing.T) {
+	t.Parallel()
+
+	for _, allowed := range []bool{true, false} {
+		sc := rulemocks.NewSubjectCreatorMock(t)
+		sc.EXPECT().IsFallbackOnErrorAllowed().Return(allowed)
+
+		creator := &revocationAwareSubjectCreator{sc: sc}
+
+		assert.Equal(t, allowed, creator.IsFallbackOnErrorAllowed())
+	}
+}
@@ -3,10 +3,10 @@
 package mocks
 
 import (
-	url "net/url"
+	heimdall "github.com/dadrus/heimdall/internal/heimdall"
+	mock "github.com/stretchr/testify/mock"
 
 	rule "github.com/dadrus/heimdall/internal/rules/rule"
-	mock "github.com/stretchr/testify/mock"
 )
 
 // RepositoryMock is an autogenerated mock type for the Repository type
@@ -22,25 +22,25 @@ func (_m *RepositoryMock) EXPECT() *RepositoryMock_Expecter {
 	return &RepositoryMock_Expecter{mock: &_m.Mock}
 }
 
-// FindRule provides a mock function with given fields: _a0
-func (_m *RepositoryMock) FindRule(_a0 *url.URL) (rule.Rule, error) {
-	ret := _m.Called(_a0)
+// FindRule provides a mock function with given fields: req
+func (_m *RepositoryMock) FindRule(req *heimdall.Request) (rule.Rule, error) {
+	ret := _m.Called(req)
 
 	var r0 rule.Rule
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*url.URL) (rule.Rule, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(*heimdall.Request) (rule.Rule, error)); ok {
+		return rf(req)
 	}
-	if rf, ok := ret.Get(0).(func(*url.URL) rule.Rule); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(*heimdall.Request) rule.Rule); ok {
+		r0 = rf(req)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(rule.Rule)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*url.URL) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(*heimdall.Request) error); ok {
+		r1 = rf(req)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -54,14 +54,14 @@ type RepositoryMock_FindRule_Call struct {
 }
 
 // FindRule is a helper method to define mock.On call
-//   - _a0 *url.URL
-func (_e *RepositoryMock_Expecter) FindRule(_a0 interface{}) *RepositoryMock_FindRule_Call {
-	return &RepositoryMock_FindRule_Call{Call: _e.mock.On("FindRule", _a0)}
+//   - req *heimdall.Request
+func (_e *RepositoryMock_Expecter) FindRule(req interface{}) *RepositoryMock_FindRule_Call {
+	return &RepositoryMock_FindRule_Call{Call: _e.mock.On("FindRule", req)}
 }
 
-func (_c *RepositoryMock_FindRule_Call) Run(run func(_a0 *url.URL)) *RepositoryMock_FindRule_Call {
+func (_c *RepositoryMock_FindRule_Call) Run(run func(req *heimdall.Request)) *RepositoryMock_FindRule_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*url.URL))
+		run(args[0].(*heimdall.Request))
 	})
 	return _c
 }
@@ -71,7 +71,7 @@ func (_c *RepositoryMock_FindRule_Call) Return(_a0 rule.Rule, _a1 error) *Reposi
 	return _c
 }
 
-func (_c *RepositoryMock_FindRule_Call) RunAndReturn(run func(*url.URL) (rule.Rule, error)) *RepositoryMock_FindRule_Call {
+func (_c *RepositoryMock_FindRule_Call) RunAndReturn(run func(*heimdall.Request) (rule.Rule, error)) *RepositoryMock_FindRule_Call {
 	_c.Call.Return(run)
 	return _c
 }
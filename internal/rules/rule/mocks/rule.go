@@ -7,8 +7,6 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	rule "github.com/dadrus/heimdall/internal/rules/rule"
-
-	url "net/url"
 )
 
 // RuleMock is an autogenerated mock type for the Rule type
@@ -24,17 +22,17 @@ func (_m *RuleMock) EXPECT() *RuleMock_Expecter {
 	return &RuleMock_Expecter{mock: &_m.Mock}
 }
 
-// Execute provides a mock function with given fields: _a0
-func (_m *RuleMock) Execute(_a0 heimdall.Context) (rule.Backend, error) {
-	ret := _m.Called(_a0)
+// Execute provides a mock function with given fields: ctx
+func (_m *RuleMock) Execute(ctx heimdall.Context) (rule.Backend, error) {
+	ret := _m.Called(ctx)
 
 	var r0 rule.Backend
 	var r1 error
 	if rf, ok := ret.Get(0).(func(heimdall.Context) (rule.Backend, error)); ok {
-		return rf(_a0)
+		return rf(ctx)
 	}
 	if rf, ok := ret.Get(0).(func(heimdall.Context) rule.Backend); ok {
-		r0 = rf(_a0)
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(rule.Backend)
@@ -42,7 +40,7 @@ func (_m *RuleMock) Execute(_a0 heimdall.Context) (rule.Backend, error) {
 	}
 
 	if rf, ok := ret.Get(1).(func(heimdall.Context) error); ok {
-		r1 = rf(_a0)
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -56,12 +54,12 @@ type RuleMock_Execute_Call struct {
 }
 
 // Execute is a helper method to define mock.On call
-//   - _a0 heimdall.Context
-func (_e *RuleMock_Expecter) Execute(_a0 interface{}) *RuleMock_Execute_Call {
-	return &RuleMock_Execute_Call{Call: _e.mock.On("Execute", _a0)}
+//   - ctx heimdall.Context
+func (_e *RuleMock_Expecter) Execute(ctx interface{}) *RuleMock_Execute_Call {
+	return &RuleMock_Execute_Call{Call: _e.mock.On("Execute", ctx)}
 }
 
-func (_c *RuleMock_Execute_Call) Run(run func(_a0 heimdall.Context)) *RuleMock_Execute_Call {
+func (_c *RuleMock_Execute_Call) Run(run func(ctx heimdall.Context)) *RuleMock_Execute_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(heimdall.Context))
 	})
@@ -119,13 +117,13 @@ func (_c *RuleMock_ID_Call) RunAndReturn(run func() string) *RuleMock_ID_Call {
 	return _c
 }
 
-// MatchesMethod provides a mock function with given fields: _a0
-func (_m *RuleMock) MatchesMethod(_a0 string) bool {
-	ret := _m.Called(_a0)
+// Matches provides a mock function with given fields: req
+func (_m *RuleMock) Matches(req *heimdall.Request) bool {
+	ret := _m.Called(req)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(string) bool); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(*heimdall.Request) bool); ok {
+		r0 = rf(req)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
@@ -133,41 +131,41 @@ func (_m *RuleMock) MatchesMethod(_a0 string) bool {
 	return r0
 }
 
-// RuleMock_MatchesMethod_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MatchesMethod'
-type RuleMock_MatchesMethod_Call struct {
+// RuleMock_Matches_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Matches'
+type RuleMock_Matches_Call struct {
 	*mock.Call
 }
 
-// MatchesMethod is a helper method to define mock.On call
-//   - _a0 string
-func (_e *RuleMock_Expecter) MatchesMethod(_a0 interface{}) *RuleMock_MatchesMethod_Call {
-	return &RuleMock_MatchesMethod_Call{Call: _e.mock.On("MatchesMethod", _a0)}
+// Matches is a helper method to define mock.On call
+//   - req *heimdall.Request
+func (_e *RuleMock_Expecter) Matches(req interface{}) *RuleMock_Matches_Call {
+	return &RuleMock_Matches_Call{Call: _e.mock.On("Matches", req)}
 }
 
-func (_c *RuleMock_MatchesMethod_Call) Run(run func(_a0 string)) *RuleMock_MatchesMethod_Call {
+func (_c *RuleMock_Matches_Call) Run(run func(req *heimdall.Request)) *RuleMock_Matches_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(*heimdall.Request))
 	})
 	return _c
 }
 
-func (_c *RuleMock_MatchesMethod_Call) Return(_a0 bool) *RuleMock_MatchesMethod_Call {
+func (_c *RuleMock_Matches_Call) Return(_a0 bool) *RuleMock_Matches_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *RuleMock_MatchesMethod_Call) RunAndReturn(run func(string) bool) *RuleMock_MatchesMethod_Call {
+func (_c *RuleMock_Matches_Call) RunAndReturn(run func(*heimdall.Request) bool) *RuleMock_Matches_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// MatchesURL provides a mock function with given fields: _a0
-func (_m *RuleMock) MatchesURL(_a0 *url.URL) bool {
-	ret := _m.Called(_a0)
+// MatchesMethod provides a mock function with given fields: method
+func (_m *RuleMock) MatchesMethod(method string) bool {
+	ret := _m.Called(method)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(*url.URL) bool); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(method)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
@@ -175,30 +173,30 @@ func (_m *RuleMock) MatchesURL(_a0 *url.URL) bool {
 	return r0
 }
 
-// RuleMock_MatchesURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MatchesURL'
-type RuleMock_MatchesURL_Call struct {
+// RuleMock_MatchesMethod_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MatchesMethod'
+type RuleMock_MatchesMethod_Call struct {
 	*mock.Call
 }
 
-// MatchesURL is a helper method to define mock.On call
-//   - _a0 *url.URL
-func (_e *RuleMock_Expecter) MatchesURL(_a0 interface{}) *RuleMock_MatchesURL_Call {
-	return &RuleMock_MatchesURL_Call{Call: _e.mock.On("MatchesURL", _a0)}
+// MatchesMethod is a helper method to define mock.On call
+//   - method string
+func (_e *RuleMock_Expecter) MatchesMethod(method interface{}) *RuleMock_MatchesMethod_Call {
+	return &RuleMock_MatchesMethod_Call{Call: _e.mock.On("MatchesMethod", method)}
 }
 
-func (_c *RuleMock_MatchesURL_Call) Run(run func(_a0 *url.URL)) *RuleMock_MatchesURL_Call {
+func (_c *RuleMock_MatchesMethod_Call) Run(run func(method string)) *RuleMock_MatchesMethod_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*url.URL))
+		run(args[0].(string))
 	})
 	return _c
 }
 
-func (_c *RuleMock_MatchesURL_Call) Return(_a0 bool) *RuleMock_MatchesURL_Call {
+func (_c *RuleMock_MatchesMethod_Call) Return(_a0 bool) *RuleMock_MatchesMethod_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *RuleMock_MatchesURL_Call) RunAndReturn(run func(*url.URL) bool) *RuleMock_MatchesURL_Call {
+func (_c *RuleMock_MatchesMethod_Call) RunAndReturn(run func(string) bool) *RuleMock_MatchesMethod_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,191 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+type ruleConflict struct {
+	rule     *ruleDescriptor
+	other    *ruleDescriptor
+	methods  []string
+	shadowed bool
+}
+
+func (c ruleConflict) String() string {
+	if c.shadowed {
+		return fmt.Sprintf("rule ID=%s from %s is shadowed by rule ID=%s from %s",
+			c.rule.id, c.rule.srcID, c.other.id, c.other.srcID)
+	}
+
+	return fmt.Sprintf("rule ID=%s from %s overlaps with rule ID=%s from %s for %s methods",
+		c.rule.id, c.rule.srcID, c.other.id, c.other.srcID, strings.Join(c.methods, ","))
+}
+
+// ruleDescriptor holds the information about a loaded rule required to detect conflicts.
+type ruleDescriptor struct {
+	id         string
+	srcID      string
+	priority   int
+	pos        int
+	matcher    config.Matcher
+	urlMatcher patternmatcher.PatternMatcher
+	methods    []string
+}
+
+// precedes returns true if the rule is evaluated before the given rule. The order is the same
+// as used by the repository.
+func (d *ruleDescriptor) precedes(other *ruleDescriptor) bool {
+	return cmp.Or(
+		cmp.Compare(other.priority, d.priority),
+		strings.Compare(d.srcID, other.srcID),
+		cmp.Compare(d.pos, other.pos),
+	) < 0
+}
+
+// isStatic returns true if the url pattern of the rule does not contain any expressions.
+// Such patterns match exactly one url, which is the pattern itself.
+func (d *ruleDescriptor) isStatic() bool {
+	return d.urlMatcher.Prefix() == d.matcher.URL
+}
+
+func (d *ruleDescriptor) hasSameRequestConditions(other *ruleDescriptor) bool {
+	return d.matcher.Scheme == other.matcher.Scheme &&
+		d.matcher.Strategy == other.matcher.Strategy &&
+		slices.Equal(d.matcher.Hosts, other.matcher.Hosts) &&
+		maps.Equal(d.matcher.Headers, other.matcher.Headers) &&
+		maps.Equal(d.matcher.QueryParameters, other.matcher.QueryParameters)
+}
+
+// conflictWith checks whether the rule conflicts with the given rule, which takes precedence
+// over it. The detection is conservative. Only overlaps, which can be decided without
+// comparing the expressions used in the url patterns, are reported.
+func (d *ruleDescriptor) conflictWith(other *ruleDescriptor) (ruleConflict, bool) {
+	methods := slices.DeleteFunc(slices.Clone(d.methods), func(method string) bool {
+		return !slices.Contains(other.methods, method)
+	})
+	if len(methods) == 0 {
+		return ruleConflict{}, false
+	}
+
+	conditionsCovered := !other.matcher.HasRequestConditions() || d.hasSameRequestConditions(other)
+	if !conditionsCovered && d.matcher.HasRequestConditions() {
+		// both rules define different request conditions
+		return ruleConflict{}, false
+	}
+
+	urlCovered := (d.matcher.Strategy == other.matcher.Strategy && d.matcher.URL == other.matcher.URL) ||
+		(d.isStatic() && other.urlMatcher.Match(d.matcher.URL))
+	if !urlCovered && (!other.isStatic() || !d.urlMatcher.Match(other.matcher.URL)) {
+		return ruleConflict{}, false
+	}
+
+	return ruleConflict{
+		rule:     d,
+		other:    other,
+		methods:  methods,
+		shadowed: urlCovered && conditionsCovered && len(methods) == len(d.methods),
+	}, true
+}
+
+// ruleConflictDetector keeps track of the rules loaded from the different sources to detect
+// rules overlapping with, or being shadowed by other rules.
+type ruleConflictDetector struct {
+	rules map[string][]*ruleDescriptor
+}
+
+func newRuleConflictDetector() *ruleConflictDetector {
+	return &ruleConflictDetector{rules: make(map[string][]*ruleDescriptor)}
+}
+
+// conflicts returns the conflicts of the given rules with each other and with the rules
+// loaded from other sources.
+func (d *ruleConflictDetector) conflicts(srcID string, rules []*ruleDescriptor) []ruleConflict {
+	var conflicts []ruleConflict
+
+	sources := make([]string, 0, len(d.rules))
+	for src := range d.rules {
+		sources = append(sources, src)
+	}
+
+	slices.Sort(sources)
+
+	for idx, rul := range rules {
+		for _, src := range sources {
+			if src == srcID {
+				continue
+			}
+
+			conflicts = appendConflicts(conflicts, rul, d.rules[src])
+		}
+
+		conflicts = appendConflicts(conflicts, rul, rules[:idx])
+	}
+
+	return conflicts
+}
+
+func (d *ruleConflictDetector) set(srcID string, rules []*ruleDescriptor) { d.rules[srcID] = rules }
+
+func (d *ruleConflictDetector) remove(srcID string) { delete(d.rules, srcID) }
+
+func appendConflicts(conflicts []ruleConflict, rul *ruleDescriptor, others []*ruleDescriptor) []ruleConflict {
+	for _, other := range others {
+		preceding, following := other, rul
+		if rul.precedes(other) {
+			preceding, following = rul, other
+		}
+
+		if conflict, ok := following.conflictWith(preceding); ok {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	return conflicts
+}
+
+func newRuleDescriptors(ruleSet *config.RuleSet, rules []rule.Rule) []*ruleDescriptor {
+	descriptors := make([]*ruleDescriptor, 0, len(rules))
+
+	for idx, rul := range rules {
+		impl, ok := rul.(*ruleImpl)
+		if !ok || impl.urlMatcher == nil {
+			continue
+		}
+
+		descriptors = append(descriptors, &ruleDescriptor{
+			id:         impl.id,
+			srcID:      impl.srcID,
+			priority:   impl.priority,
+			pos:        idx,
+			matcher:    ruleSet.Rules[idx].RuleMatcher,
+			urlMatcher: impl.urlMatcher,
+			methods:    impl.methods,
+		})
+	}
+
+	return descriptors
+}
@@ -0,0 +1,224 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
+)
+
+func TestRuleDescriptorConflictWith(t *testing.T) {
+	t.Parallel()
+
+	newDescriptor := func(t *testing.T, id string, matcher config.Matcher, methods ...string) *ruleDescriptor {
+		t.Helper()
+
+		if len(matcher.Strategy) == 0 {
+			matcher.Strategy = "glob"
+		}
+
+		urlMatcher, err := patternmatcher.NewPatternMatcher(matcher.Strategy, matcher.URL)
+		require.NoError(t, err)
+
+		return &ruleDescriptor{id: id, srcID: "test", matcher: matcher, urlMatcher: urlMatcher, methods: methods}
+	}
+
+	for _, tc := range []struct {
+		uc       string
+		rule     config.Matcher
+		methods  []string
+		other    config.Matcher
+		oMethods []string
+		conflict bool
+		shadowed bool
+	}{
+		{
+			uc:       "same pattern, but disjoint methods",
+			rule:     config.Matcher{URL: "http://foo.bar/items/<**>"},
+			methods:  []string{http.MethodPost},
+			other:    config.Matcher{URL: "http://foo.bar/items/<**>"},
+			oMethods: []string{http.MethodGet},
+		},
+		{
+			uc:       "same pattern and covered methods",
+			rule:     config.Matcher{URL: "http://foo.bar/items/<**>"},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/items/<**>"},
+			oMethods: []string{http.MethodGet, http.MethodPost},
+			conflict: true,
+			shadowed: true,
+		},
+		{
+			uc:       "same pattern and partially covered methods",
+			rule:     config.Matcher{URL: "http://foo.bar/items/<**>"},
+			methods:  []string{http.MethodGet, http.MethodDelete},
+			other:    config.Matcher{URL: "http://foo.bar/items/<**>"},
+			oMethods: []string{http.MethodGet, http.MethodPost},
+			conflict: true,
+		},
+		{
+			uc:       "same pattern, but different strategies",
+			rule:     config.Matcher{URL: "http://foo.bar/items", Strategy: "regex"},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/<**>"},
+			oMethods: []string{http.MethodGet},
+			conflict: true,
+			shadowed: true,
+		},
+		{
+			uc:       "static pattern matched by the other pattern",
+			rule:     config.Matcher{URL: "http://foo.bar/items/1"},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/<**>"},
+			oMethods: []string{http.MethodGet},
+			conflict: true,
+			shadowed: true,
+		},
+		{
+			uc:       "pattern matching the static pattern of the other rule",
+			rule:     config.Matcher{URL: "http://foo.bar/<**>"},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/items/1"},
+			oMethods: []string{http.MethodGet},
+			conflict: true,
+		},
+		{
+			uc:       "patterns with expressions, which cannot be compared",
+			rule:     config.Matcher{URL: "http://foo.bar/items/<**>"},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/<**>"},
+			oMethods: []string{http.MethodGet},
+		},
+		{
+			uc:       "not matching static patterns",
+			rule:     config.Matcher{URL: "http://foo.bar/items/1"},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/items/2"},
+			oMethods: []string{http.MethodGet},
+		},
+		{
+			uc:       "same pattern and same request conditions",
+			rule:     config.Matcher{URL: "http://foo.bar/<**>", Headers: map[string]string{"X-Foo": "bar"}},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/<**>", Headers: map[string]string{"X-Foo": "bar"}},
+			oMethods: []string{http.MethodGet},
+			conflict: true,
+			shadowed: true,
+		},
+		{
+			uc:       "same pattern and request conditions only defined by the rule",
+			rule:     config.Matcher{URL: "http://foo.bar/<**>", Headers: map[string]string{"X-Foo": "bar"}},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/<**>"},
+			oMethods: []string{http.MethodGet},
+			conflict: true,
+			shadowed: true,
+		},
+		{
+			uc:       "same pattern and request conditions only defined by the other rule",
+			rule:     config.Matcher{URL: "http://foo.bar/<**>"},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/<**>", Headers: map[string]string{"X-Foo": "bar"}},
+			oMethods: []string{http.MethodGet},
+			conflict: true,
+		},
+		{
+			uc:       "same pattern, but different request conditions",
+			rule:     config.Matcher{URL: "http://foo.bar/<**>", Headers: map[string]string{"X-Foo": "bar"}},
+			methods:  []string{http.MethodGet},
+			other:    config.Matcher{URL: "http://foo.bar/<**>", Headers: map[string]string{"X-Foo": "baz"}},
+			oMethods: []string{http.MethodGet},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			rul := newDescriptor(t, "rule", tc.rule, tc.methods...)
+			other := newDescriptor(t, "other", tc.other, tc.oMethods...)
+
+			// WHEN
+			conflict, ok := rul.conflictWith(other)
+
+			// THEN
+			assert.Equal(t, tc.conflict, ok)
+			assert.Equal(t, tc.shadowed, conflict.shadowed)
+		})
+	}
+}
+
+func TestRuleConflictDetectorConflicts(t *testing.T) {
+	t.Parallel()
+
+	newDescriptor := func(t *testing.T, id, srcID string, priority, pos int, pattern string) *ruleDescriptor {
+		t.Helper()
+
+		urlMatcher, err := patternmatcher.NewPatternMatcher("glob", pattern)
+		require.NoError(t, err)
+
+		return &ruleDescriptor{
+			id:         id,
+			srcID:      srcID,
+			priority:   priority,
+			pos:        pos,
+			matcher:    config.Matcher{URL: pattern, Strategy: "glob"},
+			urlMatcher: urlMatcher,
+			methods:    []string{http.MethodGet},
+		}
+	}
+
+	// GIVEN
+	detector := newRuleConflictDetector()
+	detector.set("src1", []*ruleDescriptor{newDescriptor(t, "rule1", "src1", 0, 0, "http://foo.bar/<**>")})
+	detector.set("src2", []*ruleDescriptor{newDescriptor(t, "rule1", "src2", 0, 0, "http://foo.bar/items")})
+
+	// WHEN
+	conflicts := detector.conflicts("src2", []*ruleDescriptor{
+		newDescriptor(t, "rule1", "src2", 0, 0, "http://foo.bar/items/1"),
+		newDescriptor(t, "rule2", "src2", 0, 1, "http://foo.bar/items/1"),
+	})
+
+	// THEN
+	require.Len(t, conflicts, 3)
+	assert.Equal(t, "rule ID=rule1 from src2 is shadowed by rule ID=rule1 from src1", conflicts[0].String())
+	assert.Equal(t, "rule ID=rule2 from src2 is shadowed by rule ID=rule1 from src1", conflicts[1].String())
+	assert.Equal(t, "rule ID=rule2 from src2 is shadowed by rule ID=rule1 from src2", conflicts[2].String())
+
+	// WHEN
+	detector.remove("src1")
+	conflicts = detector.conflicts("src2", []*ruleDescriptor{
+		newDescriptor(t, "rule1", "src2", 0, 0, "http://foo.bar/<**>"),
+		newDescriptor(t, "rule2", "src2", 0, 1, "http://foo.bar/items/1"),
+	})
+
+	// THEN
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "rule ID=rule2 from src2 is shadowed by rule ID=rule1 from src2", conflicts[0].String())
+
+	// WHEN
+	conflicts = detector.conflicts("src1", []*ruleDescriptor{
+		newDescriptor(t, "rule1", "src1", 10, 0, "http://foo.bar/<**>"),
+	})
+
+	// THEN
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "rule ID=rule1 from src2 is shadowed by rule ID=rule1 from src1", conflicts[0].String())
+}
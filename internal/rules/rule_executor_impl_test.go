@@ -49,22 +49,21 @@ func TestRuleExecutorExecute(t *testing.T) {
 				t.Helper()
 
 				ctx.EXPECT().AppContext().Return(context.Background())
-				ctx.EXPECT().Request().Return(&heimdall.Request{Method: http.MethodPost, URL: matchingURL})
-				repo.EXPECT().FindRule(matchingURL).Return(nil, heimdall.ErrNoRuleFound)
+				req := &heimdall.Request{Method: http.MethodPost, URL: &heimdall.URL{URL: *matchingURL}}
+				ctx.EXPECT().Request().Return(req)
+				repo.EXPECT().FindRule(req).Return(nil, heimdall.ErrNoRuleFound)
 			},
 		},
 		{
-			uc:     "rule doesn't match method",
+			uc:     "no rule matches method",
 			expErr: heimdall.ErrMethodNotAllowed,
-			configureMocks: func(t *testing.T, ctx *mocks2.ContextMock, repo *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
+			configureMocks: func(t *testing.T, ctx *mocks2.ContextMock, repo *mocks4.RepositoryMock, _ *mocks4.RuleMock) {
 				t.Helper()
 
 				ctx.EXPECT().AppContext().Return(context.Background())
-				ctx.EXPECT().Request().Return(&heimdall.Request{Method: http.MethodPost, URL: matchingURL})
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(false)
-				rule.EXPECT().ID().Return("test_id")
-				rule.EXPECT().SrcID().Return("test_src")
-				repo.EXPECT().FindRule(matchingURL).Return(rule, nil)
+				req := &heimdall.Request{Method: http.MethodPost, URL: &heimdall.URL{URL: *matchingURL}}
+				ctx.EXPECT().Request().Return(req)
+				repo.EXPECT().FindRule(req).Return(nil, heimdall.ErrMethodNotAllowed)
 			},
 		},
 		{
@@ -74,10 +73,10 @@ func TestRuleExecutorExecute(t *testing.T) {
 				t.Helper()
 
 				ctx.EXPECT().AppContext().Return(context.Background())
-				ctx.EXPECT().Request().Return(&heimdall.Request{Method: http.MethodGet, URL: matchingURL})
-				rule.EXPECT().MatchesMethod(http.MethodGet).Return(true)
+				req := &heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: *matchingURL}}
+				ctx.EXPECT().Request().Return(req)
 				rule.EXPECT().Execute(ctx).Return(nil, heimdall.ErrAuthentication)
-				repo.EXPECT().FindRule(matchingURL).Return(rule, nil)
+				repo.EXPECT().FindRule(req).Return(rule, nil)
 			},
 		},
 		{
@@ -88,10 +87,10 @@ func TestRuleExecutorExecute(t *testing.T) {
 				upstream := mocks4.NewBackendMock(t)
 
 				ctx.EXPECT().AppContext().Return(context.Background())
-				ctx.EXPECT().Request().Return(&heimdall.Request{Method: http.MethodGet, URL: matchingURL})
-				rule.EXPECT().MatchesMethod(http.MethodGet).Return(true)
+				req := &heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: *matchingURL}}
+				ctx.EXPECT().Request().Return(req)
 				rule.EXPECT().Execute(ctx).Return(upstream, nil)
-				repo.EXPECT().FindRule(matchingURL).Return(rule, nil)
+				repo.EXPECT().FindRule(req).Return(rule, nil)
 			},
 		},
 	} {
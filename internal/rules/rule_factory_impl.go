@@ -46,7 +46,13 @@ func NewRuleFactory(
 ) (rule.Factory, error) {
 	logger.Debug().Msg("Creating rule factory")
 
-	rf := &ruleFactory{hf: hf, hasDefaultRule: false, logger: logger, mode: mode}
+	rf := &ruleFactory{
+		hf:              hf,
+		hasDefaultRule:  false,
+		logger:          logger,
+		mode:            mode,
+		checkRevocation: conf.Revocation.Enabled,
+	}
 
 	if err := rf.initWithDefaultRule(conf.Default, logger); err != nil {
 		logger.Error().Err(err).Msg("Loading default rule failed")
@@ -58,11 +64,12 @@ func NewRuleFactory(
 }
 
 type ruleFactory struct {
-	hf             mechanisms.Factory
-	logger         zerolog.Logger
-	defaultRule    *ruleImpl
-	hasDefaultRule bool
-	mode           config.OperationMode
+	hf              mechanisms.Factory
+	logger          zerolog.Logger
+	defaultRule     *ruleImpl
+	hasDefaultRule  bool
+	mode            config.OperationMode
+	checkRevocation bool
 }
 
 //nolint:funlen,gocognit,cyclop
@@ -104,12 +111,19 @@ func (f *ruleFactory) createExecutePipeline(
 					"an authenticator is defined after some other non authenticator type")
 			}
 
+			if _, found = pipelineStep["mode"]; found {
+				return nil, nil, nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+					"mode is not supported for authenticators")
+			}
+
 			authenticator, err := f.hf.CreateAuthenticator(version, id.(string), getConfig(pipelineStep["config"]))
 			if err != nil {
 				return nil, nil, nil, err
 			}
 
-			authenticators = append(authenticators, authenticator)
+			authenticators = append(authenticators, x.IfThenElseExec(f.checkRevocation,
+				func() subjectCreator { return &revocationAwareSubjectCreator{sc: authenticator} },
+				func() subjectCreator { return authenticator }))
 
 			continue
 		}
@@ -177,6 +191,16 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 			ruleConfig.RuleMatcher.Strategy, ruleConfig.ID, srcID).CausedBy(err)
 	}
 
+	if err = checkVersionApplicability(version, srcID, ruleConfig); err != nil {
+		return nil, err
+	}
+
+	reqMatcher, err := newRequestMatcher(ruleConfig.RuleMatcher)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"bad request conditions defined for rule ID=%s from %s", ruleConfig.ID, srcID).CausedBy(err)
+	}
+
 	authenticators, subHandlers, finalizers, err := f.createExecutePipeline(version, ruleConfig.Execute)
 	if err != nil {
 		return nil, err
@@ -211,29 +235,90 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 			"no methods defined for rule ID=%s from %s", ruleConfig.ID, srcID)
 	}
 
+	canary, err := f.createCanaryVariant(version, srcID, ruleConfig, ruleVariant{
+		sc: authenticators, sh: subHandlers, fi: finalizers, eh: errorHandlers, backend: ruleConfig.Backend,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	hash, err := f.createHash(ruleConfig)
 	if err != nil {
 		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
 			"failed to create hash for rule ID=%s from %s", ruleConfig.ID, srcID)
 	}
 
+	var priority int
+	if ruleConfig.Priority != nil {
+		priority = *ruleConfig.Priority
+	}
+
 	return &ruleImpl{
-		id: ruleConfig.ID,
+		id:       ruleConfig.ID,
+		priority: priority,
 		encodedSlashesHandling: x.IfThenElse(
 			len(ruleConfig.EncodedSlashesHandling) != 0,
 			ruleConfig.EncodedSlashesHandling,
 			config2.EncodedSlashesOff,
 		),
-		urlMatcher: matcher,
-		backend:    ruleConfig.Backend,
-		methods:    methods,
-		srcID:      srcID,
-		isDefault:  false,
-		hash:       hash,
-		sc:         authenticators,
-		sh:         subHandlers,
-		fi:         finalizers,
-		eh:         errorHandlers,
+		shadow:         ruleConfig.Mode == config2.ExecutionModeShadow,
+		urlMatcher:     matcher,
+		requestMatcher: reqMatcher,
+		backend:        ruleConfig.Backend,
+		methods:        methods,
+		srcID:          srcID,
+		isDefault:      false,
+		hash:           hash,
+		sc:             authenticators,
+		sh:             subHandlers,
+		fi:             finalizers,
+		eh:             errorHandlers,
+		canary:         canary,
+	}, nil
+}
+
+func (f *ruleFactory) createCanaryVariant(
+	version, srcID string, ruleConfig config2.Rule, stable ruleVariant,
+) (*canaryVariant, error) {
+	conf := ruleConfig.Canary
+	if conf == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	if version == config2.RuleSetVersion1Alpha3 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"canary in rule ID=%s from %s requires at least rule set version %s",
+			ruleConfig.ID, srcID, config2.RuleSetVersion1Alpha4)
+	}
+
+	authenticators, subHandlers, finalizers, err := f.createExecutePipeline(version, conf.Execute)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.StickyBy == config2.CanaryStickyBySubject && len(authenticators) != 0 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"canary in rule ID=%s from %s is sticky by subject and must not define authenticators",
+			ruleConfig.ID, srcID)
+	}
+
+	errorHandlers, err := f.createOnErrorPipeline(version, conf.ErrorHandler)
+	if err != nil {
+		return nil, err
+	}
+
+	return &canaryVariant{
+		ruleVariant: ruleVariant{
+			name:    ruleVariantCanary,
+			sc:      x.IfThenElse(len(authenticators) != 0, authenticators, stable.sc),
+			sh:      x.IfThenElse(len(subHandlers) != 0, subHandlers, stable.sh),
+			fi:      x.IfThenElse(len(finalizers) != 0, finalizers, stable.fi),
+			eh:      x.IfThenElse(len(errorHandlers) != 0, errorHandlers, stable.eh),
+			backend: x.IfThenElse(conf.Backend != nil, conf.Backend, stable.backend),
+		},
+		percentage: conf.Percentage,
+		stickyBy:   conf.StickyBy,
+		header:     conf.Header,
 	}, nil
 }
 
@@ -250,6 +335,12 @@ func checkProxyModeApplicability(srcID string, ruleConfig config2.Rule) error {
 			ruleConfig.ID, srcID)
 	}
 
+	if ruleConfig.Canary != nil && ruleConfig.Canary.Backend != nil && len(ruleConfig.Canary.Backend.Host) == 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"missing host definition in forward_to of the canary in rule ID=%s from %s",
+			ruleConfig.ID, srcID)
+	}
+
 	urlRewriter := ruleConfig.Backend.URLRewriter
 	if urlRewriter == nil {
 		return nil
@@ -416,14 +507,69 @@ func createHandler[T subjectHandler](
 		return nil, err
 	}
 
+	if _, found := configMap["mode"]; found && version == config2.RuleSetVersion1Alpha3 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"mode of the %s step requires at least rule set version %s", handlerType, config2.RuleSetVersion1Alpha4)
+	}
+
+	mode, err := getExecutionMode(configMap["mode"])
+	if err != nil {
+		return nil, err
+	}
+
 	handler, err := creteHandler(version, id.(string), getConfig(configMap["config"]))
 	if err != nil {
 		return nil, err
 	}
 
+	if mode == config2.ExecutionModeShadow {
+		return &shadowSubjectHandler{h: &conditionalSubjectHandler{h: handler, c: condition}}, nil
+	}
+
 	return &conditionalSubjectHandler{h: handler, c: condition}, nil
 }
 
+// checkVersionApplicability verifies that the given rule does not make use of features
+// not available in the given rule set version.
+func checkVersionApplicability(version, srcID string, ruleConfig config2.Rule) error {
+	if version != config2.RuleSetVersion1Alpha3 {
+		return nil
+	}
+
+	var feature string
+
+	switch {
+	case ruleConfig.RuleMatcher.HasRequestConditions():
+		feature = "request conditions"
+	case ruleConfig.Priority != nil:
+		feature = "priority"
+	case len(ruleConfig.Mode) != 0:
+		feature = "mode"
+	default:
+		return nil
+	}
+
+	return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+		"rule ID=%s from %s makes use of %s, which requires at least rule set version %s",
+		ruleConfig.ID, srcID, feature, config2.RuleSetVersion1Alpha4)
+}
+
+func getExecutionMode(conf any) (config2.ExecutionMode, error) {
+	if conf == nil {
+		return config2.ExecutionModeEnforce, nil
+	}
+
+	if mode, ok := conf.(string); ok {
+		switch config2.ExecutionMode(mode) {
+		case config2.ExecutionModeEnforce, config2.ExecutionModeShadow:
+			return config2.ExecutionMode(mode), nil
+		}
+	}
+
+	return "", errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+		"unsupported execution mode '%v'", conf)
+}
+
 func getConfig(conf any) config.MechanismConfig {
 	if conf == nil {
 		return nil
@@ -536,12 +536,14 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 	t.Parallel()
 
 	for _, tc := range []struct {
-		uc             string
-		opMode         config.OperationMode
-		config         config2.Rule
-		defaultRule    *ruleImpl
-		configureMocks func(t *testing.T, mhf *mocks3.FactoryMock)
-		assert         func(t *testing.T, err error, rul *ruleImpl)
+		uc              string
+		opMode          config.OperationMode
+		version         string
+		checkRevocation bool
+		config          config2.Rule
+		defaultRule     *ruleImpl
+		configureMocks  func(t *testing.T, mhf *mocks3.FactoryMock)
+		assert          func(t *testing.T, err error, rul *ruleImpl)
 	}{
 		{
 			uc:     "without default rule and with missing id",
@@ -607,6 +609,39 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				assert.Contains(t, err.Error(), "bad URL pattern")
 			},
 		},
+		{
+			uc:      "with request conditions in a rule set of version 1alpha3",
+			version: config2.RuleSetVersion1Alpha3,
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob", Hosts: []string{"foo.bar"}},
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "makes use of request conditions, which requires at least rule set version")
+			},
+		},
+		{
+			uc: "with bad header pattern",
+			config: config2.Rule{
+				ID: "foobar",
+				RuleMatcher: config2.Matcher{
+					URL:      "http://foo.bar",
+					Strategy: "regex",
+					Headers:  map[string]string{"X-Foo": "<(bar>"},
+				},
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "bad request conditions")
+			},
+		},
 		{
 			uc:     "without default rule, with id, but bad url pattern",
 			config: config2.Rule{ID: "foobar", RuleMatcher: config2.Matcher{URL: "?>?<*??"}},
@@ -817,11 +852,40 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				assert.NotNil(t, rul.urlMatcher)
 				assert.ElementsMatch(t, rul.methods, []string{"FOO", "BAR"})
 				assert.Len(t, rul.sc, 1)
+				assert.IsType(t, &mocks2.AuthenticatorMock{}, rul.sc[0])
 				assert.Empty(t, rul.sh)
 				assert.Empty(t, rul.fi)
 				assert.Empty(t, rul.eh)
 			},
 		},
+		{
+			uc:              "with revocation check enabled",
+			checkRevocation: true,
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+				},
+				Methods: []string{"FOO"},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, rul)
+				require.Len(t, rul.sc, 1)
+
+				creator, ok := rul.sc[0].(*revocationAwareSubjectCreator)
+				require.True(t, ok)
+				assert.IsType(t, &mocks2.AuthenticatorMock{}, creator.sc)
+			},
+		},
 		{
 			uc:     "without default rule but with minimum required configuration in proxy mode",
 			opMode: config.ProxyMode,
@@ -1209,6 +1273,245 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				require.Len(t, rul.eh, 2)
 			},
 		},
+		{
+			uc: "with mode configured for an authenticator",
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo", "mode": "shadow"},
+				},
+				Methods: []string{"FOO"},
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "mode is not supported for authenticators")
+			},
+		},
+		{
+			uc: "with unsupported execution mode for a pipeline step",
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+					{"authorizer": "bar", "mode": "foo"},
+				},
+				Methods: []string{"FOO"},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "unsupported execution mode 'foo'")
+			},
+		},
+		{
+			uc: "with shadow mode for the rule and some pipeline steps",
+			config: config2.Rule{
+				ID:          "foobar",
+				Mode:        config2.ExecutionModeShadow,
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+					{"authorizer": "bar", "mode": "shadow"},
+					{"finalizer": "baz", "mode": "enforce"},
+				},
+				Methods: []string{"FOO"},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+				mhf.EXPECT().CreateAuthorizer("test", "bar", mock.Anything).
+					Return(&mocks4.AuthorizerMock{}, nil)
+				mhf.EXPECT().CreateFinalizer("test", "baz", mock.Anything).
+					Return(&mocks7.FinalizerMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, rul)
+
+				assert.True(t, rul.shadow)
+
+				require.Len(t, rul.sh, 1)
+				sh, ok := rul.sh[0].(*shadowSubjectHandler)
+				require.True(t, ok)
+				assert.IsType(t, &conditionalSubjectHandler{}, sh.h)
+
+				require.Len(t, rul.fi, 1)
+				assert.IsType(t, &conditionalSubjectHandler{}, rul.fi[0])
+			},
+		},
+		{
+			uc:      "with priority in a rule set of version 1alpha3",
+			version: config2.RuleSetVersion1Alpha3,
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Priority:    new(int),
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "makes use of priority, which requires at least rule set version")
+			},
+		},
+		{
+			uc:      "with mode in a rule set of version 1alpha3",
+			version: config2.RuleSetVersion1Alpha3,
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Mode:        config2.ExecutionModeEnforce,
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "makes use of mode, which requires at least rule set version")
+			},
+		},
+		{
+			uc:      "with pipeline step mode in a rule set of version 1alpha3",
+			version: config2.RuleSetVersion1Alpha3,
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+					{"authorizer": "bar", "mode": "shadow"},
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator(config2.RuleSetVersion1Alpha3, "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "mode of the authorizer step requires at least rule set version")
+			},
+		},
+		{
+			uc:      "with canary in a rule set of version 1alpha3",
+			version: config2.RuleSetVersion1Alpha3,
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute:     []config.MechanismConfig{{"authenticator": "foo"}},
+				Methods:     []string{"FOO"},
+				Canary:      &config2.Canary{Percentage: 10},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator(config2.RuleSetVersion1Alpha3, "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "requires at least rule set version")
+			},
+		},
+		{
+			uc: "with canary sticky by subject defining authenticators",
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute:     []config.MechanismConfig{{"authenticator": "foo"}},
+				Methods:     []string{"FOO"},
+				Canary: &config2.Canary{
+					Percentage: 10,
+					StickyBy:   config2.CanaryStickyBySubject,
+					Execute:    []config.MechanismConfig{{"authenticator": "bar"}},
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", mock.Anything, mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil).Times(2)
+			},
+			assert: func(t *testing.T, err error, _ *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "must not define authenticators")
+			},
+		},
+		{
+			uc: "with canary taking undefined settings from the rule",
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Backend:     &config2.Backend{Host: "foo.bar"},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+					{"authorizer": "bar"},
+					{"finalizer": "baz"},
+				},
+				Methods: []string{"FOO"},
+				Canary: &config2.Canary{
+					Percentage: 10,
+					StickyBy:   config2.CanaryStickyByHeader,
+					Header:     "X-Session",
+					Execute:    []config.MechanismConfig{{"authorizer": "baz"}},
+				},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+				mhf.EXPECT().CreateAuthorizer("test", mock.Anything, mock.Anything).
+					Return(&mocks4.AuthorizerMock{}, nil).Times(2)
+				mhf.EXPECT().CreateFinalizer("test", "baz", mock.Anything).
+					Return(&mocks7.FinalizerMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, rul)
+				require.NotNil(t, rul.canary)
+
+				canary := rul.canary
+				assert.Equal(t, ruleVariantCanary, canary.name)
+				assert.Equal(t, 10, canary.percentage)
+				assert.Equal(t, config2.CanaryStickyByHeader, canary.stickyBy)
+				assert.Equal(t, "X-Session", canary.header)
+				assert.Equal(t, rul.sc, canary.sc)
+				require.Len(t, canary.sh, 1)
+				assert.NotSame(t, rul.sh[0], canary.sh[0])
+				assert.Equal(t, rul.fi, canary.fi)
+				assert.Equal(t, rul.eh, canary.eh)
+				assert.Same(t, rul.backend, canary.backend)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -1220,15 +1523,16 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 			configureMocks(t, handlerFactory)
 
 			factory := &ruleFactory{
-				hf:             handlerFactory,
-				defaultRule:    tc.defaultRule,
-				mode:           tc.opMode,
-				logger:         log.Logger,
-				hasDefaultRule: x.IfThenElse(tc.defaultRule != nil, true, false),
+				hf:              handlerFactory,
+				defaultRule:     tc.defaultRule,
+				mode:            tc.opMode,
+				logger:          log.Logger,
+				hasDefaultRule:  x.IfThenElse(tc.defaultRule != nil, true, false),
+				checkRevocation: tc.checkRevocation,
 			}
 
 			// WHEN
-			rul, err := factory.CreateRule("test", "test", tc.config)
+			rul, err := factory.CreateRule(x.IfThenElse(len(tc.version) != 0, tc.version, "test"), "test", tc.config)
 
 			// THEN
 			var (
@@ -1363,6 +1667,21 @@ func TestRuleFactoryProxyModeApplicability(t *testing.T) {
 				},
 			},
 		},
+		{
+			uc: "with host and canary without host",
+			ruleConfig: config2.Rule{
+				Backend: &config2.Backend{Host: "foo.bar"},
+				Canary:  &config2.Canary{Backend: &config2.Backend{}},
+			},
+			shouldError: true,
+		},
+		{
+			uc: "with host and canary with host",
+			ruleConfig: config2.Rule{
+				Backend: &config2.Backend{Host: "foo.bar"},
+				Canary:  &config2.Canary{Backend: &config2.Backend{Host: "bar.foo"}},
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// WHEN
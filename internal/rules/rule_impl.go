@@ -32,8 +32,11 @@ import (
 
 type ruleImpl struct {
 	id                     string
+	priority               int
+	shadow                 bool
 	encodedSlashesHandling config.EncodedSlashesHandling
 	urlMatcher             patternmatcher.PatternMatcher
+	requestMatcher         requestMatcher
 	backend                *config.Backend
 	methods                []string
 	srcID                  string
@@ -43,6 +46,7 @@ type ruleImpl struct {
 	sh                     compositeSubjectHandler
 	fi                     compositeSubjectHandler
 	eh                     compositeErrorHandler
+	canary                 *canaryVariant
 }
 
 func (r *ruleImpl) Execute(ctx heimdall.Context) (rule.Backend, error) {
@@ -54,45 +58,94 @@ func (r *ruleImpl) Execute(ctx heimdall.Context) (rule.Backend, error) {
 		logger.Info().Str("_src", r.srcID).Str("_id", r.id).Msg("Executing rule")
 	}
 
-	// authenticators
-	sub, err := r.sc.Execute(ctx)
-	if err != nil {
-		return nil, r.eh.Execute(ctx, err)
+	if r.urlMatcher != nil {
+		request := ctx.Request()
+		if value, ok := urlToMatch(&request.URL.URL, r.encodedSlashesHandling); ok {
+			request.URL.Captures = r.urlMatcher.Captures(value)
+		}
 	}
 
-	// authorizers & contextualizer
-	if err = r.sh.Execute(ctx, sub); err != nil {
-		return nil, r.eh.Execute(ctx, err)
+	// in shadow mode the outcome of the pipeline is just recorded. The request
+	// is never denied, and the error handlers are not executed
+	variant, err := r.executePipeline(ctx)
+
+	if r.canary != nil {
+		recordRuleVariant(ctx, r.id, variant.name, err)
 	}
 
-	// finalizers
-	if err = r.fi.Execute(ctx, sub); err != nil {
-		return nil, r.eh.Execute(ctx, err)
+	switch {
+	case r.shadow:
+		recordShadowOutcome(ctx, shadowScopeRule, r.id, err)
+	case err != nil:
+		return nil, variant.eh.Execute(ctx, err)
 	}
 
 	var upstream rule.Backend
 
-	if r.backend != nil {
-		targetURL := *ctx.Request().URL
+	if variant.backend != nil {
+		targetURL := ctx.Request().URL.URL
 		if r.encodedSlashesHandling == config.EncodedSlashesOn && len(targetURL.RawPath) != 0 {
 			targetURL.RawPath = ""
 		}
 
 		upstream = &backend{
-			targetURL: r.backend.CreateURL(&targetURL),
+			targetURL: variant.backend.CreateURL(&targetURL),
 		}
 	}
 
 	return upstream, nil
 }
 
+func (r *ruleImpl) executePipeline(ctx heimdall.Context) (ruleVariant, error) {
+	variant := ruleVariant{name: ruleVariantStable, sc: r.sc, sh: r.sh, fi: r.fi, eh: r.eh, backend: r.backend}
+
+	// if the canary is sticky by subject, the variant can only be selected after
+	// authentication. Both variants share the authenticators in that case.
+	stickyBySubject := r.canary != nil && r.canary.stickyBy == config.CanaryStickyBySubject
+
+	if r.canary != nil && !stickyBySubject && r.canary.selected(ctx, r.id, nil) {
+		variant = r.canary.ruleVariant
+	}
+
+	// authenticators
+	sub, err := variant.sc.Execute(ctx)
+	if err != nil {
+		return variant, err
+	}
+
+	if stickyBySubject && r.canary.selected(ctx, r.id, sub) {
+		variant = r.canary.ruleVariant
+	}
+
+	// authorizers & contextualizer
+	if err = variant.sh.Execute(ctx, sub); err != nil {
+		return variant, err
+	}
+
+	// finalizers
+	return variant, variant.fi.Execute(ctx, sub)
+}
+
+func (r *ruleImpl) Matches(req *heimdall.Request) bool {
+	return r.MatchesURL(&req.URL.URL) && r.requestMatcher.Matches(req)
+}
+
 func (r *ruleImpl) MatchesURL(requestURL *url.URL) bool {
+	value, ok := urlToMatch(requestURL, r.encodedSlashesHandling)
+
+	return ok && r.urlMatcher.Match(value)
+}
+
+// urlToMatch returns the representation of the given url, the url patterns are matched
+// against. The second return value is false if the url must not be matched at all given
+// the configured handling of url-encoded slashes.
+func urlToMatch(requestURL *url.URL, slashesHandling config.EncodedSlashesHandling) (string, bool) {
 	var path string
 
-	switch r.encodedSlashesHandling {
+	switch slashesHandling {
 	case config.EncodedSlashesOff:
 		if strings.Contains(requestURL.RawPath, "%2F") {
-			return false
+			return "", false
 		}
 
 		path = requestURL.Path
@@ -110,7 +163,7 @@ func (r *ruleImpl) MatchesURL(requestURL *url.URL) bool {
 		path = requestURL.Path
 	}
 
-	return r.urlMatcher.Match(fmt.Sprintf("%s://%s%s", requestURL.Scheme, requestURL.Host, path))
+	return fmt.Sprintf("%s://%s%s", requestURL.Scheme, requestURL.Host, path), true
 }
 
 func (r *ruleImpl) MatchesMethod(method string) bool { return slices.Contains(r.methods, method) }
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/dadrus/heimdall/internal/accesscontext"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
 	"github.com/dadrus/heimdall/internal/rules/config"
@@ -229,6 +230,182 @@ func TestRuleMatchURL(t *testing.T) {
 	}
 }
 
+func TestRuleExecuteSetsURLCaptures(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	matcher, err := patternmatcher.NewPatternMatcher("glob", "http://foo.bar/tenants/<:tenant>/<**>")
+	require.NoError(t, err)
+
+	requestURL, err := url.Parse("http://foo.bar/tenants/acme/users")
+	require.NoError(t, err)
+
+	req := &heimdall.Request{URL: &heimdall.URL{URL: *requestURL}}
+	sub := &subject.Subject{ID: "foo"}
+
+	ctx := heimdallmocks.NewContextMock(t)
+	ctx.EXPECT().AppContext().Return(context.Background())
+	ctx.EXPECT().Request().Return(req)
+
+	authenticator := mocks.NewSubjectCreatorMock(t)
+	authenticator.EXPECT().Execute(ctx).Return(sub, nil)
+
+	rul := &ruleImpl{
+		urlMatcher:             matcher,
+		encodedSlashesHandling: config.EncodedSlashesOff,
+		sc:                     compositeSubjectCreator{authenticator},
+	}
+
+	// WHEN
+	_, err = rul.Execute(ctx)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"tenant": "acme"}, req.URL.Captures)
+}
+
+func TestRuleExecuteInShadowMode(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	appCtx := accesscontext.New(context.Background())
+	sub := &subject.Subject{ID: "foo"}
+
+	targetURL, err := url.Parse("http://foo.local/api/v1/foo")
+	require.NoError(t, err)
+
+	ctx := heimdallmocks.NewContextMock(t)
+	ctx.EXPECT().AppContext().Return(appCtx)
+	ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL}})
+
+	authenticator := mocks.NewSubjectCreatorMock(t)
+	authenticator.EXPECT().Execute(ctx).Return(sub, nil)
+
+	authorizer := mocks.NewSubjectHandlerMock(t)
+	authorizer.EXPECT().Execute(ctx, sub).Return(testsupport.ErrTestPurpose)
+	authorizer.EXPECT().ContinueOnError().Return(false)
+
+	rul := &ruleImpl{
+		id:                     "test",
+		shadow:                 true,
+		backend:                &config.Backend{Host: "foo.bar"},
+		encodedSlashesHandling: config.EncodedSlashesOff,
+		sc:                     compositeSubjectCreator{authenticator},
+		sh:                     compositeSubjectHandler{authorizer},
+		eh:                     compositeErrorHandler{mocks.NewErrorHandlerMock(t)},
+	}
+
+	// WHEN
+	backend, err := rul.Execute(ctx)
+
+	// THEN
+	require.NoError(t, err)
+	require.NotNil(t, backend)
+	assert.Equal(t, "http://foo.bar/api/v1/foo", backend.URL().String())
+
+	evaluated, deniedBy := accesscontext.ShadowOutcome(appCtx)
+	assert.True(t, evaluated)
+	assert.Equal(t, []string{"test"}, deniedBy)
+}
+
+func TestRuleExecuteWithCanaryVariant(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc         string
+		percentage int
+		stickyBy   config.CanaryStickiness
+		err        error
+		variant    string
+	}{
+		{uc: "canary never selected", variant: ruleVariantStable},
+		{uc: "canary always selected", percentage: 100, variant: ruleVariantCanary},
+		{
+			uc:         "canary sticky by subject selected",
+			percentage: 100,
+			stickyBy:   config.CanaryStickyBySubject,
+			variant:    ruleVariantCanary,
+		},
+		{
+			uc:         "selected canary fails",
+			percentage: 100,
+			err:        testsupport.ErrTestPurpose,
+			variant:    ruleVariantCanary,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			appCtx := accesscontext.New(context.Background())
+			sub := &subject.Subject{ID: "foo"}
+
+			targetURL, err := url.Parse("http://foo.local/api/v1/foo")
+			require.NoError(t, err)
+
+			ctx := heimdallmocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(appCtx)
+
+			authenticator := mocks.NewSubjectCreatorMock(t)
+			authenticator.EXPECT().Execute(ctx).Return(sub, nil)
+
+			stableAuthorizer := mocks.NewSubjectHandlerMock(t)
+			canaryAuthorizer := mocks.NewSubjectHandlerMock(t)
+			canaryErrHandler := mocks.NewErrorHandlerMock(t)
+
+			if tc.variant == ruleVariantStable {
+				stableAuthorizer.EXPECT().Execute(ctx, sub).Return(nil)
+			} else {
+				canaryAuthorizer.EXPECT().Execute(ctx, sub).Return(tc.err)
+			}
+
+			if tc.err != nil {
+				canaryAuthorizer.EXPECT().ContinueOnError().Return(false)
+				canaryErrHandler.EXPECT().CanExecute(ctx, tc.err).Return(true)
+				canaryErrHandler.EXPECT().Execute(ctx, tc.err).Return(tc.err)
+			} else {
+				ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL}})
+			}
+
+			rul := &ruleImpl{
+				id:                     "test",
+				backend:                &config.Backend{Host: "foo.bar"},
+				encodedSlashesHandling: config.EncodedSlashesOff,
+				sc:                     compositeSubjectCreator{authenticator},
+				sh:                     compositeSubjectHandler{stableAuthorizer},
+				eh:                     compositeErrorHandler{mocks.NewErrorHandlerMock(t)},
+				canary: &canaryVariant{
+					ruleVariant: ruleVariant{
+						name:    ruleVariantCanary,
+						sc:      compositeSubjectCreator{authenticator},
+						sh:      compositeSubjectHandler{canaryAuthorizer},
+						eh:      compositeErrorHandler{canaryErrHandler},
+						backend: &config.Backend{Host: "bar.foo"},
+					},
+					percentage: tc.percentage,
+					stickyBy:   tc.stickyBy,
+				},
+			}
+
+			// WHEN
+			backend, err := rul.Execute(ctx)
+
+			// THEN
+			assert.Equal(t, tc.variant, accesscontext.RuleVariant(appCtx))
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, backend)
+			assert.Equal(t,
+				x.IfThenElse(tc.variant == ruleVariantStable, "http://foo.bar/api/v1/foo", "http://bar.foo/api/v1/foo"),
+				backend.URL().String())
+		})
+	}
+}
+
 func TestRuleExecute(t *testing.T) {
 	t.Parallel()
 
@@ -401,7 +578,7 @@ func TestRuleExecute(t *testing.T) {
 				finalizer.EXPECT().Execute(ctx, sub).Return(nil)
 
 				targetURL, _ := url.Parse("http://foo.local/api/v1/foo%5Bid%5D")
-				ctx.EXPECT().Request().Return(&heimdall.Request{URL: targetURL})
+				ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL}})
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend) {
 				t.Helper()
@@ -431,7 +608,7 @@ func TestRuleExecute(t *testing.T) {
 				finalizer.EXPECT().Execute(ctx, sub).Return(nil)
 
 				targetURL, _ := url.Parse("http://foo.local/api/v1/foo%5Bid%5D")
-				ctx.EXPECT().Request().Return(&heimdall.Request{URL: targetURL})
+				ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL}})
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend) {
 				t.Helper()
@@ -461,7 +638,7 @@ func TestRuleExecute(t *testing.T) {
 				finalizer.EXPECT().Execute(ctx, sub).Return(nil)
 
 				targetURL, _ := url.Parse("http://foo.local/api%2Fv1/foo%5Bid%5D")
-				ctx.EXPECT().Request().Return(&heimdall.Request{URL: targetURL})
+				ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL}})
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend) {
 				t.Helper()
@@ -491,7 +668,7 @@ func TestRuleExecute(t *testing.T) {
 				finalizer.EXPECT().Execute(ctx, sub).Return(nil)
 
 				targetURL, _ := url.Parse("http://foo.local/api%2Fv1/foo%5Bid%5D")
-				ctx.EXPECT().Request().Return(&heimdall.Request{URL: targetURL})
+				ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL}})
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend) {
 				t.Helper()
@@ -521,7 +698,7 @@ func TestRuleExecute(t *testing.T) {
 				finalizer.EXPECT().Execute(ctx, sub).Return(nil)
 
 				targetURL, _ := url.Parse("http://foo.local/api/v1/foo")
-				ctx.EXPECT().Request().Return(&heimdall.Request{URL: targetURL})
+				ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *targetURL}})
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend) {
 				t.Helper()
@@ -0,0 +1,132 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dadrus/heimdall/internal/accesscontext"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+)
+
+const (
+	ruleVariantStable = "stable"
+	ruleVariantCanary = "canary"
+
+	ruleIDAttrKey            = attribute.Key("heimdall.rule.id")
+	ruleVariantAttrKey       = attribute.Key("heimdall.rule.variant")
+	ruleAccessGrantedAttrKey = attribute.Key("heimdall.rule.access_granted")
+)
+
+var ruleVariantExecutions = sync.OnceValue(func() metric.Int64Counter { //nolint:gochecknoglobals
+	counter, err := otel.Meter("github.com/dadrus/heimdall/internal/rules").Int64Counter(
+		"heimdall.rule.variant.executions",
+		metric.WithDescription("Number of executions of rules defining a canary variant, by variant."),
+		metric.WithUnit("{execution}"),
+	)
+	if err != nil {
+		return noop.Int64Counter{}
+	}
+
+	return counter
+})
+
+// ruleVariant holds the pipelines and the backend used to handle a request.
+type ruleVariant struct {
+	name    string
+	sc      compositeSubjectCreator
+	sh      compositeSubjectHandler
+	fi      compositeSubjectHandler
+	eh      compositeErrorHandler
+	backend *config.Backend
+}
+
+// canaryVariant is a rule variant, which is used for the configured percentage of the
+// requests only.
+type canaryVariant struct {
+	ruleVariant
+
+	percentage int
+	stickyBy   config.CanaryStickiness
+	header     string
+}
+
+// selected decides whether the canary variant is used for the given request. If the selection
+// is sticky and the request provides a value for the configured key, the decision is taken based
+// on the hash of that value, so that the same value always results in the same variant. Otherwise,
+// the decision is random.
+func (c *canaryVariant) selected(ctx heimdall.Context, ruleID string, sub *subject.Subject) bool {
+	var key string
+
+	switch c.stickyBy {
+	case config.CanaryStickyBySubject:
+		if sub != nil {
+			key = sub.ID
+		}
+	case config.CanaryStickyByHeader:
+		key = ctx.Request().Header(c.header)
+	}
+
+	if len(key) == 0 {
+		return rand.IntN(100) < c.percentage //nolint:gosec,gomnd
+	}
+
+	// the rule id is part of the hash to not let all canaries select the same keys
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(ruleID))
+	_, _ = hash.Write([]byte(key))
+
+	return int(hash.Sum32()%100) < c.percentage //nolint:gomnd
+}
+
+// recordRuleVariant makes the variant of a rule used to handle the request visible in the
+// logs, the access logs, the current span and the metrics. A non nil error means, the
+// request has been denied by that variant.
+func recordRuleVariant(ctx heimdall.Context, ruleID, variant string, err error) {
+	appCtx := ctx.AppContext()
+	granted := err == nil
+
+	zerolog.Ctx(appCtx).Info().
+		Str("_id", ruleID).
+		Str("_variant", variant).
+		Bool("_access_granted", granted).
+		Msg("Rule variant executed")
+
+	accesscontext.SetRuleVariant(appCtx, variant)
+
+	ruleVariantExecutions().Add(appCtx, 1, metric.WithAttributes(
+		ruleIDAttrKey.String(ruleID),
+		ruleVariantAttrKey.String(variant),
+		ruleAccessGrantedAttrKey.Bool(granted),
+	))
+
+	trace.SpanFromContext(appCtx).SetAttributes(
+		ruleIDAttrKey.String(ruleID),
+		ruleVariantAttrKey.String(variant),
+	)
+}
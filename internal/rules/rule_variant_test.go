@@ -0,0 +1,125 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+)
+
+func TestCanaryVariantSelected(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		canary *canaryVariant
+		header string
+		sub    *subject.Subject
+		assert func(t *testing.T, selected func() bool)
+	}{
+		{
+			uc:     "never selected with 0 percent",
+			canary: &canaryVariant{percentage: 0},
+			assert: func(t *testing.T, selected func() bool) {
+				t.Helper()
+
+				for range 100 {
+					assert.False(t, selected())
+				}
+			},
+		},
+		{
+			uc:     "always selected with 100 percent",
+			canary: &canaryVariant{percentage: 100},
+			assert: func(t *testing.T, selected func() bool) {
+				t.Helper()
+
+				for range 100 {
+					assert.True(t, selected())
+				}
+			},
+		},
+		{
+			uc:     "sticky by header",
+			canary: &canaryVariant{percentage: 50, stickyBy: config.CanaryStickyByHeader, header: "X-Session"},
+			header: "foo",
+			assert: func(t *testing.T, selected func() bool) {
+				t.Helper()
+
+				expected := selected()
+				for range 100 {
+					assert.Equal(t, expected, selected())
+				}
+			},
+		},
+		{
+			uc:     "sticky by subject",
+			canary: &canaryVariant{percentage: 50, stickyBy: config.CanaryStickyBySubject},
+			sub:    &subject.Subject{ID: "foo"},
+			assert: func(t *testing.T, selected func() bool) {
+				t.Helper()
+
+				expected := selected()
+				for range 100 {
+					assert.Equal(t, expected, selected())
+				}
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			ctx := heimdallmocks.NewContextMock(t)
+
+			if tc.canary.stickyBy == config.CanaryStickyByHeader {
+				reqf := heimdallmocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header(tc.canary.header).Return(tc.header)
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+			}
+
+			// WHEN & THEN
+			tc.assert(t, func() bool { return tc.canary.selected(ctx, "test", tc.sub) })
+		})
+	}
+}
+
+func TestCanaryVariantSelectedDistribution(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	canary := &canaryVariant{percentage: 20, stickyBy: config.CanaryStickyBySubject}
+	ctx := heimdallmocks.NewContextMock(t)
+
+	// WHEN
+	var selected int
+
+	for i := range 10000 {
+		if canary.selected(ctx, "test", &subject.Subject{ID: strconv.Itoa(i)}) {
+			selected++
+		}
+	}
+
+	// THEN
+	assert.InDelta(t, 2000, selected, 200)
+}
@@ -18,42 +18,74 @@ package rules
 
 import (
 	"errors"
+	"strings"
+	"sync"
 
 	"github.com/rs/zerolog"
 
+	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
-	"github.com/dadrus/heimdall/internal/rules/config"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/event"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
-var ErrUnsupportedRuleSetVersion = errors.New("unsupported rule set version")
+var (
+	ErrUnsupportedRuleSetVersion = errors.New("unsupported rule set version")
+	ErrRuleConflict              = errors.New("conflicting rules")
+)
 
 type ruleSetProcessor struct {
 	q event.RuleSetChangedEventQueue
 	f rule.Factory
 	l zerolog.Logger
+
+	conflictPolicy string
+	cd             *ruleConflictDetector
+	mut            sync.Mutex
 }
 
 func NewRuleSetProcessor(
-	queue event.RuleSetChangedEventQueue, factory rule.Factory, logger zerolog.Logger,
+	queue event.RuleSetChangedEventQueue, factory rule.Factory, conf *config.Configuration, logger zerolog.Logger,
 ) rule.SetProcessor {
 	return &ruleSetProcessor{
-		q: queue,
-		f: factory,
-		l: logger,
+		q:              queue,
+		f:              factory,
+		l:              logger,
+		conflictPolicy: conf.RuleConflicts.Policy,
+		cd:             newRuleConflictDetector(),
 	}
 }
 
 func (p *ruleSetProcessor) isVersionSupported(version string) bool {
-	return version == config.CurrentRuleSetVersion
+	return config2.IsSupportedRuleSetVersion(version)
 }
 
-func (p *ruleSetProcessor) loadRules(ruleSet *config.RuleSet) ([]rule.Rule, error) {
+func (p *ruleSetProcessor) loadRules(ruleSet *config2.RuleSet) ([]rule.Rule, error) {
+	if ruleSet.Version == config2.RuleSetVersion1Alpha3 && ruleSet.Priority != 0 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"rule set priority requires at least rule set version %s", config2.RuleSetVersion1Alpha4)
+	}
+
 	rules := make([]rule.Rule, len(ruleSet.Rules))
 
 	for idx, rc := range ruleSet.Rules {
+		// the defaults are applied before creating the rule to let them become part of the
+		// rule hash. That way rules are updated if only the defaults change.
+		rc, err := ruleSet.ExpandRule(rc)
+		if err != nil {
+			return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed loading rule").CausedBy(err)
+		}
+
+		if rc.Priority == nil && ruleSet.Version != config2.RuleSetVersion1Alpha3 {
+			// the priority defined on the rule set level applies to all rules not
+			// defining it explicitly. Setting it here lets it become part of the
+			// rule hash, so that rules are updated if the rule set priority changes.
+			priority := ruleSet.Priority
+			rc.Priority = &priority
+		}
+
 		rul, err := p.f.CreateRule(ruleSet.Version, ruleSet.Source, rc)
 		if err != nil {
 			return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed loading rule").CausedBy(err)
@@ -65,7 +97,41 @@ func (p *ruleSetProcessor) loadRules(ruleSet *config.RuleSet) ([]rule.Rule, erro
 	return rules, nil
 }
 
-func (p *ruleSetProcessor) OnCreated(ruleSet *config.RuleSet) error {
+// checkConflicts detects the conflicts of the given rules with each other and with the rules
+// loaded from other sources and handles them according to the configured policy. If the rules
+// are accepted, they are taken into account for the subsequent checks.
+func (p *ruleSetProcessor) checkConflicts(ruleSet *config2.RuleSet, rules []rule.Rule) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	descriptors := newRuleDescriptors(ruleSet, rules)
+
+	switch conflicts := p.cd.conflicts(ruleSet.Source, descriptors); {
+	case len(conflicts) == 0 || p.conflictPolicy == config.RuleConflictPolicyIgnore:
+	case p.conflictPolicy == config.RuleConflictPolicyReject:
+		messages := make([]string, len(conflicts))
+		for idx, conflict := range conflicts {
+			messages[idx] = conflict.String()
+		}
+
+		return errorchain.NewWithMessage(ErrRuleConflict, strings.Join(messages, "; "))
+	default:
+		for _, conflict := range conflicts {
+			p.l.Warn().
+				Str("_src", conflict.rule.srcID).
+				Str("_id", conflict.rule.id).
+				Str("_conflicting_src", conflict.other.srcID).
+				Str("_conflicting_id", conflict.other.id).
+				Msg(conflict.String())
+		}
+	}
+
+	p.cd.set(ruleSet.Source, descriptors)
+
+	return nil
+}
+
+func (p *ruleSetProcessor) OnCreated(ruleSet *config2.RuleSet) error {
 	if !p.isVersionSupported(ruleSet.Version) {
 		return errorchain.NewWithMessage(ErrUnsupportedRuleSetVersion, ruleSet.Version)
 	}
@@ -75,6 +141,10 @@ func (p *ruleSetProcessor) OnCreated(ruleSet *config.RuleSet) error {
 		return err
 	}
 
+	if err = p.checkConflicts(ruleSet, rules); err != nil {
+		return err
+	}
+
 	evt := event.RuleSetChanged{
 		Source:     ruleSet.Source,
 		Name:       ruleSet.Name,
@@ -87,7 +157,7 @@ func (p *ruleSetProcessor) OnCreated(ruleSet *config.RuleSet) error {
 	return nil
 }
 
-func (p *ruleSetProcessor) OnUpdated(ruleSet *config.RuleSet) error {
+func (p *ruleSetProcessor) OnUpdated(ruleSet *config2.RuleSet) error {
 	if !p.isVersionSupported(ruleSet.Version) {
 		return errorchain.NewWithMessage(ErrUnsupportedRuleSetVersion, ruleSet.Version)
 	}
@@ -97,6 +167,10 @@ func (p *ruleSetProcessor) OnUpdated(ruleSet *config.RuleSet) error {
 		return err
 	}
 
+	if err = p.checkConflicts(ruleSet, rules); err != nil {
+		return err
+	}
+
 	evt := event.RuleSetChanged{
 		Source:     ruleSet.Source,
 		Name:       ruleSet.Name,
@@ -109,7 +183,11 @@ func (p *ruleSetProcessor) OnUpdated(ruleSet *config.RuleSet) error {
 	return nil
 }
 
-func (p *ruleSetProcessor) OnDeleted(ruleSet *config.RuleSet) error {
+func (p *ruleSetProcessor) OnDeleted(ruleSet *config2.RuleSet) error {
+	p.mut.Lock()
+	p.cd.remove(ruleSet.Source)
+	p.mut.Unlock()
+
 	evt := event.RuleSetChanged{
 		Source:     ruleSet.Source,
 		Name:       ruleSet.Name,
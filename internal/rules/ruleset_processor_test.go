@@ -17,6 +17,8 @@
 package rules
 
 import (
+	"net/http"
+	"strconv"
 	"testing"
 
 	"github.com/rs/zerolog/log"
@@ -24,8 +26,12 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
-	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/event"
+	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
+	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
@@ -34,15 +40,17 @@ import (
 func TestRuleSetProcessorOnCreated(t *testing.T) {
 	t.Parallel()
 
+	priority := 20
+
 	for _, tc := range []struct {
 		uc               string
-		ruleset          *config.RuleSet
+		ruleset          *config2.RuleSet
 		configureFactory func(t *testing.T, mhf *mocks.FactoryMock)
 		assert           func(t *testing.T, err error, queue event.RuleSetChangedEventQueue)
 	}{
 		{
 			uc:      "unsupported version",
-			ruleset: &config.RuleSet{Version: "foo"},
+			ruleset: &config2.RuleSet{Version: "foo"},
 			assert: func(t *testing.T, err error, _ event.RuleSetChangedEventQueue) {
 				t.Helper()
 
@@ -52,7 +60,7 @@ func TestRuleSetProcessorOnCreated(t *testing.T) {
 		},
 		{
 			uc:      "error while loading rule set",
-			ruleset: &config.RuleSet{Version: config.CurrentRuleSetVersion, Rules: []config.Rule{{ID: "foo"}}},
+			ruleset: &config2.RuleSet{Version: config2.CurrentRuleSetVersion, Rules: []config2.Rule{{ID: "foo"}}},
 			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
 				t.Helper()
 
@@ -69,16 +77,16 @@ func TestRuleSetProcessorOnCreated(t *testing.T) {
 		},
 		{
 			uc: "successful",
-			ruleset: &config.RuleSet{
-				MetaData: config.MetaData{Source: "test"},
-				Version:  config.CurrentRuleSetVersion,
+			ruleset: &config2.RuleSet{
+				MetaData: config2.MetaData{Source: "test"},
+				Version:  config2.CurrentRuleSetVersion,
 				Name:     "foobar",
-				Rules:    []config.Rule{{ID: "foo"}},
+				Rules:    []config2.Rule{{ID: "foo"}},
 			},
 			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
 				t.Helper()
 
-				mhf.EXPECT().CreateRule(config.CurrentRuleSetVersion, mock.Anything, mock.Anything).Return(&mocks.RuleMock{}, nil)
+				mhf.EXPECT().CreateRule(config2.CurrentRuleSetVersion, mock.Anything, mock.Anything).Return(&mocks.RuleMock{}, nil)
 			},
 			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
 				t.Helper()
@@ -95,6 +103,148 @@ func TestRuleSetProcessorOnCreated(t *testing.T) {
 				assert.Equal(t, &mocks.RuleMock{}, evt.Rules[0])
 			},
 		},
+		{
+			uc: "successful with priority defined on the rule set level",
+			ruleset: &config2.RuleSet{
+				MetaData: config2.MetaData{Source: "test"},
+				Version:  config2.CurrentRuleSetVersion,
+				Name:     "foobar",
+				Priority: 10,
+				Rules:    []config2.Rule{{ID: "foo"}, {ID: "bar", Priority: &priority}},
+			},
+			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateRule(config2.CurrentRuleSetVersion, "test",
+					mock.MatchedBy(func(rc config2.Rule) bool { return rc.ID == "foo" && *rc.Priority == 10 }),
+				).Return(&mocks.RuleMock{}, nil)
+				mhf.EXPECT().CreateRule(config2.CurrentRuleSetVersion, "test",
+					mock.MatchedBy(func(rc config2.Rule) bool { return rc.ID == "bar" && *rc.Priority == 20 }),
+				).Return(&mocks.RuleMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, queue, 1)
+
+				evt := <-queue
+				require.Len(t, evt.Rules, 2)
+			},
+		},
+		{
+			uc: "with priority defined on the level of a rule set of version 1alpha3",
+			ruleset: &config2.RuleSet{
+				MetaData: config2.MetaData{Source: "test"},
+				Version:  config2.RuleSetVersion1Alpha3,
+				Name:     "foobar",
+				Priority: 10,
+				Rules:    []config2.Rule{{ID: "foo"}},
+			},
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "rule set priority requires at least rule set version")
+				assert.Empty(t, queue)
+			},
+		},
+		{
+			uc: "successful with rule set of version 1alpha3 not setting priorities",
+			ruleset: &config2.RuleSet{
+				MetaData: config2.MetaData{Source: "test"},
+				Version:  config2.RuleSetVersion1Alpha3,
+				Name:     "foobar",
+				Rules:    []config2.Rule{{ID: "foo"}},
+			},
+			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateRule(config2.RuleSetVersion1Alpha3, "test",
+					mock.MatchedBy(func(rc config2.Rule) bool { return rc.ID == "foo" && rc.Priority == nil }),
+				).Return(&mocks.RuleMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, queue, 1)
+			},
+		},
+		{
+			uc: "successful with rule set defaults",
+			ruleset: &config2.RuleSet{
+				MetaData: config2.MetaData{Source: "test"},
+				Version:  config2.CurrentRuleSetVersion,
+				Name:     "foobar",
+				Defaults: &config2.RuleDefaults{
+					Execute: []config.MechanismConfig{{"authenticator": "foo"}},
+				},
+				Rules: []config2.Rule{{ID: "foo", Execute: []config.MechanismConfig{{"authorizer": "bar"}}}},
+			},
+			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateRule(config2.CurrentRuleSetVersion, "test",
+					mock.MatchedBy(func(rc config2.Rule) bool {
+						return assert.Equal(t, []config.MechanismConfig{
+							{"authenticator": "foo"},
+							{"authorizer": "bar"},
+						}, rc.Execute)
+					}),
+				).Return(&mocks.RuleMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, queue, 1)
+			},
+		},
+		{
+			uc: "error while applying rule set defaults",
+			ruleset: &config2.RuleSet{
+				MetaData: config2.MetaData{Source: "test"},
+				Version:  config2.CurrentRuleSetVersion,
+				Name:     "foobar",
+				Rules:    []config2.Rule{{ID: "foo", Execute: []config.MechanismConfig{{"fragment": "bar"}}}},
+				Fragments: map[string][]config.MechanismConfig{
+					"foo": {{"authenticator": "foo"}},
+				},
+			},
+			assert: func(t *testing.T, err error, _ event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unknown fragment 'bar'")
+			},
+		},
+		{
+			uc: "successful with previous rule set version",
+			ruleset: &config2.RuleSet{
+				MetaData: config2.MetaData{Source: "test"},
+				Version:  config2.RuleSetVersion1Alpha3,
+				Name:     "foobar",
+				Rules:    []config2.Rule{{ID: "foo"}},
+			},
+			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateRule(config2.RuleSetVersion1Alpha3, mock.Anything, mock.Anything).Return(&mocks.RuleMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, queue, 1)
+
+				evt := <-queue
+				require.Len(t, evt.Rules, 1)
+				assert.Equal(t, event.Create, evt.ChangeType)
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEM
@@ -107,7 +257,7 @@ func TestRuleSetProcessorOnCreated(t *testing.T) {
 			factory := mocks.NewFactoryMock(t)
 			configureFactory(t, factory)
 
-			processor := NewRuleSetProcessor(queue, factory, log.Logger)
+			processor := NewRuleSetProcessor(queue, factory, &config.Configuration{}, log.Logger)
 
 			// WHEN
 			err := processor.OnCreated(tc.ruleset)
@@ -123,13 +273,13 @@ func TestRuleSetProcessorOnUpdated(t *testing.T) {
 
 	for _, tc := range []struct {
 		uc               string
-		ruleset          *config.RuleSet
+		ruleset          *config2.RuleSet
 		configureFactory func(t *testing.T, mhf *mocks.FactoryMock)
 		assert           func(t *testing.T, err error, queue event.RuleSetChangedEventQueue)
 	}{
 		{
 			uc:      "unsupported version",
-			ruleset: &config.RuleSet{Version: "foo"},
+			ruleset: &config2.RuleSet{Version: "foo"},
 			assert: func(t *testing.T, err error, _ event.RuleSetChangedEventQueue) {
 				t.Helper()
 
@@ -139,7 +289,7 @@ func TestRuleSetProcessorOnUpdated(t *testing.T) {
 		},
 		{
 			uc:      "error while loading rule set",
-			ruleset: &config.RuleSet{Version: config.CurrentRuleSetVersion, Rules: []config.Rule{{ID: "foo"}}},
+			ruleset: &config2.RuleSet{Version: config2.CurrentRuleSetVersion, Rules: []config2.Rule{{ID: "foo"}}},
 			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
 				t.Helper()
 
@@ -156,16 +306,16 @@ func TestRuleSetProcessorOnUpdated(t *testing.T) {
 		},
 		{
 			uc: "successful",
-			ruleset: &config.RuleSet{
-				MetaData: config.MetaData{Source: "test"},
-				Version:  config.CurrentRuleSetVersion,
+			ruleset: &config2.RuleSet{
+				MetaData: config2.MetaData{Source: "test"},
+				Version:  config2.CurrentRuleSetVersion,
 				Name:     "foobar",
-				Rules:    []config.Rule{{ID: "foo"}},
+				Rules:    []config2.Rule{{ID: "foo"}},
 			},
 			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
 				t.Helper()
 
-				mhf.EXPECT().CreateRule(config.CurrentRuleSetVersion, mock.Anything, mock.Anything).
+				mhf.EXPECT().CreateRule(config2.CurrentRuleSetVersion, mock.Anything, mock.Anything).
 					Return(&mocks.RuleMock{}, nil)
 			},
 			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
@@ -195,7 +345,7 @@ func TestRuleSetProcessorOnUpdated(t *testing.T) {
 			factory := mocks.NewFactoryMock(t)
 			configureFactory(t, factory)
 
-			processor := NewRuleSetProcessor(queue, factory, log.Logger)
+			processor := NewRuleSetProcessor(queue, factory, &config.Configuration{}, log.Logger)
 
 			// WHEN
 			err := processor.OnUpdated(tc.ruleset)
@@ -211,14 +361,14 @@ func TestRuleSetProcessorOnDeleted(t *testing.T) {
 
 	for _, tc := range []struct {
 		uc      string
-		ruleset *config.RuleSet
+		ruleset *config2.RuleSet
 		assert  func(t *testing.T, err error, queue event.RuleSetChangedEventQueue)
 	}{
 		{
 			uc: "successful",
-			ruleset: &config.RuleSet{
-				MetaData: config.MetaData{Source: "test"},
-				Version:  config.CurrentRuleSetVersion,
+			ruleset: &config2.RuleSet{
+				MetaData: config2.MetaData{Source: "test"},
+				Version:  config2.CurrentRuleSetVersion,
 				Name:     "foobar",
 			},
 			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
@@ -237,7 +387,7 @@ func TestRuleSetProcessorOnDeleted(t *testing.T) {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEM
 			queue := make(event.RuleSetChangedEventQueue, 10)
-			processor := NewRuleSetProcessor(queue, mocks.NewFactoryMock(t), log.Logger)
+			processor := NewRuleSetProcessor(queue, mocks.NewFactoryMock(t), &config.Configuration{}, log.Logger)
 
 			// WHEN
 			err := processor.OnDeleted(tc.ruleset)
@@ -247,3 +397,94 @@ func TestRuleSetProcessorOnDeleted(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleSetProcessorRuleConflicts(t *testing.T) {
+	t.Parallel()
+
+	newRuleSet := func(src string, urls ...string) *config2.RuleSet {
+		rules := make([]config2.Rule, len(urls))
+		for idx, url := range urls {
+			rules[idx] = config2.Rule{
+				ID:          "rule" + strconv.Itoa(idx),
+				RuleMatcher: config2.Matcher{URL: url, Strategy: "glob"},
+			}
+		}
+
+		return &config2.RuleSet{
+			MetaData: config2.MetaData{Source: src},
+			Version:  config2.CurrentRuleSetVersion,
+			Name:     "test",
+			Rules:    rules,
+		}
+	}
+
+	for _, tc := range []struct {
+		uc     string
+		policy string
+		assert func(t *testing.T, err error, queue event.RuleSetChangedEventQueue)
+	}{
+		{
+			uc:     "conflicts are ignored",
+			policy: config.RuleConflictPolicyIgnore,
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Len(t, queue, 2)
+			},
+		},
+		{
+			uc:     "conflicts are reported",
+			policy: config.RuleConflictPolicyWarn,
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Len(t, queue, 2)
+			},
+		},
+		{
+			uc:     "conflicts are rejected",
+			policy: config.RuleConflictPolicyReject,
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrRuleConflict)
+				assert.Contains(t, err.Error(), "rule ID=rule0 from src2 is shadowed by rule ID=rule0 from src1")
+				assert.Len(t, queue, 1)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			queue := make(event.RuleSetChangedEventQueue, 10)
+
+			factory := mocks.NewFactoryMock(t)
+			factory.EXPECT().CreateRule(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(
+				func(_, srcID string, rc config2.Rule) (rule.Rule, error) {
+					matcher, err := patternmatcher.NewPatternMatcher(rc.RuleMatcher.Strategy, rc.RuleMatcher.URL)
+					require.NoError(t, err)
+
+					return &ruleImpl{
+						id:         rc.ID,
+						srcID:      srcID,
+						urlMatcher: matcher,
+						methods:    []string{http.MethodGet},
+					}, nil
+				})
+
+			processor := NewRuleSetProcessor(queue, factory,
+				&config.Configuration{RuleConflicts: config.RuleConflictsConfig{Policy: tc.policy}}, log.Logger)
+
+			err := processor.OnCreated(newRuleSet("src1", "http://foo.bar/<**>"))
+			require.NoError(t, err)
+
+			// WHEN
+			err = processor.OnCreated(newRuleSet("src2", "http://foo.bar/baz"))
+
+			// THEN
+			tc.assert(t, err, queue)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dadrus/heimdall/internal/accesscontext"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+)
+
+const (
+	shadowScopeRule = "rule"
+	shadowScopeStep = "step"
+
+	shadowScopeAttrKey         = attribute.Key("heimdall.shadow.scope")
+	shadowIDAttrKey            = attribute.Key("heimdall.shadow.id")
+	shadowAccessGrantedAttrKey = attribute.Key("heimdall.shadow.access_granted")
+	shadowErrorAttrKey         = attribute.Key("heimdall.shadow.error")
+)
+
+var shadowEvaluations = sync.OnceValue(func() metric.Int64Counter { //nolint:gochecknoglobals
+	counter, err := otel.Meter("github.com/dadrus/heimdall/internal/rules").Int64Counter(
+		"heimdall.shadow.evaluations",
+		metric.WithDescription("Number of rules and pipeline steps executed in shadow mode."),
+		metric.WithUnit("{evaluation}"),
+	)
+	if err != nil {
+		return noop.Int64Counter{}
+	}
+
+	return counter
+})
+
+// shadowSubjectHandler executes the wrapped handler in shadow mode. That is, the outcome of
+// the execution is recorded, but an error does never fail the pipeline.
+type shadowSubjectHandler struct {
+	h subjectHandler
+}
+
+func (h *shadowSubjectHandler) Execute(ctx heimdall.Context, sub *subject.Subject) error {
+	recordShadowOutcome(ctx, shadowScopeStep, h.h.ID(), h.h.Execute(ctx, sub))
+
+	return nil
+}
+
+func (h *shadowSubjectHandler) ID() string { return h.h.ID() }
+
+func (h *shadowSubjectHandler) ContinueOnError() bool { return true }
+
+// recordShadowOutcome makes the outcome of a rule or a pipeline step executed in shadow mode
+// visible in the logs, the access logs, the current span and the metrics. A non nil error
+// means the request would have been denied.
+func recordShadowOutcome(ctx heimdall.Context, scope, id string, err error) {
+	appCtx := ctx.AppContext()
+	granted := err == nil
+
+	logger := zerolog.Ctx(appCtx)
+	if granted {
+		logger.Info().Str("_scope", scope).Str("_id", id).Msg("Shadow mode execution would grant access")
+	} else {
+		logger.Warn().Err(err).Str("_scope", scope).Str("_id", id).Msg("Shadow mode execution would deny access")
+	}
+
+	accesscontext.AddShadowOutcome(appCtx, id, err)
+
+	attributes := []attribute.KeyValue{
+		shadowScopeAttrKey.String(scope),
+		shadowIDAttrKey.String(id),
+		shadowAccessGrantedAttrKey.Bool(granted),
+	}
+
+	shadowEvaluations().Add(appCtx, 1, metric.WithAttributes(attributes...))
+
+	if err != nil {
+		attributes = append(attributes, shadowErrorAttrKey.String(err.Error()))
+	}
+
+	trace.SpanFromContext(appCtx).AddEvent("shadow evaluation", trace.WithAttributes(attributes...))
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/dadrus/heimdall/internal/accesscontext"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mocks"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestShadowSubjectHandlerExecute(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		err      error
+		deniedBy []string
+	}{
+		{uc: "wrapped handler succeeds"},
+		{uc: "wrapped handler fails", err: testsupport.ErrTestPurpose, deniedBy: []string{"foo"}},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			appCtx, span := provider.Tracer("test").Start(accesscontext.New(context.Background()), "test")
+
+			sub := &subject.Subject{ID: "bar"}
+
+			ctx := heimdallmocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(appCtx)
+
+			handler := mocks.NewSubjectHandlerMock(t)
+			handler.EXPECT().ID().Return("foo")
+			handler.EXPECT().Execute(ctx, sub).Return(tc.err)
+
+			shadow := &shadowSubjectHandler{h: handler}
+
+			// WHEN
+			err := shadow.Execute(ctx, sub)
+
+			// THEN
+			require.NoError(t, err)
+			assert.True(t, shadow.ContinueOnError())
+
+			evaluated, deniedBy := accesscontext.ShadowOutcome(appCtx)
+			assert.True(t, evaluated)
+			assert.Equal(t, tc.deniedBy, deniedBy)
+
+			span.End()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			require.Len(t, spans[0].Events(), 1)
+
+			event := spans[0].Events()[0]
+			assert.Equal(t, "shadow evaluation", event.Name)
+			assert.Contains(t, event.Attributes, shadowScopeAttrKey.String(shadowScopeStep))
+			assert.Contains(t, event.Attributes, shadowIDAttrKey.String("foo"))
+			assert.Contains(t, event.Attributes, shadowAccessGrantedAttrKey.Bool(tc.err == nil))
+
+			if tc.err != nil {
+				assert.Contains(t, event.Attributes, attribute.KeyValue{
+					Key: shadowErrorAttrKey, Value: attribute.StringValue(tc.err.Error()),
+				})
+			}
+		})
+	}
+}
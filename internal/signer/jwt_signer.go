@@ -217,3 +217,10 @@ func (s *jwtSigner) Keys() []jose.JSONWebKey {
 
 	return s.pubKeys
 }
+
+func (s *jwtSigner) SigningKey() (jose.JSONWebKey, crypto.Signer) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return s.jwk, s.key
+}
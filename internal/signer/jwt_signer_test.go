@@ -609,6 +609,24 @@ func TestJwtSignerKeys(t *testing.T) {
 	assert.Equal(t, "ES256", keys[1].Algorithm)
 }
 
+func TestJWTSignerSigningKey(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signer := &jwtSigner{jwk: jose.JSONWebKey{KeyID: "foo", Algorithm: "ES256", Key: privKey.Public()}, key: privKey}
+
+	// WHEN
+	jwk, key := signer.SigningKey()
+
+	// THEN
+	assert.Equal(t, "foo", jwk.KeyID)
+	assert.Equal(t, "ES256", jwk.Algorithm)
+	assert.Equal(t, privKey, key)
+}
+
 func TestJWTSignerOnChanged(t *testing.T) {
 	t.Parallel()
 
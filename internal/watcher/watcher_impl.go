@@ -56,8 +56,11 @@ func (w *watcher) startWatching() {
 				return
 			}
 
-			if evt.Has(fsnotify.Write) {
+			switch {
+			case evt.Has(fsnotify.Write):
 				w.fireOnChange(evt)
+			case evt.Has(fsnotify.Remove), evt.Has(fsnotify.Rename):
+				w.rewatch(evt)
 			}
 		case err, ok := <-w.w.Errors:
 			if !ok {
@@ -102,6 +105,31 @@ func (w *watcher) Add(path string, cl ChangeListener) error {
 	return nil
 }
 
+// rewatch is used if the watched file has been removed. That happens e.g. if a file mounted
+// from a kubernetes secret or config map is updated, which is done by atomically swapping
+// a symlink, or if an editor replaces the file on save. If the path resolves to a file
+// again, it is watched again and the listeners are informed about the change.
+func (w *watcher) rewatch(evt fsnotify.Event) {
+	w.mut.Lock()
+	_, known := w.m[evt.Name]
+	w.mut.Unlock()
+
+	if !known {
+		return
+	}
+
+	// the watch might have already been dropped together with the removed file
+	_ = w.w.Remove(evt.Name)
+
+	if err := w.w.Add(evt.Name); err != nil {
+		w.l.Warn().Err(err).Str("_file", evt.Name).Msg("Failed to watch the replaced file")
+
+		return
+	}
+
+	w.fireOnChange(evt)
+}
+
 func (w *watcher) fireOnChange(evt fsnotify.Event) {
 	w.mut.Lock()
 	listeners := w.m[evt.Name]
@@ -82,3 +82,43 @@ func TestWatcherLifeCycle(t *testing.T) {
 	cl3.AssertExpectations(t)
 	cl4.AssertExpectations(t)
 }
+
+func TestWatcherFollowsReplacedFiles(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cw, err := newWatcher(log.Logger)
+	require.NoError(t, err)
+
+	cw.start(context.TODO())
+	defer cw.stop(context.TODO())
+
+	// mimics the layout of a mounted kubernetes secret
+	testDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(testDir, "..v1"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "..v1", "file"), []byte("foo"), 0o600))
+	require.NoError(t, os.Symlink("..v1", filepath.Join(testDir, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "file"), filepath.Join(testDir, "file")))
+
+	cl := NewChangeListenerMock(t)
+	cl.EXPECT().OnChanged(mock.Anything).Times(2)
+
+	err = cw.Add(filepath.Join(testDir, "file"), cl)
+	require.NoError(t, err)
+
+	// WHEN
+	require.NoError(t, os.Mkdir(filepath.Join(testDir, "..v2"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "..v2", "file"), []byte("bar"), 0o600))
+	require.NoError(t, os.Symlink("..v2", filepath.Join(testDir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(testDir, "..data_tmp"), filepath.Join(testDir, "..data")))
+	require.NoError(t, os.RemoveAll(filepath.Join(testDir, "..v1")))
+	time.Sleep(100 * time.Millisecond)
+
+	// the new file is watched
+	err = os.WriteFile(filepath.Join(testDir, "..v2", "file"), []byte("baz"), 0o600)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	// THEN
+	cl.AssertExpectations(t)
+}
@@ -0,0 +1,191 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hashx
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var (
+	ErrUnsupportedHash = errors.New("unsupported hash")
+	ErrMalformedHash   = errors.New("malformed hash")
+)
+
+// Hash represents a parsed salted hash of a secret, like a password or an api key.
+type Hash interface {
+	// Matches returns true if the given secret results in the same hash. The
+	// comparison of the hash values happens in constant time.
+	Matches(secret []byte) bool
+}
+
+// Parse parses the given encoded hash. Supported are
+//
+//   - bcrypt hashes with $2a$, $2b$ or $2y$ prefix,
+//   - argon2id and argon2i hashes in the PHC string format, like $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+//     with salt and hash being base64 encoded without padding, and
+//   - salted SHA-256 hashes in the form $sha256$<salt>$<hash> with hash being the hex encoded SHA-256
+//     value of the salt followed by the secret, and
+//   - unsalted SHA-1 hashes in the form {SHA}<hash> with hash being the base64 encoded SHA-1 value of
+//     the secret, as created by htpasswd -s. These are supported for compatibility reasons only.
+func Parse(encoded string) (Hash, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"),
+		strings.HasPrefix(encoded, "$2b$"),
+		strings.HasPrefix(encoded, "$2y$"):
+		return parseBcrypt(encoded)
+	case strings.HasPrefix(encoded, "$argon2id$"), strings.HasPrefix(encoded, "$argon2i$"):
+		return parseArgon2(encoded)
+	case strings.HasPrefix(encoded, "$sha256$"):
+		return parseSHA256(encoded)
+	case strings.HasPrefix(encoded, "{SHA}"):
+		return parseSHA1(encoded)
+	default:
+		return nil, ErrUnsupportedHash
+	}
+}
+
+type bcryptHash []byte
+
+func parseBcrypt(encoded string) (Hash, error) {
+	if _, err := bcrypt.Cost([]byte(encoded)); err != nil {
+		return nil, errorchain.NewWithMessage(ErrMalformedHash, "invalid bcrypt hash").CausedBy(err)
+	}
+
+	return bcryptHash(encoded), nil
+}
+
+func (h bcryptHash) Matches(secret []byte) bool {
+	return bcrypt.CompareHashAndPassword(h, secret) == nil
+}
+
+type argon2Hash struct {
+	variant string
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	hash    []byte
+}
+
+func parseArgon2(encoded string) (Hash, error) {
+	var (
+		hash    argon2Hash
+		version int
+	)
+
+	// results in "", variant, version, params, salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 { //nolint:gomnd
+		return nil, errorchain.NewWithMessage(ErrMalformedHash, "invalid argon2 hash format")
+	}
+
+	hash.variant = parts[1]
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, errorchain.NewWithMessagef(ErrMalformedHash, "unsupported argon2 version '%s'", parts[2])
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hash.memory, &hash.time, &hash.threads); err != nil {
+		return nil, errorchain.NewWithMessage(ErrMalformedHash, "invalid argon2 parameters").CausedBy(err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrMalformedHash, "invalid argon2 salt").CausedBy(err)
+	}
+
+	value, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(value) == 0 {
+		return nil, errorchain.NewWithMessage(ErrMalformedHash, "invalid argon2 hash value")
+	}
+
+	hash.salt = salt
+	hash.hash = value
+
+	return &hash, nil
+}
+
+func (h *argon2Hash) Matches(secret []byte) bool {
+	var computed []byte
+
+	//nolint:gosec
+	// len(h.hash) is small, no overflow possible
+	if h.variant == "argon2id" {
+		computed = argon2.IDKey(secret, h.salt, h.time, h.memory, h.threads, uint32(len(h.hash)))
+	} else {
+		computed = argon2.Key(secret, h.salt, h.time, h.memory, h.threads, uint32(len(h.hash)))
+	}
+
+	return subtle.ConstantTimeCompare(computed, h.hash) == 1
+}
+
+type sha256Hash struct {
+	salt []byte
+	hash []byte
+}
+
+func parseSHA256(encoded string) (Hash, error) {
+	// results in "", "sha256", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 { //nolint:gomnd
+		return nil, errorchain.NewWithMessage(ErrMalformedHash, "invalid sha256 hash format")
+	}
+
+	value, err := hex.DecodeString(parts[3])
+	if err != nil || len(value) != sha256.Size {
+		return nil, errorchain.NewWithMessage(ErrMalformedHash, "invalid sha256 hash value")
+	}
+
+	return &sha256Hash{salt: []byte(parts[2]), hash: value}, nil
+}
+
+func (h *sha256Hash) Matches(secret []byte) bool {
+	md := sha256.New()
+	md.Write(h.salt)
+	md.Write(secret)
+
+	return subtle.ConstantTimeCompare(md.Sum(nil), h.hash) == 1
+}
+
+type sha1Hash []byte
+
+func parseSHA1(encoded string) (Hash, error) {
+	value, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encoded, "{SHA}"))
+	if err != nil || len(value) != sha1.Size {
+		return nil, errorchain.NewWithMessage(ErrMalformedHash, "invalid sha1 hash value")
+	}
+
+	return sha1Hash(value), nil
+}
+
+func (h sha1Hash) Matches(secret []byte) bool {
+	value := sha1.Sum(secret) //nolint:gosec
+
+	return subtle.ConstantTimeCompare(value[:], h) == 1
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hashx
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestParseAndMatch(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("foobar")
+
+	bcryptHash, err := bcrypt.GenerateFromPassword(secret, bcrypt.MinCost)
+	require.NoError(t, err)
+
+	salt := []byte("0123456789abcdef")
+	argon2idHash := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, 1024, 1, 1,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(argon2.IDKey(secret, salt, 1, 1024, 1, 32)))
+	argon2iHash := fmt.Sprintf("$argon2i$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, 1024, 1, 1,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(argon2.Key(secret, salt, 1, 1024, 1, 32)))
+
+	sha256Value := sha256.Sum256(append([]byte("salt"), secret...))
+	sha256Hash := "$sha256$salt$" + hex.EncodeToString(sha256Value[:])
+
+	sha1Value := sha1.Sum(secret)
+	sha1Hash := "{SHA}" + base64.StdEncoding.EncodeToString(sha1Value[:])
+
+	for _, tc := range []struct {
+		uc      string
+		encoded string
+		err     error
+	}{
+		{uc: "bcrypt", encoded: string(bcryptHash)},
+		{uc: "argon2id", encoded: argon2idHash},
+		{uc: "argon2i", encoded: argon2iHash},
+		{uc: "salted sha256", encoded: sha256Hash},
+		{uc: "sha1", encoded: sha1Hash},
+		{uc: "unsupported hash", encoded: "{SSHA}foo", err: ErrUnsupportedHash},
+		{uc: "malformed bcrypt hash", encoded: "$2a$foo", err: ErrMalformedHash},
+		{uc: "argon2 hash with missing parts", encoded: "$argon2id$v=19$m=1024,t=1,p=1$foo", err: ErrMalformedHash},
+		{uc: "argon2 hash with unsupported version", encoded: "$argon2id$v=16$m=1024,t=1,p=1$Zm9v$Zm9v", err: ErrMalformedHash},
+		{uc: "argon2 hash with malformed parameters", encoded: "$argon2id$v=19$m=foo$Zm9v$Zm9v", err: ErrMalformedHash},
+		{uc: "argon2 hash with malformed salt", encoded: "$argon2id$v=19$m=1024,t=1,p=1$*$Zm9v", err: ErrMalformedHash},
+		{uc: "argon2 hash with malformed value", encoded: "$argon2id$v=19$m=1024,t=1,p=1$Zm9v$*", err: ErrMalformedHash},
+		{uc: "sha256 hash with missing parts", encoded: "$sha256$foo", err: ErrMalformedHash},
+		{uc: "sha256 hash with malformed value", encoded: "$sha256$salt$abcd", err: ErrMalformedHash},
+		{uc: "sha1 hash with malformed value", encoded: "{SHA}Zm9v", err: ErrMalformedHash},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			hash, err := Parse(tc.encoded)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, hash.Matches(secret))
+			assert.False(t, hash.Matches([]byte("barfoo")))
+			assert.False(t, hash.Matches(nil))
+		})
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"math/big"
+
+	"github.com/go-jose/go-jose/v4"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// Algorithms as defined in RFC 9421, section 3.3.
+const (
+	AlgRSAPSSSHA512      = "rsa-pss-sha512"
+	AlgRSAv15SHA256      = "rsa-v1_5-sha256"
+	AlgHMACSHA256        = "hmac-sha256"
+	AlgECDSAP256SHA256   = "ecdsa-p256-sha256"
+	AlgECDSAP384SHA384   = "ecdsa-p384-sha384"
+	AlgEd25519           = "ed25519"
+	rsaPSSSaltLength     = 64
+	ecdsaP256FieldLength = 32
+	ecdsaP384FieldLength = 48
+)
+
+// AlgorithmFor returns the signature algorithm to be used with the given key. If the JWS
+// algorithm the key is intended for is known, it is mapped to the corresponding algorithm.
+// Since RSASSA-PSS is defined with SHA-512 only, all PS algorithms map to rsa-pss-sha512.
+// Otherwise, the algorithm is derived from the key type, which is not possible for RSA keys.
+func AlgorithmFor(key any, jwsAlg string) (string, error) {
+	switch jose.SignatureAlgorithm(jwsAlg) {
+	case jose.PS256, jose.PS384, jose.PS512:
+		return AlgRSAPSSSHA512, nil
+	case jose.RS256:
+		return AlgRSAv15SHA256, nil
+	case jose.HS256:
+		return AlgHMACSHA256, nil
+	case jose.ES256:
+		return AlgECDSAP256SHA256, nil
+	case jose.ES384:
+		return AlgECDSAP384SHA384, nil
+	case jose.EdDSA:
+		return AlgEd25519, nil
+	case "":
+	default:
+		return "", errorchain.NewWithMessagef(ErrUnsupportedAlgorithm,
+			"JWS algorithm '%s' has no counterpart", jwsAlg)
+	}
+
+	switch k := publicKey(key).(type) {
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return AlgECDSAP256SHA256, nil
+		case elliptic.P384():
+			return AlgECDSAP384SHA384, nil
+		}
+	case ed25519.PublicKey:
+		return AlgEd25519, nil
+	}
+
+	return "", errorchain.NewWithMessage(ErrUnsupportedAlgorithm, "cannot derive algorithm from the key")
+}
+
+// KeyAlgorithms returns the signature algorithms the given key can be used with. The
+// returned list is empty if the key cannot be used for http message signatures at all.
+func KeyAlgorithms(key any) []string {
+	switch k := publicKey(key).(type) {
+	case *rsa.PublicKey:
+		return []string{AlgRSAPSSSHA512, AlgRSAv15SHA256}
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return []string{AlgECDSAP256SHA256}
+		case elliptic.P384():
+			return []string{AlgECDSAP384SHA384}
+		}
+	case ed25519.PublicKey:
+		return []string{AlgEd25519}
+	case []byte:
+		return []string{AlgHMACSHA256}
+	}
+
+	return nil
+}
+
+func publicKey(key any) any {
+	if signer, ok := key.(crypto.Signer); ok {
+		return signer.Public()
+	}
+
+	return key
+}
+
+func verify(alg string, key any, base, signature []byte) error {
+	var valid bool
+
+	switch alg {
+	case AlgRSAPSSSHA512:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errKeyAlgorithmMismatch(alg)
+		}
+
+		digest := sha512.Sum512(base)
+		valid = rsa.VerifyPSS(pub, crypto.SHA512, digest[:], signature,
+			&rsa.PSSOptions{SaltLength: rsaPSSSaltLength}) == nil
+	case AlgRSAv15SHA256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errKeyAlgorithmMismatch(alg)
+		}
+
+		digest := sha256.Sum256(base)
+		valid = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil
+	case AlgHMACSHA256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return errKeyAlgorithmMismatch(alg)
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(base)
+		valid = hmac.Equal(mac.Sum(nil), signature)
+	case AlgECDSAP256SHA256, AlgECDSAP384SHA384:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != curveFor(alg) {
+			return errKeyAlgorithmMismatch(alg)
+		}
+
+		valid = verifyECDSA(alg, pub, base, signature)
+	case AlgEd25519:
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return errKeyAlgorithmMismatch(alg)
+		}
+
+		valid = ed25519.Verify(pub, base, signature)
+	default:
+		return errorchain.NewWithMessagef(ErrUnsupportedAlgorithm, "algorithm '%s' is not supported", alg)
+	}
+
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func sign(alg string, key any, base []byte) ([]byte, error) {
+	switch alg {
+	case AlgRSAPSSSHA512:
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errKeyAlgorithmMismatch(alg)
+		}
+
+		digest := sha512.Sum512(base)
+
+		return rsa.SignPSS(rand.Reader, priv, crypto.SHA512, digest[:],
+			&rsa.PSSOptions{SaltLength: rsaPSSSaltLength})
+	case AlgRSAv15SHA256:
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errKeyAlgorithmMismatch(alg)
+		}
+
+		digest := sha256.Sum256(base)
+
+		return rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+	case AlgHMACSHA256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, errKeyAlgorithmMismatch(alg)
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(base)
+
+		return mac.Sum(nil), nil
+	case AlgECDSAP256SHA256, AlgECDSAP384SHA384:
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok || priv.Curve != curveFor(alg) {
+			return nil, errKeyAlgorithmMismatch(alg)
+		}
+
+		return signECDSA(alg, priv, base)
+	case AlgEd25519:
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errKeyAlgorithmMismatch(alg)
+		}
+
+		return ed25519.Sign(priv, base), nil
+	default:
+		return nil, errorchain.NewWithMessagef(ErrUnsupportedAlgorithm, "algorithm '%s' is not supported", alg)
+	}
+}
+
+func curveFor(alg string) elliptic.Curve {
+	if alg == AlgECDSAP256SHA256 {
+		return elliptic.P256()
+	}
+
+	return elliptic.P384()
+}
+
+func ecdsaDigest(alg string, base []byte) ([]byte, int) {
+	if alg == AlgECDSAP256SHA256 {
+		digest := sha256.Sum256(base)
+
+		return digest[:], ecdsaP256FieldLength
+	}
+
+	digest := sha512.Sum384(base)
+
+	return digest[:], ecdsaP384FieldLength
+}
+
+// verifyECDSA verifies the signature, which is expected to be the concatenation of
+// the r and s values, each encoded as big-endian unsigned integer of the field size.
+func verifyECDSA(alg string, pub *ecdsa.PublicKey, base, signature []byte) bool {
+	digest, size := ecdsaDigest(alg, base)
+	if len(signature) != 2*size {
+		return false
+	}
+
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+
+	return ecdsa.Verify(pub, digest, r, s)
+}
+
+func signECDSA(alg string, priv *ecdsa.PrivateKey, base []byte) ([]byte, error) {
+	digest, size := ecdsaDigest(alg, base)
+
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+
+	return signature, nil
+}
+
+func errKeyAlgorithmMismatch(alg string) error {
+	return errorchain.NewWithMessagef(ErrUnsupportedAlgorithm, "key cannot be used with algorithm '%s'", alg)
+}
@@ -0,0 +1,206 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	ComponentMethod        = "@method"
+	ComponentTargetURI     = "@target-uri"
+	ComponentAuthority     = "@authority"
+	ComponentScheme        = "@scheme"
+	ComponentRequestTarget = "@request-target"
+	ComponentPath          = "@path"
+	ComponentQuery         = "@query"
+	ComponentQueryParam    = "@query-param"
+
+	componentSignatureParams = "@signature-params"
+)
+
+// Message provides access to the parts of an HTTP request, which can be covered by a signature.
+type Message interface {
+	Method() string
+	URL() *url.URL
+	// Header returns the values of all fields with the given name in the order these
+	// are present in the message.
+	Header(name string) []string
+}
+
+// Component identifies a message component covered by a signature. Name is either the
+// lower-cased name of a header field or the name of a derived component, like @method.
+// The only supported component parameter is the name parameter of @query-param.
+type Component struct {
+	Name      string
+	ParamName string
+}
+
+// ParseComponent parses a component identifier like @method, content-digest, or
+// @query-param;name="foo". The component name can, but does not need to be quoted.
+func ParseComponent(value string) (Component, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, `"`) {
+		name, rest, _ := strings.Cut(value, ";")
+		value = `"` + name + `"` + x.IfThenElse(len(rest) != 0, ";"+rest, "")
+	}
+
+	itm, err := parseItem(value)
+	if err != nil {
+		return Component{}, err
+	}
+
+	return componentFromItem(itm)
+}
+
+// ParseComponents parses the given component identifiers. If none are given, @method,
+// @authority and @path are returned.
+func ParseComponents(values []string) ([]Component, error) {
+	if len(values) == 0 {
+		return []Component{
+			{Name: ComponentMethod},
+			{Name: ComponentAuthority},
+			{Name: ComponentPath},
+		}, nil
+	}
+
+	components := make([]Component, len(values))
+
+	for idx, value := range values {
+		comp, err := ParseComponent(value)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"invalid component '%s'", value).CausedBy(err)
+		}
+
+		components[idx] = comp
+	}
+
+	return components, nil
+}
+
+func componentFromItem(itm item) (Component, error) {
+	name, ok := itm.value.(string)
+	if !ok || len(name) == 0 {
+		return Component{}, errorchain.NewWithMessage(ErrMalformedSignature,
+			"component identifier must be a non-empty string")
+	}
+
+	comp := Component{Name: strings.ToLower(name)}
+
+	for _, prm := range itm.params {
+		if prm.key != "name" || comp.Name != ComponentQueryParam {
+			return Component{}, errorchain.NewWithMessagef(ErrUnsupportedComponent,
+				"parameter '%s' of component '%s' is not supported", prm.key, comp.Name)
+		}
+
+		paramName, ok := prm.value.(string)
+		if !ok {
+			return Component{}, errorchain.NewWithMessage(ErrMalformedSignature,
+				"name parameter of @query-param must be a string")
+		}
+
+		comp.ParamName = paramName
+	}
+
+	if comp.Name == ComponentQueryParam && len(comp.ParamName) == 0 {
+		return Component{}, errorchain.NewWithMessage(ErrMalformedSignature,
+			"@query-param requires the name parameter")
+	}
+
+	return comp, nil
+}
+
+func (c Component) item() item {
+	if len(c.ParamName) == 0 {
+		return item{value: c.Name}
+	}
+
+	return item{value: c.Name, params: params{{key: "name", value: c.ParamName}}}
+}
+
+// String returns the serialized component identifier as used in the signature base.
+func (c Component) String() string {
+	return serializeItem(c.item())
+}
+
+func (c Component) value(msg Message) (string, error) {
+	reqURL := msg.URL()
+
+	switch c.Name {
+	case ComponentMethod:
+		return msg.Method(), nil
+	case ComponentTargetURI:
+		return reqURL.String(), nil
+	case ComponentAuthority:
+		return authority(reqURL), nil
+	case ComponentScheme:
+		return strings.ToLower(reqURL.Scheme), nil
+	case ComponentRequestTarget:
+		return reqURL.RequestURI(), nil
+	case ComponentPath:
+		return x.IfThenElse(len(reqURL.EscapedPath()) != 0, reqURL.EscapedPath(), "/"), nil
+	case ComponentQuery:
+		return "?" + reqURL.RawQuery, nil
+	case ComponentQueryParam:
+		values := reqURL.Query()[c.ParamName]
+		if len(values) != 1 {
+			return "", errorchain.NewWithMessagef(ErrMissingComponent,
+				"query parameter '%s' is not present or not unique", c.ParamName)
+		}
+
+		return strings.ReplaceAll(url.QueryEscape(values[0]), "+", "%20"), nil
+	}
+
+	if strings.HasPrefix(c.Name, "@") {
+		return "", errorchain.NewWithMessagef(ErrUnsupportedComponent,
+			"derived component '%s' is not supported", c.Name)
+	}
+
+	values := msg.Header(c.Name)
+	if len(values) == 0 {
+		return "", errorchain.NewWithMessagef(ErrMissingComponent, "header '%s' is not present", c.Name)
+	}
+
+	// the values of repeated fields are combined as defined in RFC 9421, section 2.1
+	for idx, value := range values {
+		values[idx] = strings.TrimSpace(value)
+	}
+
+	return strings.Join(values, ", "), nil
+}
+
+func authority(reqURL *url.URL) string {
+	host := strings.ToLower(reqURL.Host)
+
+	_, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+
+	if (reqURL.Scheme == "https" && port == "443") || (reqURL.Scheme == "http" && port == "80") {
+		return strings.TrimSuffix(host, ":"+port)
+	}
+
+	return host
+}
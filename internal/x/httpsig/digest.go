@@ -0,0 +1,102 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// This file implements the Content-Digest field according to RFC 9530.
+
+const (
+	HeaderContentDigest    = "Content-Digest"
+	ComponentContentDigest = "content-digest"
+
+	digestAlgSHA256 = "sha-256"
+	digestAlgSHA512 = "sha-512"
+)
+
+var ErrContentDigestMismatch = errors.New("content digest mismatch")
+
+// ContentDigest returns the value of the Content-Digest field for the given body
+// using sha-256.
+func ContentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return digestAlgSHA256 + "=" + serializeBareItem(sum[:])
+}
+
+// VerifyContentDigest verifies the given values of the Content-Digest field against the
+// given body. Digests using algorithms other than sha-256 and sha-512 are ignored. At
+// least one digest using one of these algorithms must be present and all of these must
+// match the body.
+func VerifyContentDigest(values []string, body []byte) error {
+	members, err := parseDictionary(strings.Join(values, ", "))
+	if err != nil {
+		return errorchain.NewWithMessage(ErrContentDigestMismatch, "malformed content digest").CausedBy(err)
+	}
+
+	verified := false
+
+	for _, member := range members {
+		var md hash.Hash
+
+		switch member.key {
+		case digestAlgSHA256:
+			md = sha256.New()
+		case digestAlgSHA512:
+			md = sha512.New()
+		default:
+			continue
+		}
+
+		itm, ok := member.value.(item)
+		if !ok {
+			return errorchain.NewWithMessagef(ErrContentDigestMismatch,
+				"%s digest is not a byte sequence", member.key)
+		}
+
+		digest, ok := itm.value.([]byte)
+		if !ok {
+			return errorchain.NewWithMessagef(ErrContentDigestMismatch,
+				"%s digest is not a byte sequence", member.key)
+		}
+
+		md.Write(body)
+
+		if subtle.ConstantTimeCompare(md.Sum(nil), digest) != 1 {
+			return errorchain.NewWithMessagef(ErrContentDigestMismatch,
+				"%s digest does not match the body", member.key)
+		}
+
+		verified = true
+	}
+
+	if !verified {
+		return errorchain.NewWithMessage(ErrContentDigestMismatch,
+			"no digest using a supported algorithm present")
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentDigest(t *testing.T) {
+	t.Parallel()
+
+	// example taken from RFC 9530, appendix B.1
+	assert.Equal(t,
+		"sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:",
+		ContentDigest([]byte(`{"hello": "world"}`)))
+}
+
+func TestVerifyContentDigest(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"hello": "world"}`)
+
+	for _, tc := range []struct {
+		uc     string
+		values []string
+		err    string
+	}{
+		{uc: "no digest present", err: "no digest"},
+		{uc: "malformed digest", values: []string{"sha-256=:foo"}, err: "malformed"},
+		{uc: "digest not being a byte sequence", values: []string{`sha-256="foo"`}, err: "not a byte sequence"},
+		{uc: "only unsupported algorithms", values: []string{"md5=:Zm9v:"}, err: "no digest"},
+		{uc: "not matching sha-256 digest", values: []string{"sha-256=:Zm9v:"}, err: "does not match"},
+		{
+			uc:     "matching sha-256 digest",
+			values: []string{"sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:"},
+		},
+		{
+			uc: "matching sha-512 digest",
+			values: []string{
+				"sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:",
+			},
+		},
+		{
+			uc: "multiple matching digests split over multiple fields",
+			values: []string{
+				"md5=:Zm9v:, sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:",
+				"sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:",
+			},
+		},
+		{
+			uc: "one of multiple digests not matching",
+			values: []string{
+				"sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:, sha-512=:Zm9v:",
+			},
+			err: "sha-512 digest does not match",
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			err := VerifyContentDigest(tc.values, body)
+
+			// THEN
+			if len(tc.err) == 0 {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrContentDigestMismatch)
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+type requestMessage struct {
+	req *heimdall.Request
+}
+
+// NewRequestMessage adapts the given heimdall request to the Message interface.
+func NewRequestMessage(req *heimdall.Request) Message {
+	return &requestMessage{req: req}
+}
+
+func (m *requestMessage) Method() string              { return m.req.Method }
+func (m *requestMessage) URL() *url.URL               { return &m.req.URL.URL }
+func (m *requestMessage) Header(name string) []string { return m.req.HeaderValues(name) }
+
+type headerOverride struct {
+	Message
+
+	name   string
+	values []string
+}
+
+// WithHeader returns a Message, which provides the given values for the header field
+// with the given name instead of the values present in msg.
+func WithHeader(msg Message, name string, values ...string) Message {
+	return &headerOverride{Message: msg, name: name, values: values}
+}
+
+func (m *headerOverride) Header(name string) []string {
+	if strings.EqualFold(name, m.name) {
+		return m.values
+	}
+
+	return m.Message.Header(name)
+}
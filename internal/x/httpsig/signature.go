@@ -0,0 +1,283 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package httpsig implements creation and verification of HTTP message signatures
+// according to RFC 9421 for requests.
+package httpsig
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	HeaderSignature      = "Signature"
+	HeaderSignatureInput = "Signature-Input"
+)
+
+var (
+	ErrMalformedSignature   = errors.New("malformed signature")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	ErrUnsupportedComponent = errors.New("unsupported component")
+	ErrMissingComponent     = errors.New("missing component")
+)
+
+// SignatureParams holds the signature parameters. When parsed from a Signature-Input field,
+// the received serialization is kept and used for the signature base.
+type SignatureParams struct {
+	Components []Component
+	Created    time.Time
+	Expires    time.Time
+	Nonce      string
+	Algorithm  string
+	KeyID      string
+	Tag        string
+
+	raw *innerList
+}
+
+func (p *SignatureParams) innerList() innerList {
+	if p.raw != nil {
+		return *p.raw
+	}
+
+	list := innerList{items: make([]item, len(p.Components))}
+
+	for idx, comp := range p.Components {
+		list.items[idx] = comp.item()
+	}
+
+	if !p.Created.IsZero() {
+		list.params = append(list.params, param{key: "created", value: p.Created.Unix()})
+	}
+
+	if !p.Expires.IsZero() {
+		list.params = append(list.params, param{key: "expires", value: p.Expires.Unix()})
+	}
+
+	for _, prm := range []param{
+		{key: "nonce", value: p.Nonce},
+		{key: "alg", value: p.Algorithm},
+		{key: "keyid", value: p.KeyID},
+		{key: "tag", value: p.Tag},
+	} {
+		if len(prm.value.(string)) != 0 { //nolint:forcetypeassert
+			list.params = append(list.params, prm)
+		}
+	}
+
+	return list
+}
+
+// String returns the serialized signature parameters as used in the Signature-Input field.
+func (p *SignatureParams) String() string {
+	return serializeInnerList(p.innerList())
+}
+
+// Covers returns whether the signature covers the given component.
+func (p *SignatureParams) Covers(comp Component) bool {
+	return slices.Contains(p.Components, comp)
+}
+
+// Signature is a signature received in the Signature field together with its parameters
+// received in the Signature-Input field.
+type Signature struct {
+	Label  string
+	Params SignatureParams
+	Value  []byte
+}
+
+// ParseSignatures parses the values of the Signature-Input and Signature fields. The
+// signatures are returned in the order of the Signature-Input field.
+func ParseSignatures(signatureInput, signature string) ([]*Signature, error) {
+	inputs, err := parseDictionary(signatureInput)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrMalformedSignature,
+			"failed parsing Signature-Input").CausedBy(err)
+	}
+
+	values, err := parseDictionary(signature)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrMalformedSignature,
+			"failed parsing Signature").CausedBy(err)
+	}
+
+	if len(inputs) == 0 {
+		return nil, errorchain.NewWithMessage(ErrMalformedSignature, "no signature present")
+	}
+
+	signatures := make([]*Signature, 0, len(inputs))
+
+	for _, input := range inputs {
+		list, ok := input.value.(innerList)
+		if !ok {
+			return nil, errorchain.NewWithMessagef(ErrMalformedSignature,
+				"signature input '%s' is not an inner list", input.key)
+		}
+
+		value, err := signatureValue(values, input.key)
+		if err != nil {
+			return nil, err
+		}
+
+		sigParams, err := parseSignatureParams(list)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(ErrMalformedSignature,
+				"failed parsing parameters of signature '%s'", input.key).CausedBy(err)
+		}
+
+		signatures = append(signatures, &Signature{Label: input.key, Params: sigParams, Value: value})
+	}
+
+	return signatures, nil
+}
+
+func signatureValue(values []dictMember, label string) ([]byte, error) {
+	for idx := len(values) - 1; idx >= 0; idx-- {
+		if values[idx].key != label {
+			continue
+		}
+
+		if itm, ok := values[idx].value.(item); ok {
+			if value, ok := itm.value.([]byte); ok {
+				return value, nil
+			}
+		}
+
+		return nil, errorchain.NewWithMessagef(ErrMalformedSignature,
+			"signature '%s' is not a byte sequence", label)
+	}
+
+	return nil, errorchain.NewWithMessagef(ErrMalformedSignature, "no signature value for '%s'", label)
+}
+
+func parseSignatureParams(list innerList) (SignatureParams, error) {
+	sigParams := SignatureParams{raw: &list}
+
+	for _, itm := range list.items {
+		comp, err := componentFromItem(itm)
+		if err != nil {
+			return SignatureParams{}, err
+		}
+
+		if sigParams.Covers(comp) {
+			return SignatureParams{}, errorchain.NewWithMessagef(ErrMalformedSignature,
+				"component '%s' is referenced multiple times", comp)
+		}
+
+		sigParams.Components = append(sigParams.Components, comp)
+	}
+
+	for _, prm := range list.params {
+		var ok bool
+
+		switch prm.key {
+		case "created", "expires":
+			var value int64
+
+			if value, ok = prm.value.(int64); ok {
+				if prm.key == "created" {
+					sigParams.Created = time.Unix(value, 0)
+				} else {
+					sigParams.Expires = time.Unix(value, 0)
+				}
+			}
+		case "nonce":
+			sigParams.Nonce, ok = prm.value.(string)
+		case "alg":
+			sigParams.Algorithm, ok = prm.value.(string)
+		case "keyid":
+			sigParams.KeyID, ok = prm.value.(string)
+		case "tag":
+			sigParams.Tag, ok = prm.value.(string)
+		default:
+			// unknown parameters are covered by the signature base but ignored otherwise
+			ok = true
+		}
+
+		if !ok {
+			return SignatureParams{}, errorchain.NewWithMessagef(ErrMalformedSignature,
+				"invalid value for parameter '%s'", prm.key)
+		}
+	}
+
+	return sigParams, nil
+}
+
+// Verify verifies the signature over the given message using the given key and algorithm.
+// If the signature parameters specify an algorithm, it must match the given one.
+func (s *Signature) Verify(msg Message, alg string, key any) error {
+	if len(s.Params.Algorithm) != 0 && s.Params.Algorithm != alg {
+		return errorchain.NewWithMessagef(ErrUnsupportedAlgorithm,
+			"signature algorithm '%s' does not match the algorithm '%s' of the key", s.Params.Algorithm, alg)
+	}
+
+	base, err := signatureBase(msg, &s.Params)
+	if err != nil {
+		return err
+	}
+
+	return verify(alg, key, base, s.Value)
+}
+
+// Sign creates a signature over the given message using the given key and algorithm and
+// returns the values for the Signature-Input and the Signature fields.
+func Sign(msg Message, label string, sigParams SignatureParams, alg string, key any) (string, string, error) {
+	base, err := signatureBase(msg, &sigParams)
+	if err != nil {
+		return "", "", err
+	}
+
+	value, err := sign(alg, key, base)
+	if err != nil {
+		return "", "", err
+	}
+
+	return label + "=" + sigParams.String(), label + "=" + serializeBareItem(value), nil
+}
+
+// signatureBase creates the signature base according to RFC 9421, section 2.5.
+func signatureBase(msg Message, sigParams *SignatureParams) ([]byte, error) {
+	var buf strings.Builder
+
+	for _, comp := range sigParams.Components {
+		if comp.Name == componentSignatureParams {
+			return nil, errorchain.NewWithMessage(ErrMalformedSignature,
+				"@signature-params must not be referenced as component")
+		}
+
+		value, err := comp.value(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.WriteString(comp.String())
+		buf.WriteString(": ")
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+	}
+
+	buf.WriteString(serializeBareItem(componentSignatureParams))
+	buf.WriteString(": ")
+	buf.WriteString(sigParams.String())
+
+	return []byte(buf.String()), nil
+}
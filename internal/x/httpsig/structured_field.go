@@ -0,0 +1,462 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// This file implements the subset of RFC 8941 (Structured Field Values for HTTP) required
+// for the Signature-Input and Signature fields. Decimals are not supported.
+
+type token string
+
+type param struct {
+	key   string
+	value any
+}
+
+type params []param
+
+func (p params) get(key string) (any, bool) {
+	for idx := len(p) - 1; idx >= 0; idx-- {
+		if p[idx].key == key {
+			return p[idx].value, true
+		}
+	}
+
+	return nil, false
+}
+
+type item struct {
+	value  any
+	params params
+}
+
+type innerList struct {
+	items  []item
+	params params
+}
+
+type dictMember struct {
+	key   string
+	value any // either item or innerList
+}
+
+type sfParser struct {
+	in  string
+	pos int
+}
+
+func (p *sfParser) eof() bool { return p.pos >= len(p.in) }
+
+func (p *sfParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.in[p.pos]
+}
+
+func (p *sfParser) skipSP() {
+	for !p.eof() && p.in[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *sfParser) skipOWS() {
+	for !p.eof() && (p.in[p.pos] == ' ' || p.in[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *sfParser) error(msg string) error {
+	return errorchain.NewWithMessagef(ErrMalformedSignature, "%s at position %d", msg, p.pos)
+}
+
+func parseDictionary(value string) ([]dictMember, error) {
+	p := &sfParser{in: value}
+
+	var members []dictMember
+
+	p.skipSP()
+
+	for !p.eof() {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		var member any
+
+		if p.peek() == '=' {
+			p.pos++
+
+			member, err = p.parseItemOrInnerList()
+		} else {
+			var prms params
+
+			prms, err = p.parseParams()
+			member = item{value: true, params: prms}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, dictMember{key: key, value: member})
+
+		p.skipOWS()
+
+		if p.eof() {
+			return members, nil
+		}
+
+		if p.peek() != ',' {
+			return nil, p.error("expected comma")
+		}
+
+		p.pos++
+		p.skipOWS()
+
+		if p.eof() {
+			return nil, p.error("trailing comma")
+		}
+	}
+
+	return members, nil
+}
+
+func parseItem(value string) (item, error) {
+	p := &sfParser{in: value}
+
+	p.skipSP()
+
+	itm, err := p.parseItem()
+	if err != nil {
+		return item{}, err
+	}
+
+	p.skipSP()
+
+	if !p.eof() {
+		return item{}, p.error("unexpected characters")
+	}
+
+	return itm, nil
+}
+
+func (p *sfParser) parseItemOrInnerList() (any, error) {
+	if p.peek() == '(' {
+		return p.parseInnerList()
+	}
+
+	return p.parseItem()
+}
+
+func (p *sfParser) parseInnerList() (innerList, error) {
+	var list innerList
+
+	// consume '('
+	p.pos++
+
+	for !p.eof() {
+		p.skipSP()
+
+		if p.peek() == ')' {
+			p.pos++
+
+			prms, err := p.parseParams()
+			if err != nil {
+				return innerList{}, err
+			}
+
+			list.params = prms
+
+			return list, nil
+		}
+
+		itm, err := p.parseItem()
+		if err != nil {
+			return innerList{}, err
+		}
+
+		list.items = append(list.items, itm)
+
+		if c := p.peek(); c != ' ' && c != ')' {
+			return innerList{}, p.error("expected space or closing parenthesis")
+		}
+	}
+
+	return innerList{}, p.error("unterminated inner list")
+}
+
+func (p *sfParser) parseItem() (item, error) {
+	value, err := p.parseBareItem()
+	if err != nil {
+		return item{}, err
+	}
+
+	prms, err := p.parseParams()
+	if err != nil {
+		return item{}, err
+	}
+
+	return item{value: value, params: prms}, nil
+}
+
+func (p *sfParser) parseParams() (params, error) {
+	var prms params
+
+	for p.peek() == ';' {
+		p.pos++
+		p.skipSP()
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		var value any = true
+
+		if p.peek() == '=' {
+			p.pos++
+
+			if value, err = p.parseBareItem(); err != nil {
+				return nil, err
+			}
+		}
+
+		prms = append(prms, param{key: key, value: value})
+	}
+
+	return prms, nil
+}
+
+func (p *sfParser) parseKey() (string, error) {
+	start := p.pos
+
+	if c := p.peek(); !isLCAlpha(c) && c != '*' {
+		return "", p.error("invalid key")
+	}
+
+	for !p.eof() {
+		c := p.in[p.pos]
+		if !isLCAlpha(c) && !isDigit(c) && c != '_' && c != '-' && c != '.' && c != '*' {
+			break
+		}
+
+		p.pos++
+	}
+
+	return p.in[start:p.pos], nil
+}
+
+func (p *sfParser) parseBareItem() (any, error) {
+	switch c := p.peek(); {
+	case c == '-' || isDigit(c):
+		return p.parseInteger()
+	case c == '"':
+		return p.parseString()
+	case c == '*' || isAlpha(c):
+		return p.parseToken(), nil
+	case c == ':':
+		return p.parseByteSequence()
+	case c == '?':
+		return p.parseBoolean()
+	default:
+		return nil, p.error("unexpected item")
+	}
+}
+
+func (p *sfParser) parseInteger() (int64, error) {
+	start := p.pos
+
+	if p.peek() == '-' {
+		p.pos++
+	}
+
+	for !p.eof() && isDigit(p.in[p.pos]) {
+		p.pos++
+	}
+
+	if p.peek() == '.' {
+		return 0, p.error("decimals are not supported")
+	}
+
+	value, err := strconv.ParseInt(p.in[start:p.pos], 10, 64)
+	if err != nil || p.pos-start > 15 { //nolint:gomnd
+		return 0, p.error("invalid integer")
+	}
+
+	return value, nil
+}
+
+func (p *sfParser) parseString() (string, error) {
+	var buf strings.Builder
+
+	// consume '"'
+	p.pos++
+
+	for !p.eof() {
+		c := p.in[p.pos]
+		p.pos++
+
+		switch {
+		case c == '\\':
+			if p.eof() || (p.in[p.pos] != '"' && p.in[p.pos] != '\\') {
+				return "", p.error("invalid escape sequence")
+			}
+
+			buf.WriteByte(p.in[p.pos])
+			p.pos++
+		case c == '"':
+			return buf.String(), nil
+		case c < 0x20 || c > 0x7e:
+			return "", p.error("invalid character in string")
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return "", p.error("unterminated string")
+}
+
+func (p *sfParser) parseToken() token {
+	start := p.pos
+
+	p.pos++
+
+	for !p.eof() && (isTChar(p.in[p.pos]) || p.in[p.pos] == ':' || p.in[p.pos] == '/') {
+		p.pos++
+	}
+
+	return token(p.in[start:p.pos])
+}
+
+func (p *sfParser) parseByteSequence() ([]byte, error) {
+	// consume ':'
+	p.pos++
+
+	end := strings.IndexByte(p.in[p.pos:], ':')
+	if end == -1 {
+		return nil, p.error("unterminated byte sequence")
+	}
+
+	value, err := base64.StdEncoding.DecodeString(p.in[p.pos : p.pos+end])
+	if err != nil {
+		return nil, p.error("invalid byte sequence")
+	}
+
+	p.pos += end + 1
+
+	return value, nil
+}
+
+func (p *sfParser) parseBoolean() (bool, error) {
+	// consume '?'
+	p.pos++
+
+	switch p.peek() {
+	case '1':
+		p.pos++
+
+		return true, nil
+	case '0':
+		p.pos++
+
+		return false, nil
+	default:
+		return false, p.error("invalid boolean")
+	}
+}
+
+func serializeInnerList(list innerList) string {
+	var buf strings.Builder
+
+	buf.WriteByte('(')
+
+	for idx, itm := range list.items {
+		if idx != 0 {
+			buf.WriteByte(' ')
+		}
+
+		buf.WriteString(serializeItem(itm))
+	}
+
+	buf.WriteByte(')')
+	buf.WriteString(serializeParams(list.params))
+
+	return buf.String()
+}
+
+func serializeItem(itm item) string {
+	return serializeBareItem(itm.value) + serializeParams(itm.params)
+}
+
+func serializeParams(prms params) string {
+	var buf strings.Builder
+
+	for _, prm := range prms {
+		buf.WriteByte(';')
+		buf.WriteString(prm.key)
+
+		if value, ok := prm.value.(bool); ok && value {
+			continue
+		}
+
+		buf.WriteByte('=')
+		buf.WriteString(serializeBareItem(prm.value))
+	}
+
+	return buf.String()
+}
+
+func serializeBareItem(value any) string {
+	switch val := value.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case string:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(val) + `"`
+	case token:
+		return string(val)
+	case []byte:
+		return ":" + base64.StdEncoding.EncodeToString(val) + ":"
+	case bool:
+		if val {
+			return "?1"
+		}
+
+		return "?0"
+	default:
+		return ""
+	}
+}
+
+func isLCAlpha(c byte) bool { return c >= 'a' && c <= 'z' }
+
+func isAlpha(c byte) bool { return isLCAlpha(c) || (c >= 'A' && c <= 'Z') }
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isTChar(c byte) bool {
+	return isAlpha(c) || isDigit(c) || strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1
+}
@@ -0,0 +1,34 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import "context"
+
+type trustedProxyKey struct{}
+
+// WithTrustedProxy marks the request the returned context belongs to as received from a trusted proxy.
+func WithTrustedProxy(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedProxyKey{}, true)
+}
+
+// FromTrustedProxy returns whether the request the given context belongs to has been received
+// from a trusted proxy.
+func FromTrustedProxy(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedProxyKey{}).(bool)
+
+	return trusted
+}
@@ -0,0 +1,215 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package radixtree
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tree is a radix tree, which associates values with string keys. Multiple values
+// can be stored under the same key. In addition to the usual add and delete
+// operations, the tree allows looking up all values, which have been stored under
+// keys being a prefix of a given string. This is what makes it useful as an index
+// over the static prefixes of patterns.
+//
+// Tree is not safe for concurrent use.
+type Tree[V any] struct {
+	root node[V]
+	size int
+}
+
+type node[V any] struct {
+	prefix   string
+	children []*node[V]
+	values   []V
+}
+
+func New[V any]() *Tree[V] {
+	return &Tree[V]{}
+}
+
+// Len returns the number of values stored in the tree.
+func (t *Tree[V]) Len() int { return t.size }
+
+// Add stores the value under the given key.
+func (t *Tree[V]) Add(key string, value V) {
+	t.root.add(key, value)
+	t.size++
+}
+
+// Delete removes all values stored under the given key, for which the matches function
+// returns true. It returns the amount of removed values.
+func (t *Tree[V]) Delete(key string, matches func(value V) bool) int {
+	removed := t.root.delete(key, matches)
+	t.size -= removed
+
+	return removed
+}
+
+// FindAll returns all values stored under keys, which are prefixes of the given value.
+// The values are returned in the order of their key length, starting with the values
+// of the shortest key (which is the empty key if present). Values stored under the same
+// key are returned in the order they have been added.
+func (t *Tree[V]) FindAll(value string) []V {
+	var result []V
+
+	nd := &t.root
+
+	for {
+		result = append(result, nd.values...)
+
+		if len(value) == 0 {
+			return result
+		}
+
+		child := nd.child(value[0])
+		if child == nil || !strings.HasPrefix(value, child.prefix) {
+			return result
+		}
+
+		value = value[len(child.prefix):]
+		nd = child
+	}
+}
+
+func (n *node[V]) add(key string, value V) {
+	nd := n
+
+	for {
+		if len(key) == 0 {
+			nd.values = append(nd.values, value)
+
+			return
+		}
+
+		child := nd.child(key[0])
+		if child == nil {
+			nd.insertChild(&node[V]{prefix: key, values: []V{value}})
+
+			return
+		}
+
+		common := commonPrefixLength(key, child.prefix)
+		if common < len(child.prefix) {
+			// split the child, so that the common part becomes a node on its own
+			split := &node[V]{
+				prefix:   child.prefix[common:],
+				children: child.children,
+				values:   child.values,
+			}
+
+			child.prefix = child.prefix[:common]
+			child.children = []*node[V]{split}
+			child.values = nil
+		}
+
+		key = key[common:]
+		nd = child
+	}
+}
+
+func (n *node[V]) delete(key string, matches func(value V) bool) int {
+	if len(key) == 0 {
+		remaining := n.values[:0]
+
+		for _, value := range n.values {
+			if !matches(value) {
+				remaining = append(remaining, value)
+			}
+		}
+
+		removed := len(n.values) - len(remaining)
+
+		// clear the no longer used tail to not keep references to deleted values
+		clear(n.values[len(remaining):])
+
+		n.values = remaining
+		if len(n.values) == 0 {
+			n.values = nil
+		}
+
+		return removed
+	}
+
+	child := n.child(key[0])
+	if child == nil || !strings.HasPrefix(key, child.prefix) {
+		return 0
+	}
+
+	removed := child.delete(key[len(child.prefix):], matches)
+	if removed == 0 {
+		return 0
+	}
+
+	switch {
+	case len(child.values) == 0 && len(child.children) == 0:
+		n.removeChild(child)
+	case len(child.values) == 0 && len(child.children) == 1:
+		// merge the child with its only child to keep the tree compact
+		grandChild := child.children[0]
+		child.prefix += grandChild.prefix
+		child.children = grandChild.children
+		child.values = grandChild.values
+	}
+
+	return removed
+}
+
+func (n *node[V]) child(label byte) *node[V] {
+	idx := sort.Search(len(n.children), func(i int) bool { return n.children[i].prefix[0] >= label })
+	if idx < len(n.children) && n.children[idx].prefix[0] == label {
+		return n.children[idx]
+	}
+
+	return nil
+}
+
+func (n *node[V]) insertChild(child *node[V]) {
+	label := child.prefix[0]
+	idx := sort.Search(len(n.children), func(i int) bool { return n.children[i].prefix[0] >= label })
+
+	n.children = append(n.children, nil)
+	copy(n.children[idx+1:], n.children[idx:])
+	n.children[idx] = child
+}
+
+func (n *node[V]) removeChild(child *node[V]) {
+	for idx, existing := range n.children {
+		if existing == child {
+			n.children = append(n.children[:idx], n.children[idx+1:]...)
+
+			break
+		}
+	}
+
+	if len(n.children) == 0 {
+		n.children = nil
+	}
+}
+
+func commonPrefixLength(a, b string) int {
+	maxLen := min(len(a), len(b))
+
+	for i := range maxLen {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return maxLen
+}
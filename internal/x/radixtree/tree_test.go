@@ -0,0 +1,106 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package radixtree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeFindAll(t *testing.T) {
+	t.Parallel()
+
+	tree := New[string]()
+	tree.Add("", "any")
+	tree.Add("http://foo.bar/", "foo")
+	tree.Add("http://foo.bar/api/", "api")
+	tree.Add("http://foo.bar/api/", "api2")
+	tree.Add("http://foo.baz/", "baz")
+	tree.Add("https://", "https")
+
+	for _, tc := range []struct {
+		uc       string
+		value    string
+		expected []string
+	}{
+		{uc: "empty value", value: "", expected: []string{"any"}},
+		{uc: "no specific match", value: "ftp://foo.bar/", expected: []string{"any"}},
+		{uc: "match of a split node", value: "http://foo.bar/index.html", expected: []string{"any", "foo"}},
+		{
+			uc:       "match of nested keys",
+			value:    "http://foo.bar/api/v1",
+			expected: []string{"any", "foo", "api", "api2"},
+		},
+		{uc: "match of a sibling", value: "http://foo.baz/api/v1", expected: []string{"any", "baz"}},
+		{uc: "value shorter than the key", value: "http://foo.ba", expected: []string{"any"}},
+		{uc: "another branch", value: "https://foo.bar/api/v1", expected: []string{"any", "https"}},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			result := tree.FindAll(tc.value)
+
+			// THEN
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestTreeDelete(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	tree := New[string]()
+	tree.Add("http://foo.bar/", "foo")
+	tree.Add("http://foo.bar/api/", "api")
+	tree.Add("http://foo.bar/api/", "api2")
+	tree.Add("http://foo.baz/", "baz")
+
+	// WHEN
+	removed := tree.Delete("http://foo.bar/api/", func(value string) bool { return value == "api" })
+
+	// THEN
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 3, tree.Len())
+	assert.Equal(t, []string{"foo", "api2"}, tree.FindAll("http://foo.bar/api/v1"))
+
+	// WHEN
+	removed = tree.Delete("http://foo.bar/", func(string) bool { return true })
+
+	// THEN
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 2, tree.Len())
+	assert.Equal(t, []string{"api2"}, tree.FindAll("http://foo.bar/api/v1"))
+	assert.Equal(t, []string{"baz"}, tree.FindAll("http://foo.baz/api/v1"))
+
+	// WHEN
+	removed = tree.Delete("http://foo.ba", func(string) bool { return true })
+
+	// THEN
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, 2, tree.Len())
+
+	// WHEN
+	removed = tree.Delete("http://foo.bar/api/", func(string) bool { return true })
+	removed += tree.Delete("http://foo.baz/", func(string) bool { return true })
+
+	// THEN
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, 0, tree.Len())
+	assert.Empty(t, tree.FindAll("http://foo.baz/api/v1"))
+	assert.Empty(t, tree.root.children)
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syncx
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DoDetached executes fn only once for all concurrent callers using the same key. As the
+// execution is shared, it is not bound to the context of the caller, which happened to trigger
+// it. Instead, fn receives a context, which is not canceled together with the one of that
+// caller and times out after the given duration. A caller, whose context is done before the
+// execution completes, stops waiting for it and receives the error of its context.
+func DoDetached[T any](
+	ctx context.Context,
+	group *singleflight.Group,
+	key string,
+	timeout time.Duration,
+	fn func(ctx context.Context) (T, error),
+) (T, error) {
+	var zero T
+
+	resCh := group.DoChan(key, func() (any, error) {
+		detachedCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+
+		return fn(detachedCtx)
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-resCh:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+
+		return res.Val.(T), nil // nolint: forcetypeassert
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syncx
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/singleflight"
+)
+
+func TestDoDetached(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	for _, tc := range []struct {
+		uc     string
+		fn     func(ctx context.Context) (string, error)
+		assert func(t *testing.T, err error, value string)
+	}{
+		{
+			uc: "successful execution",
+			fn: func(context.Context) (string, error) { return "foo", nil },
+			assert: func(t *testing.T, err error, value string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", value)
+			},
+		},
+		{
+			uc: "failed execution",
+			fn: func(context.Context) (string, error) { return "", errTest },
+			assert: func(t *testing.T, err error, value string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errTest)
+				assert.Empty(t, value)
+			},
+		},
+		{
+			uc: "execution times out",
+			fn: func(ctx context.Context) (string, error) {
+				<-ctx.Done()
+
+				return "", ctx.Err()
+			},
+			assert: func(t *testing.T, err error, value string) {
+				t.Helper()
+
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+				assert.Empty(t, value)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			value, err := DoDetached(context.Background(), &singleflight.Group{}, "key", 50*time.Millisecond, tc.fn)
+
+			// THEN
+			tc.assert(t, err, value)
+		})
+	}
+}
+
+func TestDoDetachedWithCanceledCaller(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var calls atomic.Int32
+
+	group := &singleflight.Group{}
+	fn := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+			return "foo", nil
+		}
+	}
+
+	canceledCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// WHEN
+	_, err1 := DoDetached(canceledCtx, group, "key", time.Second, fn)
+	value, err2 := DoDetached(context.Background(), group, "key", time.Second, fn)
+
+	// THEN
+	require.ErrorIs(t, err1, context.DeadlineExceeded)
+	require.NoError(t, err2)
+	assert.Equal(t, "foo", value)
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,211 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tlsx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var ErrClientCertificateNotAllowed = errors.New("client certificate not allowed")
+
+type clientCAs struct {
+	path string
+
+	pool *x509.CertPool
+	mut  sync.Mutex
+}
+
+func newClientCAs(path string) (*clientCAs, error) {
+	cas := &clientCAs{path: path}
+
+	if err := cas.load(); err != nil {
+		return nil, err
+	}
+
+	return cas, nil
+}
+
+func (ca *clientCAs) load() error {
+	if len(ca.path) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"no path to the trust store for client authentication specified")
+	}
+
+	ts, err := truststore.NewTrustStoreFromPEMFile(ca.path, false)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed loading trust store for client authentication").CausedBy(err)
+	}
+
+	if len(ts) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"trust store for client authentication does not contain any certificates")
+	}
+
+	ca.mut.Lock()
+	ca.pool = ts.CertPool()
+	ca.mut.Unlock()
+
+	return nil
+}
+
+func (ca *clientCAs) certPool() *x509.CertPool {
+	ca.mut.Lock()
+	defer ca.mut.Unlock()
+
+	return ca.pool
+}
+
+func (ca *clientCAs) OnChanged(log zerolog.Logger) {
+	err := ca.load()
+	if err != nil {
+		log.Warn().Err(err).
+			Str("_file", ca.path).
+			Msg("Client authentication trust store reload failed")
+	} else {
+		log.Info().
+			Str("_file", ca.path).
+			Msg("Client authentication trust store reloaded")
+	}
+}
+
+func configureClientAuth(cfg *tls.Config, conf *config.ClientAuth, cw watcher.Watcher) error {
+	var clientAuth tls.ClientAuthType
+
+	switch conf.ModeOrDefault() {
+	case config.ClientAuthNone:
+		return nil
+	case config.ClientAuthRequest:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case config.ClientAuthRequire:
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported client authentication mode '%s'", conf.Mode)
+	}
+
+	cas, err := newClientCAs(conf.TrustStore.Path)
+	if err != nil {
+		return err
+	}
+
+	if cw != nil {
+		if err = cw.Add(cas.path, cas); err != nil {
+			return err
+		}
+	}
+
+	cfg.ClientAuth = clientAuth
+	cfg.ClientCAs = cas.certPool()
+
+	if !conf.AllowedSANs.IsEmpty() {
+		cfg.VerifyConnection = verifyAllowedSANs(conf.AllowedSANs)
+	}
+
+	// the trust store can be reloaded. So the current trust anchors must be
+	// used for every new handshake
+	cfg.GetConfigForClient = func(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+		clientCfg := cfg.Clone()
+		clientCfg.ClientCAs = cas.certPool()
+
+		return clientCfg, nil
+	}
+
+	return nil
+}
+
+// verifyAllowedSANs returns a function, which checks whether the verified client certificate has at
+// least one subject alternative name from the given allow lists. DNS names can be specified using
+// a leading wildcard label, like *.example.com, which matches exactly one label.
+func verifyAllowedSANs(allowed config.AllowedSANs) func(cs tls.ConnectionState) error {
+	allowedIPs := make([]net.IP, 0, len(allowed.IPAddresses))
+	for _, ip := range allowed.IPAddresses {
+		if parsed := net.ParseIP(ip); parsed != nil {
+			allowedIPs = append(allowedIPs, parsed)
+		}
+	}
+
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			// no certificate presented and not required
+			return nil
+		}
+
+		cert := cs.PeerCertificates[0]
+
+		for _, name := range cert.DNSNames {
+			for _, pattern := range allowed.DNSNames {
+				if matchDNSName(pattern, name) {
+					return nil
+				}
+			}
+		}
+
+		for _, ip := range cert.IPAddresses {
+			for _, allowedIP := range allowedIPs {
+				if allowedIP.Equal(ip) {
+					return nil
+				}
+			}
+		}
+
+		for _, email := range cert.EmailAddresses {
+			for _, allowedEmail := range allowed.EmailAddresses {
+				if strings.EqualFold(allowedEmail, email) {
+					return nil
+				}
+			}
+		}
+
+		for _, uri := range cert.URIs {
+			for _, allowedURI := range allowed.URIs {
+				if allowedURI == uri.String() {
+					return nil
+				}
+			}
+		}
+
+		return errorchain.NewWithMessagef(ErrClientCertificateNotAllowed,
+			"no subject alternative name of '%s' is allowed", cert.Subject.String())
+	}
+}
+
+func matchDNSName(pattern, name string) bool {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	if suffix, found := strings.CutPrefix(pattern, "*."); found {
+		label, rest, ok := strings.Cut(name, ".")
+
+		return ok && len(label) != 0 && rest == suffix
+	}
+
+	return pattern == name
+}
@@ -0,0 +1,359 @@
+// Copyright 2024 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tlsx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestMatchDNSName(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		pattern string
+		name    string
+		matches bool
+	}{
+		{pattern: "foo.example.com", name: "foo.example.com", matches: true},
+		{pattern: "foo.example.com", name: "FOO.example.com.", matches: true},
+		{pattern: "foo.example.com", name: "bar.example.com"},
+		{pattern: "*.example.com", name: "foo.example.com", matches: true},
+		{pattern: "*.example.com", name: "foo.bar.example.com"},
+		{pattern: "*.example.com", name: "example.com"},
+		{pattern: "*.example.com", name: ".example.com"},
+	} {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.matches, matchDNSName(tc.pattern, tc.name))
+		})
+	}
+}
+
+func TestVerifyAllowedSANs(t *testing.T) {
+	t.Parallel()
+
+	spiffeID, err := url.Parse("spiffe://example.org/ns/default/sa/foo")
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "foo"},
+		DNSNames:       []string{"foo.example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		EmailAddresses: []string{"foo@example.com"},
+		URIs:           []*url.URL{spiffeID},
+	}
+
+	for _, tc := range []struct {
+		uc         string
+		allowed    config.AllowedSANs
+		certs      []*x509.Certificate
+		expAllowed bool
+	}{
+		{
+			uc:         "no certificate presented",
+			allowed:    config.AllowedSANs{DNSNames: []string{"bar.example.com"}},
+			expAllowed: true,
+		},
+		{
+			uc:         "allowed dns name",
+			allowed:    config.AllowedSANs{DNSNames: []string{"bar.example.com", "*.example.com"}},
+			certs:      []*x509.Certificate{cert},
+			expAllowed: true,
+		},
+		{
+			uc:         "allowed ip address",
+			allowed:    config.AllowedSANs{IPAddresses: []string{"foo", "10.0.0.1"}},
+			certs:      []*x509.Certificate{cert},
+			expAllowed: true,
+		},
+		{
+			uc:         "allowed email address",
+			allowed:    config.AllowedSANs{EmailAddresses: []string{"Foo@Example.com"}},
+			certs:      []*x509.Certificate{cert},
+			expAllowed: true,
+		},
+		{
+			uc:         "allowed uri",
+			allowed:    config.AllowedSANs{URIs: []string{"spiffe://example.org/ns/default/sa/foo"}},
+			certs:      []*x509.Certificate{cert},
+			expAllowed: true,
+		},
+		{
+			uc: "no subject alternative name allowed",
+			allowed: config.AllowedSANs{
+				DNSNames:       []string{"bar.example.com"},
+				IPAddresses:    []string{"10.0.0.2"},
+				EmailAddresses: []string{"bar@example.com"},
+				URIs:           []string{"spiffe://example.org/ns/default/sa/bar"},
+			},
+			certs: []*x509.Certificate{cert},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			verify := verifyAllowedSANs(tc.allowed)
+
+			// WHEN
+			err := verify(tls.ConnectionState{PeerCertificates: tc.certs})
+
+			// THEN
+			if tc.expAllowed {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrClientCertificateNotAllowed)
+			}
+		})
+	}
+}
+
+func TestClientCAsOnChanged(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ca1, err := testsupport.NewRootCA("Test CA 1", time.Hour)
+	require.NoError(t, err)
+
+	ca2, err := testsupport.NewRootCA("Test CA 2", time.Hour)
+	require.NoError(t, err)
+
+	pemBytes1, err := pemx.BuildPEM(pemx.WithX509Certificate(ca1.Certificate))
+	require.NoError(t, err)
+
+	pemBytes2, err := pemx.BuildPEM(pemx.WithX509Certificate(ca2.Certificate))
+	require.NoError(t, err)
+
+	trustStoreFile := filepath.Join(t.TempDir(), "truststore.pem")
+	require.NoError(t, os.WriteFile(trustStoreFile, pemBytes1, 0o600))
+
+	cas, err := newClientCAs(trustStoreFile)
+	require.NoError(t, err)
+
+	pool1 := x509.NewCertPool()
+	pool1.AddCert(ca1.Certificate)
+
+	pool2 := x509.NewCertPool()
+	pool2.AddCert(ca2.Certificate)
+
+	require.True(t, pool1.Equal(cas.certPool()))
+
+	// WHEN
+	require.NoError(t, os.WriteFile(trustStoreFile, pemBytes2, 0o600))
+	cas.OnChanged(log.Logger)
+
+	// THEN
+	require.True(t, pool2.Equal(cas.certPool()))
+
+	// WHEN
+	require.NoError(t, os.Remove(trustStoreFile))
+	cas.OnChanged(log.Logger)
+
+	// THEN
+	require.True(t, pool2.Equal(cas.certPool()))
+}
+
+func TestClientAuthentication(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	testDir := t.TempDir()
+
+	ca, err := testsupport.NewRootCA("Test CA", time.Hour)
+	require.NoError(t, err)
+
+	otherCA, err := testsupport.NewRootCA("Other CA", time.Hour)
+	require.NoError(t, err)
+
+	issue := func(issuer *testsupport.CA, cn string, usage x509.ExtKeyUsage) tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		cert, err := issuer.IssueCertificate(
+			testsupport.WithSubject(pkix.Name{CommonName: cn}),
+			testsupport.WithValidity(time.Now(), time.Hour),
+			testsupport.WithSubjectPubKey(&key.PublicKey, x509.ECDSAWithSHA384),
+			testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+			testsupport.WithExtendedKeyUsage(usage),
+			testsupport.WithDNSNames([]string{cn}))
+		require.NoError(t, err)
+
+		return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key, Leaf: cert}
+	}
+
+	serverCert := issue(ca, "heimdall", x509.ExtKeyUsageServerAuth)
+	fooCert := issue(ca, "foo.example.com", x509.ExtKeyUsageClientAuth)
+	barCert := issue(ca, "bar.example.com", x509.ExtKeyUsageClientAuth)
+	untrustedCert := issue(otherCA, "foo.example.com", x509.ExtKeyUsageClientAuth)
+
+	keyStoreBytes, err := pemx.BuildPEM(
+		pemx.WithECDSAPrivateKey(serverCert.PrivateKey.(*ecdsa.PrivateKey)), //nolint:forcetypeassert
+		pemx.WithX509Certificate(serverCert.Leaf),
+	)
+	require.NoError(t, err)
+
+	keyStoreFile := filepath.Join(testDir, "keystore.pem")
+	require.NoError(t, os.WriteFile(keyStoreFile, keyStoreBytes, 0o600))
+
+	trustStoreBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(ca.Certificate))
+	require.NoError(t, err)
+
+	trustStoreFile := filepath.Join(testDir, "truststore.pem")
+	require.NoError(t, os.WriteFile(trustStoreFile, trustStoreBytes, 0o600))
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.Certificate)
+
+	for _, tc := range []struct {
+		uc         string
+		mode       string
+		clientCert *tls.Certificate
+		assert     func(t *testing.T, err error, state tls.ConnectionState)
+	}{
+		{
+			uc:         "required client certificate presented",
+			mode:       config.ClientAuthRequire,
+			clientCert: &fooCert,
+			assert: func(t *testing.T, err error, state tls.ConnectionState) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, state.VerifiedChains, 1)
+				require.Len(t, state.VerifiedChains[0], 2)
+				assert.True(t, fooCert.Leaf.Equal(state.VerifiedChains[0][0]))
+				assert.True(t, ca.Certificate.Equal(state.VerifiedChains[0][1]))
+			},
+		},
+		{
+			uc:   "required client certificate not presented",
+			mode: config.ClientAuthRequire,
+			assert: func(t *testing.T, err error, _ tls.ConnectionState) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		{
+			uc:         "client certificate from an untrusted ca",
+			mode:       config.ClientAuthRequest,
+			clientCert: &untrustedCert,
+			assert: func(t *testing.T, err error, _ tls.ConnectionState) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		{
+			uc:         "client certificate with not allowed SANs",
+			mode:       config.ClientAuthRequire,
+			clientCert: &barCert,
+			assert: func(t *testing.T, err error, _ tls.ConnectionState) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrClientCertificateNotAllowed)
+			},
+		},
+		{
+			uc:   "requested client certificate not presented",
+			mode: config.ClientAuthRequest,
+			assert: func(t *testing.T, err error, state tls.ConnectionState) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, state.PeerCertificates)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			serverCfg, err := ToTLSConfig(&config.TLS{
+				KeyStore: config.KeyStore{Path: keyStoreFile},
+				ClientAuth: &config.ClientAuth{
+					Mode:        tc.mode,
+					TrustStore:  config.TrustStore{Path: trustStoreFile},
+					AllowedSANs: config.AllowedSANs{DNSNames: []string{"foo.example.com"}},
+				},
+			}, WithServerAuthentication(true))
+			require.NoError(t, err)
+
+			clientCfg := &tls.Config{
+				RootCAs:    rootCAs,
+				ServerName: "heimdall",
+				MinVersion: tls.VersionTLS13,
+				// the client certificate is presented even if not issued by one of the CAs
+				// accepted by the server to let the verification happen on the server side
+				GetClientCertificate: func(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					if tc.clientCert != nil {
+						return tc.clientCert, nil
+					}
+
+					return &tls.Certificate{}, nil
+				},
+			}
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			defer ln.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			go func() {
+				conn, err := (&tls.Dialer{Config: clientCfg}).DialContext(ctx, "tcp", ln.Addr().String())
+				if err != nil {
+					return
+				}
+
+				// with TLS 1.3 the client learns about rejected certificates only after the handshake
+				_, _ = conn.Read(make([]byte, 1))
+				conn.Close()
+			}()
+
+			conn, err := ln.Accept()
+			require.NoError(t, err)
+
+			defer conn.Close()
+
+			server := tls.Server(conn, serverCfg)
+
+			// WHEN
+			err = server.HandshakeContext(ctx)
+
+			// THEN
+			tc.assert(t, err, server.ConnectionState())
+		})
+	}
+}
@@ -65,5 +65,11 @@ func ToTLSConfig(tlsCfg *config.TLS, opts ...Option) (*tls.Config, error) {
 		cfg.CipherSuites = tlsCfg.CipherSuites.OrDefault()
 	}
 
+	if args.serverAuthRequired && tlsCfg.ClientAuth != nil {
+		if err = configureClientAuth(cfg, tlsCfg.ClientAuth, args.secretsWatcher); err != nil {
+			return nil, err
+		}
+	}
+
 	return cfg, nil
 }
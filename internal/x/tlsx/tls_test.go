@@ -78,6 +78,13 @@ func TestToTLSConfig(t *testing.T) {
 	_, err = pemFile.Write(pemBytes)
 	require.NoError(t, err)
 
+	trustStoreBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(cert))
+	require.NoError(t, err)
+
+	trustStoreFile := filepath.Join(testDir, "truststore.pem")
+	err = os.WriteFile(trustStoreFile, trustStoreBytes, 0o600)
+	require.NoError(t, err)
+
 	for _, tc := range []struct {
 		uc         string
 		conf       func(t *testing.T, wm *mocks.WatcherMock) config.TLS
@@ -303,6 +310,237 @@ func TestToTLSConfig(t *testing.T) {
 				assert.Contains(t, conf.NextProtos, "http/1.1")
 			},
 		},
+		{
+			uc:         "client auth disabled",
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(pemFile.Name(), mock.Anything).Return(nil)
+
+				return config.TLS{
+					KeyStore:   config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{Mode: config.ClientAuthNone},
+				}
+			},
+			assert: func(t *testing.T, err error, conf *tls.Config) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, conf)
+
+				assert.Equal(t, tls.NoClientCert, conf.ClientAuth)
+				assert.Nil(t, conf.ClientCAs)
+				assert.Nil(t, conf.GetConfigForClient)
+			},
+		},
+		{
+			uc:         "client auth with unsupported mode",
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(pemFile.Name(), mock.Anything).Return(nil)
+
+				return config.TLS{
+					KeyStore: config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{
+						Mode:       "foo",
+						TrustStore: config.TrustStore{Path: trustStoreFile},
+					},
+				}
+			},
+			assert: func(t *testing.T, err error, _ *tls.Config) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "unsupported client authentication mode")
+			},
+		},
+		{
+			uc:         "client auth without trust store",
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(pemFile.Name(), mock.Anything).Return(nil)
+
+				return config.TLS{
+					KeyStore:   config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{},
+				}
+			},
+			assert: func(t *testing.T, err error, _ *tls.Config) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "no path to the trust store")
+			},
+		},
+		{
+			uc:         "client auth with not existing trust store",
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(pemFile.Name(), mock.Anything).Return(nil)
+
+				return config.TLS{
+					KeyStore: config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{
+						TrustStore: config.TrustStore{Path: "/no/such/file"},
+					},
+				}
+			},
+			assert: func(t *testing.T, err error, _ *tls.Config) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorContains(t, err, "failed loading trust store")
+			},
+		},
+		{
+			uc:         "client auth with trust store without certificates",
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(pemFile.Name(), mock.Anything).Return(nil)
+
+				keyOnly, err := pemx.BuildPEM(pemx.WithECDSAPrivateKey(privKey2))
+				require.NoError(t, err)
+
+				emptyFile := filepath.Join(t.TempDir(), "empty.pem")
+				require.NoError(t, os.WriteFile(emptyFile, keyOnly, 0o600))
+
+				return config.TLS{
+					KeyStore: config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{
+						TrustStore: config.TrustStore{Path: emptyFile},
+					},
+				}
+			},
+			assert: func(t *testing.T, err error, _ *tls.Config) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "does not contain any certificates")
+			},
+		},
+		{
+			uc:         "client auth with failing watcher registration for the trust store",
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(pemFile.Name(), mock.Anything).Return(nil)
+				wm.EXPECT().Add(trustStoreFile, mock.Anything).Return(errors.New("test error"))
+
+				return config.TLS{
+					KeyStore: config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{
+						TrustStore: config.TrustStore{Path: trustStoreFile},
+					},
+				}
+			},
+			assert: func(t *testing.T, err error, _ *tls.Config) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "test error")
+			},
+		},
+		{
+			uc:         "client auth ignored for TLS client auth",
+			clientAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(pemFile.Name(), mock.Anything).Return(nil)
+
+				return config.TLS{
+					KeyStore: config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{
+						TrustStore: config.TrustStore{Path: trustStoreFile},
+					},
+				}
+			},
+			assert: func(t *testing.T, err error, conf *tls.Config) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, conf)
+
+				assert.Equal(t, tls.NoClientCert, conf.ClientAuth)
+				assert.Nil(t, conf.ClientCAs)
+			},
+		},
+		{
+			uc:         "successful with client auth requested",
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(pemFile.Name(), mock.Anything).Return(nil)
+				wm.EXPECT().Add(trustStoreFile, mock.Anything).Return(nil)
+
+				return config.TLS{
+					KeyStore: config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{
+						Mode:       config.ClientAuthRequest,
+						TrustStore: config.TrustStore{Path: trustStoreFile},
+					},
+				}
+			},
+			assert: func(t *testing.T, err error, conf *tls.Config) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, conf)
+
+				assert.Equal(t, tls.VerifyClientCertIfGiven, conf.ClientAuth)
+				assert.NotNil(t, conf.ClientCAs)
+				assert.NotNil(t, conf.GetConfigForClient)
+				assert.Nil(t, conf.VerifyConnection)
+			},
+		},
+		{
+			uc:         "successful with client auth required by default and allowed SANs",
+			serverAuth: true,
+			conf: func(t *testing.T, wm *mocks.WatcherMock) config.TLS {
+				t.Helper()
+
+				wm.EXPECT().Add(pemFile.Name(), mock.Anything).Return(nil)
+				wm.EXPECT().Add(trustStoreFile, mock.Anything).Return(nil)
+
+				return config.TLS{
+					KeyStore: config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.ClientAuth{
+						TrustStore:  config.TrustStore{Path: trustStoreFile},
+						AllowedSANs: config.AllowedSANs{DNSNames: []string{"foo.example.com"}},
+					},
+				}
+			},
+			assert: func(t *testing.T, err error, conf *tls.Config) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, conf)
+
+				assert.Equal(t, tls.RequireAndVerifyClientCert, conf.ClientAuth)
+				assert.NotNil(t, conf.ClientCAs)
+				assert.NotNil(t, conf.VerifyConnection)
+
+				clientConf, err := conf.GetConfigForClient(&tls.ClientHelloInfo{})
+				require.NoError(t, err)
+				assert.NotSame(t, conf, clientConf)
+				assert.True(t, conf.ClientCAs.Equal(clientConf.ClientCAs))
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// WHEN